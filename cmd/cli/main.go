@@ -0,0 +1,251 @@
+// Command codex-team is a command line client for the Codex Agent Team server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"codex-agent-team/pkg/client"
+)
+
+const usage = `Usage: codex-team [flags] <command> [args]
+
+Commands:
+  run "task"     Create a session, decompose it and start execution
+  status <id>    Show a session and its tasks
+  tail <id>      Stream live session events
+  merge <id>     Merge the completed task branches of a session
+
+Flags:
+`
+
+func main() {
+	server := flag.String("server", envOr("CODEX_TEAM_SERVER", "http://localhost:8080"), "Codex Agent Team server URL")
+	jsonOut := flag.Bool("json", false, "Print JSON instead of tables")
+	repoPath := flag.String("repo", "", "Repository path for new sessions (default: server's repo)")
+	follow := flag.Bool("follow", false, "With run: keep streaming events after execution starts")
+	codex := flag.String("codex", "", "With run: registered codex binary for the session (see the server's -codex-binaries)")
+	provider := flag.String("provider", "", "With run: registered model provider for the session")
+	yes := flag.Bool("yes", false, "With run: confirm the execution estimate without prompting")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	c := client.New(*server)
+	out := &printer{json: *jsonOut}
+
+	var err error
+	switch args[0] {
+	case "run":
+		req := client.CreateSessionRequest{
+			UserTask:      strings.Join(args[1:], " "),
+			RepoPath:      *repoPath,
+			Codex:         *codex,
+			ModelProvider: *provider,
+		}
+		err = runSession(ctx, c, out, req, *follow, *yes)
+	case "status":
+		err = showStatus(ctx, c, out, args[1])
+	case "tail":
+		err = tailSession(ctx, c, out, args[1])
+	case "merge":
+		err = mergeSession(ctx, c, out, args[1])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "codex-team: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSession creates a session, decomposes it and starts execution. A plan
+// whose estimate exceeds the server's thresholds only runs when confirm
+// is set.
+func runSession(ctx context.Context, c *client.Client, out *printer, req client.CreateSessionRequest, follow, confirm bool) error {
+	if req.RepoPath != "" {
+		abs, err := filepath.Abs(req.RepoPath)
+		if err != nil {
+			return fmt.Errorf("resolve repo path: %w", err)
+		}
+		req.RepoPath = abs
+	}
+
+	sess, err := c.CreateSession(ctx, req)
+	if err != nil {
+		return err
+	}
+	out.info("Created session %s", sess.ID)
+
+	out.info("Decomposing...")
+	job, err := c.Decompose(ctx, sess.ID)
+	if err != nil {
+		return err
+	}
+	if err := c.WaitJob(ctx, job); err != nil {
+		return err
+	}
+	tasks, err := c.GetTasks(ctx, sess.ID)
+	if err != nil {
+		return err
+	}
+	if !out.json {
+		printTasks(tasks)
+	}
+
+	est, err := c.GetEstimate(ctx, sess.ID)
+	if err != nil {
+		return err
+	}
+	if est.NeedsConfirm && !est.Confirmed {
+		out.info("Estimate: %d tasks, %.0f agent-minutes, ~%d tokens (exceeds %s)",
+			est.Tasks, est.AgentMinutes, est.Tokens, strings.Join(est.Exceeds, ", "))
+		if !confirm {
+			return fmt.Errorf("session %s needs confirmation; rerun with -yes or POST /api/sessions/%s/confirm", sess.ID, sess.ID)
+		}
+		if err := c.Confirm(ctx, sess.ID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.Execute(ctx, sess.ID); err != nil {
+		return err
+	}
+	out.info("Executing session %s", sess.ID)
+
+	if follow {
+		return tailSession(ctx, c, out, sess.ID)
+	}
+	if out.json {
+		return out.emit(map[string]any{"session": sess, "tasks": tasks})
+	}
+	return nil
+}
+
+// showStatus prints a session and its tasks.
+func showStatus(ctx context.Context, c *client.Client, out *printer, id string) error {
+	sess, err := c.GetSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	tasks, err := c.GetTasks(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if out.json {
+		return out.emit(map[string]any{"session": sess, "tasks": tasks})
+	}
+
+	fmt.Printf("Session:  %s\n", sess.ID)
+	fmt.Printf("Status:   %s\n", sess.Status)
+	fmt.Printf("Repo:     %s\n", sess.RepoPath)
+	fmt.Printf("Task:     %s\n\n", sess.UserTask)
+	printTasks(tasks)
+	return nil
+}
+
+// tailSession prints session events as they arrive.
+func tailSession(ctx context.Context, c *client.Client, out *printer, id string) error {
+	return c.Tail(ctx, id, func(ev client.Event) bool {
+		if out.json {
+			_ = out.emit(ev)
+		} else {
+			fmt.Printf("%-28s %s\n", ev.Type, compact(ev.Data))
+		}
+		// Stop once the session reaches a terminal state
+		return ev.Type != "session.merged" && ev.Type != "session.deleted"
+	})
+}
+
+// mergeSession merges the completed task branches of a session.
+func mergeSession(ctx context.Context, c *client.Client, out *printer, id string) error {
+	job, err := c.Merge(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := c.WaitJob(ctx, job); err != nil {
+		return err
+	}
+	if out.json {
+		return out.emit(map[string]string{"status": "merged"})
+	}
+	fmt.Printf("Session %s: merged\n", id)
+	return nil
+}
+
+// printTasks prints tasks as a table.
+func printTasks(tasks []client.Task) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tDEPENDS ON\tBRANCH\tDURATION\tTITLE")
+	for _, t := range tasks {
+		deps := make([]string, 0, len(t.DependsOn))
+		for _, d := range t.DependsOn {
+			deps = append(deps, d.ID)
+		}
+		duration := ""
+		if t.DurationMs > 0 {
+			duration = (time.Duration(t.DurationMs) * time.Millisecond).Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Status, strings.Join(deps, ","), t.BranchName, duration, t.Title)
+	}
+	tw.Flush()
+}
+
+// printer writes either human-readable or JSON output.
+type printer struct {
+	json bool
+}
+
+// info prints a progress line; in JSON mode it goes to stderr.
+func (p *printer) info(format string, args ...any) {
+	if p.json {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		return
+	}
+	fmt.Printf(format+"\n", args...)
+}
+
+// emit prints v as a single JSON line.
+func (p *printer) emit(v any) error {
+	return json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// compact renders raw JSON on one line, truncated for terminal output.
+func compact(data json.RawMessage) string {
+	s := strings.Join(strings.Fields(string(data)), " ")
+	if len(s) > 160 {
+		s = s[:157] + "..."
+	}
+	return s
+}
+
+// envOr returns the environment variable or a fallback.
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"codex-agent-team/internal/bench"
+)
+
+// runBench replays a recorded session under each scheduler and
+// parallelism level and prints the simulated wall-clock times. It returns
+// the exit code.
+func runBench(path, parallels, schedulers string, penalty float64) int {
+	w, err := bench.Load(path)
+	if err != nil {
+		log.Printf("Bench: %v", err)
+		return exitError
+	}
+
+	var levels []int
+	for _, item := range splitList(parallels) {
+		n, err := strconv.Atoi(item)
+		if err != nil || n <= 0 {
+			log.Printf("-bench-parallel: invalid level %q", item)
+			return exitError
+		}
+		levels = append(levels, n)
+	}
+	var scheds []bench.Scheduler
+	for _, name := range splitList(schedulers) {
+		s, err := bench.SchedulerByName(name)
+		if err != nil {
+			log.Printf("-bench-schedulers: %v", err)
+			return exitError
+		}
+		scheds = append(scheds, s)
+	}
+	if len(levels) == 0 || len(scheds) == 0 {
+		log.Printf("Bench: no parallelism levels or schedulers to compare")
+		return exitError
+	}
+
+	var estimated int
+	for _, job := range w.Jobs {
+		if !job.Recorded {
+			estimated++
+		}
+	}
+	fmt.Printf("Session %s: %d tasks (%d with estimated durations)\n", w.SessionID, len(w.Jobs), estimated)
+	if w.Recorded > 0 {
+		fmt.Printf("Recorded wall clock: %s\n", w.Recorded.Round(time.Second))
+	}
+	fmt.Printf("Critical path:       %s\n\n", w.CriticalPath().Round(time.Second))
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARALLEL\tSCHEDULER\tWALL CLOCK\tUTILIZATION\tCONFLICTS")
+	for _, r := range bench.Run(w, scheds, levels, bench.Options{ConflictPenalty: penalty}) {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%.0f%%\t%d\n", r.Parallel, r.Scheduler, r.WallClock.Round(time.Second), r.Utilization*100, r.Conflicts)
+	}
+	tw.Flush()
+	return exitOK
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codex-agent-team/pkg/agentteam"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix prefixes the environment variables that override flags,
+// e.g. CODEX_TEAM_ADDR for -addr and CODEX_TEAM_DOCKER_IMAGE for -docker-image.
+const envPrefix = "CODEX_TEAM_"
+
+// applyConfig fills flags not given on the command line from the
+// environment and then from the config file at path. Precedence is:
+// command-line flag, CODEX_TEAM_* variable, config file, flag default.
+//
+// The config file is a YAML (or JSON) mapping keyed by flag name. Lists
+// are joined with commas and mappings become comma-separated key=value
+// pairs:
+//
+//	addr: ":9090"
+//	max-parallel: 4
+//	cors-origins:
+//	  - https://ci.example.com
+//	codex-roles:
+//	  worker: /opt/codex/worker
+func applyConfig(fs *flag.FlagSet, path string) error {
+	values := make(map[string]any)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for name := range values {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("%s: unknown setting %q", path, name)
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] || f.Name == "config" {
+			return
+		}
+		env := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if v, ok := os.LookupEnv(env); ok {
+			if setErr := fs.Set(f.Name, v); setErr != nil {
+				err = fmt.Errorf("%s: %w", env, setErr)
+			}
+			return
+		}
+		if v, ok := values[f.Name]; ok {
+			s, convErr := configValue(v)
+			if convErr == nil {
+				convErr = fs.Set(f.Name, s)
+			}
+			if convErr != nil {
+				err = fmt.Errorf("%s: %s: %w", path, f.Name, convErr)
+			}
+		}
+	})
+	return err
+}
+
+// configValue renders a decoded YAML value in flag syntax.
+func configValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(v))
+		for _, k := range keys {
+			s, err := configValue(v[k])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, k+"="+s)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+// parseRoleMap parses "role=value,role=value" into a per-role map.
+func parseRoleMap(s string) (map[agentteam.Role]string, error) {
+	m := make(map[agentteam.Role]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		role, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected role=value, got %q", pair)
+		}
+		m[agentteam.Role(strings.TrimSpace(role))] = strings.TrimSpace(value)
+	}
+	return m, nil
+}
+
+// parseNamedMap parses "name=value,name=value" into a map.
+func parseNamedMap(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, pair := range splitList(s) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("expected name=value, got %q", pair)
+		}
+		m[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return m, nil
+}
+
+// loadMCPConfig reads per-role MCP servers from a JSON file of the form
+// {"worker": {"db": {"command": "db-mcp", "args": ["--read-only"]}}}.
+func loadMCPConfig(path string) (map[agentteam.Role]map[string]agentteam.MCPServer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var servers map[agentteam.Role]map[string]agentteam.MCPServer
+	if err := json.Unmarshal(data, &servers); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for role, named := range servers {
+		switch role {
+		case agentteam.RoleOrchestrator, agentteam.RoleWorker, agentteam.RoleMerger:
+		default:
+			return nil, fmt.Errorf("%s: unknown role %q", path, role)
+		}
+		for name, server := range named {
+			if name == "" || strings.ContainsAny(name, ". ") {
+				return nil, fmt.Errorf("%s: %s: invalid server name %q", path, role, name)
+			}
+			if err := server.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %s.%s: %w", path, role, name, err)
+			}
+		}
+	}
+	return servers, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
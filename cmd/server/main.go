@@ -1,23 +1,338 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"codex-agent-team/internal/agent"
 	"codex-agent-team/internal/api"
+	"codex-agent-team/internal/codexrpc"
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/issues"
+	"codex-agent-team/internal/kube"
+	"codex-agent-team/internal/slack"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/teamaccess"
+	"codex-agent-team/internal/tenant"
+	"codex-agent-team/pkg/agentteam"
 )
 
 func main() {
 	// Command line flags
 	addr := flag.String("addr", ":8080", "HTTP server address")
+	grpcAddr := flag.String("grpc-addr", "", "Also serve the gRPC API (pkg/teampb) on this address, e.g. :9090")
 	codexBin := flag.String("codex", "codex2", "Path to codex app-server binary")
-	repoPath := flag.String("repo", ".", "Path to the repository to work on")
+	repoPath := flag.String("repo", ".", "Path to the repository to work on (may be bare, e.g. a server-side mirror)")
 	skipCheck := flag.Bool("skip-check", false, "Skip codex binary check")
+	oneshot := flag.String("oneshot", "", "Run a single user task (decompose, execute, merge) without the HTTP server")
+	reportPath := flag.String("report", "codex-team-report.json", "Report file written by -oneshot (empty to skip)")
+	benchPath := flag.String("bench", "", "Replay a recorded session file or -oneshot report with simulated agents and compare schedulers, then exit")
+	benchParallel := flag.String("bench-parallel", "1,2,3,4,8", "Comma-separated parallelism levels simulated by -bench")
+	benchSchedulers := flag.String("bench-schedulers", "fifo,critical-path,file-overlap", "Comma-separated schedulers compared by -bench")
+	benchPenalty := flag.Float64("bench-conflict-penalty", 0.25, "Extra run time, as a fraction, of a -bench task started next to one sharing its files")
+	dockerImage := flag.String("docker-image", "", "Run agents inside containers from this image (empty to disable)")
+	dockerRoles := flag.String("docker-roles", "worker", "Comma-separated agent roles to containerize")
+	dockerRuntime := flag.String("docker-runtime", "docker", "Container runtime CLI")
+	dockerBin := flag.String("docker-codex", "codex2", "Path to codex binary inside the container image")
+	dockerCPUs := flag.String("docker-cpus", "", "CPU limit per agent container")
+	dockerMemory := flag.String("docker-memory", "", "Memory limit per agent container")
+	dockerNetwork := flag.String("docker-network", "", "Network mode for agent containers (e.g. none)")
+	dockerEnv := flag.String("docker-env", "", "Comma-separated variables for agent containers: NAME passes the host's value (e.g. OPENAI_API_KEY), NAME=value sets one")
+	dockerCodexHome := flag.String("docker-codex-home", "", "Host codex home (auth and config) mounted into agent containers as CODEX_HOME")
+	dockerRoleImages := flag.String("docker-role-images", "", "Per-role container images, e.g. worker=img:full,orchestrator=img:slim (default: -docker-image)")
+	dockerRoleCPUs := flag.String("docker-role-cpus", "", "Per-role CPU limits, e.g. worker=4,merger=1 (default: -docker-cpus)")
+	dockerRoleMemory := flag.String("docker-role-memory", "", "Per-role memory limits, e.g. worker=8g (default: -docker-memory)")
+	k8sImage := flag.String("k8s-image", "", "Run tasks as Kubernetes Jobs using this image (empty to disable)")
+	k8sNamespace := flag.String("k8s-namespace", "default", "Namespace for task Jobs")
+	k8sRemote := flag.String("k8s-remote", "", "Git remote URL reachable from the cluster, used to ship task branches")
+	k8sBackoff := flag.Int("k8s-backoff-limit", 2, "Pod retries before a task Job fails")
+	k8sSecret := flag.String("k8s-secret", "", "Secret exposed as env to task pods (codex/git credentials)")
+	k8sCPU := flag.String("k8s-cpu", "", "CPU request/limit per task pod")
+	k8sMemory := flag.String("k8s-memory", "", "Memory request/limit per task pod")
+	stallTimeout := flag.Duration("stall-timeout", 0, "Treat a turn as stuck after this long without agent activity (0 to disable)")
+	heartbeat := flag.Duration("heartbeat", 0, "Ping agent app-servers this often to detect ones that stopped answering (0 to disable)")
+	heartbeatTimeout := flag.Duration("heartbeat-timeout", 10*time.Second, "Time an app-server has to answer a ping")
+	heartbeatMisses := flag.Int("heartbeat-misses", 2, "Missed pings in a row before an agent counts as unresponsive and its task fails")
+	stallRetries := flag.Int("stall-retries", 1, "Times a stuck turn is interrupted and restarted before failing")
+	humanApproval := flag.Bool("human-approval", false, "Wait for a human to approve agent commands and file changes")
+	approvalTimeout := flag.Duration("approval-timeout", 10*time.Minute, "How long to wait for a human approval (0 waits forever)")
+	approvalDefault := flag.String("approval-default", "decline", "Decision applied when an approval times out (accept or decline)")
+	slackToken := flag.String("slack-token", "", "Slack bot token; posts pending approvals to -slack-channel")
+	slackChannel := flag.String("slack-channel", "", "Slack channel ID for approval messages")
+	slackSecret := flag.String("slack-signing-secret", "", "Slack signing secret for /api/slack/interactions")
+	scheduler := flag.Bool("scheduler", false, "Run the schedules saved through /api/schedules")
+	watchInterval := flag.Duration("watch-interval", 0, "Poll the repositories of /api/watches for new commits this often (0 disables polling)")
+	watchSecret := flag.String("watch-secret", "", "Secret of push webhooks sent to /api/hooks/push; enables the endpoint")
+	githubToken := flag.String("github-token", "", "GitHub token; enables sessions from GitHub issues and report comments")
+	prRemote := flag.String("pr-remote", "origin", "Remote that merges with ?mode=pr-stack push task branches to before opening pull requests (needs -github-token)")
+	githubAPI := flag.String("github-api-url", "https://api.github.com", "GitHub API URL, for GitHub Enterprise")
+	jiraURL := flag.String("jira-url", "", "Jira site URL, e.g. https://example.atlassian.net; enables sessions from Jira issues")
+	jiraUser := flag.String("jira-user", "", "Jira account email for -jira-token (empty for a personal access token)")
+	jiraToken := flag.String("jira-token", "", "Jira API token")
+	linearKey := flag.String("linear-api-key", "", "Linear API key; enables sessions from Linear issues")
+	shareSecret := flag.String("share-secret", "", "Key for signing read-only share links (random per start if empty)")
+	tenantsFile := flag.String("tenants", "", "JSON file declaring tenants; enables multi-tenant mode with per-tenant API keys")
+	configPath := flag.String("config", os.Getenv(envPrefix+"CONFIG"), "YAML (or JSON) config file keyed by flag name; flags and CODEX_TEAM_* env vars take precedence")
+	corsOrigins := flag.String("cors-origins", "*", "Comma-separated origins allowed to call the API")
+	codexBinaries := flag.String("codex-binaries", "", "Codex binaries sessions may select by name, e.g. stable=/opt/codex,next=/opt/codex-next")
+	modelProviders := flag.String("model-providers", "", "Comma-separated model providers (from the codex config) sessions may select")
+	codexRoles := flag.String("codex-roles", "", "Per-role codex binaries, e.g. worker=/opt/codex-worker,merger=/opt/codex")
+	sandbox := flag.String("sandbox", "", "Sandbox mode for worker agents (read-only, workspace-write, danger-full-access)")
+	ephemeralRoles := flag.String("ephemeral-threads", "worker,merger", "Roles whose codex threads are ephemeral and kept out of codex history (empty for none)")
+	mcpConfig := flag.String("mcp-config", "", "JSON file of per-role MCP servers, e.g. {\"worker\": {\"db\": {\"command\": \"db-mcp\"}}}")
+	testCommand := flag.String("test-command", "", "Shell command that runs the repository's tests; offered to workers as the run_tests tool")
+	testTimeout := flag.Duration("test-timeout", 10*time.Minute, "Maximum duration of one run_tests call (0 for no limit)")
+	verifyMerge := flag.Bool("verify-merge", false, "Run -test-command on the merged result and fail the session if it does not pass")
+	fixAttempts := flag.Int("fix-attempts", 2, "Fixer agents tried on the merged result when -verify-merge finds failing tests")
+	octopusThreshold := flag.Int("octopus-threshold", agent.DefaultOctopusThreshold, "Merge more branches than this with one octopus merge first, falling back to one at a time on failure")
+	noOctopus := flag.Bool("no-octopus", false, "Never try octopus merges; merge task branches one at a time")
+	resolveCheck := flag.String("resolve-check", "", "Shell command that must pass after the merger agent resolves conflicts before the merge is committed, e.g. \"go build ./...\"")
+	resolveAttempts := flag.Int("resolve-attempts", agent.DefaultResolveAttempts, "Times the merger agent is asked to resolve each conflicted file before the branch's merge fails")
+	threadApproval := flag.String("approval-policy", "", "Codex approval policy for agent threads (untrusted, on-failure, on-request, never)")
+	maxInFlight := flag.Int("max-inflight-calls", 0, "Maximum outstanding app-server calls per agent; further calls queue (0 for no limit)")
+	maxParallel := flag.Int("max-parallel", 3, "Tasks run at once per session")
+	maxAgents := flag.Int("max-agents", 0, "Tasks run at once across all sessions; waiting tasks start by session priority (0 for no limit)")
+	urgentPriority := flag.Int("urgent-priority", 0, "Session priority at or above which lower-priority sessions start no new tasks while it executes (0 to disable)")
+	codeIndex := flag.Bool("code-index", false, "Index repository symbols to ground task decompositions and check suggested files")
+	maxTasks := flag.Int("max-tasks", 0, "Maximum tasks in a decomposition; larger plans are sent back for rework (0 for no limit)")
+	maxTaskFiles := flag.Int("max-task-files", 0, "Maximum files per decomposed task before it is split (0 for no limit)")
+	maxTaskTime := flag.Duration("max-task-time", 0, "Maximum estimated time per decomposed task before it is split (0 for no limit)")
+	splitRounds := flag.Int("split-rounds", 1, "Follow-up turns asking the orchestrator to split oversize tasks")
+	gateTasks := flag.Int("gate-tasks", 0, "Require confirmation before executing plans with more tasks than this (0 for no gate)")
+	gateMinutes := flag.Float64("gate-agent-minutes", 0, "Require confirmation before executing plans estimated above this many agent-minutes (0 for no gate)")
+	gateTokens := flag.Int64("gate-tokens", 0, "Require confirmation before executing plans estimated above this many tokens (0 for no gate)")
+	tokensPerMinute := flag.Int64("tokens-per-minute", 0, "Tokens an agent is assumed to use per minute when estimating cost (default 20000)")
+	assumeYes := flag.Bool("yes", false, "With -oneshot, confirm the execution estimate without prompting")
+	storage := flag.String("storage", "file", "Session storage backend (file)")
+	dataDir := flag.String("data-dir", "", "Directory for sessions, templates and caches (default: user cache dir)")
+	worktreeRoot := flag.String("worktree-root", "", "Directory for task worktrees (default: <repo>/.worktrees)")
+	targetBranch := flag.String("target-branch", "", "Branch merges go into (default: the checked-out branch); merges into a branch that is not checked out, or into a bare repository, happen in an integration worktree")
+	pushMerged := flag.String("push-merged", "", "Remote to push the target branch to after a successful merge (empty to not push)")
+	worktreeMode := flag.String("worktree-mode", "worktree", "How task checkouts are made: \"worktree\" (git worktree) or \"clone\" (temporary git clone --shared, for old git or filesystems where worktrees misbehave)")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file (PEM); serves HTTPS with -tls-key")
+	tlsKey := flag.String("tls-key", "", "TLS private key file (PEM)")
+	tlsSelfSigned := flag.Bool("tls-self-signed", false, "Serve HTTPS with a generated self-signed certificate (local use)")
+	trustProxy := flag.Bool("trust-proxy", false, "Trust X-Forwarded-For/Proto/Host headers from a reverse proxy")
+	trustedProxies := flag.String("trusted-proxies", "", "Comma-separated proxy IPs/CIDRs; with -trust-proxy, headers are honored only from these and they are skipped when reading X-Forwarded-For (default: the direct peer only)")
+	basePath := flag.String("base-path", "", "Serve under this path prefix, e.g. /codex-team")
+	rateLimit := flag.Float64("rate-limit", api.DefaultLimits.Rate, "API requests per second allowed per client IP or API key (0 to disable)")
+	rateBurst := flag.Int("rate-burst", api.DefaultLimits.Burst, "API requests a client may burst above -rate-limit")
+	spawnLimit := flag.Int("spawn-limit", api.DefaultLimits.SpawnPerMin, "Agent-spawning requests (create, decompose, execute, ...) per minute per client (0 to disable)")
+	maxBody := flag.Int64("max-body", api.DefaultLimits.MaxBodyBytes, "Maximum API request body size in bytes (0 for no limit)")
+	eventsDir := flag.String("events-dir", "", "Append each session's events to <dir>/<session>.jsonl (empty to disable)")
+	webhooks := flag.String("webhooks", "", "Comma-separated URLs that receive every event as a JSON POST (output chunks excluded)")
+	logEvents := flag.Bool("log-events", false, "Log every event (output chunks excluded)")
+	logBuffer := flag.Int("log-buffer", 5000, "Recent log entries kept in memory for session log bundles (0 to disable)")
+	rpcTraceDir := flag.String("rpc-trace-dir", "", "Record every agent's JSON-RPC frames to <dir>/<agent>.jsonl for session log bundles (empty to disable; traces hold prompts and output in full)")
 	flag.Parse()
 
-	// Validate codex binary (unless skipped)
-	if !*skipCheck {
+	if err := applyConfig(flag.CommandLine, *configPath); err != nil {
+		log.Fatalf("Config: %v", err)
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		log.Fatalf("-tls-cert and -tls-key must be given together")
+	}
+	if *benchPath != "" {
+		os.Exit(runBench(*benchPath, *benchParallel, *benchSchedulers, *benchPenalty))
+	}
+	if *storage != "file" {
+		log.Fatalf("-storage: unsupported backend %q", *storage)
+	}
+	if *stallTimeout < 0 || (*stallTimeout > 0 && *stallTimeout < time.Second) {
+		log.Fatalf("-stall-timeout: must be 0 or at least 1s, got %s", *stallTimeout)
+	}
+	if *verifyMerge && *testCommand == "" {
+		log.Fatalf("-verify-merge requires -test-command")
+	}
+	roleBinaries, err := parseRoleMap(*codexRoles)
+	if err != nil {
+		log.Fatalf("-codex-roles: %v", err)
+	}
+	namedBinaries, err := parseNamedMap(*codexBinaries)
+	if err != nil {
+		log.Fatalf("-codex-binaries: %v", err)
+	}
+	if *worktreeMode != "worktree" && *worktreeMode != "clone" {
+		log.Fatalf("-worktree-mode: expected worktree or clone, got %q", *worktreeMode)
+	}
+	opts := agentteam.Options{
+		DataDir:              *dataDir,
+		WorktreeRoot:         *worktreeRoot,
+		CloneMode:            *worktreeMode == "clone",
+		MaxParallel:          *maxParallel,
+		MaxAgents:            *maxAgents,
+		UrgentPriority:       *urgentPriority,
+		RoleBinaries:         roleBinaries,
+		ThreadApprovalPolicy: *threadApproval,
+		CodeIndex:            *codeIndex,
+		PlanLimits: agentteam.PlanLimits{
+			MaxTasks:        *maxTasks,
+			MaxFilesPerTask: *maxTaskFiles,
+			MaxTaskTime:     *maxTaskTime,
+			SplitRounds:     *splitRounds,
+		},
+		CodexBinaries:    namedBinaries,
+		ModelProviders:   splitList(*modelProviders),
+		MaxInFlightCalls: *maxInFlight,
+		Heartbeat: agentteam.HeartbeatPolicy{
+			Interval: *heartbeat,
+			Timeout:  *heartbeatTimeout,
+			Misses:   *heartbeatMisses,
+		},
+		TestCommand: agentteam.TestCommand{
+			Command: *testCommand,
+			Timeout: *testTimeout,
+		},
+		VerifyMerge: *verifyMerge,
+		FixAttempts: *fixAttempts,
+
+		OctopusThreshold: *octopusThreshold,
+		NoOctopus:        *noOctopus,
+		ResolveAttempts:  *resolveAttempts,
+		PRRemote:         *prRemote,
+		TargetBranch:     *targetBranch,
+		PushRemote:       *pushMerged,
+		ResolveCheck: agentteam.TestCommand{
+			Command: *resolveCheck,
+			Timeout: *testTimeout,
+		},
+		Gate: agentteam.ExecutionGate{
+			MaxTasks:        *gateTasks,
+			MaxAgentMinutes: *gateMinutes,
+			MaxTokens:       *gateTokens,
+			TokensPerMinute: *tokensPerMinute,
+		},
+	}
+	opts.RoleEphemeral = map[agentteam.Role]bool{
+		agentteam.RoleOrchestrator: false,
+		agentteam.RoleWorker:       false,
+		agentteam.RoleMerger:       false,
+	}
+	for _, role := range splitList(*ephemeralRoles) {
+		if _, ok := opts.RoleEphemeral[agentteam.Role(role)]; !ok {
+			log.Fatalf("-ephemeral-threads: unknown role %q", role)
+		}
+		opts.RoleEphemeral[agentteam.Role(role)] = true
+	}
+	if *mcpConfig != "" {
+		servers, err := loadMCPConfig(*mcpConfig)
+		if err != nil {
+			log.Fatalf("-mcp-config: %v", err)
+		}
+		opts.RoleMCPServers = servers
+	}
+	if *sandbox != "" {
+		opts.RoleSandboxes = map[agentteam.Role]string{agentteam.RoleWorker: *sandbox}
+	}
+	if *rpcTraceDir != "" {
+		if err := os.MkdirAll(*rpcTraceDir, 0700); err != nil {
+			log.Fatalf("-rpc-trace-dir: %v", err)
+		}
+		opts.RPCTraceDir = *rpcTraceDir
+	}
+
+	if (*jiraURL == "") != (*jiraToken == "") {
+		log.Fatalf("-jira-url and -jira-token must be set together")
+	}
+	if *slackToken != "" && (*slackChannel == "" || *slackSecret == "") {
+		log.Fatalf("-slack-token requires -slack-channel and -slack-signing-secret")
+	}
+	if *approvalDefault != codexrpc.DecisionAccept && *approvalDefault != codexrpc.DecisionDecline {
+		log.Fatalf("-approval-default must be accept or decline")
+	}
+
+	if *k8sImage != "" && *k8sRemote == "" {
+		log.Fatalf("-k8s-image requires -k8s-remote")
+	}
+	kubeCfg := kube.Config{
+		Namespace:    *k8sNamespace,
+		Image:        *k8sImage,
+		Remote:       *k8sRemote,
+		BackoffLimit: *k8sBackoff,
+		SecretName:   *k8sSecret,
+		CPU:          *k8sCPU,
+		Memory:       *k8sMemory,
+	}
+	var newRunner func(repoPath string) task.Runner
+	if *k8sImage != "" {
+		newRunner = func(repoPath string) task.Runner {
+			return kube.NewRunner(kubeCfg, repoPath)
+		}
+	}
+
+	// Container isolation for the selected roles
+	if *dockerImage != "" {
+		opts.Containers = make(map[agentteam.Role]agentteam.ContainerConfig)
+		roleImages, err := parseRoleMap(*dockerRoleImages)
+		if err != nil {
+			log.Fatalf("-docker-role-images: %v", err)
+		}
+		roleCPUs, err := parseRoleMap(*dockerRoleCPUs)
+		if err != nil {
+			log.Fatalf("-docker-role-cpus: %v", err)
+		}
+		roleMemory, err := parseRoleMap(*dockerRoleMemory)
+		if err != nil {
+			log.Fatalf("-docker-role-memory: %v", err)
+		}
+		env := splitList(*dockerEnv)
+		var mounts []string
+		if *dockerCodexHome != "" {
+			home, err := filepath.Abs(*dockerCodexHome)
+			if err != nil {
+				log.Fatalf("-docker-codex-home: %v", err)
+			}
+			mounts = append(mounts, home)
+			env = append(env, "CODEX_HOME="+home)
+		}
+		orDefault := func(m map[agentteam.Role]string, role agentteam.Role, def string) string {
+			if v, ok := m[role]; ok && v != "" {
+				return v
+			}
+			return def
+		}
+		for _, name := range strings.Split(*dockerRoles, ",") {
+			role := agentteam.Role(strings.TrimSpace(name))
+			opts.Containers[role] = agentteam.ContainerConfig{
+				Runtime:    *dockerRuntime,
+				Image:      orDefault(roleImages, role, *dockerImage),
+				BinaryPath: *dockerBin,
+				CPUs:       orDefault(roleCPUs, role, *dockerCPUs),
+				Memory:     orDefault(roleMemory, role, *dockerMemory),
+				Network:    *dockerNetwork,
+				Env:        env,
+				Mounts:     mounts,
+			}
+		}
+	}
+
+	opts.Stall = agentteam.StallPolicy{Timeout: *stallTimeout, Retries: *stallRetries}
+
+	// Event sinks; streamed output is too chatty for logs and webhooks
+	sinks := make(map[string]events.Sink)
+	if *eventsDir != "" {
+		fileSink, err := events.NewFileSink(*eventsDir)
+		if err != nil {
+			log.Fatalf("-events-dir: %v", err)
+		}
+		sinks["file"] = fileSink
+	}
+	chunks := []string{"item/agentMessage/delta", agent.EventCommandOutput}
+	for _, url := range splitList(*webhooks) {
+		sinks["webhook "+url] = events.Without(events.WebhookSink(url), chunks...)
+	}
+	if *logEvents {
+		sinks["log"] = events.Without(events.LogSink(), chunks...)
+	}
+
+	// Validate codex binary (unless skipped or running in containers)
+	if !*skipCheck && *dockerImage == "" {
 		if _, err := os.Stat(*codexBin); os.IsNotExist(err) {
 			log.Printf("Warning: Codex binary not found at: %s", *codexBin)
 			log.Printf("Server will start but agent operations will fail.")
@@ -25,16 +340,124 @@ func main() {
 		}
 	}
 
+	// Headless mode: run one task in-process and exit with its status
+	if *oneshot != "" {
+		os.Exit(runOneshot(*codexBin, *repoPath, *oneshot, *reportPath, *assumeYes, opts, newRunner, sinks))
+	}
+
 	// Create server
-	server := api.NewServer(*codexBin, *repoPath)
+	team, err := agentteam.New(*codexBin, *repoPath, opts)
+	if err != nil {
+		log.Fatalf("Invalid repo path: %v", err)
+	}
+	server := api.NewServerWithManager(*codexBin, team.RepoPath(), teamaccess.Manager(team))
+	if *logBuffer > 0 {
+		logs := api.NewLogBuffer(*logBuffer)
+		log.SetOutput(io.MultiWriter(os.Stderr, logs))
+		server.SetLogBuffer(logs)
+	}
+	server.SetCORSOrigins(splitList(*corsOrigins))
+	for name, sink := range sinks {
+		server.Bus().Subscribe(name, sink)
+	}
+	server.SetTrustProxy(*trustProxy)
+	if err := server.SetTrustedProxies(splitList(*trustedProxies)); err != nil {
+		log.Fatalf("-trusted-proxies: %v", err)
+	}
+	server.SetBasePath(*basePath)
+	server.SetLimits(api.Limits{
+		Rate:         *rateLimit,
+		Burst:        *rateBurst,
+		SpawnPerMin:  *spawnLimit,
+		MaxBodyBytes: *maxBody,
+	})
+	scheme := "http"
+	if *tlsCert != "" || *tlsSelfSigned {
+		server.SetTLS(api.TLSOptions{CertFile: *tlsCert, KeyFile: *tlsKey, SelfSigned: *tlsCert == ""})
+		scheme = "https"
+	}
+	if newRunner != nil {
+		server.SetRunnerFactory(newRunner)
+	}
+	if !*skipCheck && *dockerImage == "" {
+		for _, st := range server.CodexStatuses(context.Background()) {
+			logCodexStatus(st)
+		}
+	}
+	server.SetApprovalPolicy(agent.ApprovalPolicy{
+		Human:   *humanApproval,
+		Timeout: *approvalTimeout,
+		Default: *approvalDefault,
+	})
+	if *tenantsFile != "" {
+		reg, err := tenant.Load(*tenantsFile)
+		if err != nil {
+			log.Fatalf("Load tenants: %v", err)
+		}
+		server.SetTenants(reg)
+	}
+	if *shareSecret != "" {
+		server.SetShareSecret(*shareSecret)
+	}
+	if *slackToken != "" {
+		server.SetSlack(slack.Config{
+			Token:         *slackToken,
+			Channel:       *slackChannel,
+			SigningSecret: *slackSecret,
+		})
+	}
+	trackers := issues.NewRegistry(issues.Config{
+		GitHubToken:  *githubToken,
+		GitHubAPIURL: *githubAPI,
+		JiraURL:      *jiraURL,
+		JiraUser:     *jiraUser,
+		JiraToken:    *jiraToken,
+		LinearAPIKey: *linearKey,
+	})
+	if *scheduler {
+		server.StartScheduler()
+	}
+	if *watchInterval > 0 {
+		server.StartWatcher(*watchInterval)
+	}
+	server.SetWatchSecret(*watchSecret)
+	if names := trackers.Names(); len(names) > 0 {
+		server.SetIssueTrackers(trackers)
+		log.Printf("Issue trackers: %s", strings.Join(names, ", "))
+	}
 
 	// Start server
 	log.Printf("Starting Codex Agent Team server on %s", *addr)
 	log.Printf("Codex binary: %s", *codexBin)
 	log.Printf("Repository: %s", *repoPath)
-	log.Printf("Visit http://localhost%s", *addr)
+	prefix := ""
+	if p := strings.Trim(*basePath, "/"); p != "" {
+		prefix = "/" + p
+	}
+	log.Printf("Visit %s://localhost%s%s/", scheme, *addr, prefix)
+
+	if *grpcAddr != "" {
+		log.Printf("Serving gRPC on %s", *grpcAddr)
+		go func() {
+			if err := server.StartGRPC(*grpcAddr); err != nil {
+				log.Fatalf("gRPC server error: %v", err)
+			}
+		}()
+	}
 
 	if err := server.Start(*addr); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// logCodexStatus reports the login state of a codex binary at startup.
+func logCodexStatus(st agent.CodexStatus) {
+	switch {
+	case st.Error != "":
+		log.Printf("Warning: could not check codex login of %s: %s", st.Binary, st.Error)
+	case !st.Authenticated:
+		log.Printf("Warning: %v; sessions are refused until it is logged in", st.Err())
+	default:
+		log.Printf("Codex %s: logged in, %d models available", st.Binary, len(st.Models))
+	}
+}
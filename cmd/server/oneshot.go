@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/teamaccess"
+	"codex-agent-team/internal/testreport"
+	"codex-agent-team/internal/worktree"
+	"codex-agent-team/pkg/agentteam"
+)
+
+// Exit codes for -oneshot mode.
+const (
+	exitOK              = 0
+	exitError           = 1
+	exitDecomposeFailed = 2
+	exitExecuteFailed   = 3
+	exitMergeFailed     = 4
+)
+
+// oneshotReport is the JSON report written at the end of a -oneshot run.
+type oneshotReport struct {
+	SessionID string       `json:"sessionId"`
+	UserTask  string       `json:"userTask"`
+	RepoPath  string       `json:"repoPath"`
+	Status    string       `json:"status"`
+	Error     string       `json:"error,omitempty"`
+	Duration  string       `json:"duration"`
+	Tasks     []*task.Task `json:"tasks"`
+
+	Tests   *testreport.Report   `json:"tests,omitempty"`   // aggregated test and coverage reports
+	Changes *worktree.ChangeStat `json:"changes,omitempty"` // lines changed by all tasks
+}
+
+// runOneshot runs decompose, execute and merge in-process for a single
+// user task, printing progress to the terminal. It returns the exit code.
+// Plans whose estimate exceeds the execution gate only run when confirm
+// is set.
+func runOneshot(codexBin, repoPath, userTask, reportPath string, confirm bool, opts agentteam.Options, newRunner func(string) task.Runner, sinks map[string]events.Sink) int {
+	team, err := agentteam.New(codexBin, repoPath, opts)
+	if err != nil {
+		log.Printf("Invalid repo path: %v", err)
+		return exitError
+	}
+	defer team.Close()
+
+	ctx := context.Background()
+	start := time.Now()
+	if newRunner != nil {
+		teamaccess.Manager(team).SetRunnerFactory(newRunner)
+	}
+	for name, sink := range sinks {
+		team.Subscribe(name, sink)
+	}
+
+	if err := team.Check(ctx); err != nil {
+		log.Printf("%v", err)
+		return exitError
+	}
+	team.Subscribe("console", events.SinkFunc(printAgentEvent))
+
+	stopWatch := func() {}
+	sess, runErr := team.Run(ctx, userTask, agentteam.RunConfig{
+		Confirm: confirm,
+		OnStage: func(sess *agentteam.Session, stage agentteam.Stage) {
+			switch stage {
+			case agentteam.StageDecompose:
+				log.Printf("Session %s: %s", sess.ID, userTask)
+				log.Printf("Decomposing...")
+			case agentteam.StageExecute:
+				log.Printf("Executing %d tasks...", len(sess.DAG.GetTasks()))
+				stopWatch = watchTasks(sess.DAG)
+			case agentteam.StageMerge:
+				stopWatch()
+				log.Printf("Merging...")
+			}
+		},
+		OnDecomposed: func(sess *agentteam.Session, result *agentteam.DecomposeResult) {
+			for _, warning := range result.Warnings {
+				log.Printf("Warning: %s", warning)
+			}
+			tasks := sess.DAG.GetTasks()
+			sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+			for _, t := range tasks {
+				log.Printf("  %s: %s (depends on %v)", t.ID, t.Title, t.DependsOn)
+			}
+			if est := sess.Estimate(); est.NeedsConfirm {
+				log.Printf("Estimate: %d tasks, %.0f agent-minutes, ~%d tokens (exceeds %s)",
+					est.Tasks, est.AgentMinutes, est.Tokens, strings.Join(est.Exceeds, ", "))
+				if !confirm {
+					log.Printf("Rerun with -yes to execute this plan")
+				}
+			}
+		},
+	})
+	stopWatch()
+	if sess == nil {
+		log.Printf("Create session: %v", runErr)
+		return exitError
+	}
+
+	code := exitOK
+	var stageErr *agentteam.StageError
+	if errors.As(runErr, &stageErr) {
+		switch stageErr.Stage {
+		case agentteam.StageDecompose:
+			code = exitDecomposeFailed
+		case agentteam.StageExecute:
+			code = exitExecuteFailed
+		case agentteam.StageMerge:
+			code = exitMergeFailed
+		}
+	}
+
+	report := oneshotReport{
+		SessionID: sess.ID,
+		UserTask:  userTask,
+		RepoPath:  team.RepoPath(),
+		Status:    string(sess.Status),
+		Duration:  time.Since(start).Round(time.Second).String(),
+		Tasks:     sess.DAG.GetTasks(),
+		Tests:     sess.TestReport(),
+		Changes:   sess.Changes,
+	}
+	if c := report.Changes; c != nil {
+		log.Printf("Changes: %s", c.Summary)
+	}
+	if t := report.Tests; t != nil {
+		log.Printf("Tests: %d passed, %d failed, %d skipped; coverage %.1f%%", t.Passed, t.Failed, t.Skipped, t.Coverage)
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+		log.Printf("Failed: %v", runErr)
+	} else {
+		log.Printf("Completed in %s", report.Duration)
+	}
+
+	if reportPath != "" {
+		if err := writeReport(reportPath, &report); err != nil {
+			log.Printf("Write report: %v", err)
+			if code == exitOK {
+				code = exitError
+			}
+		} else {
+			log.Printf("Report written to %s", reportPath)
+		}
+	}
+
+	return code
+}
+
+// printAgentEvent prints the commands agents run as they happen.
+func printAgentEvent(ev events.Event) {
+	data, ok := ev.Data.(json.RawMessage)
+	if ev.Source != events.SourceAgent || !ok {
+		return
+	}
+	var cmd agent.CommandEvent
+	switch ev.Type {
+	case agent.EventCommandStarted:
+		if json.Unmarshal(data, &cmd) == nil {
+			log.Printf("[%s] $ %s", ev.AgentID, cmd.Command)
+		}
+	case agent.EventCommandCompleted:
+		if json.Unmarshal(data, &cmd) == nil && cmd.ExitCode != nil && *cmd.ExitCode != 0 {
+			log.Printf("[%s] exit %d: %s", ev.AgentID, *cmd.ExitCode, cmd.Command)
+		}
+	case agent.EventAgentStalled:
+		var stall agent.StallEvent
+		if json.Unmarshal(data, &stall) == nil {
+			log.Printf("[%s] stalled after %.0fs idle (attempt %d, retrying: %t)", ev.AgentID, stall.IdleSeconds, stall.Attempt, stall.Retrying)
+		}
+	}
+}
+
+// watchTasks logs task status transitions until the returned func is called.
+func watchTasks(dag *task.DAG) func() {
+	done := make(chan struct{})
+	go func() {
+		last := make(map[string]task.TaskStatus)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			for _, t := range dag.GetTasks() {
+				if last[t.ID] != t.Status {
+					last[t.ID] = t.Status
+					if t.Error != "" {
+						log.Printf("Task %s: %s (%s)", t.ID, t.Status, t.Error)
+					} else {
+						log.Printf("Task %s: %s", t.ID, t.Status)
+					}
+				}
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// writeReport writes the run report as indented JSON.
+func writeReport(path string, report *oneshotReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
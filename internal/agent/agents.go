@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"context"
+	"sync"
+)
+
+// Agents runs agents on behalf of the orchestrator, the merger and the task
+// executor. *Manager implements it against Codex app-servers; tests can
+// substitute an in-memory fake such as agenttest.Agents.
+type Agents interface {
+	SpawnAgent(ctx context.Context, cfg AgentConfig) (*Instance, error)
+	SendTask(ctx context.Context, agentID, taskID, message string) (*Turn, error)
+	WaitForCompletion(ctx context.Context, turn *Turn) error
+	GetOutput(agentID string) string
+	TestRuns(agentID string) []TestRun
+	StopAgent(agentID string) error
+}
+
+var _ Agents = (*Manager)(nil)
+
+// spawnLog records the agents an orchestrator or merger spawned, so the
+// session that owns them, and their traces once they stopped, can be found.
+type spawnLog struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// spawn spawns an agent through agents and records it.
+func (l *spawnLog) spawn(ctx context.Context, agents Agents, cfg AgentConfig) (*Instance, error) {
+	instance, err := agents.SpawnAgent(ctx, cfg)
+	if err == nil {
+		l.mu.Lock()
+		l.ids = append(l.ids, cfg.ID)
+		l.mu.Unlock()
+	}
+	return instance, err
+}
+
+func (l *spawnLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.ids...)
+}
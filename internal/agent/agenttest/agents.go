@@ -0,0 +1,170 @@
+// Package agenttest provides an in-memory agent.Agents for tests of the
+// orchestrator, the merger and the task executor that should not need
+// Codex.
+package agenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"codex-agent-team/internal/agent"
+)
+
+// Reply is how a fake agent answers a task.
+type Reply struct {
+	Output   string          // appended to the agent's output
+	TestRuns []agent.TestRun // recorded as the agent's test runs
+	Err      error           // returned by WaitForCompletion
+}
+
+// Message is a task sent to a fake agent.
+type Message struct {
+	AgentID string
+	TaskID  string
+	Text    string
+}
+
+// Agents is an in-memory agent.Agents. Tasks run synchronously in
+// SendTask through Do, so a test controls exactly what every agent does,
+// for example editing a worktreetest.Git worktree at cfg.Cwd.
+type Agents struct {
+	// Do answers a task sent to the agent configured by cfg. Nil completes
+	// every task without output.
+	Do func(cfg agent.AgentConfig, taskID, message string) Reply
+
+	// SpawnErr, when set, is returned by SpawnAgent.
+	SpawnErr error
+
+	mu       sync.Mutex
+	agents   map[string]*fakeAgent
+	outcomes map[*agent.Turn]error
+	spawned  []agent.AgentConfig
+	sent     []Message
+}
+
+type fakeAgent struct {
+	cfg      agent.AgentConfig
+	output   string
+	testRuns []agent.TestRun
+	stopped  bool
+}
+
+var _ agent.Agents = (*Agents)(nil)
+
+// New creates fake agents that answer tasks with do.
+func New(do func(cfg agent.AgentConfig, taskID, message string) Reply) *Agents {
+	return &Agents{Do: do}
+}
+
+// SpawnAgent records the agent; IDs must be unique among running agents.
+// Spawning the ID of a stopped agent starts over with empty output.
+func (a *Agents) SpawnAgent(ctx context.Context, cfg agent.AgentConfig) (*agent.Instance, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.SpawnErr != nil {
+		return nil, a.SpawnErr
+	}
+	if a.agents == nil {
+		a.agents = make(map[string]*fakeAgent)
+	}
+	if ag, ok := a.agents[cfg.ID]; ok && !ag.stopped {
+		return nil, fmt.Errorf("agent %s already exists", cfg.ID)
+	}
+	a.agents[cfg.ID] = &fakeAgent{cfg: cfg}
+	a.spawned = append(a.spawned, cfg)
+	return &agent.Instance{Config: cfg, ThreadID: "thread-" + cfg.ID}, nil
+}
+
+// SendTask runs the task through Do before returning its turn.
+func (a *Agents) SendTask(ctx context.Context, agentID, taskID, message string) (*agent.Turn, error) {
+	a.mu.Lock()
+	ag, ok := a.agents[agentID]
+	if !ok || ag.stopped {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("agent %s not found", agentID)
+	}
+	a.sent = append(a.sent, Message{AgentID: agentID, TaskID: taskID, Text: message})
+	cfg := ag.cfg
+	a.mu.Unlock()
+
+	// Do runs unlocked so it may call back into the fake
+	var reply Reply
+	if a.Do != nil {
+		reply = a.Do(cfg, taskID, message)
+	}
+
+	turn := &agent.Turn{AgentID: agentID, TaskID: taskID}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ag.output += reply.Output
+	ag.testRuns = append(ag.testRuns, reply.TestRuns...)
+	if a.outcomes == nil {
+		a.outcomes = make(map[*agent.Turn]error)
+	}
+	a.outcomes[turn] = reply.Err
+	return turn, nil
+}
+
+// WaitForCompletion returns the error of the turn's reply.
+func (a *Agents) WaitForCompletion(ctx context.Context, turn *agent.Turn) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err, ok := a.outcomes[turn]
+	if !ok {
+		return errors.New("unknown turn")
+	}
+	delete(a.outcomes, turn)
+	return err
+}
+
+// GetOutput returns the output of every reply of the agent.
+func (a *Agents) GetOutput(agentID string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if ag, ok := a.agents[agentID]; ok {
+		return ag.output
+	}
+	return ""
+}
+
+// TestRuns returns the test runs of every reply of the agent.
+func (a *Agents) TestRuns(agentID string) []agent.TestRun {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if ag, ok := a.agents[agentID]; ok {
+		return append([]agent.TestRun(nil), ag.testRuns...)
+	}
+	return nil
+}
+
+// StopAgent stops the agent. Its output and test runs stay readable, and
+// its ID may be spawned again.
+func (a *Agents) StopAgent(agentID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ag, ok := a.agents[agentID]
+	if !ok || ag.stopped {
+		return fmt.Errorf("agent %s not found", agentID)
+	}
+	ag.stopped = true
+	return nil
+}
+
+// Spawned returns the configurations of every agent spawned so far.
+func (a *Agents) Spawned() []agent.AgentConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]agent.AgentConfig(nil), a.spawned...)
+}
+
+// Sent returns every task sent so far, in order.
+func (a *Agents) Sent() []Message {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Message(nil), a.sent...)
+}
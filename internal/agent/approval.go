@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"codex-agent-team/internal/codexrpc"
+)
+
+// ErrApprovalNotFound is returned when deciding an unknown or already
+// resolved approval request.
+var ErrApprovalNotFound = errors.New("approval request not found")
+
+// Approval event types.
+const (
+	EventApprovalRequested = "approval/requested"
+	EventApprovalResolved  = "approval/resolved"
+)
+
+// ApprovalPolicy configures how command and file change approvals are
+// answered. By default every request is accepted automatically.
+type ApprovalPolicy struct {
+	Human   bool          // wait for a human decision instead of auto-accepting
+	Timeout time.Duration // how long to wait for a human; 0 waits forever
+	Default string        // decision applied on timeout (decline if empty)
+}
+
+// ApprovalRequest is an approval waiting for a human decision.
+type ApprovalRequest struct {
+	ID        string          `json:"id"`
+	AgentID   string          `json:"agentId"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params"`
+	CreatedAt time.Time       `json:"createdAt"`
+
+	decision chan string
+}
+
+// ApprovalResolution describes how an approval request was answered.
+type ApprovalResolution struct {
+	ID       string `json:"id"`
+	Decision string `json:"decision"`
+	TimedOut bool   `json:"timedOut"`
+}
+
+// SetApprovalPolicy changes how subsequent approval requests are answered.
+func (m *Manager) SetApprovalPolicy(p ApprovalPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.approval = p
+}
+
+// DecideApproval answers a pending approval request.
+func (m *Manager) DecideApproval(id, decision string) error {
+	switch decision {
+	case codexrpc.DecisionAccept, codexrpc.DecisionAcceptForSession,
+		codexrpc.DecisionDecline, codexrpc.DecisionCancel:
+	default:
+		return fmt.Errorf("invalid decision %q", decision)
+	}
+
+	m.approvalMu.Lock()
+	req, ok := m.approvals[id]
+	if ok {
+		delete(m.approvals, id)
+	}
+	m.approvalMu.Unlock()
+
+	if !ok {
+		return ErrApprovalNotFound
+	}
+	req.decision <- decision
+	return nil
+}
+
+// cancelApprovals cancels the pending approvals of a stopped agent.
+func (m *Manager) cancelApprovals(agentID string) {
+	m.approvalMu.Lock()
+	var reqs []*ApprovalRequest
+	for id, req := range m.approvals {
+		if req.AgentID == agentID {
+			reqs = append(reqs, req)
+			delete(m.approvals, id)
+		}
+	}
+	m.approvalMu.Unlock()
+
+	for _, req := range reqs {
+		req.decision <- codexrpc.DecisionCancel
+	}
+}
+
+// PendingApprovals returns the approval requests still waiting for a
+// decision, oldest first.
+func (m *Manager) PendingApprovals() []*ApprovalRequest {
+	m.approvalMu.Lock()
+	defer m.approvalMu.Unlock()
+
+	reqs := make([]*ApprovalRequest, 0, len(m.approvals))
+	for _, req := range m.approvals {
+		reqs = append(reqs, req)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].CreatedAt.Before(reqs[j].CreatedAt) })
+	return reqs
+}
+
+// awaitApproval registers a pending request and blocks until it is
+// decided or the policy timeout applies the default decision.
+func (m *Manager) awaitApproval(agentID, method string, params json.RawMessage, policy ApprovalPolicy) string {
+	req := &ApprovalRequest{
+		ID:        GenerateID(),
+		AgentID:   agentID,
+		Method:    method,
+		Params:    params,
+		CreatedAt: time.Now(),
+		decision:  make(chan string, 1),
+	}
+
+	m.approvalMu.Lock()
+	m.approvals[req.ID] = req
+	m.approvalMu.Unlock()
+
+	m.setAwaitingApproval(agentID, 1)
+	defer m.setAwaitingApproval(agentID, -1)
+
+	m.emitJSONEvent(agentID, EventApprovalRequested, req)
+
+	var timeout <-chan time.Time
+	if policy.Timeout > 0 {
+		timer := time.NewTimer(policy.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	resolution := ApprovalResolution{ID: req.ID}
+	select {
+	case resolution.Decision = <-req.decision:
+	case <-timeout:
+		m.approvalMu.Lock()
+		_, stillPending := m.approvals[req.ID]
+		delete(m.approvals, req.ID)
+		m.approvalMu.Unlock()
+
+		if stillPending {
+			resolution.Decision = policy.Default
+			if resolution.Decision == "" {
+				resolution.Decision = codexrpc.DecisionDecline
+			}
+			resolution.TimedOut = true
+		} else {
+			// A decision raced the timer
+			resolution.Decision = <-req.decision
+		}
+	}
+
+	m.emitJSONEvent(agentID, EventApprovalResolved, resolution)
+	return resolution.Decision
+}
+
+// setAwaitingApproval adjusts the agent's pending approval count. Time
+// spent waiting for a human does not count towards stall detection.
+func (m *Manager) setAwaitingApproval(agentID string, delta int) {
+	m.mu.RLock()
+	instance, exists := m.agents[agentID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	instance.mu.Lock()
+	instance.awaitingApproval += delta
+	instance.lastActivity = time.Now()
+	instance.mu.Unlock()
+}
+
+// emitJSONEvent publishes an agent event with a JSON payload, attributed
+// to the agent's task in progress.
+func (m *Manager) emitJSONEvent(agentID, eventType string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	m.mu.RLock()
+	instance, exists := m.agents[agentID]
+	m.mu.RUnlock()
+	if !exists {
+		m.emit(AgentEvent{AgentID: agentID, EventType: eventType, Data: data})
+		return
+	}
+	m.emitFor(instance, "", eventType, data)
+}
+
+// emitJSONFor publishes an event of instance with a JSON payload,
+// attributed to turnID's task.
+func (m *Manager) emitJSONFor(instance *Instance, turnID, eventType string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	m.emitFor(instance, turnID, eventType, data)
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Context pack limits keep the decomposition prompt small enough that the
+// pack saves exploration turns instead of crowding out the task.
+const (
+	packMaxTreeEntries = 300   // paths listed in the directory tree
+	packMaxFileBytes   = 4000  // bytes of a single manifest or README
+	packMaxBytes       = 24000 // bytes of the whole pack
+	packCommits        = 15    // recent commits listed
+)
+
+// packManifests are the files that describe a project's language,
+// dependencies and build, in the order they are included.
+var packManifests = []string{
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "requirements.txt",
+	"pom.xml", "build.gradle", "Gemfile", "Makefile", "README.md", "README",
+}
+
+// packSkipDirs are never listed in the tree when git is unavailable.
+var packSkipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, "dist": true, ".worktrees": true,
+}
+
+// BuildContextPack summarizes a repository for the orchestrator: its
+// directory tree, manifests, README and recent commits. Sections that
+// cannot be read are left out, so the pack may be empty.
+func BuildContextPack(ctx context.Context, repoPath string) string {
+	var b strings.Builder
+
+	if tree := packTree(ctx, repoPath); tree != "" {
+		b.WriteString("## Directory tree\n")
+		b.WriteString(tree)
+	}
+
+	for _, name := range packManifests {
+		data, err := os.ReadFile(filepath.Join(repoPath, name))
+		if err != nil {
+			continue
+		}
+		if len(data) > packMaxFileBytes {
+			data = append(data[:packMaxFileBytes], "\n... (truncated)"...)
+		}
+		fmt.Fprintf(&b, "\n## %s\n%s\n", name, strings.TrimRight(string(data), "\n"))
+	}
+
+	if log := packGit(ctx, repoPath, "log", "--oneline", "--no-decorate", fmt.Sprintf("-%d", packCommits)); log != "" {
+		b.WriteString("\n## Recent commits\n")
+		b.WriteString(log)
+	}
+
+	pack := b.String()
+	if len(pack) > packMaxBytes {
+		pack = pack[:packMaxBytes] + "\n... (truncated)\n"
+	}
+	return pack
+}
+
+// packTree lists the repository's files, grouped by directory. Tracked
+// files come from git so ignored build output stays out.
+func packTree(ctx context.Context, repoPath string) string {
+	var paths []string
+	if out := packGit(ctx, repoPath, "ls-files"); out != "" {
+		scanner := bufio.NewScanner(strings.NewReader(out))
+		for scanner.Scan() {
+			paths = append(paths, scanner.Text())
+		}
+	} else {
+		_ = filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if packSkipDirs[d.Name()] || (path != repoPath && strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if rel, err := filepath.Rel(repoPath, path); err == nil {
+				paths = append(paths, filepath.ToSlash(rel))
+			}
+			if len(paths) > packMaxTreeEntries {
+				return filepath.SkipAll
+			}
+			return nil
+		})
+	}
+	if len(paths) == 0 {
+		return ""
+	}
+
+	sort.Strings(paths)
+	var b strings.Builder
+	for i, p := range paths {
+		if i == packMaxTreeEntries {
+			fmt.Fprintf(&b, "... and %d more files\n", len(paths)-i)
+			break
+		}
+		b.WriteString(p)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// packGit runs a read-only git command in repoPath, returning "" on error.
+func packGit(ctx context.Context, repoPath string, args ...string) string {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
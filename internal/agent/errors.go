@@ -0,0 +1,34 @@
+package agent
+
+import "errors"
+
+// ErrAgentStalled is returned when a turn showed no activity for longer
+// than the stall timeout and no retries were left.
+var ErrAgentStalled = errors.New("agent stalled")
+
+// ErrDecompositionParse is returned when the orchestrator's output holds
+// no decomposition that can be parsed.
+var ErrDecompositionParse = errors.New("parse decomposition")
+
+// SpawnError is returned when an agent's app-server could not be started
+// or set up. Stderr holds what the process wrote before it failed.
+type SpawnError struct {
+	Err    error
+	Stderr string
+}
+
+func (e *SpawnError) Error() string { return e.Err.Error() }
+func (e *SpawnError) Unwrap() error { return e.Err }
+
+// TurnError is returned when the app-server reports a failed turn.
+type TurnError struct {
+	Message string // the turn error reported by codex
+	Details string // additional details, if any
+}
+
+func (e *TurnError) Error() string {
+	if e.Message == "" {
+		return "agent task failed"
+	}
+	return "agent task failed: " + e.Message
+}
@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxGlobMatches bounds the files a single glob in a plan expands to.
+const maxGlobMatches = 50
+
+// ResolveFiles validates the files suggested for each task against the
+// repository, in place. Paths are normalized to slash-separated paths
+// relative to the repository, globs are expanded, and paths outside the
+// repository are dropped. A missing file is corrected through the code
+// index when enabled, and otherwise kept as a file the task may create.
+// Corrections and missing files are noted in the task description, so
+// workers are not sent hunting for files that do not exist. It returns one
+// warning per problem.
+func (o *Orchestrator) ResolveFiles(ctx context.Context, repoPath string, d *TaskDecomposition) []string {
+	idx := o.index(ctx, repoPath)
+
+	var warnings []string
+	for i := range d.Tasks {
+		t := &d.Tasks[i]
+		var files, notes []string
+		seen := make(map[string]bool)
+		add := func(f string) {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+
+		for _, raw := range t.Files {
+			f, ok := normalizePath(repoPath, raw)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("task %s: dropped file %q outside the repository", t.ID, raw))
+				continue
+			}
+
+			if strings.ContainsAny(f, "*?[") {
+				matches := expandGlob(repoPath, f)
+				if len(matches) == 0 {
+					warnings = append(warnings, fmt.Sprintf("task %s: pattern %q matches no files", t.ID, raw))
+					notes = append(notes, fmt.Sprintf("The plan mentioned %s, but no file matches it.", raw))
+				}
+				for _, m := range matches {
+					add(m)
+				}
+				continue
+			}
+
+			if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(f))); err == nil {
+				add(f)
+				continue
+			}
+			if idx != nil {
+				if resolved, ok := idx.Resolve(f); ok {
+					warnings = append(warnings, fmt.Sprintf("task %s: corrected file %q to %q", t.ID, raw, resolved))
+					notes = append(notes, fmt.Sprintf("The plan mentioned %s; the actual file is %s.", raw, resolved))
+					add(resolved)
+					continue
+				}
+			}
+			warnings = append(warnings, fmt.Sprintf("task %s: file %q does not exist", t.ID, raw))
+			notes = append(notes, fmt.Sprintf("%s does not exist yet; create it if the task needs it rather than searching for it.", f))
+			add(f)
+		}
+
+		t.Files = files
+		if len(notes) > 0 {
+			t.Description += "\n\nFile notes:\n- " + strings.Join(notes, "\n- ")
+		}
+	}
+	return warnings
+}
+
+// normalizePath turns a suggested path into a clean slash-separated path
+// relative to repoPath. It reports false for paths outside the repository.
+func normalizePath(repoPath, p string) (string, bool) {
+	p = strings.TrimSpace(strings.ReplaceAll(p, "\\", "/"))
+	if p == "" {
+		return "", false
+	}
+	if path.IsAbs(p) || filepath.IsAbs(p) {
+		rel, err := filepath.Rel(repoPath, filepath.FromSlash(p))
+		if err != nil {
+			return "", false
+		}
+		p = filepath.ToSlash(rel)
+	}
+	p = path.Clean(p)
+	if p == "." || p == ".." || strings.HasPrefix(p, "../") || path.IsAbs(p) {
+		return "", false
+	}
+	return p, true
+}
+
+// expandGlob returns the repository files matching pattern, where "**"
+// matches any number of directories.
+func expandGlob(repoPath, pattern string) []string {
+	var matches []string
+	_ = filepath.WalkDir(repoPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != repoPath && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(repoPath, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if globMatch(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			matches = append(matches, rel)
+		}
+		if len(matches) >= maxGlobMatches {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return matches
+}
+
+// globMatch matches path segments against pattern segments.
+func globMatch(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if globMatch(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], segments[0])
+	return err == nil && ok && globMatch(pattern[1:], segments[1:])
+}
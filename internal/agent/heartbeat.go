@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrAgentUnresponsive is returned by WaitForCompletion when the agent's
+// app-server stops answering pings.
+var ErrAgentUnresponsive = errors.New("agent unresponsive")
+
+// defaultHeartbeatMisses is the number of missed pings after which an
+// agent is given up when the policy does not say.
+const defaultHeartbeatMisses = 2
+
+// SetHeartbeat enables liveness pings of the app-servers of agents
+// spawned afterwards.
+func (m *Manager) SetHeartbeat(p HeartbeatPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.heartbeat = p
+}
+
+// runHeartbeat pings instance's app-server until the agent stops. Each
+// missed ping is reported as EventAgentUnresponsive; after p.Misses in a
+// row the agent is given up, which fails a WaitForCompletion in progress
+// instead of letting it hang on a process that no longer answers.
+func (m *Manager) runHeartbeat(instance *Instance, p HeartbeatPolicy) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = p.Interval
+	}
+	misses := p.Misses
+	if misses <= 0 {
+		misses = defaultHeartbeatMisses
+	}
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	lastAnswer := time.Now()
+	missed := 0
+	for {
+		select {
+		case <-ticker.C:
+		case <-instance.stopped:
+			return
+		case <-instance.Client.Done():
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := instance.Client.Ping(ctx)
+		cancel()
+		if err == nil {
+			lastAnswer, missed = time.Now(), 0
+			continue
+		}
+
+		missed++
+		gaveUp := missed >= misses
+		instance.mu.Lock()
+		turnID := instance.TurnID
+		if gaveUp {
+			instance.State = StateFailed
+		}
+		instance.mu.Unlock()
+		m.emitJSONFor(instance, turnID, EventAgentUnresponsive, UnresponsiveEvent{
+			Missed:        missed,
+			SilentSeconds: time.Since(lastAnswer).Seconds(),
+			GaveUp:        gaveUp,
+			Error:         err.Error(),
+		})
+		if gaveUp {
+			close(instance.unresponsive)
+			return
+		}
+	}
+}
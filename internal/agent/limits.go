@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlanLimits are guardrails on the size of a decomposition. Zero fields
+// are not enforced.
+type PlanLimits struct {
+	MaxTasks        int           // tasks in the plan
+	MaxFilesPerTask int           // files suggested for one task
+	MaxTaskTime     time.Duration // upper bound of a task's estimated time
+	SplitRounds     int           // follow-up turns asking to split oversize tasks (default 1)
+}
+
+// defaultSplitRounds is how often the orchestrator is asked to split
+// oversize tasks when PlanLimits.SplitRounds is zero.
+const defaultSplitRounds = 1
+
+// SetLimits sets the guardrails Decompose enforces on plans.
+func (o *Orchestrator) SetLimits(l PlanLimits) {
+	o.limits = l
+}
+
+// CheckLimits returns one problem per violated limit, empty if the plan
+// is within the orchestrator's limits.
+func (o *Orchestrator) CheckLimits(d *TaskDecomposition) []string {
+	l := o.limits
+	var problems []string
+	if l.MaxTasks > 0 && len(d.Tasks) > l.MaxTasks {
+		problems = append(problems, fmt.Sprintf("the plan has %d tasks, more than the limit of %d", len(d.Tasks), l.MaxTasks))
+	}
+	for _, t := range d.Tasks {
+		if l.MaxFilesPerTask > 0 && len(t.Files) > l.MaxFilesPerTask {
+			problems = append(problems, fmt.Sprintf("task %s touches %d files, more than the limit of %d", t.ID, len(t.Files), l.MaxFilesPerTask))
+		}
+		if est, ok := ParseEstimate(t.EstimatedTime); ok && l.MaxTaskTime > 0 && est > l.MaxTaskTime {
+			problems = append(problems, fmt.Sprintf("task %s is estimated at %s, more than the limit of %s", t.ID, t.EstimatedTime, l.MaxTaskTime))
+		}
+	}
+	return problems
+}
+
+// buildSplitPrompt asks the orchestrator to rework a plan that violates
+// the limits.
+func (o *Orchestrator) buildSplitPrompt(problems []string) string {
+	var hints []string
+	if o.limits.MaxTasks > 0 {
+		hints = append(hints, fmt.Sprintf("use at most %d tasks, merging small related tasks if needed", o.limits.MaxTasks))
+	}
+	if o.limits.MaxFilesPerTask > 0 {
+		hints = append(hints, fmt.Sprintf("keep each task to at most %d files", o.limits.MaxFilesPerTask))
+	}
+	if o.limits.MaxTaskTime > 0 {
+		hints = append(hints, fmt.Sprintf("keep each task's estimated time at most %s", o.limits.MaxTaskTime))
+	}
+	return fmt.Sprintf(`Your plan exceeds the size limits:
+- %s
+
+Split the oversize tasks further into smaller tasks with correct dependencies: %s.
+
+Respond with the complete updated plan as JSON in the same format as before. Respond ONLY with valid JSON, no markdown, no explanation.`,
+		strings.Join(problems, "\n- "), strings.Join(hints, "; "))
+}
+
+// estimatePattern matches the upper bound of estimates like "5-10 min",
+// "1h" or "2 hours".
+var estimatePattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(h|hr|hrs|hours?|m|min|mins|minutes?)\b`)
+
+// ParseEstimate returns the upper bound of an estimated time such as
+// "5-10 min". Estimates without a unit are ambiguous and not parsed.
+func ParseEstimate(s string) (time.Duration, bool) {
+	matches := estimatePattern.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	m := matches[len(matches)-1]
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit := time.Minute
+	if strings.HasPrefix(strings.ToLower(m[2]), "h") {
+		unit = time.Hour
+	}
+	return time.Duration(n * float64(unit)), true
+}
@@ -3,9 +3,15 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"codex-agent-team/internal/codexrpc"
 )
@@ -16,6 +22,32 @@ type Manager struct {
 	agents   map[string]*Instance
 	codexBin string
 	eventCh  chan AgentEvent
+	dropped  atomic.Uint64 // events dropped on a full eventCh
+
+	containers map[Role]ContainerConfig // per-role container isolation
+	binaries   map[Role]string          // per-role codex binary overrides
+	sandboxes  map[Role]string          // per-role sandbox overrides
+	ephemeral  map[Role]bool            // per-role ephemeral threads
+
+	mcp map[Role]map[string]MCPServer // per-role MCP servers by name
+
+	testCommand TestCommand // backs the run_tests tool of workers
+	stall      StallPolicy
+	approval   ApprovalPolicy
+	heartbeat  HeartbeatPolicy
+
+	threadApprovalPolicy string // approvalPolicy sent with thread/start
+	maxInFlight          int    // outstanding calls per app-server, 0 for no limit
+	traceDir             string // RPC trace files, see SetRPCTraceDir
+
+	approvalMu sync.Mutex
+	approvals  map[string]*ApprovalRequest // pending human approvals by ID
+
+	turnMu    sync.Mutex
+	turnTasks map[string]string // owning task by app-server turn ID
+
+	statusMu sync.Mutex
+	statuses map[string]CodexStatus // last status by binary
 }
 
 // Instance represents a running Codex agent instance.
@@ -24,18 +56,52 @@ type Instance struct {
 	Process    *codexrpc.Process
 	Client     *codexrpc.Client
 	ThreadID   string
-	mu         sync.Mutex // protects State and OutputBuffer
+	mu         sync.Mutex // protects State, TurnID, OutputBuffer and the fields below
 	State      AgentState
-	doneCh     chan error // task completion signal
+	TurnID     string // current turn, used for interrupts
+	stopped    chan struct{} // closed by StopAgent
 	OutputBuffer strings.Builder // accumulated agent output
+
+	resumePending bool      // an interrupt is followed by a new turn
+	prompt        string    // last task message, resent on stall retries
+	lastActivity  time.Time // time of the last notification
+
+	awaitingApproval int   // approvals waiting for a human decision
+	tokensUsed       int64 // cumulative thread token usage
+
+	current  *Turn            // handle of the task in progress
+	starting *Turn            // handle whose TurnStart is in flight
+	turns    map[string]*Turn // handles by app-server turn ID
+
+	unresponsive chan struct{} // closed when the heartbeat gives up
+
+	testRuns []TestRun // run_tests calls, oldest first
+
+	container *codexrpc.ContainerOptions // nil when the app-server runs on the host
 }
 
+// ErrAgentStopped is returned by WaitForCompletion when the agent is
+// stopped before its task completes.
+var ErrAgentStopped = errors.New("agent stopped")
+
+// eventBufferSize bounds the agent events waiting for a consumer.
+const eventBufferSize = 1024
+
 // NewManager creates a new Agent Manager.
 func NewManager(codexBin string) *Manager {
 	return &Manager{
 		agents:   make(map[string]*Instance),
 		codexBin: codexBin,
-		eventCh:  make(chan AgentEvent, 100),
+		eventCh:  make(chan AgentEvent, eventBufferSize),
+
+		containers: make(map[Role]ContainerConfig),
+		binaries:   make(map[Role]string),
+		sandboxes:  make(map[Role]string),
+		ephemeral:  map[Role]bool{RoleWorker: true, RoleMerger: true},
+		mcp:        make(map[Role]map[string]MCPServer),
+		approvals:  make(map[string]*ApprovalRequest),
+		turnTasks:  make(map[string]string),
+		statuses:   make(map[string]CodexStatus),
 	}
 }
 
@@ -48,8 +114,11 @@ func (m *Manager) SpawnAgent(ctx context.Context, cfg AgentConfig) (*Instance, e
 		return nil, fmt.Errorf("agent %s already exists", cfg.ID)
 	}
 
-	// Determine sandbox mode based on role
+	// Determine sandbox mode based on role; a configured override wins
 	sandbox := cfg.SandboxMode
+	if override, ok := m.sandboxes[cfg.Role]; ok {
+		sandbox = override
+	}
 	if sandbox == "" {
 		switch cfg.Role {
 		case RoleOrchestrator:
@@ -62,78 +131,285 @@ func (m *Manager) SpawnAgent(ctx context.Context, cfg AgentConfig) (*Instance, e
 	}
 
 	// Spawn the app-server process
-	process, err := codexrpc.Spawn(ctx, codexrpc.SpawnOptions{
+	spawnOpts := codexrpc.SpawnOptions{
 		BinaryPath: m.codexBin,
 		ListenAddr: "stdio://",
-	})
+	}
+	if bin, ok := m.binaries[cfg.Role]; ok {
+		spawnOpts.BinaryPath = bin
+	}
+	if cfg.Runtime.Binary != "" {
+		spawnOpts.BinaryPath = cfg.Runtime.Binary
+	}
+	if cc, ok := m.containers[cfg.Role]; ok {
+		spawnOpts.Container = &codexrpc.ContainerOptions{
+			Runtime:    cc.Runtime,
+			Image:      cc.Image,
+			BinaryPath: cc.BinaryPath,
+			Mounts:     append([]string{cfg.Cwd}, cc.Mounts...),
+			Workdir:    cfg.Cwd,
+			CPUs:       cc.CPUs,
+			Memory:     cc.Memory,
+			Network:    cc.Network,
+			Env:        cc.Env,
+			Name:       containerName(cfg.ID),
+		}
+		// A worktree's .git file points into the repository's git
+		// directory; without it git cannot even show a status
+		if gitDir := gitCommonDir(ctx, cfg.Cwd); gitDir != "" && !within(gitDir, cfg.Cwd) {
+			spawnOpts.Container.ReadOnlyMounts = []string{gitDir}
+		}
+	}
+	process, err := codexrpc.Spawn(ctx, spawnOpts)
 	if err != nil {
-		return nil, fmt.Errorf("spawn process: %w", err)
+		return nil, &SpawnError{Err: fmt.Errorf("spawn process: %w", err)}
 	}
 
 	client := process.Client()
+	client.SetMaxInFlight(m.maxInFlight)
+	if t := m.frameTracer(cfg.ID); t != nil {
+		client.SetFrameTracer(t)
+	}
 
-	// Perform handshake
-	if _, err := client.Initialize(ctx); err != nil {
+	// Perform handshake; dynamic tools need the experimental API
+	var caps *codexrpc.InitializeCapabilities
+	withTests := cfg.Role == RoleWorker && m.testCommand.Command != ""
+	if withTests {
+		caps = &codexrpc.InitializeCapabilities{ExperimentalAPI: true}
+	}
+	if _, err := client.InitializeWithCapabilities(ctx, caps); err != nil {
 		process.Close()
-		return nil, fmt.Errorf("initialize: %w", err)
+		return nil, &SpawnError{Err: fmt.Errorf("initialize: %w", err), Stderr: process.Stderr()}
 	}
 
 	// Create thread
-	threadResp, err := client.ThreadStart(ctx, codexrpc.ThreadStartParams{
+	threadParams := codexrpc.ThreadStartParams{
 		Cwd:                   &cfg.Cwd,
 		Sandbox:               &sandbox,
 		BaseInstructions:      &cfg.BaseInstructions,
 		DeveloperInstructions: &cfg.DeveloperInstructions,
-	})
+	}
+	if m.threadApprovalPolicy != "" {
+		policy := m.threadApprovalPolicy
+		threadParams.ApprovalPolicy = &policy
+	}
+	if ephemeral, ok := m.ephemeral[cfg.Role]; ok {
+		threadParams.Ephemeral = &ephemeral
+	}
+	if servers := m.mcp[cfg.Role]; len(servers) > 0 {
+		// One key per server adds to the servers of the codex config
+		// instead of replacing them
+		threadParams.Config = make(map[string]any, len(servers))
+		for name, server := range servers {
+			threadParams.Config["mcp_servers."+name] = server
+		}
+	}
+	if cfg.Runtime.ModelProvider != "" {
+		provider := cfg.Runtime.ModelProvider
+		threadParams.ModelProvider = &provider
+	}
+	if withTests {
+		threadParams.DynamicTools = []codexrpc.DynamicToolSpec{runTestsSpec()}
+	}
+	threadResp, err := client.ThreadStart(ctx, threadParams)
 	if err != nil {
 		process.Close()
-		return nil, fmt.Errorf("thread start: %w", err)
+		return nil, &SpawnError{Err: fmt.Errorf("thread start: %w", err), Stderr: process.Stderr()}
 	}
 
-	// Set up auto-approve handler for command/file approvals
+	// Set up auto-approve handler for command/file approvals and tool calls
 	client.SetServerRequestHandler(m.createApprovalHandler(cfg.ID))
 
-	// Set up notification handler for events
-	client.SetNotificationHandler(m.createNotificationHandler(cfg.ID))
-
 	instance := &Instance{
-		Config:   cfg,
-		Process:  process,
-		Client:   client,
-		ThreadID: threadResp.Thread.ID,
-		State:    StateIdle,
-		doneCh:   make(chan error, 1),
+		Config:    cfg,
+		Process:   process,
+		Client:    client,
+		ThreadID:  threadResp.Thread.ID,
+		State:     StateIdle,
+		stopped:   make(chan struct{}),
+		container: spawnOpts.Container,
+
+		unresponsive: make(chan struct{}),
 	}
 
+	// Set up notification handler for events, bound to this instance so a
+	// stopped agent's late notifications never reach a successor that
+	// reuses its ID
+	client.SetNotificationHandler(m.createNotificationHandler(instance))
+
 	m.agents[cfg.ID] = instance
+	if m.heartbeat.Interval > 0 {
+		go m.runHeartbeat(instance, m.heartbeat)
+	}
 
 	// Emit agent spawned event
-	m.eventCh <- AgentEvent{
+	m.emit(AgentEvent{
 		AgentID:   cfg.ID,
 		EventType: "spawned",
 		Data:      nil,
-	}
+	})
 
 	return instance, nil
 }
 
-// SendTask sends a task message to an agent.
-func (m *Manager) SendTask(ctx context.Context, agentID string, message string) error {
+// gitCommonDir returns the absolute git directory shared by the checkout
+// at dir and its worktrees, or "" if dir is not in a repository.
+func gitCommonDir(ctx context.Context, dir string) string {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-common-dir")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	gitDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return filepath.Clean(gitDir)
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// containerName returns a container name for an agent. Agent IDs are
+// reused, so a timestamp keeps a successor from clashing with a container
+// that is still shutting down.
+func containerName(agentID string) string {
+	name := containerNameInvalid.ReplaceAllString(agentID, "-")
+	return fmt.Sprintf("codex-agent-%s-%d", name, time.Now().UnixNano())
+}
+
+// containerNameInvalid matches characters container runtimes reject in names.
+var containerNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// SetContainerConfig runs agents of the given role inside containers.
+// It only affects agents spawned afterwards.
+func (m *Manager) SetContainerConfig(role Role, cfg ContainerConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containers[role] = cfg
+}
+
+// SetRoleBinary makes agents of the given role use a different codex binary.
+func (m *Manager) SetRoleBinary(role Role, bin string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.binaries[role] = bin
+}
+
+// RoleBinary returns the codex binary agents of the given role run.
+func (m *Manager) RoleBinary(role Role) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if bin, ok := m.binaries[role]; ok {
+		return bin
+	}
+	return m.codexBin
+}
+
+// SetRoleSandbox overrides the sandbox mode of agents of the given role.
+func (m *Manager) SetRoleSandbox(role Role, mode string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sandboxes[role] = mode
+}
+
+// SetRoleEphemeral sets whether agents of the given role start ephemeral
+// threads, which are kept out of the user's codex history. Worker and
+// merger threads, one per task or merge, are ephemeral by default.
+func (m *Manager) SetRoleEphemeral(role Role, ephemeral bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ephemeral[role] = ephemeral
+}
+
+// SetRoleMCPServers gives agents of the given role the tools of the named
+// MCP servers, in addition to those of the codex config. It only affects
+// agents spawned afterwards.
+func (m *Manager) SetRoleMCPServers(role Role, servers map[string]MCPServer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mcp[role] = servers
+}
+
+// SetThreadApprovalPolicy sets the codex approval policy ("untrusted",
+// "on-failure", "on-request" or "never") of new threads. Empty keeps the
+// app-server default.
+func (m *Manager) SetThreadApprovalPolicy(policy string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.threadApprovalPolicy = policy
+}
+
+// SetMaxInFlightCalls limits the calls each agent has outstanding on its
+// app-server at once; further calls queue. It only affects agents spawned
+// afterwards. n <= 0 removes the limit.
+func (m *Manager) SetMaxInFlightCalls(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxInFlight = n
+}
+
+// CallStats sums the app-server call counters of the running agents.
+func (m *Manager) CallStats() codexrpc.CallStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := codexrpc.CallStats{Limit: max(m.maxInFlight, 0)}
+	for _, inst := range m.agents {
+		s := inst.Client.CallStats()
+		total.InFlight += s.InFlight
+		total.Queued += s.Queued
+		total.Calls += s.Calls
+		total.Waited += s.Waited
+		total.TotalWait += s.TotalWait
+		total.MaxWait = max(total.MaxWait, s.MaxWait)
+	}
+	return total
+}
+
+// SetStallPolicy enables stuck-turn detection in WaitForCompletion.
+func (m *Manager) SetStallPolicy(p StallPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stall = p
+}
+
+// SendTask starts a turn working on taskID with the given message and
+// returns a handle to wait on with WaitForCompletion. Events of the turn
+// carry taskID, which may be empty for work outside a task graph.
+func (m *Manager) SendTask(ctx context.Context, agentID, taskID, message string) (*Turn, error) {
 	m.mu.Lock()
 	instance, exists := m.agents[agentID]
 	m.mu.Unlock()
 
 	if !exists {
-		return fmt.Errorf("agent %s not found", agentID)
+		return nil, fmt.Errorf("agent %s not found", agentID)
+	}
+
+	turn := newTurn(instance, taskID)
+	if err := m.startTurn(ctx, instance, message, turn); err != nil {
+		return nil, err
 	}
+	return turn, nil
+}
 
+// startTurn sends message as a new turn whose outcome is reported to turn.
+func (m *Manager) startTurn(ctx context.Context, instance *Instance, message string, turn *Turn) error {
 	// Update state to running
 	instance.mu.Lock()
 	instance.State = StateRunning
+	instance.prompt = message
+	instance.lastActivity = time.Now()
+	instance.current = turn
+	instance.starting = turn
 	instance.mu.Unlock()
 
 	// Send the task via TurnStart
-	_, err := instance.Client.TurnStart(ctx, codexrpc.TurnStartParams{
+	resp, err := instance.Client.TurnStart(ctx, codexrpc.TurnStartParams{
 		ThreadID: instance.ThreadID,
 		Input: []codexrpc.UserInput{
 			{
@@ -145,78 +421,207 @@ func (m *Manager) SendTask(ctx context.Context, agentID string, message string)
 	if err != nil {
 		instance.mu.Lock()
 		instance.State = StateFailed
+		if instance.starting == turn {
+			instance.starting = nil
+		}
 		instance.mu.Unlock()
 		return fmt.Errorf("turn start: %w", err)
 	}
 
+	instance.mu.Lock()
+	instance.TurnID = resp.Turn.ID
+	if instance.starting == turn {
+		instance.starting = nil
+		instance.bindTurn(resp.Turn.ID, turn)
+		m.registerTurn(resp.Turn.ID, turn)
+	}
+	instance.mu.Unlock()
+
 	return nil
 }
 
-// StopAgent stops an agent instance.
-func (m *Manager) StopAgent(agentID string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
+// InterruptTurn stops the agent's current turn without failing it.
+// If followUp is non-empty it is sent as a new turn and the agent keeps
+// working; otherwise the interrupted turn counts as completed.
+func (m *Manager) InterruptTurn(ctx context.Context, agentID string, followUp string) error {
+	m.mu.RLock()
 	instance, exists := m.agents[agentID]
+	m.mu.RUnlock()
+
 	if !exists {
 		return fmt.Errorf("agent %s not found", agentID)
 	}
 
-	if err := instance.Process.Close(); err != nil {
-		return fmt.Errorf("close process: %w", err)
+	instance.mu.Lock()
+	turnID := instance.TurnID
+	if instance.State != StateRunning || turnID == "" {
+		instance.mu.Unlock()
+		return fmt.Errorf("agent %s has no running turn", agentID)
 	}
+	instance.resumePending = followUp != ""
+	turn := instance.current
+	instance.mu.Unlock()
 
-	delete(m.agents, agentID)
+	err := instance.Client.TurnInterrupt(ctx, codexrpc.TurnInterruptParams{
+		ThreadID: instance.ThreadID,
+		TurnID:   turnID,
+	})
+	if err != nil {
+		instance.mu.Lock()
+		instance.resumePending = false
+		instance.mu.Unlock()
+		return fmt.Errorf("turn interrupt: %w", err)
+	}
 
-	// Emit agent stopped event
-	m.eventCh <- AgentEvent{
+	if followUp == "" {
+		return nil
+	}
+	return m.startTurn(ctx, instance, followUp, turn)
+}
+
+// StopAgent stops an agent instance. A WaitForCompletion blocked on the
+// agent returns ErrAgentStopped, pending approvals are cancelled and an
+// unconsumed completion is discarded, so the agent ID can be reused
+// without inheriting any state.
+func (m *Manager) StopAgent(agentID string) error {
+	m.mu.Lock()
+	instance, exists := m.agents[agentID]
+	if exists {
+		delete(m.agents, agentID)
+	}
+	m.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("agent %s not found", agentID)
+	}
+
+	// Attribute the stopped event before stop forgets the turns
+	instance.mu.Lock()
+	stopped := AgentEvent{
 		AgentID:   agentID,
+		ThreadID:  instance.ThreadID,
+		TurnID:    instance.TurnID,
 		EventType: "stopped",
-		Data:      nil,
 	}
+	if instance.current != nil {
+		stopped.TaskID = instance.current.TaskID
+	}
+	turnIDs := []string{instance.TurnID}
+	for id := range instance.turns {
+		turnIDs = append(turnIDs, id)
+	}
+	instance.mu.Unlock()
+
+	instance.stop()
+	m.cancelApprovals(agentID)
+	closeErr := instance.Process.Close()
+	m.forgetTurns(turnIDs...)
 
+	// Emit agent stopped event
+	m.emit(stopped)
+
+	if closeErr != nil {
+		return fmt.Errorf("close process: %w", closeErr)
+	}
 	return nil
 }
 
-// Events returns the event channel for receiving agent events.
+// stop marks the instance stopped, waking its waiters, and forgets its
+// turns so no completion outlives it.
+func (i *Instance) stop() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	select {
+	case <-i.stopped:
+		return
+	default:
+	}
+	close(i.stopped)
+	i.current, i.starting, i.turns = nil, nil, nil
+}
+
+// Events returns the event channel for receiving agent events. It must be
+// drained; events that do not fit in its buffer are dropped.
 func (m *Manager) Events() <-chan AgentEvent {
 	return m.eventCh
 }
 
-// createApprovalHandler creates a handler that auto-approves all requests.
+// DroppedEvents returns how many events were dropped because Events was
+// not drained fast enough.
+func (m *Manager) DroppedEvents() uint64 {
+	return m.dropped.Load()
+}
+
+// emit publishes an agent event without blocking. Events are sent while
+// locks are held and from the notification loop, so a slow consumer costs
+// events rather than stalling agents.
+func (m *Manager) emit(ev AgentEvent) {
+	select {
+	case m.eventCh <- ev:
+	default:
+		m.dropped.Add(1)
+	}
+}
+
+// createApprovalHandler creates a handler that answers approval requests
+// according to the approval policy, auto-approving by default.
 func (m *Manager) createApprovalHandler(agentID string) codexrpc.ServerRequestHandler {
 	return func(id codexrpc.RequestID, method string, params json.RawMessage) (json.RawMessage, error) {
-		var decision string
+		if method == codexrpc.MethodDynamicToolCall {
+			return m.handleToolCall(agentID, params)
+		}
+		if method != "command/approval" && method != "fileChange/approval" {
+			return nil, fmt.Errorf("unknown request method: %s", method)
+		}
+
+		m.mu.RLock()
+		policy := m.approval
+		m.mu.RUnlock()
+
+		decision := codexrpc.DecisionAccept
+		if policy.Human {
+			decision = m.awaitApproval(agentID, method, params, policy)
+		}
 
 		switch method {
 		case "command/approval":
-			decision = codexrpc.DecisionAccept
 			resp := codexrpc.CommandApprovalResponse{Decision: decision}
 			return json.Marshal(resp)
-		case "fileChange/approval":
-			decision = codexrpc.DecisionAccept
+		default:
 			resp := codexrpc.FileChangeApprovalResponse{Decision: decision}
 			return json.Marshal(resp)
-		default:
-			return nil, fmt.Errorf("unknown request method: %s", method)
 		}
 	}
 }
 
 // createNotificationHandler creates a handler for server notifications.
-func (m *Manager) createNotificationHandler(agentID string) codexrpc.NotificationHandler {
+func (m *Manager) createNotificationHandler(instance *Instance) codexrpc.NotificationHandler {
 	return func(method string, params json.RawMessage) {
-		m.mu.RLock()
-		instance, exists := m.agents[agentID]
-		m.mu.RUnlock()
-
-		if !exists {
+		select {
+		case <-instance.stopped:
 			return
+		default:
 		}
+		turnID := notificationTurn(params)
+
+		instance.mu.Lock()
+		instance.lastActivity = time.Now()
+		instance.mu.Unlock()
 
 		switch method {
 		case "turn/started":
+			var notif codexrpc.TurnStartedNotification
+			_ = json.Unmarshal(params, &notif)
 			instance.mu.Lock()
+			if notif.Turn.ID != "" {
+				instance.TurnID = notif.Turn.ID
+				if instance.starting != nil {
+					instance.bindTurn(notif.Turn.ID, instance.starting)
+					m.registerTurn(notif.Turn.ID, instance.starting)
+					instance.starting = nil
+				}
+			}
 			instance.State = StateRunning
 			instance.OutputBuffer.Reset() // Clear buffer for new turn
 			instance.mu.Unlock()
@@ -225,22 +630,35 @@ func (m *Manager) createNotificationHandler(agentID string) codexrpc.Notificatio
 			var notif codexrpc.TurnCompletedNotification
 			if err := json.Unmarshal(params, &notif); err == nil {
 				instance.mu.Lock()
-				if notif.Turn.Status == "failed" {
+				turn := instance.takeTurn(notif.Turn.ID)
+				var turnErr error
+				switch {
+				case turn == nil:
+					// A turn nobody waits for, e.g. from before a retry
+				case notif.Turn.Status == "failed":
 					instance.State = StateFailed
-					instance.mu.Unlock()
-					select {
-					case instance.doneCh <- fmt.Errorf("agent task failed"):
-					default:
+					failed := &TurnError{}
+					if e := notif.Turn.Error; e != nil {
+						failed.Message = e.Message
+						if e.AdditionalDetails != nil {
+							failed.Details = *e.AdditionalDetails
+						}
 					}
-				} else if notif.Turn.Status == "completed" {
+					turnErr = failed
+				case notif.Turn.Status == "completed":
 					instance.State = StateCompleted
-					instance.mu.Unlock()
-					select {
-					case instance.doneCh <- nil:
-					default:
-					}
-				} else {
-					instance.mu.Unlock()
+				case notif.Turn.Status == "interrupted" && instance.resumePending:
+					// A follow-up turn is on its way; keep waiting for it
+					instance.resumePending = false
+					turn = nil
+				case notif.Turn.Status == "interrupted":
+					instance.State = StateCompleted
+				default:
+					turn = nil
+				}
+				instance.mu.Unlock()
+				if turn != nil {
+					turn.finish(turnErr)
 				}
 			}
 		case "item/agentMessage/delta":
@@ -251,33 +669,142 @@ func (m *Manager) createNotificationHandler(agentID string) codexrpc.Notificatio
 				instance.OutputBuffer.WriteString(delta.Delta)
 				instance.mu.Unlock()
 			}
+		case "item/started", "item/completed":
+			var notif codexrpc.ItemStartedNotification
+			if err := json.Unmarshal(params, &notif); err == nil && notif.Item != nil &&
+				notif.Item.Type == codexrpc.ItemTypeCommandExecution {
+				eventType := EventCommandStarted
+				if method == "item/completed" {
+					eventType = EventCommandCompleted
+				}
+				m.emitJSONFor(instance, turnID, eventType, CommandEvent{
+					CommandID: notif.Item.ID,
+					Command:   notif.Item.Command,
+					Cwd:       notif.Item.Cwd,
+					Status:    notif.Item.Status,
+					ExitCode:  notif.Item.ExitCode,
+				})
+			}
+		case "thread/tokenUsage/updated":
+			var notif codexrpc.ThreadTokenUsageUpdatedNotification
+			if err := json.Unmarshal(params, &notif); err == nil {
+				instance.mu.Lock()
+				delta := notif.TokenUsage.Total.TotalTokens - instance.tokensUsed
+				instance.tokensUsed = notif.TokenUsage.Total.TotalTokens
+				instance.mu.Unlock()
+				if delta > 0 {
+					m.emitJSONFor(instance, turnID, EventTokensUsed, TokenUsageEvent{
+						Tokens: delta,
+						Total:  notif.TokenUsage.Total.TotalTokens,
+					})
+				}
+			}
+		case "item/commandExecution/outputDelta":
+			var delta codexrpc.CommandExecutionOutputDelta
+			if err := json.Unmarshal(params, &delta); err == nil {
+				m.emitJSONFor(instance, turnID, EventCommandOutput, CommandEvent{
+					CommandID: delta.ItemID,
+					Chunk:     delta.Delta,
+				})
+			}
 		}
 
 		// Forward the notification as an event
-		m.eventCh <- AgentEvent{
-			AgentID:   agentID,
-			EventType: method,
-			Data:      params,
+		m.emitFor(instance, turnID, method, params)
+		if method == "turn/completed" {
+			m.forgetTurns(turnID)
+		}
+	}
+}
+
+// WaitForCompletion blocks until the task behind turn completes, the
+// agent is stopped or stops answering pings, or the context is cancelled.
+func (m *Manager) WaitForCompletion(ctx context.Context, turn *Turn) error {
+	instance, agentID := turn.inst, turn.AgentID
+
+	m.mu.RLock()
+	stall := m.stall
+	m.mu.RUnlock()
+
+	if stall.Timeout <= 0 {
+		select {
+		case err := <-turn.done:
+			return err
+		case <-instance.stopped:
+			return ErrAgentStopped
+		case <-instance.unresponsive:
+			return fmt.Errorf("%w: %s stopped answering pings", ErrAgentUnresponsive, agentID)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Check a few times per timeout; tiny timeouts still need a positive tick
+	interval := stall.Timeout / 4
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	attempt := 0
+	for {
+		select {
+		case err := <-turn.done:
+			return err
+		case <-instance.stopped:
+			return ErrAgentStopped
+		case <-instance.unresponsive:
+			return fmt.Errorf("%w: %s stopped answering pings", ErrAgentUnresponsive, agentID)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		instance.mu.Lock()
+		idle := time.Since(instance.lastActivity)
+		running := instance.State == StateRunning && instance.awaitingApproval == 0
+		prompt := instance.prompt
+		instance.mu.Unlock()
+
+		if !running || idle < stall.Timeout {
+			continue
+		}
+
+		attempt++
+		retrying := attempt <= stall.Retries
+		m.emitJSONEvent(agentID, EventAgentStalled, StallEvent{
+			IdleSeconds: idle.Seconds(),
+			Attempt:     attempt,
+			Retrying:    retrying,
+		})
+		if !retrying {
+			return fmt.Errorf("%w: %s had no activity for %s", ErrAgentStalled, agentID, idle.Round(time.Second))
+		}
+
+		// Restart the turn with the original prompt
+		retryCtx, cancel := context.WithTimeout(ctx, stall.Timeout)
+		err := m.InterruptTurn(retryCtx, agentID, prompt)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("agent %s stalled, retry failed: %w", agentID, err)
 		}
 	}
 }
 
-// WaitForCompletion blocks until the agent's current task completes or the context is cancelled.
-func (m *Manager) WaitForCompletion(ctx context.Context, agentID string) error {
+// GetState returns the current state of an agent, or "" if it is not running.
+func (m *Manager) GetState(agentID string) AgentState {
 	m.mu.RLock()
 	instance, exists := m.agents[agentID]
 	m.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("agent %s not found", agentID)
+		return ""
 	}
 
-	select {
-	case err := <-instance.doneCh:
-		return err
-	case <-ctx.Done():
-		return ctx.Err()
-	}
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+	return instance.State
 }
 
 // GetOutput retrieves the accumulated output from an agent.
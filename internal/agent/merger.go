@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,15 +12,78 @@ import (
 
 // Merger handles merging worktree branches back to main.
 type Merger struct {
-	agentMgr    *Manager
-	worktreeMgr *worktree.Manager
+	agentMgr    Agents
+	worktreeMgr worktree.Git
+
+	runtime Runtime // codex binary and provider of merge agents
+
+	octopusThreshold int // branches above which an octopus merge is tried first, negative for never
+	resolveAttempts  int // prompts per conflicted file before giving up on it
+
+	checkCommand TestCommand // run on resolved conflicts before the merge is committed
+	notes        string      // what earlier merges of the repository taught, for the instructions
+
+	spawned spawnLog
+}
+
+// AgentIDs returns the IDs of the merger and resolver agents the merger
+// spawned, oldest first.
+func (m *Merger) AgentIDs() []string {
+	return m.spawned.list()
+}
+
+// SetNotes adds notes from earlier merges of the repository, such as
+// resolution conventions, to the instructions of merge agents.
+func (m *Merger) SetNotes(notes string) {
+	m.notes = notes
+}
+
+// ErrInvalidResolution is returned when conflicts the agent resolved fail
+// validation: conflict markers are left, git diff --check complains or the
+// check command fails.
+var ErrInvalidResolution = errors.New("invalid conflict resolution")
+
+// SetCheckCommand makes resolved conflicts pass tc, typically a build,
+// before the merge is committed. An empty command skips the check.
+func (m *Merger) SetCheckCommand(tc TestCommand) {
+	m.checkCommand = tc
+}
+
+// DefaultOctopusThreshold is the number of branches above which merge plans
+// try an octopus merge before merging one branch at a time.
+const DefaultOctopusThreshold = 3
+
+// DefaultResolveAttempts is the number of times the merger agent is asked
+// to resolve one conflicted file.
+const DefaultResolveAttempts = 2
+
+// SetResolveAttempts sets how many times the merger agent is asked to
+// resolve each conflicted file before the merge of its branch fails.
+func (m *Merger) SetResolveAttempts(n int) {
+	if n < 1 {
+		n = 1
+	}
+	m.resolveAttempts = n
+}
+
+// SetOctopusThreshold makes plans of more than n branches try an octopus
+// merge first. A negative n disables octopus merges.
+func (m *Merger) SetOctopusThreshold(n int) {
+	m.octopusThreshold = n
+}
+
+// SetRuntime makes merge and conflict resolution agents run with rt.
+func (m *Merger) SetRuntime(rt Runtime) {
+	m.runtime = rt
 }
 
 // NewMerger creates a new Merger.
-func NewMerger(agentMgr *Manager, wtMgr *worktree.Manager) *Merger {
+func NewMerger(agentMgr Agents, wtMgr worktree.Git) *Merger {
 	return &Merger{
-		agentMgr:    agentMgr,
-		worktreeMgr: wtMgr,
+		agentMgr:         agentMgr,
+		worktreeMgr:      wtMgr,
+		octopusThreshold: DefaultOctopusThreshold,
+		resolveAttempts:  DefaultResolveAttempts,
 	}
 }
 
@@ -31,6 +95,25 @@ type MergeResult struct {
 	Conflicts       []string `json:"conflicts,omitempty"`
 	ResolvedByAgent []string `json:"resolvedByAgent,omitempty"`
 	MergeCommit     string   `json:"mergeCommit,omitempty"`
+
+	Strategy        string        `json:"strategy"`                  // strategy that ran: "octopus" or "sequential"
+	OctopusFallback bool          `json:"octopusFallback,omitempty"` // the octopus merge failed and branches were merged one at a time
+	BaseCommit      string        `json:"baseCommit,omitempty"`      // target branch head before the first merge
+	Merges          []BranchMerge `json:"merges,omitempty"`          // merged branches, in merge order
+
+	ResolvedFiles   []string `json:"resolvedFiles,omitempty"`   // conflicted files the merger agent fixed
+	UnresolvedFiles []string `json:"unresolvedFiles,omitempty"` // conflicted files it gave up on
+
+	ValidationErrors []string `json:"validationErrors,omitempty"` // why resolutions were rejected, by branch
+	Conventions      []string `json:"conventions,omitempty"`      // reusable resolution conventions the agent reported
+}
+
+// BranchMerge records the commit that brought a branch into the target.
+type BranchMerge struct {
+	Branch          string `json:"branch"`
+	Commit          string   `json:"commit"`
+	ResolvedByAgent bool     `json:"resolvedByAgent,omitempty"` // conflicts were resolved by the merger agent
+	ResolvedFiles   []string `json:"resolvedFiles,omitempty"`   // files whose conflicts it resolved
 }
 
 // MergePlan defines the order and strategy for merging.
@@ -56,12 +139,13 @@ func (m *Merger) Merge(ctx context.Context, repoPath string, plan *MergePlan) (*
 
 // mergeSequentialWithAgent merges branches one by one with agent conflict resolution.
 func (m *Merger) mergeSequentialWithAgent(ctx context.Context, repoPath string, plan *MergePlan) (*MergeResult, error) {
-	result := &MergeResult{Success: true}
+	result := &MergeResult{Success: true, Strategy: "sequential"}
 
 	// Switch to target branch first
 	if err := m.checkoutBranch(ctx, repoPath, plan.TargetBranch); err != nil {
 		return nil, fmt.Errorf("checkout target branch: %w", err)
 	}
+	result.BaseCommit, _ = m.worktreeMgr.HeadCommitAt(ctx, repoPath)
 
 	// Spawn a single Merger agent for the entire process
 	agentCfg := AgentConfig{
@@ -70,39 +154,37 @@ func (m *Merger) mergeSequentialWithAgent(ctx context.Context, repoPath string,
 		Cwd:           repoPath,
 		SandboxMode:   codexrpc.SandboxWorkspaceWrite,
 		BaseInstructions: m.getMergeInstructions(plan),
+		Runtime:          m.runtime,
 	}
 
-	instance, err := m.agentMgr.SpawnAgent(ctx, agentCfg)
+	instance, err := m.spawned.spawn(ctx, m.agentMgr, agentCfg)
 	if err != nil {
 		return nil, fmt.Errorf("spawn merger agent: %w", err)
 	}
 	defer m.agentMgr.StopAgent(instance.Config.ID)
 
 	for _, branch := range plan.Branches {
-		// Attempt merge
-		commitSHA, err := m.worktreeMgr.Merge(ctx, repoPath, branch)
+		// Attempt merge, keeping it in progress on conflicts
+		commitSHA, conflictFiles, err := m.worktreeMgr.TryMerge(ctx, repoPath, branch)
 		if err == nil {
 			// Success
 			result.MergedCount++
+			result.Merges = append(result.Merges, BranchMerge{Branch: branch, Commit: commitSHA})
 			if result.MergeCommit == "" {
 				result.MergeCommit = commitSHA
 			}
 			continue
 		}
 
-		// Check if there are conflicts
-		hasConflicts, conflictFiles, err := m.worktreeMgr.HasConflicts(ctx, repoPath)
-		if err != nil {
-			result.FailedBranches = append(result.FailedBranches, branch)
-			result.Success = false
-			m.worktreeMgr.AbortMerge(ctx, repoPath)
-			continue
-		}
-
-		if hasConflicts {
-			// Try to resolve conflicts with the agent
-			resolved, err := m.resolveConflictsWithAgent(ctx, instance.Config.ID, conflictFiles)
+		if errors.Is(err, worktree.ErrConflict) {
+			// Try to resolve conflicts with the agent, file by file
+			resolved, unresolved, err := m.resolveConflictsWithAgent(ctx, instance.Config.ID, repoPath, conflictFiles)
+			result.ResolvedFiles = append(result.ResolvedFiles, resolved...)
+			result.UnresolvedFiles = append(result.UnresolvedFiles, unresolved...)
 			if err != nil {
+				if errors.Is(err, ErrInvalidResolution) {
+					result.ValidationErrors = append(result.ValidationErrors, fmt.Sprintf("%s: %v", branch, err))
+				}
 				result.FailedBranches = append(result.FailedBranches, branch)
 				result.Conflicts = append(result.Conflicts, conflictFiles...)
 				result.Success = false
@@ -110,7 +192,7 @@ func (m *Merger) mergeSequentialWithAgent(ctx context.Context, repoPath string,
 				continue
 			}
 
-			if resolved {
+			if len(unresolved) == 0 {
 				// Commit the resolved merge
 				commitMsg := fmt.Sprintf("Merge %s (conflicts resolved by agent)", branch)
 				commitSHA, err := m.worktreeMgr.CommitChanges(ctx, repoPath, commitMsg)
@@ -122,6 +204,7 @@ func (m *Merger) mergeSequentialWithAgent(ctx context.Context, repoPath string,
 				}
 				result.MergedCount++
 				result.ResolvedByAgent = append(result.ResolvedByAgent, branch)
+				result.Merges = append(result.Merges, BranchMerge{Branch: branch, Commit: commitSHA, ResolvedByAgent: true, ResolvedFiles: resolved})
 				if result.MergeCommit == "" {
 					result.MergeCommit = commitSHA
 				}
@@ -138,9 +221,24 @@ func (m *Merger) mergeSequentialWithAgent(ctx context.Context, repoPath string,
 		}
 	}
 
+	result.Conventions = parseConventions(m.agentMgr.GetOutput(instance.Config.ID))
 	return result, nil
 }
 
+// parseConventions extracts the "CONVENTION:" lines of an agent's output.
+func parseConventions(output string) []string {
+	var conventions []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if c, ok := strings.CutPrefix(line, "CONVENTION:"); ok {
+			if c = strings.TrimSpace(c); c != "" && c != "<one sentence>" {
+				conventions = append(conventions, c)
+			}
+		}
+	}
+	return conventions
+}
+
 // mergeOctopusWithFallback attempts octopus merge, falls back to sequential.
 func (m *Merger) mergeOctopusWithFallback(ctx context.Context, repoPath string, plan *MergePlan) (*MergeResult, error) {
 	// Switch to target branch first
@@ -149,64 +247,203 @@ func (m *Merger) mergeOctopusWithFallback(ctx context.Context, repoPath string,
 	}
 
 	// Try octopus merge
+	base, _ := m.worktreeMgr.HeadCommitAt(ctx, repoPath)
 	commitSHA, err := m.worktreeMgr.OctopusMerge(ctx, repoPath, plan.Branches)
 	if err == nil {
-		return &MergeResult{
+		result := &MergeResult{
 			Success:     true,
 			MergedCount: len(plan.Branches),
 			MergeCommit: commitSHA,
-		}, nil
+			Strategy:    "octopus",
+			BaseCommit:  base,
+		}
+		for _, branch := range plan.Branches {
+			result.Merges = append(result.Merges, BranchMerge{Branch: branch, Commit: commitSHA})
+		}
+		return result, nil
 	}
 
-	// Octopus failed, check for conflicts and fall back to sequential
-	hasConflicts, _, err := m.worktreeMgr.HasConflicts(ctx, repoPath)
-	if err == nil && hasConflicts {
-		// Abort and fall back to sequential with agent
-		_ = m.worktreeMgr.AbortMerge(ctx, repoPath)
-		return m.mergeSequentialWithAgent(ctx, repoPath, plan)
+	// Octopus failed, either on conflicts or e.g. unrelated histories:
+	// abort and fall back to sequential with agent
+	_ = m.worktreeMgr.AbortMerge(ctx, repoPath)
+	result, err := m.mergeSequentialWithAgent(ctx, repoPath, plan)
+	if result != nil {
+		result.OctopusFallback = true
 	}
+	return result, err
+}
 
-	// No conflicts but octopus failed (e.g., unrelated histories)
-	// Fall back to sequential
-	_ = m.worktreeMgr.AbortMerge(ctx, repoPath)
-	return m.mergeSequentialWithAgent(ctx, repoPath, plan)
+// ResolveConflicts spawns a short-lived resolver agent in worktreePath to
+// resolve an in-progress merge of branch. It reports whether the agent
+// claimed success; the caller should verify and commit the result.
+func (m *Merger) ResolveConflicts(ctx context.Context, worktreePath, branch string, conflictFiles []string) (bool, error) {
+	agentCfg := AgentConfig{
+		ID:          "resolver-" + GenerateID(),
+		Role:        RoleMerger,
+		Cwd:         worktreePath,
+		SandboxMode: codexrpc.SandboxWorkspaceWrite,
+		BaseInstructions: m.getMergeInstructions(&MergePlan{
+			Branches:     []string{branch},
+			Strategy:     "sequential",
+			TargetBranch: "the current task branch",
+		}),
+		Runtime: m.runtime,
+	}
+
+	instance, err := m.spawned.spawn(ctx, m.agentMgr, agentCfg)
+	if err != nil {
+		return false, fmt.Errorf("spawn resolver agent: %w", err)
+	}
+	defer m.agentMgr.StopAgent(instance.Config.ID)
+
+	_, unresolved, err := m.resolveConflictsWithAgent(ctx, instance.Config.ID, worktreePath, conflictFiles)
+	return err == nil && len(unresolved) == 0, err
 }
 
-// resolveConflictsWithAgent asks the Merger agent to resolve conflicts.
-func (m *Merger) resolveConflictsWithAgent(ctx context.Context, agentID string, conflictFiles []string) (bool, error) {
+// resolveConflictsWithAgent asks the Merger agent to resolve the conflicts
+// of one file per turn, retrying each file up to the configured attempts.
+// A file counts as resolved once git no longer lists it as unmerged in
+// worktreePath. Files left after the first one given up on are not tried.
+// Once all are resolved, the result is validated; a resolution still
+// invalid after the agent's fix attempts fails with ErrInvalidResolution.
+func (m *Merger) resolveConflictsWithAgent(ctx context.Context, agentID, worktreePath string, conflictFiles []string) (resolved, unresolved []string, err error) {
 	if len(conflictFiles) == 0 {
-		return false, fmt.Errorf("no conflict files to resolve")
+		return nil, nil, fmt.Errorf("no conflict files to resolve")
 	}
 
-	prompt := fmt.Sprintf(`Please resolve the merge conflicts in the following files:
+	for i, file := range conflictFiles {
+		fixed := false
+		for attempt := 1; attempt <= m.resolveAttempts && !fixed; attempt++ {
+			if fixed, err = m.resolveFile(ctx, agentID, worktreePath, file, attempt); err != nil {
+				return resolved, append(unresolved, conflictFiles[i:]...), err
+			}
+		}
+		if !fixed {
+			// The branch fails anyway; spare the remaining turns
+			return resolved, append(unresolved, conflictFiles[i:]...), nil
+		}
+		resolved = append(resolved, file)
+	}
+
+	for attempt := 1; ; attempt++ {
+		problem, err := m.validateResolution(ctx, worktreePath, resolved)
+		if err != nil {
+			return nil, resolved, err
+		}
+		if problem == "" {
+			return resolved, nil, nil
+		}
+		if attempt >= m.resolveAttempts {
+			return nil, resolved, fmt.Errorf("%w: %s", ErrInvalidResolution, problem)
+		}
+		if err := m.fixResolution(ctx, agentID, problem); err != nil {
+			return nil, resolved, err
+		}
+	}
+}
+
+// validateResolution checks resolved files for leftover conflict markers
+// and whitespace errors, then runs the check command. It returns what is
+// wrong, or "" when the resolution can be committed.
+func (m *Merger) validateResolution(ctx context.Context, worktreePath string, files []string) (string, error) {
+	markers, err := m.worktreeMgr.ConflictMarkers(worktreePath, files)
+	if err != nil {
+		return "", err
+	}
+	if len(markers) > 0 {
+		return fmt.Sprintf("conflict markers left at %s", strings.Join(markers, ", ")), nil
+	}
+
+	issues, err := m.worktreeMgr.DiffCheck(ctx, worktreePath, files)
+	if err != nil {
+		return "", err
+	}
+	if issues != "" {
+		return "git diff --check reports:\n" + issues, nil
+	}
+
+	if m.checkCommand.Command == "" {
+		return "", nil
+	}
+	run := runTests(worktreePath, m.checkCommand, nil)
+	switch {
+	case run.Passed:
+		return "", nil
+	case run.Error != "":
+		return fmt.Sprintf("check command %q: %s", run.Command, run.Error), nil
+	default:
+		return fmt.Sprintf("check command %q exited with %d:\n%s", run.Command, run.ExitCode, strings.TrimSpace(run.Output)), nil
+	}
+}
+
+// fixResolution asks the agent to correct a resolution that failed
+// validation.
+func (m *Merger) fixResolution(ctx context.Context, agentID, problem string) error {
+	prompt := fmt.Sprintf(`The conflict resolution is not ready to commit:
+
 %s
 
-For each conflict:
+Fix the merged files so the problem goes away, keeping the intent of both sides, and git add them again. Report "DONE" when finished.`, problem)
+
+	turn, err := m.agentMgr.SendTask(ctx, agentID, "", prompt)
+	if err != nil {
+		return fmt.Errorf("send task: %w", err)
+	}
+	if err := m.agentMgr.WaitForCompletion(ctx, turn); err != nil {
+		return fmt.Errorf("wait for completion: %w", err)
+	}
+	return nil
+}
+
+// resolveFile runs one turn asking the agent to resolve file and reports
+// whether git considers it merged afterwards.
+func (m *Merger) resolveFile(ctx context.Context, agentID, worktreePath, file string, attempt int) (bool, error) {
+	if unmerged, err := m.unmergedFiles(ctx, worktreePath); err != nil {
+		return false, err
+	} else if !unmerged[file] {
+		// Resolved along with an earlier file
+		return true, nil
+	}
+
+	prompt := fmt.Sprintf(`Please resolve the merge conflicts in %s.
+
 1. Open the file and examine both sides
 2. Understand the intent of both changes
 3. Create a merged version that preserves functionality from both sides
-4. Use git add to mark each file as resolved
+4. Use git add to mark the file as resolved
 
-After resolving all conflicts, report "DONE". If you cannot resolve a conflict, report "FAILED: <reason>".`,
-		strings.Join(conflictFiles, "\n"))
+Only change this file. Report "DONE" when it is resolved, or "FAILED: <reason>" if you cannot resolve it.
+If you followed a convention worth reusing in future merges of this repository, also add a line "CONVENTION: <one sentence>".`, file)
+	if attempt > 1 {
+		prompt += fmt.Sprintf("\n\nAttempt %d: git still lists %s as unmerged. Remove every conflict marker and git add the file.", attempt, file)
+	}
 
-	err := m.agentMgr.SendTask(ctx, agentID, prompt)
+	turn, err := m.agentMgr.SendTask(ctx, agentID, "", prompt)
 	if err != nil {
 		return false, fmt.Errorf("send task: %w", err)
 	}
-
-	err = m.agentMgr.WaitForCompletion(ctx, agentID)
-	if err != nil {
+	if err := m.agentMgr.WaitForCompletion(ctx, turn); err != nil {
 		return false, fmt.Errorf("wait for completion: %w", err)
 	}
 
-	// Check if agent reported success
-	output := m.agentMgr.GetOutput(agentID)
-	if strings.Contains(output, "DONE") {
-		return true, nil
+	unmerged, err := m.unmergedFiles(ctx, worktreePath)
+	if err != nil {
+		return false, err
 	}
+	return !unmerged[file], nil
+}
 
-	return false, nil
+// unmergedFiles returns the files git lists as unmerged in worktreePath.
+func (m *Merger) unmergedFiles(ctx context.Context, worktreePath string) (map[string]bool, error) {
+	_, files, err := m.worktreeMgr.HasConflicts(ctx, worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	unmerged := make(map[string]bool, len(files))
+	for _, f := range files {
+		unmerged[f] = true
+	}
+	return unmerged, nil
 }
 
 // checkoutBranch switches to the specified branch.
@@ -218,7 +455,7 @@ func (m *Merger) checkoutBranch(ctx context.Context, repoPath, branch string) er
 
 // getMergeInstructions returns instructions for the merge agent.
 func (m *Merger) getMergeInstructions(plan *MergePlan) string {
-	return fmt.Sprintf(`You are a merge assistant. Your job is to help merge branches into %s.
+	instructions := fmt.Sprintf(`You are a merge assistant. Your job is to help merge branches into %s.
 
 When conflicts occur:
 1. Analyze both sides carefully
@@ -230,9 +467,14 @@ When conflicts occur:
 Merge strategy: %s
 
 You will be asked to resolve conflicts as they arise. Focus on creating a clean, functional merge.`, plan.TargetBranch, plan.Strategy)
+	if m.notes != "" {
+		instructions += "\n\nNotes from earlier merges in this repository:\n" + m.notes
+	}
+	return instructions
 }
 
-// CreateMergePlan creates a merge plan from completed tasks.
+// CreateMergePlan creates a merge plan from completed tasks. Branches are
+// merged in the order of taskIDs.
 func (m *Merger) CreateMergePlan(taskIDs []string, branchMap map[string]string) *MergePlan {
 	branches := make([]string, 0, len(taskIDs))
 	for _, id := range taskIDs {
@@ -242,7 +484,7 @@ func (m *Merger) CreateMergePlan(taskIDs []string, branchMap map[string]string)
 	}
 
 	strategy := "sequential"
-	if len(branches) > 3 {
+	if m.octopusThreshold >= 0 && len(branches) > m.octopusThreshold {
 		// Use octopus for many branches
 		strategy = "octopus"
 	}
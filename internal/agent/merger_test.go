@@ -0,0 +1,143 @@
+package agent_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/agent/agenttest"
+	"codex-agent-team/internal/worktree/worktreetest"
+)
+
+const repo = "/repo"
+
+// conflictingBranches returns a repository whose branches task-a and
+// task-b both changed greet.txt, so merging the second one conflicts.
+func conflictingBranches(t *testing.T) *worktreetest.Git {
+	t.Helper()
+	ctx := context.Background()
+	g := worktreetest.New(repo, "main", map[string]string{"greet.txt": "hello\n"})
+	for branch, content := range map[string]string{"task-a": "hello a\n", "task-b": "hello b\n"} {
+		wt, err := g.Create(ctx, branch, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.WriteFile(wt.Path, "greet.txt", content); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.CommitChanges(ctx, wt.Path, "Task "+branch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g
+}
+
+func mergePlan() *agent.MergePlan {
+	return &agent.MergePlan{Branches: []string{"task-a", "task-b"}, Strategy: "sequential", TargetBranch: "main"}
+}
+
+func TestMergerResolvesConflicts(t *testing.T) {
+	g := conflictingBranches(t)
+	agents := agenttest.New(func(cfg agent.AgentConfig, taskID, message string) agenttest.Reply {
+		if !strings.Contains(message, "resolve the merge conflicts in greet.txt") {
+			return agenttest.Reply{}
+		}
+		if err := g.Resolve(cfg.Cwd, "greet.txt", "hello a and b\n"); err != nil {
+			t.Error(err)
+		}
+		return agenttest.Reply{Output: "DONE\nCONVENTION: keep both greetings\n"}
+	})
+
+	result, err := agent.NewMerger(agents, g).Merge(context.Background(), repo, mergePlan())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.MergedCount != 2 {
+		t.Fatalf("merge failed: %+v", result)
+	}
+	if len(result.ResolvedByAgent) != 1 || result.ResolvedByAgent[0] != "task-b" {
+		t.Errorf("ResolvedByAgent = %v, want [task-b]", result.ResolvedByAgent)
+	}
+	if len(result.ResolvedFiles) != 1 || result.ResolvedFiles[0] != "greet.txt" {
+		t.Errorf("ResolvedFiles = %v, want [greet.txt]", result.ResolvedFiles)
+	}
+	if len(result.Conventions) != 1 || result.Conventions[0] != "keep both greetings" {
+		t.Errorf("Conventions = %v", result.Conventions)
+	}
+
+	head, _ := g.Branch("main")
+	if got := g.Files(head)["greet.txt"]; got != "hello a and b\n" {
+		t.Errorf("merged greet.txt = %q", got)
+	}
+	if parents := g.Parents(head); len(parents) != 2 {
+		t.Errorf("resolved merge has parents %v, want two", parents)
+	}
+	if spawned := agents.Spawned(); len(spawned) != 1 || spawned[0].Role != agent.RoleMerger {
+		t.Errorf("spawned %+v, want one merger agent", spawned)
+	}
+}
+
+func TestMergerGivesUpOnUnresolvedConflict(t *testing.T) {
+	g := conflictingBranches(t)
+	agents := agenttest.New(func(cfg agent.AgentConfig, taskID, message string) agenttest.Reply {
+		return agenttest.Reply{Output: "FAILED: cannot decide\n"}
+	})
+
+	m := agent.NewMerger(agents, g)
+	m.SetResolveAttempts(3)
+	result, err := m.Merge(context.Background(), repo, mergePlan())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || result.MergedCount != 1 {
+		t.Fatalf("merge should fail on task-b only: %+v", result)
+	}
+	if len(result.FailedBranches) != 1 || result.FailedBranches[0] != "task-b" {
+		t.Errorf("FailedBranches = %v, want [task-b]", result.FailedBranches)
+	}
+	if len(result.UnresolvedFiles) != 1 || result.UnresolvedFiles[0] != "greet.txt" {
+		t.Errorf("UnresolvedFiles = %v, want [greet.txt]", result.UnresolvedFiles)
+	}
+	if sent := agents.Sent(); len(sent) != 3 {
+		t.Errorf("agent was prompted %d times, want one per attempt (3)", len(sent))
+	}
+
+	// The failed merge was aborted, leaving task-a's version
+	if got, _ := g.ReadFile(repo, "greet.txt"); got != "hello a\n" {
+		t.Errorf("greet.txt after abort = %q, want task-a's version", got)
+	}
+	if conflicted, _, _ := g.HasConflicts(context.Background(), repo); conflicted {
+		t.Error("merge still in progress after giving up")
+	}
+}
+
+func TestMergerRejectsLeftoverConflictMarkers(t *testing.T) {
+	g := conflictingBranches(t)
+	agents := agenttest.New(func(cfg agent.AgentConfig, taskID, message string) agenttest.Reply {
+		if strings.Contains(message, "resolve the merge conflicts in greet.txt") {
+			// Marks the file resolved but keeps one side's markers
+			if err := g.Resolve(cfg.Cwd, "greet.txt", "<<<<<<< HEAD\nhello a\n"); err != nil {
+				t.Error(err)
+			}
+		}
+		return agenttest.Reply{Output: "DONE\n"}
+	})
+
+	result, err := agent.NewMerger(agents, g).Merge(context.Background(), repo, mergePlan())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success {
+		t.Fatal("merge with conflict markers left succeeded")
+	}
+	if len(result.ValidationErrors) != 1 || !strings.Contains(result.ValidationErrors[0], "conflict markers left at greet.txt:1") {
+		t.Errorf("ValidationErrors = %v", result.ValidationErrors)
+	}
+
+	// One resolution turn, then one turn asking to fix it
+	sent := agents.Sent()
+	if len(sent) != 2 || !strings.Contains(sent[1].Text, "not ready to commit") {
+		t.Errorf("sent %d prompts, want a resolution and a fix request", len(sent))
+	}
+}
@@ -4,18 +4,78 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"codex-agent-team/internal/codexrpc"
+	"codex-agent-team/internal/index"
 )
 
+// OrchestratorPromptVersion identifies the decomposition prompts below.
+// Bump it whenever the prompts change so cached decompositions are invalidated.
+const OrchestratorPromptVersion = "3"
+
 // Orchestrator handles task decomposition using Codex.
 type Orchestrator struct {
-	agentMgr *Manager
+	agentMgr Agents
+
+	codeIndex bool // ground plans in a code index, see SetCodeIndex
+	idxMu     sync.Mutex
+	idx       *index.Index
+	idxHead   string // repo HEAD idx was built at
+
+	limits PlanLimits // guardrails on plan size, see SetLimits
+
+	runtime Runtime // codex binary and provider of the orchestrator agent
+
+	spawned spawnLog
+}
+
+// AgentIDs returns the IDs of the agents the orchestrator spawned, oldest
+// first.
+func (o *Orchestrator) AgentIDs() []string {
+	return o.spawned.list()
+}
+
+// SetRuntime makes the orchestrator agent run with rt.
+func (o *Orchestrator) SetRuntime(rt Runtime) {
+	o.runtime = rt
+}
+
+// SetCodeIndex enables the code index: symbols related to the task are
+// added to the decomposition prompt, and ResolveFiles uses it to correct
+// suggested files that do not exist.
+func (o *Orchestrator) SetCodeIndex(enabled bool) {
+	o.codeIndex = enabled
+}
+
+// index returns the code index of repoPath, rebuilt when HEAD moves, or
+// nil if indexing is disabled or fails.
+func (o *Orchestrator) index(ctx context.Context, repoPath string) *index.Index {
+	if !o.codeIndex {
+		return nil
+	}
+	head := strings.TrimSpace(packGit(ctx, repoPath, "rev-parse", "HEAD"))
+
+	o.idxMu.Lock()
+	defer o.idxMu.Unlock()
+	if o.idx != nil && o.idx.Root == repoPath && head != "" && head == o.idxHead {
+		return o.idx
+	}
+	idx, err := index.Build(ctx, repoPath)
+	if err != nil {
+		return nil
+	}
+	o.idx, o.idxHead = idx, head
+	return idx
 }
 
+// maxPromptSymbols bounds the indexed symbols added to the prompt.
+const maxPromptSymbols = 40
+
 // NewOrchestrator creates a new Orchestrator.
-func NewOrchestrator(mgr *Manager) *Orchestrator {
+func NewOrchestrator(mgr Agents) *Orchestrator {
 	return &Orchestrator{
 		agentMgr: mgr,
 	}
@@ -36,6 +96,14 @@ type TaskSuggestion struct {
 	DependsOn      []string `json:"dependsOn"`
 	Files          []string `json:"files,omitempty"`
 	EstimatedTime  string   `json:"estimatedTime,omitempty"`
+
+	// Outputs are files dependent tasks build on, such as a schema or an
+	// API spec. The task fails if it does not produce them.
+	Outputs []string `json:"outputs,omitempty"`
+
+	// Collect are globs of files worth keeping after the worktree is
+	// cleaned up, such as built binaries or coverage reports.
+	Collect []string `json:"collect,omitempty"`
 }
 
 // Decompose analyzes the user's task and codebase, then returns a suggested task decomposition.
@@ -47,37 +115,133 @@ func (o *Orchestrator) Decompose(ctx context.Context, repoPath, userTask string)
 		Cwd:            repoPath,
 		SandboxMode:    codexrpc.SandboxReadOnly,
 		BaseInstructions: o.getAnalysisPrompt(),
+		Runtime:          o.runtime,
 	}
 
-	instance, err := o.agentMgr.SpawnAgent(ctx, agentCfg)
+	instance, err := o.spawned.spawn(ctx, o.agentMgr, agentCfg)
 	if err != nil {
 		return nil, fmt.Errorf("spawn orchestrator agent: %w", err)
 	}
 	defer o.agentMgr.StopAgent(instance.Config.ID)
 
-	// 2. Send analysis prompt to Codex
-	prompt := o.buildDecompositionPrompt(userTask)
-	err = o.agentMgr.SendTask(ctx, instance.Config.ID, prompt)
+	// 2. Send analysis prompt to Codex, with a summary of the repository
+	// so the agent spends fewer turns exploring
+	pack := BuildContextPack(ctx, repoPath)
+	if idx := o.index(ctx, repoPath); idx != nil {
+		if symbols := idx.Search(userTask, maxPromptSymbols); len(symbols) > 0 {
+			pack += "\n## Symbols related to the task\n" + index.FormatSymbols(symbols)
+		}
+	}
+	prompt := o.buildDecompositionPrompt(userTask, pack)
+	decomp, err := o.ask(ctx, instance.Config.ID, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Ask for oversize tasks to be split before accepting the plan.
+	// The thread keeps its context, so the follow-up can refer to it.
+	rounds := o.limits.SplitRounds
+	if rounds <= 0 {
+		rounds = defaultSplitRounds
+	}
+	for i := 0; i < rounds; i++ {
+		problems := o.CheckLimits(decomp)
+		if len(problems) == 0 {
+			break
+		}
+		decomp, err = o.ask(ctx, instance.Config.ID, o.buildSplitPrompt(problems))
+		if err != nil {
+			return nil, fmt.Errorf("split oversize tasks: %w", err)
+		}
+	}
+
+	return decomp, nil
+}
+
+// ask sends prompt as a new turn and parses the reply as a decomposition.
+func (o *Orchestrator) ask(ctx context.Context, agentID, prompt string) (*TaskDecomposition, error) {
+	turn, err := o.agentMgr.SendTask(ctx, agentID, "", prompt)
 	if err != nil {
 		return nil, fmt.Errorf("send task: %w", err)
 	}
 
-	// 3. Wait for completion
-	err = o.agentMgr.WaitForCompletion(ctx, instance.Config.ID)
+	err = o.agentMgr.WaitForCompletion(ctx, turn)
 	if err != nil {
 		return nil, fmt.Errorf("wait for completion: %w", err)
 	}
 
-	// 4. Parse the response as TaskDecomposition
-	output := o.agentMgr.GetOutput(instance.Config.ID)
+	output := o.agentMgr.GetOutput(agentID)
 	decomp, err := o.parseDecomposition(output)
 	if err != nil {
-		return nil, fmt.Errorf("parse decomposition: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrDecompositionParse, err)
 	}
-
 	return decomp, nil
 }
 
+// Sanitize repairs common LLM mistakes in a decomposition in place:
+// duplicate task IDs, self-dependencies, duplicate edges, dependencies
+// on tasks that do not exist and outputs or collect globs outside the
+// repository are dropped. It returns one warning per fix.
+func (d *TaskDecomposition) Sanitize() []string {
+	var warnings []string
+
+	ids := make(map[string]bool, len(d.Tasks))
+	tasks := d.Tasks[:0]
+	for _, t := range d.Tasks {
+		if t.ID == "" || ids[t.ID] {
+			warnings = append(warnings, fmt.Sprintf("dropped task %q: missing or duplicate ID", t.ID))
+			continue
+		}
+		ids[t.ID] = true
+		tasks = append(tasks, t)
+	}
+	d.Tasks = tasks
+
+	for i := range d.Tasks {
+		t := &d.Tasks[i]
+		seen := make(map[string]bool, len(t.DependsOn))
+		deps := make([]string, 0, len(t.DependsOn))
+		for _, dep := range t.DependsOn {
+			switch {
+			case dep == t.ID:
+				warnings = append(warnings, fmt.Sprintf("task %s: dropped dependency on itself", t.ID))
+			case !ids[dep]:
+				warnings = append(warnings, fmt.Sprintf("task %s: dropped dependency on unknown task %q", t.ID, dep))
+			case seen[dep]:
+				// Duplicate edge, harmless
+			default:
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+		t.DependsOn = deps
+
+		outputs := t.Outputs[:0]
+		for _, out := range t.Outputs {
+			clean := filepath.Clean(out)
+			if out == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+				warnings = append(warnings, fmt.Sprintf("task %s: dropped output %q outside the repository", t.ID, out))
+				continue
+			}
+			outputs = append(outputs, clean)
+		}
+		t.Outputs = outputs
+
+		collect := t.Collect[:0]
+		for _, glob := range t.Collect {
+			clean := filepath.Clean(glob)
+			if glob == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+				warnings = append(warnings, fmt.Sprintf("task %s: dropped collect glob %q outside the repository", t.ID, glob))
+				continue
+			}
+			collect = append(collect, filepath.ToSlash(clean))
+		}
+		t.Collect = collect
+	}
+
+	return warnings
+}
+
 // parseDecomposition extracts JSON from the agent's output.
 func (o *Orchestrator) parseDecomposition(output string) (*TaskDecomposition, error) {
 	// Try to extract JSON from markdown code blocks or plain JSON
@@ -120,11 +284,14 @@ Always respond with valid JSON, no markdown formatting.`
 }
 
 // buildDecompositionPrompt builds the prompt for task decomposition.
-func (o *Orchestrator) buildDecompositionPrompt(userTask string) string {
+func (o *Orchestrator) buildDecompositionPrompt(userTask, contextPack string) string {
+	if contextPack != "" {
+		contextPack = "\nRepository overview (explore further only where it is not enough):\n\n" + contextPack
+	}
 	return fmt.Sprintf(`Analyze this codebase and decompose the following task into sub-tasks.
 
 User Task: %s
-
+%s
 Please analyze:
 1. The current codebase structure
 2. Which parts can be done in parallel
@@ -140,11 +307,19 @@ Output your analysis as a JSON object with this format:
       "description": "What to do",
       "dependsOn": [],
       "files": ["path/to/file1.go", "path/to/file2.go"],
+      "outputs": ["path/to/schema.sql"],
+      "collect": ["coverage.out"],
       "estimatedTime": "5-10 min"
     }
   ],
   "totalEstimatedTime": "20-30 min"
 }
 
-Respond ONLY with valid JSON, no markdown, no explanation.`, userTask)
+"outputs" lists files, such as a generated schema or an API spec, that
+dependent tasks build on. Leave it empty when no other task needs them.
+"collect" lists globs ("**" matches any directories) of files worth keeping
+after the task, such as built binaries, coverage reports or generated docs.
+Leave it empty when the task produces none.
+
+Respond ONLY with valid JSON, no markdown, no explanation.`, userTask, contextPack)
 }
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/codexrpc"
+)
+
+// TraceFrame is a line of an RPC trace file.
+type TraceFrame struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"dir"` // codexrpc.FrameSent or codexrpc.FrameReceived
+	Frame     json.RawMessage `json:"frame"`
+}
+
+// SetRPCTraceDir records the JSON-RPC frames of agents spawned afterwards
+// to <dir>/<agent>.jsonl, for debugging app-server issues. dir must exist.
+// Agent IDs can be reused, e.g. by a retried task, so a file holds the
+// last agent of its ID. Traces hold prompts and agent output in full. An
+// empty dir disables tracing.
+func (m *Manager) SetRPCTraceDir(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.traceDir = dir
+}
+
+// RPCTraceFile returns the trace file of an agent, or "" when tracing is
+// disabled. The file does not exist if the agent was not traced.
+func (m *Manager) RPCTraceFile(agentID string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return traceFile(m.traceDir, agentID)
+}
+
+func traceFile(dir, agentID string) string {
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, filepath.Base(agentID)+".jsonl")
+}
+
+// frameTracer returns the tracer of a new agent, or nil when tracing is
+// disabled. m.mu must be held.
+func (m *Manager) frameTracer(agentID string) codexrpc.FrameTracer {
+	path := traceFile(m.traceDir, agentID)
+	if path == "" {
+		return nil
+	}
+	var mu sync.Mutex
+	flag := os.O_CREATE | os.O_TRUNC | os.O_WRONLY // replaces an earlier agent's trace
+	return func(direction string, frame []byte) {
+		tf := TraceFrame{Time: time.Now(), Direction: direction, Frame: frame}
+		if !json.Valid(frame) {
+			tf.Frame, _ = json.Marshal(string(frame))
+		}
+		line, err := json.Marshal(tf)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		f, err := os.OpenFile(path, flag, 0600)
+		if err != nil {
+			log.Printf("RPC trace: %v", err)
+			return
+		}
+		defer f.Close()
+		flag = os.O_CREATE | os.O_APPEND | os.O_WRONLY
+		f.Write(append(line, '\n'))
+	}
+}
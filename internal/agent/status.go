@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"codex-agent-team/internal/codexrpc"
+)
+
+// ErrNotAuthenticated is returned when the app-server reports that no
+// account is logged in, so every turn would fail.
+var ErrNotAuthenticated = errors.New("codex not authenticated")
+
+// maxModelPages bounds the model/list pages read by a status check.
+const maxModelPages = 10
+
+// CodexStatus is the login state and model list of a codex binary, as
+// reported by its app-server. Error is set when the app-server could not
+// be queried; Authenticated is then unknown and false.
+type CodexStatus struct {
+	Binary        string            `json:"binary"`
+	UserAgent     string            `json:"userAgent,omitempty"`
+	Authenticated bool              `json:"authenticated"`
+	Account       *codexrpc.Account `json:"account,omitempty"`
+	Models        []codexrpc.Model  `json:"models,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	CheckedAt     time.Time         `json:"checkedAt"`
+}
+
+// Err returns ErrNotAuthenticated if the status shows no login. A status
+// that could not be read is not treated as an error, since older
+// app-servers do not implement the account methods.
+func (s CodexStatus) Err() error {
+	if s.Error == "" && !s.Authenticated {
+		return fmt.Errorf("%w (%s): run `codex login`", ErrNotAuthenticated, s.Binary)
+	}
+	return nil
+}
+
+// CodexStatus returns the status of a codex binary, "" for the default
+// one, querying a short-lived app-server when the cached status is older
+// than maxAge. ctx should carry a deadline: an app-server that starts
+// but never answers is only abandoned when ctx ends.
+func (m *Manager) CodexStatus(ctx context.Context, bin string, maxAge time.Duration) CodexStatus {
+	if bin == "" {
+		bin = m.codexBin
+	}
+
+	m.statusMu.Lock()
+	cached, ok := m.statuses[bin]
+	m.statusMu.Unlock()
+	if ok && time.Since(cached.CheckedAt) < maxAge {
+		return cached
+	}
+
+	status := m.probeCodex(ctx, bin)
+	m.statusMu.Lock()
+	m.statuses[bin] = status
+	m.statusMu.Unlock()
+	return status
+}
+
+// probeCodex starts an app-server, reads its account and models and shuts
+// it down again.
+func (m *Manager) probeCodex(ctx context.Context, bin string) CodexStatus {
+	status := CodexStatus{Binary: bin, CheckedAt: time.Now()}
+	err := m.withAppServer(ctx, bin, func(client *codexrpc.Client, init *codexrpc.InitializeResponse) error {
+		status.UserAgent = init.UserAgent
+		account, err := client.AccountRead(ctx, codexrpc.AccountReadParams{})
+		if err != nil {
+			return err
+		}
+		status.Account = account.Account
+		status.Authenticated = account.Authenticated()
+
+		// The model list is informational; a failure leaves it empty
+		var cursor *string
+		for page := 0; page < maxModelPages; page++ {
+			models, err := client.ModelList(ctx, codexrpc.ModelListParams{Cursor: cursor})
+			if err != nil {
+				break
+			}
+			status.Models = append(status.Models, models.Data...)
+			if models.NextCursor == nil || *models.NextCursor == "" {
+				break
+			}
+			cursor = models.NextCursor
+		}
+		return nil
+	})
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"codex-agent-team/internal/codexrpc"
+)
+
+// ErrThreadActive is returned when archiving a thread a running agent uses.
+var ErrThreadActive = errors.New("thread belongs to a running agent")
+
+// withAppServer runs fn against a short-lived app-server of bin, "" for
+// the default binary, outside any agent. fn also gets the handshake
+// response.
+func (m *Manager) withAppServer(ctx context.Context, bin string, fn func(*codexrpc.Client, *codexrpc.InitializeResponse) error) error {
+	if bin == "" {
+		bin = m.codexBin
+	}
+	process, err := codexrpc.Spawn(ctx, codexrpc.SpawnOptions{BinaryPath: bin, ListenAddr: "stdio://"})
+	if err != nil {
+		return err
+	}
+	defer process.Close()
+
+	client := process.Client()
+	init, err := client.Initialize(ctx)
+	if err != nil {
+		return err
+	}
+	return fn(client, init)
+}
+
+// ListThreads returns one page of the threads stored by a codex binary,
+// "" for the default one.
+func (m *Manager) ListThreads(ctx context.Context, bin, cursor string, limit int) (*codexrpc.ThreadListResponse, error) {
+	params := codexrpc.ThreadListParams{}
+	if cursor != "" {
+		params.Cursor = &cursor
+	}
+	if limit > 0 {
+		params.Limit = &limit
+	}
+
+	var resp *codexrpc.ThreadListResponse
+	err := m.withAppServer(ctx, bin, func(c *codexrpc.Client, _ *codexrpc.InitializeResponse) error {
+		var err error
+		resp, err = c.ThreadList(ctx, params)
+		return err
+	})
+	return resp, err
+}
+
+// ArchiveThreads archives threads of a codex binary and returns those it
+// archived. Threads of running agents are refused.
+func (m *Manager) ArchiveThreads(ctx context.Context, bin string, ids []string) ([]string, error) {
+	active := m.ActiveThreads()
+	for _, id := range ids {
+		if active[id] {
+			return nil, fmt.Errorf("%w: %s", ErrThreadActive, id)
+		}
+	}
+
+	var archived []string
+	err := m.withAppServer(ctx, bin, func(c *codexrpc.Client, _ *codexrpc.InitializeResponse) error {
+		for _, id := range ids {
+			if err := c.ThreadArchive(ctx, codexrpc.ThreadArchiveParams{ThreadID: id}); err != nil {
+				return err
+			}
+			archived = append(archived, id)
+		}
+		return nil
+	})
+	return archived, err
+}
+
+// ActiveThreads returns the thread IDs of the running agents.
+func (m *Manager) ActiveThreads() map[string]bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	active := make(map[string]bool, len(m.agents))
+	for _, inst := range m.agents {
+		active[inst.ThreadID] = true
+	}
+	return active
+}
+
+// maxThreadPages bounds the thread/list pages read by ArchiveIdleThreads.
+const maxThreadPages = 100
+
+// ArchiveIdleThreads archives the threads of a codex binary last updated
+// before cutoff, except those of running agents, and returns them. With
+// dryRun the threads are only returned.
+func (m *Manager) ArchiveIdleThreads(ctx context.Context, bin string, cutoff time.Time, dryRun bool) ([]string, error) {
+	active := m.ActiveThreads()
+	var idle []string
+	err := m.withAppServer(ctx, bin, func(c *codexrpc.Client, _ *codexrpc.InitializeResponse) error {
+		var cursor *string
+		for page := 0; page < maxThreadPages; page++ {
+			resp, err := c.ThreadList(ctx, codexrpc.ThreadListParams{Cursor: cursor})
+			if err != nil {
+				return err
+			}
+			for _, t := range resp.Data {
+				if !active[t.ID] && time.Unix(t.UpdatedAt, 0).Before(cutoff) {
+					idle = append(idle, t.ID)
+				}
+			}
+			if resp.NextCursor == nil || *resp.NextCursor == "" {
+				break
+			}
+			cursor = resp.NextCursor
+		}
+		if dryRun {
+			return nil
+		}
+
+		for i, id := range idle {
+			if err := c.ThreadArchive(ctx, codexrpc.ThreadArchiveParams{ThreadID: id}); err != nil {
+				idle = idle[:i]
+				return err
+			}
+		}
+		return nil
+	})
+	return idle, err
+}
@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"codex-agent-team/internal/codexrpc"
+)
+
+// RunTestsTool is the dynamic tool workers call to run the repository's
+// tests instead of guessing a test command.
+const RunTestsTool = "run_tests"
+
+// EventTestsRun is emitted with a TestRun each time an agent runs the tests.
+const EventTestsRun = "tests/run"
+
+// maxTestOutput bounds the test output returned to the agent and kept in
+// a TestRun. The tail is kept since failures are usually reported last.
+const maxTestOutput = 16 * 1024
+
+// TestCommand configures the run_tests tool. Workers only get the tool
+// when Command is set.
+type TestCommand struct {
+	Command string        // shell command run in the agent's working directory
+	Timeout time.Duration // 0 for no limit
+}
+
+// TestRun is the outcome of one run_tests call.
+type TestRun struct {
+	Command   string    `json:"command"`
+	Passed    bool      `json:"passed"`
+	ExitCode  int       `json:"exitCode"`
+	TimedOut  bool      `json:"timedOut,omitempty"`
+	Duration  int64     `json:"durationMs"`
+	Output    string    `json:"output"` // combined stdout and stderr, tail only
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// SetTestCommand configures the run_tests tool of workers spawned
+// afterwards. An empty command removes the tool.
+func (m *Manager) SetTestCommand(tc TestCommand) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.testCommand = tc
+}
+
+// TestRuns returns the test runs of an agent, oldest first.
+func (m *Manager) TestRuns(agentID string) []TestRun {
+	m.mu.RLock()
+	instance, exists := m.agents[agentID]
+	m.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+	return append([]TestRun(nil), instance.testRuns...)
+}
+
+// runTestsSpec describes the run_tests tool to the model.
+func runTestsSpec() codexrpc.DynamicToolSpec {
+	return codexrpc.DynamicToolSpec{
+		Name: RunTestsTool,
+		Description: "Run the repository's test suite in your working directory and " +
+			"return whether it passed, the exit code and the tail of the output. " +
+			"Use this instead of guessing a test command.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{},"additionalProperties":false}`),
+	}
+}
+
+// handleToolCall answers a dynamic tool call of an agent.
+func (m *Manager) handleToolCall(agentID string, params json.RawMessage) (json.RawMessage, error) {
+	var call codexrpc.DynamicToolCallParams
+	if err := json.Unmarshal(params, &call); err != nil {
+		return nil, fmt.Errorf("invalid tool call: %w", err)
+	}
+	if call.Tool != RunTestsTool {
+		return json.Marshal(codexrpc.DynamicToolCallResponse{
+			ContentItems: []codexrpc.DynamicToolContentItem{{Type: "inputText", Text: "unknown tool " + call.Tool}},
+		})
+	}
+
+	m.mu.RLock()
+	instance, exists := m.agents[agentID]
+	tc := m.testCommand
+	m.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("agent %s not found", agentID)
+	}
+
+	// A containerized agent sees its own toolchain; test there, not on the host
+	run := runTests(instance.Config.Cwd, tc, instance.container)
+
+	instance.mu.Lock()
+	instance.testRuns = append(instance.testRuns, run)
+	instance.lastActivity = time.Now()
+	instance.mu.Unlock()
+	m.emitJSONFor(instance, call.TurnID, EventTestsRun, run)
+
+	text, err := json.Marshal(run)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(codexrpc.DynamicToolCallResponse{
+		ContentItems: []codexrpc.DynamicToolContentItem{{Type: "inputText", Text: string(text)}},
+		Success:      run.Passed,
+	})
+}
+
+// RunTests runs the configured test command in dir, for example to verify
+// a merge. ok is false when no command is configured.
+func (m *Manager) RunTests(dir string) (run TestRun, ok bool) {
+	m.mu.RLock()
+	tc := m.testCommand
+	m.mu.RUnlock()
+	if tc.Command == "" {
+		return TestRun{}, false
+	}
+	return runTests(dir, tc, nil), true
+}
+
+// runTests runs the test command in dir, inside container when it is set.
+func runTests(dir string, tc TestCommand, container *codexrpc.ContainerOptions) TestRun {
+	run := TestRun{Command: tc.Command, StartedAt: time.Now()}
+	if tc.Command == "" {
+		run.ExitCode = -1
+		run.Error = "no test command is configured"
+		return run
+	}
+
+	ctx := context.Background()
+	if tc.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tc.Timeout)
+		defer cancel()
+	}
+
+	var out bytes.Buffer
+	var cmd *exec.Cmd
+	if container != nil {
+		cmd = container.Exec(ctx, dir, "sh", "-c", tc.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", tc.Command)
+		cmd.Dir = dir
+	}
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second // don't wait for children still holding the output open
+	err := cmd.Run()
+	run.Duration = time.Since(run.StartedAt).Milliseconds()
+
+	output := out.Bytes()
+	if len(output) > maxTestOutput {
+		output = output[len(output)-maxTestOutput:]
+	}
+	run.Output = string(output)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		run.Passed = true
+	case ctx.Err() != nil:
+		run.ExitCode = -1
+		run.TimedOut = true
+		run.Error = fmt.Sprintf("timed out after %s", tc.Timeout)
+	case errors.As(err, &exitErr):
+		run.ExitCode = exitErr.ExitCode()
+	default:
+		run.ExitCode = -1
+		run.Error = err.Error()
+	}
+	return run
+}
@@ -0,0 +1,137 @@
+package agent
+
+import "encoding/json"
+
+// Turn is a handle to one task sent to an agent. Follow-up turns started
+// by an interrupt or a stall retry continue the same task, so they report
+// to the handle of the turn they replace.
+type Turn struct {
+	AgentID string
+	TaskID  string     // task the turn works on, "" if none
+	done    chan error // receives the task outcome once
+	inst    *Instance
+}
+
+// newTurn creates a handle for a task sent to inst.
+func newTurn(inst *Instance, taskID string) *Turn {
+	return &Turn{
+		AgentID: inst.Config.ID,
+		TaskID:  taskID,
+		done:    make(chan error, 1),
+		inst:    inst,
+	}
+}
+
+// finish delivers the task outcome; later outcomes are ignored.
+func (t *Turn) finish(err error) {
+	select {
+	case t.done <- err:
+	default:
+	}
+}
+
+// bindTurn maps an app-server turn ID to the task handle waiting on it.
+// The caller must hold inst.mu.
+func (inst *Instance) bindTurn(turnID string, t *Turn) {
+	if turnID == "" || t == nil {
+		return
+	}
+	if inst.turns == nil {
+		inst.turns = make(map[string]*Turn)
+	}
+	inst.turns[turnID] = t
+}
+
+// takeTurn returns and forgets the handle of an app-server turn. A turn
+// that was never bound, such as one completing before its TurnStart
+// response arrived, falls back to the handle whose start is in flight.
+// Completions of turns nobody waits for return nil. The caller must hold
+// inst.mu.
+func (inst *Instance) takeTurn(turnID string) *Turn {
+	if t, ok := inst.turns[turnID]; ok {
+		delete(inst.turns, turnID)
+		return t
+	}
+	if t := inst.starting; t != nil {
+		inst.starting = nil
+		return t
+	}
+	return nil
+}
+
+// registerTurn records which task an app-server turn works on, so its
+// notifications can be attributed even when the agent has moved on.
+func (m *Manager) registerTurn(turnID string, t *Turn) {
+	if turnID == "" || t == nil {
+		return
+	}
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+	m.turnTasks[turnID] = t.TaskID
+}
+
+// forgetTurns drops the registry entries of finished turns.
+func (m *Manager) forgetTurns(turnIDs ...string) {
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+	for _, id := range turnIDs {
+		delete(m.turnTasks, id)
+	}
+}
+
+// turnTask returns the task an app-server turn works on.
+func (m *Manager) turnTask(turnID string) (string, bool) {
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+	taskID, ok := m.turnTasks[turnID]
+	return taskID, ok
+}
+
+// turnRef is the part of a notification that names its turn. Turn
+// notifications carry it as turn.id, item notifications as turnId.
+type turnRef struct {
+	TurnID string `json:"turnId"`
+	Turn   struct {
+		ID string `json:"id"`
+	} `json:"turn"`
+}
+
+// notificationTurn returns the turn a notification belongs to, or "".
+func notificationTurn(params json.RawMessage) string {
+	var ref turnRef
+	if err := json.Unmarshal(params, &ref); err != nil {
+		return ""
+	}
+	if ref.TurnID != "" {
+		return ref.TurnID
+	}
+	return ref.Turn.ID
+}
+
+// emitFor publishes an event of instance attributed to its thread, the
+// given turn and the task that turn works on. Without a turn the event
+// belongs to the turn and task in progress. The caller must not hold
+// instance.mu.
+func (m *Manager) emitFor(instance *Instance, turnID, eventType string, data []byte) {
+	instance.mu.Lock()
+	taskID := ""
+	if instance.current != nil {
+		taskID = instance.current.TaskID
+	}
+	if turnID == "" {
+		turnID = instance.TurnID
+	}
+	instance.mu.Unlock()
+
+	if id, ok := m.turnTask(turnID); ok {
+		taskID = id
+	}
+	m.emit(AgentEvent{
+		AgentID:   instance.Config.ID,
+		ThreadID:  instance.ThreadID,
+		TurnID:    turnID,
+		TaskID:    taskID,
+		EventType: eventType,
+		Data:      data,
+	})
+}
@@ -32,15 +32,131 @@ type AgentConfig struct {
 	SandboxMode           string // "read-only" | "workspace-write"
 	BaseInstructions      string
 	DeveloperInstructions string
+
+	Runtime Runtime // codex binary and model provider overrides
+}
+
+// Runtime selects the codex binary and model provider an agent runs
+// with, e.g. a test account for experiments. Empty fields keep the
+// manager's defaults.
+type Runtime struct {
+	Binary        string `json:"binary,omitempty"`        // codex binary path, wins over role binaries
+	ModelProvider string `json:"modelProvider,omitempty"` // model_provider of the codex config
+}
+
+// MCPServer configures an MCP server that gives an agent extra tools, such
+// as a database inspector. It is either a command started over stdio or
+// a streamable HTTP URL. The JSON form matches an mcp_servers entry of
+// the codex config.
+type MCPServer struct {
+	Command           string            `json:"command,omitempty"`
+	Args              []string          `json:"args,omitempty"`
+	Env               map[string]string `json:"env,omitempty"`
+	URL               string            `json:"url,omitempty"`
+	BearerTokenEnvVar string            `json:"bearer_token_env_var,omitempty"`
+	EnabledTools      []string          `json:"enabled_tools,omitempty"` // all tools when empty
+	StartupTimeoutSec float64           `json:"startup_timeout_sec,omitempty"`
+	ToolTimeoutSec    float64           `json:"tool_timeout_sec,omitempty"`
+}
+
+// Validate checks that the server is either a command or a URL.
+func (s MCPServer) Validate() error {
+	if (s.Command == "") == (s.URL == "") {
+		return fmt.Errorf("exactly one of command and url must be set")
+	}
+	return nil
 }
 
-// AgentEvent represents an event emitted by an agent instance.
+// ContainerConfig configures container-isolated execution for a role.
+// The agent's working directory is mounted read-write, the repository's
+// git directory read-only, plus any extra Mounts.
+type ContainerConfig struct {
+	Runtime    string   // "docker" (default) or a compatible CLI such as "podman"
+	Image      string   // image providing the codex binary
+	BinaryPath string   // codex path inside the image
+	CPUs       string   // e.g. "2"
+	Memory     string   // e.g. "4g"
+	Network    string   // e.g. "none", "bridge"
+	Env        []string // variables set in the container: NAME passes the host's value, NAME=value sets one
+	Mounts     []string // extra host directories mounted at the same path, e.g. a codex home holding credentials
+}
+
+// AgentEvent represents an event emitted by an agent instance. Events of
+// a turn name the thread, the turn and the task the turn works on.
 type AgentEvent struct {
 	AgentID   string
+	ThreadID  string
+	TurnID    string
+	TaskID    string
 	EventType string
 	Data      []byte
 }
 
+// Command event types emitted for commandExecution items.
+const (
+	EventCommandStarted   = "command/started"
+	EventCommandOutput    = "command/output"
+	EventCommandCompleted = "command/completed"
+)
+
+// CommandEvent describes one step of a command executed by an agent.
+// CommandID is the app-server item ID, so output chunks can be attributed.
+type CommandEvent struct {
+	CommandID string `json:"commandId"`
+	Command   string `json:"command,omitempty"`
+	Cwd       string `json:"cwd,omitempty"`
+	Chunk     string `json:"chunk,omitempty"`
+	Status    string `json:"status,omitempty"`
+	ExitCode  *int   `json:"exitCode,omitempty"`
+}
+
+// EventTokensUsed is emitted when an agent's thread reports new token usage.
+const EventTokensUsed = "tokens/used"
+
+// TokenUsageEvent reports tokens consumed since the previous report.
+type TokenUsageEvent struct {
+	Tokens int64 `json:"tokens"` // tokens used since the last event
+	Total  int64 `json:"total"`  // cumulative tokens of the agent's thread
+}
+
+// EventAgentStalled is emitted when a running turn produced no
+// notifications within the stall timeout.
+const EventAgentStalled = "agent/stalled"
+
+// StallPolicy configures stuck-turn detection. A zero Timeout disables it.
+type StallPolicy struct {
+	Timeout time.Duration // max silence before a turn counts as stalled
+	Retries int           // times a stalled turn is interrupted and restarted
+}
+
+// StallEvent describes a stalled turn.
+type StallEvent struct {
+	IdleSeconds float64 `json:"idleSeconds"`
+	Attempt     int     `json:"attempt"`
+	Retrying    bool    `json:"retrying"`
+}
+
+// HeartbeatPolicy configures liveness pings of agent app-servers, which
+// detect a process that keeps its pipes open but no longer answers. A zero
+// Interval disables them.
+type HeartbeatPolicy struct {
+	Interval time.Duration // time between pings
+	Timeout  time.Duration // wait for an answer (default: Interval)
+	Misses   int           // missed pings in a row before the agent is given up (default 2)
+}
+
+// EventAgentUnresponsive is emitted when an agent's app-server does not
+// answer a ping in time.
+const EventAgentUnresponsive = "agent/unresponsive"
+
+// UnresponsiveEvent describes a missed ping.
+type UnresponsiveEvent struct {
+	Missed        int     `json:"missed"`        // pings missed in a row
+	SilentSeconds float64 `json:"silentSeconds"` // since the last answered ping
+	GaveUp        bool    `json:"gaveUp"`        // the agent's wait fails
+	Error         string  `json:"error"`
+}
+
 // GenerateID generates a unique ID using timestamp.
 func GenerateID() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
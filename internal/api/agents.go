@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"codex-agent-team/internal/session"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getAgent returns an ad-hoc agent visible to the request's tenant.
+func (s *Server) getAgent(r *http.Request, id string) (*session.AdHocAgent, bool) {
+	a, err := s.sessionMgr.Agent(id)
+	if err != nil {
+		return nil, false
+	}
+	if t := tenantFrom(r); t != nil && a.TenantID != t.ID {
+		return nil, false
+	}
+	return a, true
+}
+
+// handleListAgents returns the ad-hoc agents.
+func (s *Server) handleListAgents(w http.ResponseWriter, r *http.Request) {
+	agents := s.sessionMgr.Agents()
+	if t := tenantFrom(r); t != nil {
+		owned := agents[:0]
+		for _, a := range agents {
+			if a.TenantID == t.ID {
+				owned = append(owned, a)
+			}
+		}
+		agents = owned
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agents)
+}
+
+// handleSpawnAgent starts a standalone agent outside any session.
+func (s *Server) handleSpawnAgent(w http.ResponseWriter, r *http.Request) {
+	var req session.AdHocConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+
+	cwd := req.Cwd
+	if cwd == "" {
+		cwd = s.defaultRepo
+	}
+	absPath, err := filepath.Abs(cwd)
+	if err != nil {
+		httpError(w, "Invalid cwd", http.StatusBadRequest)
+		return
+	}
+	req.Cwd = absPath
+
+	if !repoAllowed(r, absPath) {
+		httpError(w, "Repository not registered for tenant", http.StatusForbidden)
+		return
+	}
+	if !s.checkBudget(w, r) {
+		return
+	}
+
+	tenantID := ""
+	if t := tenantFrom(r); t != nil {
+		tenantID = t.ID
+	}
+	a, err := s.sessionMgr.SpawnAgent(req, tenantID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, session.ErrInvalidAgentConfig) {
+			status = http.StatusBadRequest
+		}
+		httpError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+// handleGetAgent returns an ad-hoc agent with the output of its last
+// message.
+func (s *Server) handleGetAgent(w http.ResponseWriter, r *http.Request) {
+	a, ok := s.getAgent(r, chi.URLParam(r, "id"))
+	if !ok {
+		httpError(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// handleMessageAgent sends a message to an ad-hoc agent. The agent works
+// on it in the background; poll the agent for its state and output.
+func (s *Server) handleMessageAgent(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := s.getAgent(r, id); !ok {
+		httpError(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	if req.Message == "" {
+		httpError(w, "message is required", http.StatusBadRequest)
+		return
+	}
+	if !s.checkBudget(w, r) {
+		return
+	}
+
+	if err := s.sessionMgr.MessageAgent(id, req.Message); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, session.ErrAgentBusy) {
+			status = http.StatusConflict
+		}
+		httpError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "running"})
+}
+
+// handleStopAgent stops an ad-hoc agent.
+func (s *Server) handleStopAgent(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := s.getAgent(r, id); !ok {
+		httpError(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+
+	if err := s.sessionMgr.StopAgent(id); err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "stopped"})
+}
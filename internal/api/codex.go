@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"codex-agent-team/internal/agent"
+)
+
+// healthStatusMaxAge is how long a health check reuses codex statuses.
+const healthStatusMaxAge = 30 * time.Second
+
+// CodexStatuses checks the login of every codex binary now.
+func (s *Server) CodexStatuses(ctx context.Context) []agent.CodexStatus {
+	return s.sessionMgr.CodexStatuses(ctx, 0)
+}
+
+// checkCodex writes 503 and returns false when the codex binary of rt is
+// not logged in.
+func (s *Server) checkCodex(w http.ResponseWriter, r *http.Request, rt agent.Runtime) bool {
+	if err := s.sessionMgr.CheckCodex(r.Context(), rt); err != nil {
+		httpError(w, err.Error(), http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// handleListModels returns the models each role can use, for a model
+// picker. ?role= limits the list to one role; ?codex= and ?modelProvider=
+// select a registered runtime as for a new session.
+func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rt, err := s.sessionMgr.ResolveRuntime(q.Get("codex"), q.Get("modelProvider"))
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	roles := []agent.Role{agent.RoleOrchestrator, agent.RoleWorker, agent.RoleMerger}
+	if role := agent.Role(q.Get("role")); role != "" {
+		if !slices.Contains(roles, role) {
+			httpError(w, "Unknown role", http.StatusBadRequest)
+			return
+		}
+		roles = []agent.Role{role}
+	}
+
+	lists, err := s.sessionMgr.Models(r.Context(), roles, rt)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lists)
+}
+
+// handleHealth reports whether the server can run agents: the login state
+// and models of each codex binary. It answers 503 when a binary is known
+// not to be logged in.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	statuses := s.sessionMgr.CodexStatuses(r.Context(), healthStatusMaxAge)
+
+	status, code := "ok", http.StatusOK
+	for _, st := range statuses {
+		if st.Err() != nil {
+			status, code = "degraded", http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]any{
+		"status": status,
+		"codex":  statuses,
+	})
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/session"
+)
+
+// Error codes of API error responses. They are stable, so clients can
+// branch on them instead of on messages.
+const (
+	CodeInvalidRequest       = "INVALID_REQUEST"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeSessionNotFound      = "SESSION_NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodeInvalidState         = "INVALID_STATE"
+	CodeDirtyRepo            = "DIRTY_REPO"
+	CodeRepoLocked           = "REPO_LOCKED"
+	CodeNoTargetBranch       = "NO_TARGET_BRANCH"
+	CodeNoMergeToRollback    = "NO_MERGE_TO_ROLLBACK"
+	CodeMergeConflict        = "MERGE_CONFLICT"
+	CodeDecomposeParseFailed = "DECOMPOSE_PARSE_FAILED"
+	CodeConfirmationRequired = "CONFIRMATION_REQUIRED"
+	CodeCodexUnauthenticated = "CODEX_NOT_AUTHENTICATED"
+	CodeBodyTooLarge         = "BODY_TOO_LARGE"
+	CodeUnprocessable        = "UNPROCESSABLE"
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeUnsupportedVersion   = "UNSUPPORTED_API_VERSION"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeNotImplemented       = "NOT_IMPLEMENTED"
+	CodeUpstream             = "UPSTREAM_ERROR"
+	CodeUnavailable          = "UNAVAILABLE"
+	CodeInternal             = "INTERNAL"
+)
+
+// ErrorResponse is the JSON body of every error response.
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// MergeConflictDetails are the details of a MERGE_CONFLICT error.
+type MergeConflictDetails struct {
+	Branches  []string `json:"branches"`
+	Conflicts []string `json:"conflicts"`
+}
+
+// writeError writes an error response.
+func writeError(w http.ResponseWriter, status int, code, message string, details ...any) {
+	resp := ErrorResponse{Code: code, Message: message}
+	if len(details) > 0 {
+		resp.Details = details[0]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// httpError is http.Error with a JSON body whose code follows from the
+// status.
+func httpError(w http.ResponseWriter, message string, status int) {
+	writeError(w, status, statusCode(status), message)
+}
+
+// writeErrorFor writes err with the status, code and details it maps to.
+func writeErrorFor(w http.ResponseWriter, err error) {
+	code, details := errorCode(err)
+	writeError(w, errorStatus(err), code, err.Error(), details)
+}
+
+// errorEvent is the data of a "session.error" WebSocket event.
+func errorEvent(err error) map[string]any {
+	code, details := errorCode(err)
+	data := map[string]any{"error": err.Error(), "code": code}
+	if details != nil {
+		data["details"] = details
+	}
+	return data
+}
+
+// errorCode returns the code of err and the details that go with it.
+func errorCode(err error) (string, any) {
+	var mergeErr *session.MergeError
+	switch {
+	case errors.As(err, &mergeErr) && errors.Is(err, session.ErrMergeConflict):
+		return CodeMergeConflict, MergeConflictDetails{Branches: mergeErr.Branches, Conflicts: mergeErr.Conflicts}
+	case errors.Is(err, agent.ErrDecompositionParse):
+		return CodeDecomposeParseFailed, nil
+	case errors.Is(err, session.ErrInvalidTransition):
+		return CodeInvalidState, nil
+	case errors.Is(err, session.ErrDirtyRepo):
+		return CodeDirtyRepo, nil
+	case errors.Is(err, session.ErrRepoLocked):
+		return CodeRepoLocked, nil
+	case errors.Is(err, session.ErrNoTargetBranch):
+		return CodeNoTargetBranch, nil
+	case errors.Is(err, session.ErrNoMergeToRollback):
+		return CodeNoMergeToRollback, nil
+	case errors.Is(err, session.ErrConfirmationRequired):
+		return CodeConfirmationRequired, nil
+	case errors.Is(err, agent.ErrNotAuthenticated):
+		return CodeCodexUnauthenticated, nil
+	}
+	return statusCode(errorStatus(err)), nil
+}
+
+// statusCode is the generic code of an HTTP status.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeBodyTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusPreconditionRequired:
+		return CodeConfirmationRequired
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusBadGateway:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status < http.StatusInternalServerError {
+		return CodeInvalidRequest
+	}
+	return CodeInternal
+}
+
+// jobView is a job as the API returns it, with the code of its error.
+type jobView struct {
+	session.Job
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+func newJobView(job session.Job) jobView {
+	view := jobView{Job: job}
+	if err := job.Err(); err != nil {
+		view.ErrorCode, _ = errorCode(err)
+	}
+	return view
+}
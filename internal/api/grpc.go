@@ -0,0 +1,640 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/session"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/tenant"
+	pb "codex-agent-team/pkg/teampb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcErrorDomain is the domain of the ErrorInfo attached to gRPC errors.
+const grpcErrorDomain = "codex-agent-team"
+
+// transcriptPoll is how often a followed transcript is checked for new
+// output.
+const transcriptPoll = 200 * time.Millisecond
+
+// grpcSpawning lists the gRPC methods that count against the agent
+// spawn limit, as their POST routes do.
+var grpcSpawning = map[string]bool{
+	pb.Team_CreateSession_FullMethodName: true,
+	pb.Team_Decompose_FullMethodName:     true,
+	pb.Team_Execute_FullMethodName:       true,
+	pb.Team_Resume_FullMethodName:        true,
+	pb.Team_Merge_FullMethodName:         true,
+}
+
+// StartGRPC serves the gRPC API of pkg/teampb on addr until Shutdown. It
+// shares the sessions, TLS settings, tenants and rate limits of the HTTP
+// API.
+func (s *Server) StartGRPC(addr string) error {
+	tlsCfg, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := s.grpcAuth(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := s.grpcAuth(ss.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authedStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+	if s.limits.MaxBodyBytes > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(s.limits.MaxBodyBytes)))
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	server := grpc.NewServer(opts...)
+	pb.RegisterTeamServer(server, &grpcService{s: s})
+
+	// Shutdown handler; event streams only end when their clients go, so
+	// they are cut after the grace period
+	go func() {
+		<-s.shutdownCh
+		timer := time.AfterFunc(10*time.Second, server.Stop)
+		defer timer.Stop()
+		server.GracefulStop()
+	}()
+
+	return server.Serve(lis)
+}
+
+// authedStream is a server stream whose context carries the caller's
+// tenant.
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcAuth applies the HTTP API's rate limits and tenant authentication
+// to a gRPC call and returns its context with the caller's tenant.
+func (s *Server) grpcAuth(ctx context.Context, method string) (context.Context, error) {
+	key := grpcAPIKey(ctx)
+	var t *tenant.Tenant
+	if s.tenants != nil {
+		t, _ = s.tenants.Authenticate(key)
+	}
+
+	client := "ip:"
+	if t != nil {
+		client = "tenant:" + t.ID
+	} else if p, ok := peer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		client += host
+	}
+	if _, ok := s.reqLimit.allow(client); !ok {
+		return nil, grpcError(codes.ResourceExhausted, CodeRateLimited, "Too many requests")
+	}
+	if grpcSpawning[method] {
+		if _, ok := s.spawnLimit.allow(client); !ok {
+			return nil, grpcError(codes.ResourceExhausted, CodeRateLimited, "Too many requests")
+		}
+	}
+
+	if s.tenants == nil {
+		return ctx, nil
+	}
+	if t == nil {
+		return nil, grpcError(codes.Unauthenticated, CodeUnauthorized, "Unauthorized")
+	}
+	return context.WithValue(ctx, tenantKey{}, t), nil
+}
+
+// grpcAPIKey returns the API key sent with a gRPC call, from the
+// authorization ("Bearer <key>") or x-api-key metadata.
+func grpcAPIKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if key := strings.TrimPrefix(v, "Bearer "); key != "" {
+			return key
+		}
+	}
+	if v := md.Get("x-api-key"); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// grpcError returns a status error carrying the HTTP API's error code as
+// the reason of an ErrorInfo.
+func grpcError(c codes.Code, code, message string, md ...map[string]string) error {
+	info := &errdetails.ErrorInfo{Reason: code, Domain: grpcErrorDomain}
+	if len(md) > 0 {
+		info.Metadata = md[0]
+	}
+	st := status.New(c, message)
+	if detailed, err := st.WithDetails(info); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// grpcErrorFor converts an error of a session operation as writeErrorFor
+// does for HTTP.
+func grpcErrorFor(err error) error {
+	code, details := errorCode(err)
+	var md map[string]string
+	if d, ok := details.(MergeConflictDetails); ok {
+		md = map[string]string{
+			"branches":  strings.Join(d.Branches, ","),
+			"conflicts": strings.Join(d.Conflicts, ","),
+		}
+	}
+	return grpcError(grpcCode(errorStatus(err)), code, err.Error(), md)
+}
+
+// grpcCode maps an HTTP status to a gRPC code.
+func grpcCode(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict, http.StatusPreconditionRequired:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// errSessionNotFound is returned for unknown sessions and sessions of
+// other tenants alike.
+var errSessionNotFound = grpcError(codes.NotFound, CodeSessionNotFound, "Session not found")
+
+// grpcService implements pb.TeamServer on the sessions of a Server.
+type grpcService struct {
+	pb.UnimplementedTeamServer
+	s *Server
+}
+
+// session looks up a session visible to the caller's tenant.
+func (g *grpcService) session(ctx context.Context, id string) (*session.Session, error) {
+	sess, ok := g.s.tenantSession(ctx, id)
+	if !ok {
+		return nil, errSessionNotFound
+	}
+	return sess, nil
+}
+
+// CreateSession validates and creates a session as POST /sessions does.
+func (g *grpcService) CreateSession(ctx context.Context, req *pb.CreateSessionRequest) (*pb.Session, error) {
+	rt, err := g.s.sessionMgr.ResolveRuntime(req.Codex, req.ModelProvider)
+	if err != nil {
+		return nil, grpcError(codes.InvalidArgument, CodeInvalidRequest, err.Error())
+	}
+
+	repoPath := req.RepoPath
+	if repoPath == "" {
+		repoPath = g.s.defaultRepo
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, grpcError(codes.InvalidArgument, CodeInvalidRequest, "Invalid repo path")
+	}
+	if !tenantAllowsRepo(ctx, absPath) {
+		return nil, grpcError(codes.PermissionDenied, CodeForbidden, "Repository not registered for tenant")
+	}
+	var v validation
+	v.required("userTask", req.UserTask)
+	v.repo(ctx, "repoPath", absPath)
+	if len(v.fields) > 0 {
+		return nil, grpcError(codes.InvalidArgument, CodeValidationFailed, v.message())
+	}
+	if err := g.s.sessionMgr.CheckCodex(ctx, rt); err != nil {
+		return nil, grpcError(codes.Unavailable, CodeUnavailable, err.Error())
+	}
+
+	sess, err := g.s.sessionMgr.CreateWithPath(ctx, req.UserTask, absPath)
+	if err != nil {
+		return nil, grpcError(codes.Internal, CodeInternal, err.Error())
+	}
+	if err := sess.SetRuntime(req.Codex, req.ModelProvider); err != nil {
+		return nil, grpcError(codes.Internal, CodeInternal, err.Error())
+	}
+	if t := contextTenant(ctx); t != nil {
+		sess.SetTenant(t.ID)
+	}
+	if req.Priority != 0 {
+		sess.SetPriority(int(req.Priority))
+	}
+	sess.RefreshRepo(ctx)
+
+	g.s.hub.Broadcast(sess.ID, Event{
+		Type: "session.created",
+		Data: sess,
+	})
+	return sessionProto(sess), nil
+}
+
+func (g *grpcService) GetSession(ctx context.Context, req *pb.GetSessionRequest) (*pb.Session, error) {
+	sess, err := g.session(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	sess.RefreshRepo(ctx)
+	return sessionProto(sess), nil
+}
+
+func (g *grpcService) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	t := contextTenant(ctx)
+	resp := &pb.ListSessionsResponse{}
+	for _, sess := range g.s.sessionMgr.ListAll() {
+		if t != nil && sess.TenantID != t.ID {
+			continue
+		}
+		sess.RefreshRepo(ctx)
+		resp.Sessions = append(resp.Sessions, sessionProto(sess))
+	}
+	return resp, nil
+}
+
+func (g *grpcService) DeleteSession(ctx context.Context, req *pb.DeleteSessionRequest) (*pb.DeleteSessionResponse, error) {
+	if _, err := g.session(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	if err := g.s.sessionMgr.Delete(ctx, req.Id, req.DeleteBranches); err != nil {
+		return nil, grpcError(codes.NotFound, CodeNotFound, err.Error())
+	}
+
+	g.s.hub.Broadcast(req.Id, Event{
+		Type: "session.deleted",
+		Data: map[string]string{"id": req.Id},
+	})
+	return &pb.DeleteSessionResponse{}, nil
+}
+
+// Decompose starts decomposing the session's task; the outcome is
+// reported on the event stream as for POST .../decompose.
+func (g *grpcService) Decompose(ctx context.Context, req *pb.DecomposeRequest) (*pb.Job, error) {
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.s.tenantBudget(ctx); err != nil {
+		return nil, grpcError(codes.PermissionDenied, CodeForbidden, err.Error())
+	}
+
+	id := sess.ID
+	job, err := sess.DecomposeAsync(req.Force, func(result *session.DecomposeResult, err error) {
+		if err != nil {
+			g.s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+			return
+		}
+		for _, warning := range result.Warnings {
+			g.s.hub.Broadcast(id, Event{
+				Type: "session.warning",
+				Data: map[string]string{"warning": warning},
+			})
+		}
+		g.s.hub.Broadcast(id, Event{
+			Type: "session.decomposed",
+			Data: map[string]any{"tasks": sess.TaskViews(), "cached": result.Cached, "warnings": result.Warnings},
+		})
+	})
+	if err != nil {
+		return nil, grpcErrorFor(err)
+	}
+	return jobProto(job), nil
+}
+
+func (g *grpcService) Confirm(ctx context.Context, req *pb.ConfirmRequest) (*pb.Estimate, error) {
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	est := sess.Confirm()
+	return &pb.Estimate{
+		Tasks:             int32(est.Tasks),
+		AgentMinutes:      est.AgentMinutes,
+		Tokens:            est.Tokens,
+		Unestimated:       int32(est.Unestimated),
+		Exceeds:           est.Exceeds,
+		NeedsConfirmation: est.NeedsConfirm,
+		Confirmed:         est.Confirmed,
+	}, nil
+}
+
+func (g *grpcService) Execute(ctx context.Context, req *pb.RunRequest) (*pb.Job, error) {
+	return g.run(ctx, req, func(sess *session.Session, opts session.RunOptions, done func(error)) (session.Job, error) {
+		return sess.ExecuteAsync(ctx, opts, done)
+	})
+}
+
+func (g *grpcService) Resume(ctx context.Context, req *pb.RunRequest) (*pb.Job, error) {
+	return g.run(ctx, req, func(sess *session.Session, opts session.RunOptions, done func(error)) (session.Job, error) {
+		opts.RetryWithContext = req.RetryWithContext
+		return sess.Resume(ctx, opts, done)
+	})
+}
+
+// run starts an execution job of the session, announcing it and its
+// failure on the event stream as the execute and resume routes do.
+func (g *grpcService) run(ctx context.Context, req *pb.RunRequest, start func(*session.Session, session.RunOptions, func(error)) (session.Job, error)) (*pb.Job, error) {
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.s.tenantBudget(ctx); err != nil {
+		return nil, grpcError(codes.PermissionDenied, CodeForbidden, err.Error())
+	}
+
+	id := sess.ID
+	job, err := start(sess, session.RunOptions{AutoStash: req.AutoStash}, func(err error) {
+		if err != nil {
+			g.s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+		}
+	})
+	if err != nil {
+		return nil, grpcErrorFor(err)
+	}
+
+	g.s.hub.Broadcast(id, Event{
+		Type: "session.executing",
+		Data: map[string]string{"status": "running"},
+	})
+	return jobProto(job), nil
+}
+
+func (g *grpcService) Merge(ctx context.Context, req *pb.RunRequest) (*pb.Job, error) {
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	id := sess.ID
+	job, err := sess.MergeAsync(ctx, session.RunOptions{AutoStash: req.AutoStash}, func(err error) {
+		if err != nil {
+			g.s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+			return
+		}
+		g.s.hub.Broadcast(id, Event{
+			Type: "session.merged",
+			Data: map[string]string{"status": "completed"},
+		})
+	})
+	if err != nil {
+		return nil, grpcErrorFor(err)
+	}
+	return jobProto(job), nil
+}
+
+func (g *grpcService) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	job, ok := sess.Job(req.JobId)
+	if !ok {
+		return nil, grpcError(codes.NotFound, CodeNotFound, "Job not found")
+	}
+	return jobProto(job), nil
+}
+
+func (g *grpcService) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	views := sess.TaskViews()
+	resp := &pb.ListTasksResponse{Tasks: make([]*pb.Task, 0, len(views))}
+	for _, v := range views {
+		resp.Tasks = append(resp.Tasks, taskProto(v))
+	}
+	return resp, nil
+}
+
+func (g *grpcService) InterruptTask(ctx context.Context, req *pb.InterruptTaskRequest) (*pb.InterruptTaskResponse, error) {
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := sess.InterruptTask(ctx, req.TaskId, req.Instruction); err != nil {
+		if errors.Is(err, session.ErrTaskNotFound) {
+			return nil, grpcError(codes.NotFound, CodeNotFound, err.Error())
+		}
+		return nil, grpcError(codes.FailedPrecondition, CodeConflict, err.Error())
+	}
+
+	g.s.hub.Broadcast(sess.ID, Event{
+		Type: "task.interrupted",
+		Data: map[string]string{"taskId": req.TaskId, "instruction": req.Instruction},
+	})
+	return &pb.InterruptTaskResponse{}, nil
+}
+
+// WatchEvents streams the session's hub events, the WebSocket's feed,
+// until the client cancels.
+func (g *grpcService) WatchEvents(req *pb.WatchEventsRequest, stream pb.Team_WatchEventsServer) error {
+	ctx := stream.Context()
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return err
+	}
+
+	client := NewClient(sess.ID, nil, g.s.hub)
+	g.s.hub.Register(client)
+	go func() {
+		<-ctx.Done()
+		g.s.hub.Unregister(client)
+	}()
+
+	err = client.drain(func(ev Event) error {
+		data, err := json.Marshal(ev.Data)
+		if err != nil {
+			log.Printf("Failed to marshal event: %v", err)
+			return nil
+		}
+		return stream.Send(&pb.Event{Type: ev.Type, Data: data})
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// StreamTranscript sends the task's agent output, then with follow polls
+// for more until the task completes, fails or is cancelled.
+func (g *grpcService) StreamTranscript(req *pb.StreamTranscriptRequest, stream pb.Team_StreamTranscriptServer) error {
+	ctx := stream.Context()
+	sess, err := g.session(ctx, req.SessionId)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(transcriptPoll)
+	defer ticker.Stop()
+	sent := ""
+	for {
+		// Check the status first, so the last read sees the final output
+		done := !req.Follow || taskFinished(sess, req.TaskId)
+		output, ok := sess.Transcript(req.TaskId)
+		if !ok {
+			return grpcError(codes.NotFound, CodeNotFound, "Task not found")
+		}
+
+		var chunk *pb.TranscriptChunk
+		switch {
+		case strings.HasPrefix(output, sent):
+			if len(output) > len(sent) {
+				chunk = &pb.TranscriptChunk{Text: output[len(sent):]}
+			}
+		default:
+			// A new attempt replaced the transcript
+			chunk = &pb.TranscriptChunk{Text: output, Replace: true}
+		}
+		if chunk != nil {
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+			sent = output
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// taskFinished reports whether the task completed, failed or was
+// cancelled.
+func taskFinished(sess *session.Session, taskID string) bool {
+	for _, t := range sess.DAG.Snapshot() {
+		if t.ID == taskID {
+			return t.Status == task.StatusCompleted || t.Status == task.StatusFailed || t.Status == task.StatusCancelled
+		}
+	}
+	return true
+}
+
+func sessionProto(sess *session.Session) *pb.Session {
+	p := &pb.Session{
+		Id:            sess.ID,
+		UserTask:      sess.UserTask,
+		RepoPath:      sess.RepoPath,
+		TenantId:      sess.TenantID,
+		Status:        string(sess.Status),
+		CreatedAt:     timestamppb.New(sess.CreatedAt),
+		StartedAt:     timeProto(sess.StartedAt),
+		CompletedAt:   timeProto(sess.CompletedAt),
+		Priority:      int32(sess.GetPriority()),
+		Codex:         sess.Codex,
+		ModelProvider: sess.ModelProvider,
+		Etag:          sess.ETag(),
+	}
+	if r := sess.Repo; r != nil {
+		p.Repo = &pb.Repo{Path: r.Path, Head: r.Head, Branch: r.Branch, Bare: r.Bare, Dirty: r.Dirty}
+	}
+	return p
+}
+
+func taskProto(v task.TaskView) *pb.Task {
+	p := &pb.Task{
+		Id:           v.ID,
+		Title:        v.Title,
+		Description:  v.Description,
+		Status:       string(v.Status),
+		BranchName:   v.BranchName,
+		WorktreePath: v.WorktreePath,
+		AgentId:      v.AgentID,
+		AgentState:   v.AgentState,
+		BaseCommit:   v.BaseCommit,
+		ResultCommit: v.ResultCommit,
+		CreatedAt:    timestamppb.New(v.CreatedAt),
+		StartedAt:    timeProto(v.StartedAt),
+		CompletedAt:  timeProto(v.CompletedAt),
+		DurationMs:   v.DurationMs,
+		DiffStat:     v.DiffStat,
+		Error:        v.Error,
+		NoChanges:    v.NoChanges,
+	}
+	for _, dep := range v.DependsOn {
+		p.DependsOn = append(p.DependsOn, &pb.Dependency{Id: dep.ID, Title: dep.Title, Status: string(dep.Status), Missing: dep.Missing})
+	}
+	return p
+}
+
+func jobProto(job session.Job) *pb.Job {
+	view := newJobView(job)
+	return &pb.Job{
+		Id:         job.ID,
+		SessionId:  job.SessionID,
+		Kind:       job.Kind,
+		Status:     string(job.Status),
+		Error:      job.Error,
+		ErrorCode:  view.ErrorCode,
+		StartedAt:  timestamppb.New(job.StartedAt),
+		FinishedAt: timeProto(job.FinishedAt),
+	}
+}
+
+// timeProto converts an optional time.
+func timeProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/issues"
+	"codex-agent-team/internal/session"
+	"codex-agent-team/internal/task"
+)
+
+// issueCommentTimeout bounds posting a session report to its issue.
+const issueCommentTimeout = 30 * time.Second
+
+// SetIssueTrackers enables creating sessions from tracker issues and
+// posts the report of each such session to its issue when it completes
+// or fails. A tracker that opens pull requests also enables merging as a
+// PR stack. Call before Start.
+func (s *Server) SetIssueTrackers(reg *issues.Registry) {
+	s.issues = reg
+	s.sessionMgr.Bus().Subscribe("issues", events.SinkFunc(s.postIssueReport))
+	if p, ok := reg.PullRequests(); ok {
+		s.sessionMgr.SetPullRequests(p)
+	}
+}
+
+// issuesAllowed writes 403 and returns false for tenants: trackers are
+// read with the server's credentials, which reach issues of repositories
+// outside the tenant's.
+func issuesAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if tenantFrom(r) != nil {
+		httpError(w, "Issue trackers are not available to tenants", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// handleCreateSessionFromIssue creates a session whose task is a tracker
+// issue, including its comments, and links the session to the issue.
+func (s *Server) handleCreateSessionFromIssue(w http.ResponseWriter, r *http.Request) {
+	if !issuesAllowed(w, r) {
+		return
+	}
+	var req struct {
+		Tracker  string `json:"tracker,omitempty"` // inferred from issue when empty
+		Issue    string `json:"issue"`             // e.g. "owner/repo#12", "PROJ-34" or an issue URL
+		RepoPath string `json:"repoPath,omitempty"`
+
+		Codex         string `json:"codex,omitempty"`
+		ModelProvider string `json:"modelProvider,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	if s.issues == nil {
+		httpError(w, "No issue trackers configured", http.StatusNotFound)
+		return
+	}
+	if req.Issue == "" {
+		httpError(w, "issue is required", http.StatusBadRequest)
+		return
+	}
+	tracker, err := s.issues.Resolve(req.Tracker, req.Issue)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fetch := func() (string, *session.IssueLink, bool) {
+		issue, err := tracker.Fetch(r.Context(), req.Issue)
+		if err != nil {
+			httpError(w, err.Error(), http.StatusBadGateway)
+			return "", nil, false
+		}
+		link := &session.IssueLink{Tracker: issue.Tracker, Key: issue.Key, URL: issue.URL}
+		return issues.UserTask(issue), link, true
+	}
+	sess, ok := s.createSession(w, r, "", req.RepoPath, req.Codex, req.ModelProvider, fetch)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess)
+}
+
+// postIssueReport comments on the linked issue when a session completes
+// or fails.
+func (s *Server) postIssueReport(ev events.Event) {
+	change, ok := ev.Data.(session.StatusChange)
+	if !ok || ev.Type != events.TypeStatusChanged {
+		return
+	}
+	if change.To != session.StatusCompleted && change.To != session.StatusFailed {
+		return
+	}
+	sess, ok := s.sessionMgr.Get(ev.SessionID)
+	if !ok {
+		return
+	}
+	link := sess.IssueLink()
+	if link == nil {
+		return
+	}
+	tracker, err := s.issues.Get(link.Tracker)
+	if err != nil {
+		log.Printf("Issue report for session %s: %v", sess.ID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), issueCommentTimeout)
+	defer cancel()
+	if err := tracker.Comment(ctx, link.Key, issueReport(sess, change.To)); err != nil {
+		log.Printf("Issue report for session %s on %s: %v", sess.ID, link.Key, err)
+	}
+}
+
+// issueReport renders the outcome of a session as a Markdown comment.
+func issueReport(sess *session.Session, status session.SessionStatus) string {
+	var b strings.Builder
+	switch status {
+	case session.StatusCompleted:
+		fmt.Fprintf(&b, "Codex Agent Team session `%s` completed and its changes were merged.\n\n", sess.ID)
+	default:
+		fmt.Fprintf(&b, "Codex Agent Team session `%s` failed.\n\n", sess.ID)
+	}
+
+	for _, t := range sess.TaskViews() {
+		mark := " "
+		if t.Status == task.StatusCompleted {
+			mark = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] %s (%s)", mark, t.Title, t.Status)
+		if t.ResultCommit != "" {
+			fmt.Fprintf(&b, " `%.12s`", t.ResultCommit)
+		}
+		switch {
+		case t.Error != "":
+			fmt.Fprintf(&b, ": %s", t.Error)
+		case t.Result != nil && t.Result.Summary != "":
+			fmt.Fprintf(&b, ": %s", firstLine(t.Result.Summary))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// firstLine returns s up to its first line break.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits configures request throttling. Zero values disable a limit.
+type Limits struct {
+	Rate         float64 // sustained requests per second per client
+	Burst        int     // requests a client may make at once
+	SpawnPerMin  int     // agent-spawning requests per minute per client
+	MaxBodyBytes int64   // largest accepted request body
+}
+
+// DefaultLimits are generous enough for the web UI while stopping a
+// runaway client from flooding the server with sessions.
+var DefaultLimits = Limits{
+	Rate:         20,
+	Burst:        40,
+	SpawnPerMin:  30,
+	MaxBodyBytes: 1 << 20,
+}
+
+// SetLimits configures rate limiting and body size limits. Call before
+// Start.
+func (s *Server) SetLimits(l Limits) {
+	s.limits = l
+	s.reqLimit = newRateLimiter(l.Rate, l.Burst)
+	s.spawnLimit = newRateLimiter(float64(l.SpawnPerMin)/60, l.SpawnPerMin)
+}
+
+// limitMiddleware rejects clients that exceed their request rate and caps
+// the size of API request bodies.
+func (s *Server) limitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if r.Method == http.MethodOptions ||
+			!(strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/ws/")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := s.clientKey(r)
+		if wait, ok := s.reqLimit.allow(key); !ok {
+			tooManyRequests(w, wait)
+			return
+		}
+		if r.Method == http.MethodPost && spawnsAgents(path) {
+			if wait, ok := s.spawnLimit.allow(key); !ok {
+				tooManyRequests(w, wait)
+				return
+			}
+		}
+
+		if s.limits.MaxBodyBytes > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, s.limits.MaxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// spawnsAgents reports whether a POST to path starts codex agents.
+func spawnsAgents(path string) bool {
+	if path == apiPrefix+"/sessions" || path == apiPrefix+"/agents" {
+		return true
+	}
+	if strings.HasPrefix(path, apiPrefix+"/agents/") && strings.HasSuffix(path, "/message") {
+		return true
+	}
+	for _, suffix := range []string{"/decompose", "/execute", "/resume", "/merge", "/clone"} {
+		if strings.HasPrefix(path, apiPrefix+"/sessions/") && strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return strings.HasPrefix(path, apiPrefix+"/templates/") && strings.HasSuffix(path, "/sessions")
+}
+
+// clientKey identifies the caller by tenant when the request carries a
+// valid tenant API key, otherwise by IP address. An unverified key must
+// not pick the bucket, or a client could dodge its limit by rotating
+// made-up keys.
+func (s *Server) clientKey(r *http.Request) string {
+	if s.tenants != nil {
+		if t, ok := s.tenants.Authenticate(requestAPIKey(r)); ok {
+			return "tenant:" + t.ID
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// tooManyRequests writes a 429 with a Retry-After hint.
+func tooManyRequests(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	httpError(w, "Too many requests", http.StatusTooManyRequests)
+}
+
+// badRequestBody reports a body that failed to decode, distinguishing
+// bodies that exceeded the size limit.
+func badRequestBody(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		httpError(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	httpError(w, "Invalid request body", http.StatusBadRequest)
+}
+
+// rateLimiter is a set of per-client token buckets.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketIdle is how long an untouched bucket is kept; by then it is full.
+const bucketIdle = 10 * time.Minute
+
+// newRateLimiter returns a limiter, or nil if rate or burst is zero.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 || burst <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		swept:   time.Now(),
+	}
+}
+
+// allow takes a token from key's bucket. When the bucket is empty it
+// returns how long until the next token is available.
+func (l *rateLimiter) allow(key string) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.swept) > bucketIdle {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > bucketIdle {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
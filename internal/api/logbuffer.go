@@ -0,0 +1,45 @@
+package api
+
+import "sync"
+
+// LogBuffer keeps the most recent entries written to it, so that log
+// bundles can include server logs. Install it as an output of the
+// standard logger, which writes one entry per Write.
+type LogBuffer struct {
+	mu      sync.Mutex
+	entries []string
+	next    int // index of the oldest entry once the buffer is full
+	full    bool
+}
+
+// NewLogBuffer creates a buffer of the last size entries.
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{entries: make([]string, max(size, 1))}
+}
+
+// Write records p as an entry.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[b.next] = string(p)
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+	return len(p), nil
+}
+
+// Entries returns the buffered entries, oldest first.
+func (b *LogBuffer) Entries() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]string(nil), b.entries[:b.next]...)
+	}
+	return append(append([]string(nil), b.entries[b.next:]...), b.entries[:b.next]...)
+}
+
+// SetLogBuffer includes the entries of b in session log bundles.
+func (s *Server) SetLogBuffer(b *LogBuffer) {
+	s.logs = b
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"codex-agent-team/internal/session"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// scheduleInfo is a schedule and when it next fires.
+type scheduleInfo struct {
+	*session.Schedule
+	NextRun *time.Time `json:"nextRun,omitempty"`
+}
+
+// newScheduleInfo computes the next run of sc.
+func newScheduleInfo(sc *session.Schedule) scheduleInfo {
+	info := scheduleInfo{Schedule: sc}
+	if next := sc.Next(time.Now()); !next.IsZero() {
+		info.NextRun = &next
+	}
+	return info
+}
+
+// schedulesAllowed writes 403 and returns false for tenants: scheduled
+// sessions run outside any tenant.
+func schedulesAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if tenantFrom(r) != nil {
+		httpError(w, "Schedules are not available to tenants", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// StartScheduler runs the stored schedules until the server shuts down.
+func (s *Server) StartScheduler() {
+	s.sessionMgr.StartScheduler()
+}
+
+// handleSaveSchedule creates or replaces a schedule.
+func (s *Server) handleSaveSchedule(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Schedules()
+	if store == nil {
+		httpError(w, "Schedule store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var sc session.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&sc); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	if sc.RepoPath == "" {
+		sc.RepoPath = s.defaultRepo
+	}
+	absPath, err := filepath.Abs(sc.RepoPath)
+	if err != nil {
+		httpError(w, "Invalid repo path", http.StatusBadRequest)
+		return
+	}
+	sc.RepoPath = absPath
+	// Runs are recorded by the scheduler, not the client
+	sc.LastRun = nil
+	if prev, err := store.Get(sc.Name); err == nil {
+		sc.CreatedAt, sc.LastRun = prev.CreatedAt, prev.LastRun
+	}
+
+	var v validation
+	v.name("name", sc.Name)
+	v.repo(r.Context(), "repoPath", sc.RepoPath)
+	if v.failed(w) {
+		return
+	}
+	if err := sc.Normalize(); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sc.Template != "" {
+		templates := s.sessionMgr.Templates()
+		if templates == nil {
+			httpError(w, "Template store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := templates.Get(sc.Template); err != nil {
+			httpError(w, "Template not found", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := store.Save(&sc); err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newScheduleInfo(&sc))
+}
+
+// handleListSchedules returns all schedules with their next run.
+func (s *Server) handleListSchedules(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Schedules()
+	if store == nil {
+		httpError(w, "Schedule store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	schedules, err := store.List()
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	infos := make([]scheduleInfo, 0, len(schedules))
+	for _, sc := range schedules {
+		infos = append(infos, newScheduleInfo(sc))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+// handleGetSchedule returns one schedule.
+func (s *Server) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Schedules()
+	if store == nil {
+		httpError(w, "Schedule store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	sc, err := store.Get(chi.URLParam(r, "name"))
+	if err != nil {
+		httpError(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newScheduleInfo(sc))
+}
+
+// handleDeleteSchedule removes a schedule. A run in progress continues.
+func (s *Server) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Schedules()
+	if store == nil {
+		httpError(w, "Schedule store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := store.Delete(chi.URLParam(r, "name")); err != nil {
+		httpError(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// handleRunSchedule starts a run of a schedule now.
+func (s *Server) handleRunSchedule(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+
+	run, err := s.sessionMgr.RunSchedule(chi.URLParam(r, "name"))
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, session.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		httpError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run)
+}
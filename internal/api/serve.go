@@ -0,0 +1,222 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	web "codex-agent-team/web"
+)
+
+// TLSOptions configures HTTPS serving.
+type TLSOptions struct {
+	CertFile   string // PEM certificate
+	KeyFile    string // PEM private key
+	SelfSigned bool   // generate a throwaway certificate for local use
+}
+
+// SetTLS serves HTTPS instead of plain HTTP.
+func (s *Server) SetTLS(opts TLSOptions) {
+	s.tls = &opts
+}
+
+// SetTrustProxy honors X-Forwarded-For, X-Forwarded-Proto and
+// X-Forwarded-Host. Enable only behind a proxy that sets them.
+func (s *Server) SetTrustProxy(trust bool) {
+	s.trustProxy = trust
+}
+
+// SetTrustedProxies lists the proxy addresses (IPs or CIDRs) in front of
+// the server. With -trust-proxy, X-Forwarded-For is read right to left and
+// the first address that is not a trusted proxy is taken as the client.
+// With no list, only the peer itself is trusted, so the rightmost entry -
+// the one it appended - wins; entries to its left are client-supplied.
+func (s *Server) SetTrustedProxies(proxies []string) error {
+	s.proxies = nil
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return fmt.Errorf("invalid proxy address %q", p)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 128
+			}
+			p = fmt.Sprintf("%s/%d", p, bits)
+		}
+		_, cidr, err := net.ParseCIDR(p)
+		if err != nil {
+			return fmt.Errorf("invalid proxy address %q", p)
+		}
+		s.proxies = append(s.proxies, cidr)
+	}
+	return nil
+}
+
+// trustedProxy reports whether addr is a configured proxy.
+func (s *Server) trustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range s.proxies {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedClient picks the client address from an X-Forwarded-For chain:
+// the rightmost entry that is not one of our proxies.
+func (s *Server) forwardedClient(fwd string) string {
+	hops := strings.Split(fwd, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop != "" && (i == 0 || !s.trustedProxy(hop)) {
+			return hop
+		}
+	}
+	return ""
+}
+
+// SetBasePath serves the UI, API and WebSockets under a path prefix such
+// as "/codex-team".
+func (s *Server) SetBasePath(prefix string) {
+	s.basePath = "/" + strings.Trim(prefix, "/")
+	if s.basePath == "/" {
+		s.basePath = ""
+	}
+}
+
+// handler returns the root handler, stripping the base path if one is set.
+func (s *Server) handler() http.Handler {
+	if s.basePath == "" {
+		return s.router
+	}
+	mux := http.NewServeMux()
+	mux.Handle(s.basePath+"/", http.StripPrefix(s.basePath, s.router))
+	mux.Handle(s.basePath, http.RedirectHandler(s.basePath+"/", http.StatusMovedPermanently))
+	return mux
+}
+
+// proxyMiddleware rewrites the client address, scheme and host from
+// X-Forwarded-* headers when the proxy is trusted.
+func (s *Server) proxyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.trustProxy {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Headers only mean something when they come from our proxy
+		if len(s.proxies) > 0 {
+			peer, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				peer = r.RemoteAddr
+			}
+			if !s.trustedProxy(peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if client := s.forwardedClient(r.Header.Get("X-Forwarded-For")); client != "" {
+			r.RemoteAddr = net.JoinHostPort(client, "0")
+		} else if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			r.RemoteAddr = net.JoinHostPort(ip, "0")
+		}
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			r.URL.Scheme = proto
+		}
+		if host := r.Header.Get("X-Forwarded-Host"); host != "" {
+			r.Host = host
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// externalURL returns the absolute URL clients use to reach path.
+func (s *Server) externalURL(r *http.Request, path string) string {
+	scheme := r.URL.Scheme
+	if scheme == "" {
+		scheme = "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+	}
+	return scheme + "://" + r.Host + s.basePath + path
+}
+
+// serveIndex serves index.html with asset URLs rewritten for the base path
+// and the base path exposed to the frontend as window.__BASE_PATH__.
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	data, err := fs.ReadFile(web.FS(), "index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	data = bytes.ReplaceAll(data, []byte(`="/assets/`), []byte(`="`+s.basePath+`/assets/`))
+	data = bytes.Replace(data, []byte("<head>"),
+		[]byte(`<head><script>window.__BASE_PATH__="`+s.basePath+`"</script>`), 1)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(data)
+}
+
+// tlsConfig returns the TLS configuration for Start, or nil for plain HTTP.
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	if s.tls == nil {
+		return nil, nil
+	}
+	if !s.tls.SelfSigned {
+		cert, err := tls.LoadX509KeyPair(s.tls.CertFile, s.tls.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	cert, err := selfSignedCert()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// selfSignedCert generates a certificate for localhost valid for one year.
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"Codex Agent Team"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -1,16 +1,30 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"codex-agent-team/internal/codexrpc"
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/issues"
 	"codex-agent-team/internal/session"
+	"codex-agent-team/internal/slack"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/tenant"
+	"codex-agent-team/internal/testreport"
 	web "codex-agent-team/web"
 
 	"github.com/go-chi/chi/v5"
@@ -27,18 +41,47 @@ type Server struct {
 	hub          *Hub
 	shutdownOnce sync.Once
 	shutdownCh   chan struct{}
+	slack        *slack.Client    // optional chat-ops approvals
+	slackPosts   sync.Map         // approval ID -> channel closed once its Slack post returns
+	issues       *issues.Registry // optional issue trackers
+	watchSecret  string           // verifies push webhooks; empty disables them
+	shares       *shareSigner     // read-only share tokens
+	tenants      *tenant.Registry // nil in single-tenant mode
+	corsOrigins  []string         // allowed origins; empty allows all
+	tls          *TLSOptions      // nil serves plain HTTP
+	trustProxy   bool             // honor X-Forwarded-* headers
+	proxies      []*net.IPNet     // trusted proxy hops; see SetTrustedProxies
+	basePath     string           // path prefix, e.g. "/codex-team"
+	limits       Limits           // body size and rate limits
+	reqLimit     *rateLimiter     // all API requests, per client
+	spawnLimit   *rateLimiter     // agent-spawning requests, per client
+	logs         *LogBuffer       // recent log entries for log bundles; may be nil
 }
 
 // NewServer creates a new API server.
 func NewServer(codexBin, defaultRepo string) *Server {
+	return NewServerWithOptions(codexBin, defaultRepo, session.Options{})
+}
+
+// NewServerWithOptions creates a new API server whose session manager is
+// configured with opts.
+func NewServerWithOptions(codexBin, defaultRepo string, opts session.Options) *Server {
+	return NewServerWithManager(codexBin, defaultRepo, session.NewManagerWithOptions(codexBin, defaultRepo, opts))
+}
+
+// NewServerWithManager creates a new API server for the sessions of mgr,
+// e.g. the manager of an embedded agentteam.Team.
+func NewServerWithManager(codexBin, defaultRepo string, mgr *session.Manager) *Server {
 	s := &Server{
 		router:      chi.NewRouter(),
 		codexBin:    codexBin,
 		defaultRepo: defaultRepo,
-		sessionMgr:  session.NewManager(codexBin, defaultRepo),
+		sessionMgr:  mgr,
 		hub:         NewHub(),
 		shutdownCh:  make(chan struct{}),
+		shares:      newShareSigner(""),
 	}
+	s.SetLimits(DefaultLimits)
 
 	s.setupMiddleware()
 	s.setupRoutes()
@@ -46,41 +89,182 @@ func NewServer(codexBin, defaultRepo string) *Server {
 	// Start the hub broadcast loop
 	go s.hub.Run()
 
+	// Forward session and agent events to subscribed WebSocket clients
+	s.sessionMgr.Bus().Subscribe("websocket", events.SinkFunc(s.broadcastEvent))
+
 	return s
 }
 
+// Bus returns the event bus, for subscribing additional sinks.
+func (s *Server) Bus() *events.Bus {
+	return s.sessionMgr.Bus()
+}
+
+// SetContainerConfig runs agents of the given role inside containers.
+func (s *Server) SetContainerConfig(role agent.Role, cfg agent.ContainerConfig) {
+	s.sessionMgr.SetContainerConfig(role, cfg)
+}
+
+// SetApprovalPolicy configures how agent approval requests are answered.
+func (s *Server) SetApprovalPolicy(p agent.ApprovalPolicy) {
+	s.sessionMgr.SetApprovalPolicy(p)
+}
+
+// SetSlack posts pending approvals to Slack and accepts decisions from
+// its interactive messages. Call before Start.
+func (s *Server) SetSlack(cfg slack.Config) {
+	s.slack = slack.NewClient(cfg)
+}
+
+// SetStallPolicy configures stuck-turn detection for all agents.
+func (s *Server) SetStallPolicy(p agent.StallPolicy) {
+	s.sessionMgr.SetStallPolicy(p)
+}
+
+// SetRunnerFactory makes new sessions execute tasks through a remote backend.
+func (s *Server) SetRunnerFactory(f func(repoPath string) task.Runner) {
+	s.sessionMgr.SetRunnerFactory(f)
+}
+
 // setupMiddleware configures server middleware.
 func (s *Server) setupMiddleware() {
+	s.router.Use(s.proxyMiddleware)
+	s.router.Use(s.versionMiddleware)
 	s.router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
+		AllowOriginFunc:  s.originAllowed,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", versionHeader},
+		ExposedHeaders:   []string{"Link", "Deprecation", versionHeader},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
+	s.router.Use(s.limitMiddleware)
+	s.router.Use(s.tenantMiddleware)
+}
+
+// SetCORSOrigins restricts cross-origin requests to the given origins.
+// An empty list or "*" allows any origin.
+func (s *Server) SetCORSOrigins(origins []string) {
+	s.corsOrigins = origins
+}
+
+// originAllowed reports whether a cross-origin request may proceed.
+func (s *Server) originAllowed(r *http.Request, origin string) bool {
+	if len(s.corsOrigins) == 0 {
+		return true
+	}
+	for _, o := range s.corsOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
 }
 
-// setupRoutes configures all HTTP routes.
+// setupRoutes configures all HTTP routes. The API is served under
+// /api/<version> and /ws/<version>; see versionMiddleware for the
+// unversioned paths.
 func (s *Server) setupRoutes() {
-	// Directory API
-	s.router.Get("/api/dirs", s.handleListDirs)
-	s.router.Get("/api/dirs/*", s.handleListDirs)
-
-	// Session API
-	s.router.Post("/api/sessions", s.handleCreateSession)
-	s.router.Get("/api/sessions/{id}", s.handleGetSession)
-	s.router.Post("/api/sessions/{id}/decompose", s.handleDecompose)
-	s.router.Post("/api/sessions/{id}/execute", s.handleExecute)
-	s.router.Post("/api/sessions/{id}/merge", s.handleMerge)
-	s.router.Get("/api/sessions/{id}/tasks", s.handleGetTasks)
-	s.router.Get("/api/sessions", s.handleListSessions)
-
-	// System info
-	s.router.Get("/api/info", s.handleInfo)
+	s.router.Get("/api/versions", s.handleVersions)
+
+	s.router.Route(apiPrefix, func(r chi.Router) {
+		// Directory API
+		r.Get("/dirs", s.handleListDirs)
+		r.Get("/dirs/*", s.handleListDirs)
+
+		// Session API
+		r.Post("/sessions", s.handleCreateSession)
+		r.Post("/sessions/from-issue", s.handleCreateSessionFromIssue)
+		r.Get("/sessions/{id}", s.handleGetSession)
+		r.Delete("/sessions/{id}", s.handleDeleteSession)
+		r.Post("/sessions/{id}/archive", s.handleArchiveSession)
+		r.Post("/sessions/{id}/clone", s.handleCloneSession)
+		r.Put("/sessions/{id}/priority", s.handleSetPriority)
+		r.Post("/sessions/{id}/decompose", s.handleDecompose)
+		r.Get("/sessions/{id}/estimate", s.handleGetEstimate)
+		r.Post("/sessions/{id}/simulate", s.handleSimulate)
+		r.Post("/sessions/{id}/confirm", s.handleConfirm)
+		r.Post("/sessions/{id}/execute", s.handleExecute)
+		r.Post("/sessions/{id}/resume", s.handleResume)
+		r.Post("/sessions/{id}/merge", s.handleMerge)
+		r.Post("/sessions/{id}/merge/rollback", s.handleRollbackMerge)
+		r.Get("/sessions/{id}/merge", s.handleGetMergeProvenance)
+		r.Get("/sessions/{id}/jobs", s.handleListJobs)
+		r.Get("/sessions/{id}/jobs/{jobId}", s.handleGetJob)
+		r.Get("/sessions/{id}/tasks", s.handleGetTasks)
+		r.Get("/sessions/{id}/dag/levels", s.handleGetLevels)
+		r.Get("/sessions/{id}/timeline", s.handleGetTimeline)
+		r.Get("/sessions/{id}/occupancy", s.handleGetOccupancy)
+		r.Post("/sessions/{id}/tasks/{taskId}/interrupt", s.handleInterruptTask)
+		r.Get("/sessions/{id}/tasks/{taskId}/comments", s.handleListComments)
+		r.Post("/sessions/{id}/tasks/{taskId}/comments", s.handleAddComment)
+		r.Get("/sessions/{id}/tests", s.handleGetTestReport)
+		r.Get("/sessions/{id}/artifacts", s.handleListArtifacts)
+		r.Get("/sessions/{id}/artifacts/{taskId}/*", s.handleDownloadArtifact)
+		r.Get("/sessions/{id}/logs.zip", s.handleDownloadLogs)
+		r.Get("/sessions/{id}/approvals", s.handleListApprovals)
+		r.Post("/sessions/{id}/approvals/{approvalId}", s.handleDecideApproval)
+		r.Get("/sessions/{id}/scratchpad", s.handleGetScratchpad)
+		r.Put("/sessions/{id}/scratchpad/{key}", s.handleSetNote)
+		r.Delete("/sessions/{id}/scratchpad/{key}", s.handleDeleteNote)
+		r.Post("/slack/interactions", s.handleSlackInteraction)
+		r.Post("/sessions/{id}/share", s.handleCreateShare)
+		r.Get("/sessions", s.handleListSessions)
+		r.Get("/runtimes", s.handleListRuntimes)
+		r.Get("/models", s.handleListModels)
+		r.Get("/threads", s.handleListThreads)
+		r.Delete("/threads/{id}", s.handleArchiveThread)
+		r.Post("/threads/archive", s.handleArchiveIdleThreads)
+
+		// Read-only shared session API
+		r.Get("/shared/{token}", s.handleSharedSession)
+		r.Get("/shared/{token}/tasks", s.handleSharedTasks)
+		r.Get("/shared/{token}/tasks/{taskId}/transcript", s.handleSharedTranscript)
+
+		// Template API
+		r.Post("/templates", s.handleCreateTemplate)
+		r.Get("/templates", s.handleListTemplates)
+		r.Get("/templates/{name}", s.handleGetTemplate)
+		r.Delete("/templates/{name}", s.handleDeleteTemplate)
+		r.Post("/templates/{name}/sessions", s.handleInstantiateTemplate)
+
+		// Schedule API
+		r.Post("/schedules", s.handleSaveSchedule)
+		r.Get("/schedules", s.handleListSchedules)
+		r.Get("/schedules/{name}", s.handleGetSchedule)
+		r.Delete("/schedules/{name}", s.handleDeleteSchedule)
+		r.Post("/schedules/{name}/run", s.handleRunSchedule)
+
+		// Watch API
+		r.Post("/watches", s.handleSaveWatch)
+		r.Get("/watches", s.handleListWatches)
+		r.Get("/watches/{name}", s.handleGetWatch)
+		r.Delete("/watches/{name}", s.handleDeleteWatch)
+		r.Post("/watches/{name}/run", s.handleRunWatch)
+		r.Post("/hooks/push", s.handlePushHook)
+
+		// Merge notes API
+		r.Get("/merge-notes", s.handleGetMergeNotes)
+		r.Put("/merge-notes", s.handleSetMergeConventions)
+
+		// Ad-hoc agent API
+		r.Post("/agents", s.handleSpawnAgent)
+		r.Get("/agents", s.handleListAgents)
+		r.Get("/agents/{id}", s.handleGetAgent)
+		r.Delete("/agents/{id}", s.handleStopAgent)
+		r.Post("/agents/{id}/message", s.handleMessageAgent)
+
+		// System info
+		r.Get("/info", s.handleInfo)
+		r.Get("/health", s.handleHealth)
+		r.Get("/tenant", s.handleGetTenant)
+	})
 
 	// WebSocket endpoint
-	s.router.Get("/ws/sessions/{id}", s.handleWebSocket)
+	s.router.Route(wsPrefix, func(r chi.Router) {
+		r.Get("/sessions/{id}", s.handleWebSocket)
+		r.Get("/shared/{token}", s.handleSharedWebSocket)
+	})
 
 	// Serve embedded frontend (catch-all route)
 	frontendFS := http.FS(web.FS())
@@ -90,6 +274,10 @@ func (s *Server) setupRoutes() {
 			http.NotFound(w, r)
 			return
 		}
+		if r.URL.Path == "/" || r.URL.Path == "/index.html" {
+			s.serveIndex(w, r)
+			return
+		}
 		http.FileServer(frontendFS).ServeHTTP(w, r)
 	})
 }
@@ -101,7 +289,10 @@ func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
 		"version":     "1.0.0",
 		"name":        "Codex Agent Team",
 		"defaultRepo": s.defaultRepo,
+		"apiVersion":   APIVersion,
 		"codexBin":     s.codexBin,
+		"droppedEvents": s.sessionMgr.DroppedAgentEvents(),
+		"agentCalls":    s.sessionMgr.AgentCallStats(),
 	})
 }
 
@@ -119,14 +310,25 @@ func (s *Server) handleListDirs(w http.ResponseWriter, r *http.Request) {
 	// Convert to absolute path
 	absPath, err := filepath.Abs(path)
 	if err != nil {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		httpError(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	// Tenants may only browse inside their registered repos
+	if t := tenantFrom(r); t != nil && !t.AllowsRepo(absPath) {
+		repos := make([]map[string]any, 0, len(t.Repos))
+		for _, repo := range t.Repos {
+			repos = append(repos, map[string]any{"name": filepath.Base(repo), "path": repo, "isGit": true})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"path": "", "dirs": repos, "parent": ""})
 		return
 	}
 
 	// Read directory
 	entries, err := os.ReadDir(absPath)
 	if err != nil {
-		http.Error(w, "Failed to read directory", http.StatusNotFound)
+		httpError(w, "Failed to read directory", http.StatusNotFound)
 		return
 	}
 
@@ -170,6 +372,18 @@ func (s *Server) handleListDirs(w http.ResponseWriter, r *http.Request) {
 // handleListSessions returns all sessions.
 func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
 	sessions := s.sessionMgr.ListAll()
+	if t := tenantFrom(r); t != nil {
+		owned := sessions[:0]
+		for _, sess := range sessions {
+			if sess.TenantID == t.ID {
+				owned = append(owned, sess)
+			}
+		}
+		sessions = owned
+	}
+	for _, sess := range sessions {
+		sess.RefreshRepo(r.Context())
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(sessions)
 }
@@ -179,46 +393,191 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		UserTask  string `json:"userTask"`
 		RepoPath  string `json:"repoPath,omitempty"`
+
+		Codex         string `json:"codex,omitempty"`         // registered codex binary, see /api/runtimes
+		ModelProvider string `json:"modelProvider,omitempty"` // registered model provider
+
+		Priority int `json:"priority,omitempty"` // higher runs first when agents are scarce
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequestBody(w, err)
+		return
+	}
+	sess, ok := s.createSession(w, r, req.UserTask, req.RepoPath, req.Codex, req.ModelProvider, nil)
+	if !ok {
 		return
 	}
+	if req.Priority != 0 {
+		sess.SetPriority(req.Priority)
+	}
+	sess.RefreshRepo(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess)
+}
+
+// createSession validates the task, repository and runtime of a new
+// session, creates it and announces it. fetchIssue, if set, supplies the task and
+// the linked issue once the request is validated. On failure the error
+// has been written.
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request, userTask, repoPath, codex, provider string, fetchIssue func() (string, *session.IssueLink, bool)) (*session.Session, bool) {
+	rt, err := s.sessionMgr.ResolveRuntime(codex, provider)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
 
 	// Use provided repo path or default
-	repoPath := req.RepoPath
 	if repoPath == "" {
 		repoPath = s.defaultRepo
 	}
 	// Convert to absolute path
 	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
-		http.Error(w, "Invalid repo path", http.StatusBadRequest)
-		return
+		httpError(w, "Invalid repo path", http.StatusBadRequest)
+		return nil, false
+	}
+
+	if !repoAllowed(r, absPath) {
+		httpError(w, "Repository not registered for tenant", http.StatusForbidden)
+		return nil, false
+	}
+	var v validation
+	if fetchIssue == nil {
+		v.required("userTask", userTask)
+	}
+	v.repo(r.Context(), "repoPath", absPath)
+	if v.failed(w) {
+		return nil, false
+	}
+	if !s.checkCodex(w, r, rt) {
+		return nil, false
+	}
+	var issue *session.IssueLink
+	if fetchIssue != nil {
+		var ok bool
+		if userTask, issue, ok = fetchIssue(); !ok {
+			return nil, false
+		}
 	}
 
 	ctx := r.Context()
-	sess, err := s.sessionMgr.CreateWithPath(ctx, req.UserTask, absPath)
+	sess, err := s.sessionMgr.CreateWithPath(ctx, userTask, absPath)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if err := sess.SetRuntime(codex, provider); err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	assignTenant(r, sess)
+	if issue != nil {
+		sess.SetIssue(issue)
 	}
 
 	s.hub.Broadcast(sess.ID, Event{
 		Type: "session.created",
 		Data: sess,
 	})
+	return sess, true
+}
 
+// handleListRuntimes returns the codex binaries and model providers a
+// session can be created with.
+func (s *Server) handleListRuntimes(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(sess)
+	json.NewEncoder(w).Encode(s.sessionMgr.Runtimes())
+}
+
+// mergeNotesRepo resolves the repository of a merge notes request, the
+// default repository when none is given. It answers the request and
+// returns false when the path is unusable.
+func (s *Server) mergeNotesRepo(w http.ResponseWriter, r *http.Request, repoPath string) (string, bool) {
+	if repoPath == "" {
+		repoPath = s.defaultRepo
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		httpError(w, "Invalid repo path", http.StatusBadRequest)
+		return "", false
+	}
+	if !repoAllowed(r, absPath) {
+		httpError(w, "Repository not registered for tenant", http.StatusForbidden)
+		return "", false
+	}
+	return absPath, true
+}
+
+// handleGetMergeNotes returns what earlier merges taught about a
+// repository (?repo=, default repository otherwise).
+func (s *Server) handleGetMergeNotes(w http.ResponseWriter, r *http.Request) {
+	repoPath, ok := s.mergeNotesRepo(w, r, r.URL.Query().Get("repo"))
+	if !ok {
+		return
+	}
+	notes := s.sessionMgr.MergeNotes(repoPath)
+	if notes == nil {
+		httpError(w, "Merge notes are not stored", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notes)
+}
+
+// handleSetMergeConventions replaces the resolution conventions given to
+// merger agents of a repository.
+func (s *Server) handleSetMergeConventions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RepoPath    string   `json:"repoPath,omitempty"`
+		Conventions []string `json:"conventions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	repoPath, ok := s.mergeNotesRepo(w, r, req.RepoPath)
+	if !ok {
+		return
+	}
+
+	notes, err := s.sessionMgr.SetMergeConventions(repoPath, req.Conventions)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notes)
 }
 
 // handleGetSession retrieves a session by ID.
+// With ?wait=<duration>&ifNoneMatch=<etag> it long-polls: while the
+// session's ETag still matches, the response is held until the session
+// changes or the wait runs out, and then returns the current state. A
+// matching If-None-Match header is answered with 304 Not Modified.
 func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	sess, ok := s.sessionMgr.Get(id)
+	sess, ok := s.getSession(r, id)
 	if !ok {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	// The repository can move without a session event; refresh before
+	// comparing ETags so a new HEAD or dirty state is not answered with 304
+	sess.RefreshRepo(r.Context())
+	if q := r.URL.Query(); q.Get("wait") != "" {
+		wait, err := time.ParseDuration(q.Get("wait"))
+		if err != nil || wait <= 0 || wait > maxLongPoll {
+			httpError(w, "wait must be a positive duration up to "+maxLongPoll.String(), http.StatusBadRequest)
+			return
+		}
+		waitForChange(r.Context(), sess, q.Get("ifNoneMatch"), wait)
+		sess.RefreshRepo(r.Context())
+	}
+	if notModified(w, r, sess) {
 		return
 	}
 
@@ -226,156 +585,1393 @@ func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(sess)
 }
 
-// handleDecompose triggers task decomposition.
-func (s *Server) handleDecompose(w http.ResponseWriter, r *http.Request) {
+// maxLongPoll bounds how long a session GET may be held open.
+const maxLongPoll = 2 * time.Minute
+
+// waitForChange blocks until the session's ETag no longer matches etag,
+// the wait runs out or the request goes away. An empty etag does not wait.
+func waitForChange(ctx context.Context, sess *session.Session, etag string, wait time.Duration) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for etag != "" {
+		_, changed := sess.Revision()
+		if sess.ETag() != etag {
+			return
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notModified sets the session's ETag on the response and answers 304 Not
+// Modified when the request's If-None-Match already names it. Responses
+// are marked for revalidation, so browsers send If-None-Match themselves.
+func notModified(w http.ResponseWriter, r *http.Request, sess *session.Session) bool {
+	etag := sess.ETag()
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// handleDeleteSession stops a session's agents, removes its worktrees and
+// deletes its persisted data. Task branches are deleted only when
+// ?deleteBranches=true is given.
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	sess, ok := s.sessionMgr.Get(id)
-	if !ok {
-		http.Error(w, "Session not found", http.StatusNotFound)
+	deleteBranches := r.URL.Query().Get("deleteBranches") == "true"
+	if _, ok := s.getSession(r, id); !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
 		return
 	}
 
-	ctx := r.Context()
-	if err := sess.Decompose(ctx); err != nil {
-		s.hub.Broadcast(id, Event{
-			Type: "session.error",
-			Data: map[string]string{"error": err.Error()},
-		})
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := s.sessionMgr.Delete(r.Context(), id, deleteBranches); err != nil {
+		httpError(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	// Broadcast updated tasks
-	tasks := sess.DAG.GetTasks()
 	s.hub.Broadcast(id, Event{
-		Type: "session.decomposed",
-		Data: map[string]any{"tasks": tasks},
+		Type: "session.deleted",
+		Data: map[string]string{"id": id},
 	})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "decomposed"})
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 }
 
-// handleExecute starts task execution.
-func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
+// handleArchiveSession frees a session's runtime resources but keeps its record.
+func (s *Server) handleArchiveSession(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	sess, ok := s.sessionMgr.Get(id)
+	sess, ok := s.getSession(r, id)
 	if !ok {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
 		return
 	}
 
-	// Start execution in background
-	go func() {
-		ctx := context.Background()
-		if err := sess.Execute(ctx); err != nil {
-			s.hub.Broadcast(id, Event{
-				Type: "session.error",
-				Data: map[string]string{"error": err.Error()},
-			})
-			return
-		}
-	}()
+	if err := sess.Archive(r.Context()); err != nil {
+		writeErrorFor(w, err)
+		return
+	}
 
 	s.hub.Broadcast(id, Event{
-		Type: "session.executing",
-		Data: map[string]string{"status": "running"},
+		Type: "session.archived",
+		Data: map[string]string{"id": id},
 	})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "executing"})
+	json.NewEncoder(w).Encode(map[string]string{"status": "archived"})
 }
 
-// handleMerge triggers merging of completed tasks.
-func (s *Server) handleMerge(w http.ResponseWriter, r *http.Request) {
+// handleCloneSession creates a new session reusing another session's DAG.
+func (s *Server) handleCloneSession(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	sess, ok := s.sessionMgr.Get(id)
+	src, ok := s.getSession(r, id)
 	if !ok {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+	if !s.checkCodex(w, r, src.Runtime()) {
 		return
 	}
 
-	ctx := r.Context()
-	if err := sess.Merge(ctx); err != nil {
-		s.hub.Broadcast(id, Event{
-			Type: "session.error",
-			Data: map[string]string{"error": err.Error()},
-		})
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	sess, err := s.sessionMgr.Clone(r.Context(), id)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusConflict)
 		return
 	}
+	assignTenant(r, sess)
 
-	s.hub.Broadcast(id, Event{
-		Type: "session.merged",
-		Data: map[string]string{"status": "completed"},
+	s.hub.Broadcast(sess.ID, Event{
+		Type: "session.created",
+		Data: sess,
 	})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "merged"})
+	json.NewEncoder(w).Encode(sess)
 }
 
-// handleGetTasks returns all tasks in a session.
-func (s *Server) handleGetTasks(w http.ResponseWriter, r *http.Request) {
+// handleSetPriority changes a session's scheduling priority. A running
+// session's tasks that have not started yet are scheduled with it.
+func (s *Server) handleSetPriority(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	sess, ok := s.sessionMgr.Get(id)
+	sess, ok := s.getSession(r, id)
 	if !ok {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
 		return
 	}
 
-	tasks := sess.DAG.GetTasks()
+	var req struct {
+		Priority int `json:"priority"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	sess.SetPriority(req.Priority)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(tasks)
+	json.NewEncoder(w).Encode(sess)
 }
 
-// handleWebSocket handles WebSocket connections for real-time updates.
-func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	sessionID := chi.URLParam(r, "id")
+// handleDecompose triggers task decomposition.
+func (s *Server) handleDecompose(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
 
-	// Check if session exists
-	if _, ok := s.sessionMgr.Get(sessionID); !ok {
-		http.Error(w, "Session not found", http.StatusNotFound)
+	if !s.checkBudget(w, r) {
 		return
 	}
 
-	opts := &websocket.AcceptOptions{
-		OriginPatterns: []string{"*"},
+	// ?force=true bypasses the decomposition cache
+	force := r.URL.Query().Get("force") == "true"
+
+	// Decomposition runs on the session context, so it survives the client
+	// disconnecting; the outcome is reported over the WebSocket.
+	job, err := sess.DecomposeAsync(force, func(result *session.DecomposeResult, err error) {
+		if err != nil {
+			s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+			return
+		}
+
+		for _, warning := range result.Warnings {
+			s.hub.Broadcast(id, Event{
+				Type: "session.warning",
+				Data: map[string]string{"warning": warning},
+			})
+		}
+
+		// Broadcast updated tasks
+		tasks := sess.TaskViews()
+		s.hub.Broadcast(id, Event{
+			Type: "session.decomposed",
+			Data: map[string]any{"tasks": tasks, "cached": result.Cached, "warnings": result.Warnings},
+		})
+	})
+	if err != nil {
+		writeErrorFor(w, err)
+		return
 	}
 
-	conn, err := websocket.Accept(w, r, opts)
+	s.writeJobAccepted(w, job, "decomposing")
+}
+
+// handleExecute starts task execution.
+func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	if !s.checkBudget(w, r) {
+		return
+	}
+
+	// Start execution in background
+	opts := session.RunOptions{AutoStash: r.URL.Query().Get("autoStash") == "true"}
+	job, err := sess.ExecuteAsync(r.Context(), opts, func(err error) {
+		if err != nil {
+			s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+		}
+	})
 	if err != nil {
+		writeErrorFor(w, err)
 		return
 	}
 
-	client := NewClient(sessionID, conn, s.hub)
-	s.hub.Register(client)
+	s.hub.Broadcast(id, Event{
+		Type: "session.executing",
+		Data: map[string]string{"status": "running"},
+	})
 
-	// Start client read/write loops
-	go client.ReadLoop()
-	go client.WriteLoop()
+	s.writeJobAccepted(w, job, "executing")
 }
 
-// Start starts the HTTP server.
-func (s *Server) Start(addr string) error {
-	server := &http.Server{
-		Addr:    addr,
-		Handler: s.router,
+// handleResume continues a failed or crashed execution from its checkpoint.
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
 	}
 
-	// Shutdown handler
-	go func() {
-		<-s.shutdownCh
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		server.Shutdown(ctx)
-	}()
+	if !s.checkBudget(w, r) {
+		return
+	}
+
+	// ?retryWithContext=true shows retried tasks their failed attempt
+	opts := session.RunOptions{
+		AutoStash:        r.URL.Query().Get("autoStash") == "true",
+		RetryWithContext: r.URL.Query().Get("retryWithContext") == "true",
+	}
+	job, err := sess.Resume(r.Context(), opts, func(err error) {
+		if err != nil {
+			s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+		}
+	})
+	if err != nil {
+		writeErrorFor(w, err)
+		return
+	}
 
+	s.hub.Broadcast(id, Event{
+		Type: "session.executing",
+		Data: map[string]string{"status": "running"},
+	})
+
+	s.writeJobAccepted(w, job, "resumed")
+}
+
+// handleMerge triggers merging of completed tasks.
+func (s *Server) handleMerge(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	// ?mode=pr-stack opens chained pull requests instead of merging locally
+	if r.URL.Query().Get("mode") == "pr-stack" {
+		s.handlePRStack(w, sess)
+		return
+	}
+
+	// ?autoStash=true stashes local changes in the checkout around the merge
+	opts := session.RunOptions{AutoStash: r.URL.Query().Get("autoStash") == "true"}
+
+	job, err := sess.MergeAsync(r.Context(), opts, func(err error) {
+		if err != nil {
+			s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+			return
+		}
+		s.hub.Broadcast(id, Event{
+			Type: "session.merged",
+			Data: map[string]string{"status": "completed"},
+		})
+	})
+	if err != nil {
+		writeErrorFor(w, err)
+		return
+	}
+
+	s.writeJobAccepted(w, job, "merging")
+}
+
+// handleGetMergeProvenance returns which commit merged each task in the
+// session's last merge phase.
+func (s *Server) handleGetMergeProvenance(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+	rec := sess.MergeProvenance()
+	if rec == nil {
+		httpError(w, "Session has not been merged", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// handleRollbackMerge resets the checkout to where a failed merge phase
+// started, so the session can be merged again.
+func (s *Server) handleRollbackMerge(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	opts := session.RunOptions{AutoStash: r.URL.Query().Get("autoStash") == "true"}
+	commit, err := sess.RollbackMerge(r.Context(), opts)
+	if err != nil {
+		writeErrorFor(w, err)
+		return
+	}
+
+	s.hub.Broadcast(id, Event{
+		Type: "session.mergeRolledBack",
+		Data: map[string]string{"commit": commit},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": string(session.StatusMerging), "commit": commit})
+}
+
+// handlePRStack pushes the session's task branches as a stack and opens a
+// pull request for each, in the background.
+func (s *Server) handlePRStack(w http.ResponseWriter, sess *session.Session) {
+	id := sess.ID
+	job, err := sess.PRStackAsync(func(stack []session.StackedPR, err error) {
+		if err != nil {
+			s.hub.Broadcast(id, Event{
+				Type: "session.error",
+				Data: errorEvent(err),
+			})
+			return
+		}
+		s.hub.Broadcast(id, Event{
+			Type: "session.prStack",
+			Data: map[string]any{"status": "completed", "pullRequests": stack},
+		})
+	})
+	if err != nil {
+		writeErrorFor(w, err)
+		return
+	}
+
+	s.writeJobAccepted(w, job, "opening pull requests")
+}
+
+// writeJobAccepted answers a request that started a background job with
+// 202 and the job, whose status is polled at the Location URL.
+func (s *Server) writeJobAccepted(w http.ResponseWriter, job session.Job, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", s.basePath+"/api/sessions/"+job.SessionID+"/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]any{"status": status, "jobId": job.ID, "job": job})
+}
+
+// handleListJobs returns a session's recent background jobs.
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	jobs := sess.Jobs()
+	views := make([]jobView, 0, len(jobs))
+	for _, job := range jobs {
+		views = append(views, newJobView(job))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views)
+}
+
+// handleGetJob returns the status of one background job.
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+	job, ok := sess.Job(chi.URLParam(r, "jobId"))
+	if !ok {
+		httpError(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newJobView(job))
+}
+
+// handleGetTasks returns all tasks in a session.
+func (s *Server) handleGetTasks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+	if notModified(w, r, sess) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess.TaskViews())
+}
+
+// handleGetLevels returns tasks grouped by dependency depth for lane rendering.
+func (s *Server) handleGetLevels(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+	if notModified(w, r, sess) {
+		return
+	}
+
+	levels, err := sess.TaskLevels()
+	if err != nil {
+		httpError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	type level struct {
+		Level int             `json:"level"`
+		Tasks []task.TaskView `json:"tasks"`
+	}
+	resp := make([]level, 0, len(levels))
+	for i, tasks := range levels {
+		resp = append(resp, level{Level: i, Tasks: tasks})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"levels": resp})
+}
+
+// handleInterruptTask interrupts the current turn of a running task.
+func (s *Server) handleInterruptTask(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	taskID := chi.URLParam(r, "taskId")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	// The follow-up instruction is optional, so an empty body is allowed
+	var req struct {
+		Instruction string `json:"instruction,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			badRequestBody(w, err)
+			return
+		}
+	}
+
+	if err := sess.InterruptTask(r.Context(), taskID, req.Instruction); err != nil {
+		if errors.Is(err, session.ErrTaskNotFound) {
+			writeError(w, http.StatusNotFound, CodeNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusConflict, CodeConflict, err.Error())
+		return
+	}
+
+	s.hub.Broadcast(id, Event{
+		Type: "task.interrupted",
+		Data: map[string]string{"taskId": taskID, "instruction": req.Instruction},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "interrupted"})
+}
+
+// handleGetTimeline returns the session's phases, task spans and events in
+// chronological order.
+func (s *Server) handleGetTimeline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	timeline, err := sess.Timeline()
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timeline)
+}
+
+// handleGetOccupancy returns per-agent busy intervals and parallelism over
+// time, to tell whether maxParallel or the DAG limits a session.
+func (s *Server) handleGetOccupancy(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	occ, err := sess.Occupancy()
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(occ)
+}
+
+// handleGetTestReport returns the test results and coverage aggregated
+// from the reports the session's tasks produced.
+func (s *Server) handleGetTestReport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	report := sess.TestReport()
+	if report == nil {
+		report = &testreport.Report{Sources: []string{}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleListArtifacts lists the files collected from the session's task
+// worktrees.
+func (s *Server) handleListArtifacts(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess.CollectedArtifacts())
+}
+
+// handleDownloadArtifact serves a file collected from a task's worktree.
+func (s *Server) handleDownloadArtifact(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	rel := chi.URLParam(r, "*")
+	f, err := sess.OpenArtifact(chi.URLParam(r, "taskId"), rel)
+	if err != nil {
+		if errors.Is(err, session.ErrArtifactNotFound) {
+			httpError(w, "Artifact not found", http.StatusNotFound)
+			return
+		}
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		httpError(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(rel)}))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// handleDownloadLogs returns a zip of the session's logs, transcripts, RPC
+// traces and git state to attach to bug reports.
+func (s *Server) handleDownloadLogs(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	var serverLog []string
+	if s.logs != nil {
+		serverLog = s.logs.Entries()
+	}
+	var buf bytes.Buffer
+	if err := sess.WriteLogBundle(r.Context(), &buf, serverLog); err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": sess.ID + "-logs.zip"}))
+	w.Write(buf.Bytes())
+}
+
+// handleListComments returns the human comments on a task, oldest first.
+func (s *Server) handleListComments(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	taskID := chi.URLParam(r, "taskId")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	comments, ok := sess.DAG.Comments(taskID)
+	if !ok {
+		httpError(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	if comments == nil {
+		comments = []task.Comment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// handleAddComment leaves a human comment on a task. Agents working on the
+// task later, such as a resumed attempt, get the recent comments.
+func (s *Server) handleAddComment(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	taskID := chi.URLParam(r, "taskId")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	var req struct {
+		Author string `json:"author,omitempty"`
+		Body   string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		httpError(w, "body is required", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := sess.AddTaskComment(taskID, req.Author, req.Body)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.hub.Broadcast(id, Event{
+		Type: "task.commented",
+		Data: map[string]any{"taskId": taskID, "comment": comment},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// handleListApprovals returns the session's pending approval requests.
+func (s *Server) handleListApprovals(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.sessionMgr.PendingApprovals(sess))
+}
+
+// handleDecideApproval answers a pending approval request.
+func (s *Server) handleDecideApproval(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	approvalID := chi.URLParam(r, "approvalId")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	var req struct {
+		Decision string `json:"decision"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+
+	if err := s.sessionMgr.DecideApproval(sess, approvalID, req.Decision); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, agent.ErrApprovalNotFound) {
+			status = http.StatusNotFound
+		}
+		httpError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "decided"})
+}
+
+// handleSimulate projects the execution of the session's plan from task
+// estimates, without spawning agents or creating branches. The body may
+// set maxParallel to try another parallelism.
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	var req struct {
+		MaxParallel int `json:"maxParallel,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			badRequestBody(w, err)
+			return
+		}
+	}
+	var v validation
+	v.maxParallel("maxParallel", req.MaxParallel)
+	if v.failed(w) {
+		return
+	}
+
+	sim, err := sess.Simulate(req.MaxParallel)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sim)
+}
+
+// handleGetEstimate returns the expected cost of executing the session's
+// plan and whether it must be confirmed first.
+func (s *Server) handleGetEstimate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess.Estimate())
+}
+
+// handleConfirm approves executing the session's plan at its current
+// estimate.
+func (s *Server) handleConfirm(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess.Confirm())
+}
+
+// handleGetScratchpad returns the session's scratchpad entries.
+func (s *Server) handleGetScratchpad(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess.Scratchpad.Entries())
+}
+
+// handleSetNote writes a scratchpad entry shown to tasks started later,
+// e.g. a naming decision every worker must follow.
+func (s *Server) handleSetNote(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	key := chi.URLParam(r, "key")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	var req struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	if req.Value == "" {
+		httpError(w, "value is required", http.StatusBadRequest)
+		return
+	}
+
+	sess.SetNote(key, req.Value)
+	s.hub.Broadcast(id, Event{
+		Type: "scratchpad.updated",
+		Data: sess.Scratchpad.Entries(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "saved"})
+}
+
+// handleDeleteNote removes a scratchpad entry.
+func (s *Server) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	key := chi.URLParam(r, "key")
+	sess, ok := s.getSession(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	if !sess.DeleteNote(key) {
+		httpError(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+	s.hub.Broadcast(id, Event{
+		Type: "scratchpad.updated",
+		Data: sess.Scratchpad.Entries(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// handleSlackInteraction routes an Approve/Decline click from Slack to the
+// approval queue.
+func (s *Server) handleSlackInteraction(w http.ResponseWriter, r *http.Request) {
+	if s.slack == nil {
+		httpError(w, "Slack integration disabled", http.StatusNotFound)
+		return
+	}
+
+	action, err := s.slack.ParseInteraction(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, slack.ErrInvalidSignature) {
+			status = http.StatusUnauthorized
+		}
+		httpError(w, err.Error(), status)
+		return
+	}
+
+	sess, ok := s.sessionMgr.Get(action.SessionID)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	// Slack only needs a 200; the message is updated once the decision lands
+	if err := s.sessionMgr.DecideApproval(sess, action.ApprovalID, action.Decision); err != nil {
+		log.Printf("Slack approval %s: %v", action.ApprovalID, err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// templatesAllowed writes 403 and returns false for tenants: the template
+// store is shared by all tenants.
+func templatesAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if tenantFrom(r) != nil {
+		httpError(w, "Templates are not available to tenants", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// handleCreateTemplate saves a decomposition as a named template, either
+// from an existing session or from an explicit task list.
+func (s *Server) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	if !templatesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Templates()
+	if store == nil {
+		httpError(w, "Template store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		SessionID   string                 `json:"sessionId,omitempty"`
+		Replace     map[string]string      `json:"replace,omitempty"` // literal -> param name
+		UserTask    string                 `json:"userTask,omitempty"`
+		Tasks       []session.TemplateTask `json:"tasks,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+
+	var tmpl *session.Template
+	if req.SessionID != "" {
+		sess, ok := s.getSession(r, req.SessionID)
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+			return
+		}
+		var err error
+		tmpl, err = session.NewTemplateFromSession(req.Name, req.Description, sess, req.Replace)
+		if err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		tmpl = &session.Template{
+			Name:        req.Name,
+			Description: req.Description,
+			UserTask:    req.UserTask,
+			Tasks:       req.Tasks,
+		}
+	}
+	var v validation
+	v.name("name", tmpl.Name)
+	v.tasks("tasks", tmpl.Tasks)
+	if v.failed(w) {
+		return
+	}
+
+	if err := tmpl.Normalize(); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := store.Save(tmpl); err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmpl)
+}
+
+// handleListTemplates returns all saved templates.
+func (s *Server) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	if !templatesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Templates()
+	if store == nil {
+		httpError(w, "Template store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	templates, err := store.List()
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if templates == nil {
+		templates = []*session.Template{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// handleGetTemplate retrieves a template by name.
+func (s *Server) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	if !templatesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Templates()
+	if store == nil {
+		httpError(w, "Template store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	tmpl, err := store.Get(chi.URLParam(r, "name"))
+	if err != nil {
+		httpError(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmpl)
+}
+
+// handleDeleteTemplate removes a template.
+func (s *Server) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if !templatesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Templates()
+	if store == nil {
+		httpError(w, "Template store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := store.Delete(chi.URLParam(r, "name")); err != nil {
+		httpError(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// handleInstantiateTemplate creates a new session from a template.
+func (s *Server) handleInstantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	if !templatesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Templates()
+	if store == nil {
+		httpError(w, "Template store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	tmpl, err := store.Get(chi.URLParam(r, "name"))
+	if err != nil {
+		httpError(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		Params   map[string]string `json:"params"`
+		RepoPath string            `json:"repoPath,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+
+	repoPath := req.RepoPath
+	if repoPath == "" {
+		repoPath = s.defaultRepo
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		httpError(w, "Invalid repo path", http.StatusBadRequest)
+		return
+	}
+
+	if !repoAllowed(r, absPath) {
+		httpError(w, "Repository not registered for tenant", http.StatusForbidden)
+		return
+	}
+	var v validation
+	for _, p := range tmpl.Params {
+		if _, ok := req.Params[p]; !ok {
+			v.addf("params."+p, "is required")
+		}
+	}
+	v.repo(r.Context(), "repoPath", absPath)
+	if v.failed(w) {
+		return
+	}
+	if !s.checkCodex(w, r, agent.Runtime{}) {
+		return
+	}
+
+	sess, err := s.sessionMgr.CreateFromTemplate(r.Context(), tmpl, req.Params, absPath)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	assignTenant(r, sess)
+
+	s.hub.Broadcast(sess.ID, Event{
+		Type: "session.created",
+		Data: sess,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess)
+}
+
+// handleWebSocket handles WebSocket connections for real-time updates.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+
+	// Check if session exists
+	if _, ok := s.getSession(r, sessionID); !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	opts := &websocket.AcceptOptions{
+		OriginPatterns: []string{"*"},
+	}
+
+	conn, err := websocket.Accept(w, r, opts)
+	if err != nil {
+		return
+	}
+
+	client := NewClient(sessionID, conn, s.hub)
+	client.OnMessage = func(msg ClientMessage) {
+		s.handleClientMessage(sessionID, msg)
+	}
+	s.hub.Register(client)
+
+	// Start client read/write loops
+	go client.ReadLoop()
+	go client.WriteLoop()
+}
+
+// handleClientMessage handles a message received over a session WebSocket.
+func (s *Server) handleClientMessage(sessionID string, msg ClientMessage) {
+	sess, ok := s.sessionMgr.Get(sessionID)
+	if !ok {
+		return
+	}
+
+	switch msg.Type {
+	case "approval.decide":
+		var req struct {
+			ID       string `json:"id"`
+			Decision string `json:"decision"`
+		}
+		if err := json.Unmarshal(msg.Data, &req); err != nil {
+			return
+		}
+		if err := s.sessionMgr.DecideApproval(sess, req.ID, req.Decision); err != nil {
+			s.hub.Broadcast(sessionID, Event{
+				Type: "error",
+				Data: errorEvent(err),
+			})
+		}
+	}
+}
+
+// broadcastEvent is the bus sink feeding the WebSocket hub. It translates
+// bus events into the events the frontend understands.
+func (s *Server) broadcastEvent(ev events.Event) {
+	if ev.SessionID == "" {
+		return
+	}
+	switch ev.Source {
+	case events.SourceSession:
+		s.broadcastSessionEvent(ev)
+	case events.SourceAgent:
+		s.broadcastAgentEvent(ev)
+	case events.SourceExecutor:
+		s.broadcastExecutionEvent(ev)
+	}
+}
+
+// broadcastExecutionEvent forwards task lifecycle events as task.ready,
+// task.queued, task.started, task.unchanged, task.completed, task.failed and
+// task.progress.
+func (s *Server) broadcastExecutionEvent(ev events.Event) {
+	data := map[string]any{
+		"taskId": ev.TaskID,
+		"task":   ev.TaskID,
+		"agent":  ev.AgentID,
+	}
+	if sess, ok := s.sessionMgr.Get(ev.SessionID); ok {
+		if t, ok := sess.DAG.Get(ev.TaskID); ok {
+			data["task"] = t.Title
+		}
+	}
+
+	eventType := "task." + ev.Type
+	switch ev.Type {
+	case "failed":
+		data["error"] = ev.Data
+		if f, ok := ev.Data.(task.Failure); ok {
+			data["error"] = f.Error
+			data["failureKind"] = f.Kind
+			data["detail"] = f.Detail
+			data["remediation"] = f.Remediation
+		}
+	case "output":
+		eventType = "task.progress"
+		data["line"] = ev.Data
+	case "queued":
+		data["queue"] = ev.Data
+	case "unchanged":
+		data["warning"] = ev.Data
+	case "completed":
+		data["changes"] = ev.Data
+	}
+	s.hub.Broadcast(ev.SessionID, Event{Type: eventType, Data: data})
+}
+
+// broadcastSessionEvent forwards status changes and notices.
+func (s *Server) broadcastSessionEvent(ev events.Event) {
+	switch ev.Type {
+	case events.TypeStatusChanged:
+		s.hub.Broadcast(ev.SessionID, Event{
+			Type: "session.statusChanged",
+			Data: ev.Data,
+		})
+	case session.TypeVerified:
+		s.hub.Broadcast(ev.SessionID, Event{
+			Type: "session.verified",
+			Data: ev.Data,
+		})
+	case events.TypeNotice:
+		notice, _ := ev.Data.(session.Notice)
+		s.hub.Broadcast(ev.SessionID, Event{
+			Type: "session.warning",
+			Data: map[string]string{"warning": notice.Message},
+		})
+	}
+}
+
+// broadcastAgentEvent forwards the agent events shown in the UI.
+func (s *Server) broadcastAgentEvent(ev events.Event) {
+	data, _ := ev.Data.(json.RawMessage)
+	sessionID, taskID := ev.SessionID, ev.TaskID
+
+	var event Event
+	switch ev.Type {
+	case agent.EventAgentStalled:
+		event = Event{
+			Type: "agent.stalled",
+			Data: map[string]any{
+				"taskId":  taskID,
+				"agentId": ev.AgentID,
+				"stall":   data,
+			},
+		}
+	case agent.EventApprovalRequested:
+		if s.slack != nil {
+			var req agent.ApprovalRequest
+			if json.Unmarshal(data, &req) == nil {
+				posted := make(chan struct{})
+				s.slackPosts.Store(req.ID, posted)
+				go func() {
+					defer close(posted)
+					s.postSlackApproval(sessionID, taskID, &req)
+				}()
+			}
+		}
+		event = Event{
+			Type: "approval.requested",
+			Data: map[string]any{
+				"taskId":   taskID,
+				"approval": data,
+			},
+		}
+	case agent.EventApprovalResolved:
+		var res agent.ApprovalResolution
+		_ = json.Unmarshal(data, &res)
+		event = Event{
+			Type: "approval.resolved",
+			Data: map[string]any{
+				"taskId":     taskID,
+				"resolution": res,
+			},
+		}
+		if res.TimedOut {
+			event.Type = "approval.timeout"
+		}
+		if s.slack != nil {
+			go func() {
+				// A quick decision can land while the post is in flight;
+				// wait for it so there is a message to update
+				if posted, ok := s.slackPosts.LoadAndDelete(res.ID); ok {
+					<-posted.(chan struct{})
+				}
+				if err := s.slack.ResolveApproval(context.Background(), res.ID, res.Decision, res.TimedOut); err != nil {
+					log.Printf("Slack update for approval %s: %v", res.ID, err)
+				}
+			}()
+		}
+	case "item/agentMessage/delta":
+		var delta codexrpc.AgentMessageDelta
+		_ = json.Unmarshal(data, &delta)
+		event = Event{
+			Type: "task.output",
+			Data: taskOutput{TaskID: taskID, AgentID: ev.AgentID, ItemID: delta.ItemID, Delta: delta.Delta},
+		}
+	case agent.EventCommandOutput:
+		var cmd agent.CommandEvent
+		_ = json.Unmarshal(data, &cmd)
+		event = Event{
+			Type: "task.command.output",
+			Data: commandOutput{TaskID: taskID, AgentID: ev.AgentID, Command: cmd},
+		}
+	case agent.EventCommandStarted, agent.EventCommandCompleted:
+		event = Event{
+			Type: "task." + strings.ReplaceAll(ev.Type, "/", "."),
+			Data: map[string]any{
+				"taskId":  taskID,
+				"agentId": ev.AgentID,
+				"command": data,
+			},
+		}
+	default:
+		return
+	}
+	s.hub.Broadcast(sessionID, event)
+}
+
+// postSlackApproval posts a pending approval to Slack.
+func (s *Server) postSlackApproval(sessionID, taskID string, req *agent.ApprovalRequest) {
+	err := s.slack.PostApproval(context.Background(), slack.Approval{
+		SessionID: sessionID,
+		TaskID:    taskID,
+		ID:        req.ID,
+		Method:    req.Method,
+		Params:    req.Params,
+	})
+	if err != nil {
+		log.Printf("Slack post for approval %s: %v", req.ID, err)
+	}
+}
+
+// errorStatus maps a session error to an HTTP status code.
+func errorStatus(err error) int {
+	if errors.Is(err, session.ErrInvalidTransition) || errors.Is(err, session.ErrDirtyRepo) ||
+		errors.Is(err, session.ErrRepoLocked) || errors.Is(err, session.ErrNoMergeToRollback) ||
+		errors.Is(err, session.ErrNoTargetBranch) || errors.Is(err, session.ErrMergeConflict) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, session.ErrConfirmationRequired) {
+		return http.StatusPreconditionRequired
+	}
+	if errors.Is(err, session.ErrNoPullRequests) {
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// Start starts the HTTP server.
+func (s *Server) Start(addr string) error {
+	tlsCfg, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   s.handler(),
+		TLSConfig: tlsCfg,
+	}
+
+	// Shutdown handler
+	go func() {
+		<-s.shutdownCh
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	if tlsCfg != nil {
+		return server.ListenAndServeTLS("", "")
+	}
 	return server.ListenAndServe()
 }
 
 // Shutdown stops the server gracefully.
 func (s *Server) Shutdown() {
 	s.shutdownOnce.Do(func() {
+		s.sessionMgr.Shutdown()
 		close(s.shutdownCh)
 	})
 }
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/session"
+
+	"github.com/go-chi/chi/v5"
+	"nhooyr.io/websocket"
+)
+
+// defaultShareTTL is the lifetime of a share token when none is requested.
+const defaultShareTTL = 24 * time.Hour
+
+// maxShareTTL caps how long a share token may stay valid.
+const maxShareTTL = 30 * 24 * time.Hour
+
+// errInvalidShareToken is returned for malformed, forged or expired tokens.
+var errInvalidShareToken = errors.New("invalid or expired share token")
+
+// shareSigner issues and verifies read-only share tokens. A token is
+// "<sessionID>.<expiry>.<signature>", base64url encoded, signed with HMAC-SHA256.
+type shareSigner struct {
+	key []byte
+}
+
+// newShareSigner creates a signer. An empty secret generates a random key,
+// which invalidates outstanding tokens on restart.
+func newShareSigner(secret string) *shareSigner {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &shareSigner{key: key}
+}
+
+// Issue creates a token granting read access to sessionID until expiresAt.
+func (s *shareSigner) Issue(sessionID string, expiresAt time.Time) string {
+	payload := sessionID + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + s.sign(payload)
+}
+
+// Verify returns the session ID a valid token grants access to.
+func (s *shareSigner) Verify(token string) (string, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", errInvalidShareToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errInvalidShareToken
+	}
+	payload := string(raw)
+	if !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+		return "", errInvalidShareToken
+	}
+
+	i := strings.LastIndex(payload, ".")
+	if i < 0 {
+		return "", errInvalidShareToken
+	}
+	exp, err := strconv.ParseInt(payload[i+1:], 10, 64)
+	if err != nil || time.Now().After(time.Unix(exp, 0)) {
+		return "", errInvalidShareToken
+	}
+	return payload[:i], nil
+}
+
+// sign returns the base64url HMAC of payload.
+func (s *shareSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SetShareSecret sets the key used to sign share tokens, so tokens survive
+// restarts and can be verified by every replica.
+func (s *Server) SetShareSecret(secret string) {
+	s.shares = newShareSigner(secret)
+}
+
+// handleCreateShare issues a read-only share token for a session.
+func (s *Server) handleCreateShare(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := s.getSession(r, id); !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return
+	}
+
+	// The TTL is optional, so an empty body is allowed
+	var req struct {
+		TTL string `json:"ttl,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			badRequestBody(w, err)
+			return
+		}
+	}
+
+	ttl := defaultShareTTL
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 || d > maxShareTTL {
+			httpError(w, "Invalid ttl", http.StatusBadRequest)
+			return
+		}
+		ttl = d
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	token := s.shares.Issue(id, expiresAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"token":     token,
+		"expiresAt": expiresAt.Format(time.RFC3339),
+		"url":       s.externalURL(r, apiPrefix+"/shared/"+token),
+		"wsUrl":     s.externalURL(r, wsPrefix+"/shared/"+token),
+	})
+}
+
+// sharedSession resolves the session granted by the {token} URL parameter,
+// writing an error response if the token is not valid.
+func (s *Server) sharedSession(w http.ResponseWriter, r *http.Request) (*session.Session, bool) {
+	id, err := s.shares.Verify(chi.URLParam(r, "token"))
+	if err != nil {
+		httpError(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+	sess, ok := s.sessionMgr.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeSessionNotFound, "Session not found")
+		return nil, false
+	}
+	return sess, true
+}
+
+// handleSharedSession returns a shared session's status.
+func (s *Server) handleSharedSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess)
+}
+
+// handleSharedTasks returns a shared session's tasks.
+func (s *Server) handleSharedTasks(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess.TaskViews())
+}
+
+// handleSharedTranscript returns the agent output of a shared session's task.
+func (s *Server) handleSharedTranscript(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+
+	taskID := chi.URLParam(r, "taskId")
+	output, ok := sess.Transcript(taskID)
+	if !ok {
+		httpError(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"taskId": taskID, "output": output})
+}
+
+// handleSharedWebSocket streams a shared session's live events. Messages
+// from the client are ignored, so viewers cannot act on the session.
+func (s *Server) handleSharedWebSocket(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.sharedSession(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns: []string{"*"},
+	})
+	if err != nil {
+		return
+	}
+
+	client := NewClient(sess.ID, conn, s.hub)
+	s.hub.Register(client)
+
+	go client.ReadLoop()
+	go client.WriteLoop()
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/session"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/tenant"
+)
+
+// tenantKey is the request context key holding the authenticated tenant.
+type tenantKey struct{}
+
+// SetTenants enables multi-tenant mode: every API request must carry a
+// tenant API key and only sees that tenant's repos and sessions.
+func (s *Server) SetTenants(reg *tenant.Registry) {
+	s.tenants = reg
+	s.sessionMgr.SetLimiterFactory(func(tenantID string) task.Limiter {
+		if l := reg.Limiter(tenantID); l != nil {
+			return l
+		}
+		return nil
+	})
+	s.sessionMgr.Bus().Subscribe("tenant-usage", events.SinkFunc(s.recordTokenUsage))
+}
+
+// recordTokenUsage charges reported agent token usage to the tenant owning
+// the session, or the ad-hoc agent.
+func (s *Server) recordTokenUsage(ev events.Event) {
+	if ev.Source != events.SourceAgent || ev.Type != agent.EventTokensUsed {
+		return
+	}
+	tenantID := ""
+	if sess, ok := s.sessionMgr.Get(ev.SessionID); ok {
+		tenantID = sess.TenantID
+	} else if a, err := s.sessionMgr.Agent(ev.AgentID); err == nil {
+		tenantID = a.TenantID
+	}
+	if tenantID == "" {
+		return
+	}
+	var usage agent.TokenUsageEvent
+	if data, ok := ev.Data.(json.RawMessage); ok && json.Unmarshal(data, &usage) == nil {
+		s.tenants.AddTokens(tenantID, usage.Tokens)
+	}
+}
+
+// tenantMiddleware authenticates API and WebSocket requests by API key,
+// taken from "Authorization: Bearer", X-API-Key or, for browser
+// WebSockets, the apiKey query parameter. Share links, Slack callbacks and
+// static assets are exempt.
+func (s *Server) tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if s.tenants == nil || r.Method == http.MethodOptions ||
+			!(strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/ws/")) ||
+			strings.HasPrefix(path, apiPrefix+"/shared/") || strings.HasPrefix(path, wsPrefix+"/shared/") ||
+			strings.HasPrefix(path, apiPrefix+"/slack/") || strings.HasPrefix(path, apiPrefix+"/hooks/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		t, ok := s.tenants.Authenticate(requestAPIKey(r))
+		if !ok {
+			httpError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, t)))
+	})
+}
+
+// requestAPIKey returns the API key sent with r, if any.
+func requestAPIKey(r *http.Request) string {
+	key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if key == "" {
+		key = r.Header.Get("X-API-Key")
+	}
+	if key == "" {
+		key = r.URL.Query().Get("apiKey")
+	}
+	return key
+}
+
+// tenantFrom returns the request's tenant, or nil in single-tenant mode.
+func tenantFrom(r *http.Request) *tenant.Tenant {
+	return contextTenant(r.Context())
+}
+
+// contextTenant returns the tenant an API or gRPC call was authenticated
+// as, or nil in single-tenant mode.
+func contextTenant(ctx context.Context) *tenant.Tenant {
+	t, _ := ctx.Value(tenantKey{}).(*tenant.Tenant)
+	return t
+}
+
+// getSession looks up a session visible to the request's tenant. Sessions
+// of other tenants are reported as not found.
+func (s *Server) getSession(r *http.Request, id string) (*session.Session, bool) {
+	return s.tenantSession(r.Context(), id)
+}
+
+// tenantSession looks up a session visible to the tenant of ctx.
+func (s *Server) tenantSession(ctx context.Context, id string) (*session.Session, bool) {
+	sess, ok := s.sessionMgr.Get(id)
+	if !ok {
+		return nil, false
+	}
+	if t := contextTenant(ctx); t != nil && sess.TenantID != t.ID {
+		return nil, false
+	}
+	return sess, true
+}
+
+// repoAllowed reports whether the request's tenant may use the repo.
+func repoAllowed(r *http.Request, repoPath string) bool {
+	return tenantAllowsRepo(r.Context(), repoPath)
+}
+
+// tenantAllowsRepo reports whether the tenant of ctx may use the repo.
+func tenantAllowsRepo(ctx context.Context, repoPath string) bool {
+	t := contextTenant(ctx)
+	return t == nil || t.AllowsRepo(repoPath)
+}
+
+// assignTenant records the request's tenant as the session owner.
+func assignTenant(r *http.Request, sess *session.Session) {
+	if t := tenantFrom(r); t != nil {
+		sess.SetTenant(t.ID)
+	}
+}
+
+// checkBudget writes 403 and returns false when the request's tenant has
+// exhausted its token budget.
+func (s *Server) checkBudget(w http.ResponseWriter, r *http.Request) bool {
+	if err := s.tenantBudget(r.Context()); err != nil {
+		httpError(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// tenantBudget returns why the tenant of ctx may not start more work, or
+// nil.
+func (s *Server) tenantBudget(ctx context.Context) error {
+	t := contextTenant(ctx)
+	if t == nil {
+		return nil
+	}
+	return s.tenants.CheckBudget(t.ID)
+}
+
+// handleGetTenant returns the calling tenant's repos, quotas and usage.
+func (s *Server) handleGetTenant(w http.ResponseWriter, r *http.Request) {
+	t := tenantFrom(r)
+	if t == nil {
+		httpError(w, "Tenants not configured", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":                  t.ID,
+		"name":                t.Name,
+		"repos":               t.Repos,
+		"maxConcurrentAgents": t.MaxConcurrentAgents,
+		"tokenBudget":         t.TokenBudget,
+		"tokensUsed":          s.tenants.TokensUsed(t.ID),
+	})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/codexrpc"
+	"codex-agent-team/internal/session"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// threadInfo is a codex thread and whether a running agent uses it.
+type threadInfo struct {
+	codexrpc.Thread
+	Active bool `json:"active"`
+}
+
+// threadsAllowed writes 403 and returns false for tenants: threads are
+// shared by everyone using the codex binary.
+func threadsAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if tenantFrom(r) != nil {
+		httpError(w, "Thread management is not available to tenants", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// threadErrorStatus maps thread management errors to HTTP statuses.
+func threadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, session.ErrUnknownRuntime):
+		return http.StatusBadRequest
+	case errors.Is(err, agent.ErrThreadActive):
+		return http.StatusConflict
+	}
+	return http.StatusBadGateway
+}
+
+// handleListThreads returns one page of the codex threads. ?codex= names a
+// registered binary; ?cursor= and ?limit= page through the list.
+func (s *Server) handleListThreads(w http.ResponseWriter, r *http.Request) {
+	if !threadsAllowed(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			httpError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	resp, err := s.sessionMgr.Threads(r.Context(), q.Get("codex"), q.Get("cursor"), limit)
+	if err != nil {
+		httpError(w, err.Error(), threadErrorStatus(err))
+		return
+	}
+
+	active := s.sessionMgr.ActiveThreads()
+	threads := make([]threadInfo, len(resp.Data))
+	for i, t := range resp.Data {
+		threads[i] = threadInfo{Thread: t, Active: active[t.ID]}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data":       threads,
+		"nextCursor": resp.NextCursor,
+	})
+}
+
+// handleArchiveThread archives one codex thread.
+func (s *Server) handleArchiveThread(w http.ResponseWriter, r *http.Request) {
+	if !threadsAllowed(w, r) {
+		return
+	}
+	id := chi.URLParam(r, "id")
+
+	if _, err := s.sessionMgr.ArchiveThreads(r.Context(), r.URL.Query().Get("codex"), []string{id}); err != nil {
+		httpError(w, err.Error(), threadErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "archived"})
+}
+
+// handleArchiveIdleThreads archives the codex threads idle for longer than
+// olderThan, such as those left behind by finished task agents.
+func (s *Server) handleArchiveIdleThreads(w http.ResponseWriter, r *http.Request) {
+	if !threadsAllowed(w, r) {
+		return
+	}
+	var req struct {
+		Codex     string `json:"codex,omitempty"`
+		OlderThan string `json:"olderThan"` // duration, e.g. "24h"
+		DryRun    bool   `json:"dryRun,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	idle, err := time.ParseDuration(req.OlderThan)
+	if err != nil || idle <= 0 {
+		httpError(w, "olderThan must be a positive duration such as \"24h\"", http.StatusBadRequest)
+		return
+	}
+
+	ids, err := s.sessionMgr.ArchiveIdleThreads(r.Context(), req.Codex, idle, req.DryRun)
+	if err != nil {
+		httpError(w, err.Error(), threadErrorStatus(err))
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"threads": ids,
+		"dryRun":  req.DryRun,
+	})
+}
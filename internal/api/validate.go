@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"codex-agent-team/internal/session"
+)
+
+// maxParallelLimit bounds the parallelism a request may ask for.
+const maxParallelLimit = 256
+
+// FieldError is a problem with one field of a request. Field is the JSON
+// path of the field, e.g. "tasks[1].dependsOn[0]".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationDetails are the details of a VALIDATION_FAILED error.
+type ValidationDetails struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// validation collects the field errors of a request, so a client learns
+// of every problem at once rather than of the first, or of an opaque
+// failure once the request is underway.
+type validation struct {
+	fields []FieldError
+}
+
+func (v *validation) addf(field, format string, args ...any) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// required checks that value is not blank.
+func (v *validation) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.addf(field, "is required")
+		return false
+	}
+	return true
+}
+
+// name checks that value is a valid template, schedule or watch name.
+func (v *validation) name(field, value string) {
+	if v.required(field, value) && !session.ValidName(value) {
+		v.addf(field, "may only contain letters, digits, '-' and '_'")
+	}
+}
+
+// repo checks that path, an absolute path, is a git repository.
+func (v *validation) repo(ctx context.Context, field, path string) {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		v.addf(field, "%s does not exist", path)
+		return
+	case err != nil:
+		v.addf(field, "%v", err)
+		return
+	case !info.IsDir():
+		v.addf(field, "%s is not a directory", path)
+		return
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-dir")
+	cmd.Dir = path
+	if err := cmd.Run(); err != nil {
+		v.addf(field, "%s is not a git repository", path)
+	}
+}
+
+// branch checks that name is a valid branch name.
+func (v *validation) branch(field, name string) {
+	if err := checkBranchName(name); err != nil {
+		v.addf(field, "%v", err)
+	}
+}
+
+// maxParallel checks a parallelism, where 0 means no limit.
+func (v *validation) maxParallel(field string, n int) {
+	if n < 0 || n > maxParallelLimit {
+		v.addf(field, "must be between 0 and %d", maxParallelLimit)
+	}
+}
+
+// tasks checks a plan: IDs are unique and usable in branch names, and
+// dependencies name other tasks of the plan without forming a cycle.
+func (v *validation) tasks(field string, tasks []session.TemplateTask) {
+	if len(tasks) == 0 {
+		v.addf(field, "must not be empty")
+		return
+	}
+	index := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		f := fmt.Sprintf("%s[%d]", field, i)
+		if !v.required(f+".id", t.ID) {
+			continue
+		}
+		if j, dup := index[t.ID]; dup {
+			v.addf(f+".id", "duplicates the ID of %s[%d]", field, j)
+			continue
+		}
+		index[t.ID] = i
+		if err := checkBranchName("task-" + t.ID); err != nil {
+			v.addf(f+".id", "cannot be used in a branch name: %v", err)
+		}
+		if strings.TrimSpace(t.Title) == "" && strings.TrimSpace(t.Description) == "" {
+			v.addf(f+".description", "is required when there is no title")
+		}
+	}
+
+	resolvable := true
+	for i, t := range tasks {
+		for j, dep := range t.DependsOn {
+			f := fmt.Sprintf("%s[%d].dependsOn[%d]", field, i, j)
+			if _, ok := index[dep]; !ok {
+				v.addf(f, "unknown task %q", dep)
+				resolvable = false
+			} else if dep == t.ID {
+				v.addf(f, "a task cannot depend on itself")
+				resolvable = false
+			}
+		}
+	}
+	if resolvable {
+		if cycle := dependencyCycle(tasks); cycle != nil {
+			v.addf(field, "dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+}
+
+// failed writes the collected errors as a 422 response and reports
+// whether there were any.
+func (v *validation) failed(w http.ResponseWriter) bool {
+	if len(v.fields) == 0 {
+		return false
+	}
+	writeError(w, http.StatusUnprocessableEntity, CodeValidationFailed, v.message(), ValidationDetails{Fields: v.fields})
+	return true
+}
+
+// message summarizes the field errors, naming the first.
+func (v *validation) message() string {
+	msg := fmt.Sprintf("%s: %s", v.fields[0].Field, v.fields[0].Message)
+	if n := len(v.fields) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+// dependencyCycle returns the IDs along a dependency cycle of tasks, the
+// first repeated at the end, or nil. Every dependency must resolve.
+func dependencyCycle(tasks []session.TemplateTask) []string {
+	deps := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		deps[t.ID] = t.DependsOn
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(tasks))
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case visiting:
+			for i, p := range path {
+				if p == id {
+					return append(append([]string{}, path[i:]...), id)
+				}
+			}
+		case done:
+			return nil
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range deps[id] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+	for _, t := range tasks {
+		if cycle := visit(t.ID); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// checkBranchName applies the rules of git check-ref-format --branch.
+func checkBranchName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("is empty")
+	case name == "@":
+		return fmt.Errorf("cannot be %q", name)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("cannot start with '-'")
+	case strings.HasSuffix(name, "/") || strings.HasSuffix(name, "."):
+		return fmt.Errorf("cannot end with %q", name[len(name)-1:])
+	case strings.Contains(name, ".."), strings.Contains(name, "@{"), strings.Contains(name, "//"):
+		return fmt.Errorf("cannot contain \"..\", \"@{\" or \"//\"")
+	}
+	for _, c := range name {
+		if c < 0x20 || c == 0x7f || strings.ContainsRune(" ~^:?*[\\", c) {
+			return fmt.Errorf("cannot contain %q", c)
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("components cannot start with '.' or end with \".lock\"")
+		}
+	}
+	return nil
+}
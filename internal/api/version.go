@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// APIVersion is the current version of the REST and WebSocket API. Routes
+// are served under /api/<version> and /ws/<version>.
+const APIVersion = "v1"
+
+// apiVersions lists the versions the server serves, oldest first.
+var apiVersions = []string{APIVersion}
+
+const (
+	apiPrefix = "/api/" + APIVersion
+	wsPrefix  = "/ws/" + APIVersion
+)
+
+// versionHeader names the version of a response and, on an unversioned
+// request, the version the client wants.
+const versionHeader = "API-Version"
+
+// versionPattern matches the version segment of a versioned path.
+var versionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// versionMiddleware keeps the unversioned /api and /ws paths working as
+// shims of a versioned API: such requests are served by the version named
+// in the API-Version header, the current one by default, and their
+// responses are marked deprecated with a link to the versioned path.
+// Unknown versions are rejected.
+func (s *Server) versionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		root, rest, ok := splitVersionedPath(r.URL.Path)
+		if !ok || r.URL.Path == "/api/versions" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		segment, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+		version := segment
+		if !versionPattern.MatchString(segment) {
+			// Unversioned path
+			version = r.Header.Get(versionHeader)
+			if version == "" {
+				version = APIVersion
+			}
+			if !slices.Contains(apiVersions, version) {
+				unsupportedVersion(w, http.StatusBadRequest, version)
+				return
+			}
+			path := root + "/" + version + rest
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", "<"+s.basePath+path+`>; rel="successor-version"`)
+			r.URL.Path = path
+			r.URL.RawPath = ""
+		} else if !slices.Contains(apiVersions, version) {
+			unsupportedVersion(w, http.StatusNotFound, version)
+			return
+		}
+		w.Header().Set(versionHeader, version)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// splitVersionedPath splits an API or WebSocket path into its root, "/api"
+// or "/ws", and the rest.
+func splitVersionedPath(path string) (root, rest string, ok bool) {
+	for _, root := range []string{"/api", "/ws"} {
+		if rest, ok := strings.CutPrefix(path, root); ok && strings.HasPrefix(rest, "/") {
+			return root, rest, true
+		}
+	}
+	return "", "", false
+}
+
+// VersionsDetails are the details of an UNSUPPORTED_API_VERSION error.
+type VersionsDetails struct {
+	Supported []string `json:"supported"`
+}
+
+func unsupportedVersion(w http.ResponseWriter, status int, version string) {
+	writeError(w, status, CodeUnsupportedVersion, "Unsupported API version "+version,
+		VersionsDetails{Supported: apiVersions})
+}
+
+// handleVersions lists the API versions the server serves.
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"current":   APIVersion,
+		"supported": apiVersions,
+		// Unversioned paths are shims of the current version
+		"deprecated": []string{"unversioned"},
+	})
+}
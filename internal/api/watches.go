@@ -0,0 +1,265 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/session"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SetWatchSecret enables /api/hooks/push for push webhooks signed with
+// secret (GitHub's X-Hub-Signature-256) or carrying it as a token
+// (GitLab's X-Gitlab-Token).
+func (s *Server) SetWatchSecret(secret string) {
+	s.watchSecret = secret
+}
+
+// StartWatcher polls the repositories of the stored watches for new
+// commits every interval.
+func (s *Server) StartWatcher(interval time.Duration) {
+	s.sessionMgr.StartWatcher(interval)
+}
+
+// handleSaveWatch creates or replaces a watch.
+func (s *Server) handleSaveWatch(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Watches()
+	if store == nil {
+		httpError(w, "Watch store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var watch session.Watch
+	if err := json.NewDecoder(r.Body).Decode(&watch); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	if watch.RepoPath == "" {
+		watch.RepoPath = s.defaultRepo
+	}
+	absPath, err := filepath.Abs(watch.RepoPath)
+	if err != nil {
+		httpError(w, "Invalid repo path", http.StatusBadRequest)
+		return
+	}
+	watch.RepoPath = absPath
+	// Runs are recorded by the watcher, not the client
+	watch.LastRun = nil
+	if prev, err := store.Get(watch.Name); err == nil {
+		watch.CreatedAt, watch.LastRun = prev.CreatedAt, prev.LastRun
+	}
+
+	var v validation
+	v.name("name", watch.Name)
+	v.repo(r.Context(), "repoPath", watch.RepoPath)
+	if watch.Branch != "" {
+		v.branch("branch", watch.Branch)
+	}
+	if v.failed(w) {
+		return
+	}
+	if err := watch.Normalize(); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if watch.Template != "" {
+		templates := s.sessionMgr.Templates()
+		if templates == nil {
+			httpError(w, "Template store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := templates.Get(watch.Template); err != nil {
+			httpError(w, "Template not found", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := store.Save(&watch); err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watch)
+}
+
+// handleListWatches returns all watches.
+func (s *Server) handleListWatches(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Watches()
+	if store == nil {
+		httpError(w, "Watch store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	watches, err := store.List()
+	if err != nil {
+		httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if watches == nil {
+		watches = []*session.Watch{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watches)
+}
+
+// handleGetWatch returns one watch.
+func (s *Server) handleGetWatch(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Watches()
+	if store == nil {
+		httpError(w, "Watch store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	watch, err := store.Get(chi.URLParam(r, "name"))
+	if err != nil {
+		httpError(w, "Watch not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watch)
+}
+
+// handleDeleteWatch removes a watch. A run in progress continues.
+func (s *Server) handleDeleteWatch(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+	store := s.sessionMgr.Watches()
+	if store == nil {
+		httpError(w, "Watch store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := store.Delete(chi.URLParam(r, "name")); err != nil {
+		httpError(w, "Watch not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// handleRunWatch starts a run of a watch now.
+func (s *Server) handleRunWatch(w http.ResponseWriter, r *http.Request) {
+	if !schedulesAllowed(w, r) {
+		return
+	}
+
+	run, err := s.sessionMgr.RunWatch(chi.URLParam(r, "name"))
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, session.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		httpError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run)
+}
+
+// pushPayload is the part of a GitHub or GitLab push event watches need.
+type pushPayload struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		FullName string `json:"full_name"` // GitHub
+	} `json:"repository"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"` // GitLab
+	} `json:"project"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// handlePushHook triggers the watches matching the files changed by a
+// push. Other events are acknowledged and ignored.
+func (s *Server) handlePushHook(w http.ResponseWriter, r *http.Request) {
+	if s.watchSecret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	if !s.verifyPushHook(r, body) {
+		httpError(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	if event := r.Header.Get("X-GitHub-Event"); event != "" && event != "push" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if event := r.Header.Get("X-Gitlab-Event"); event != "" && event != "Push Hook" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	var payload pushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		badRequestBody(w, err)
+		return
+	}
+	push := session.Push{
+		Repository: payload.Repository.FullName,
+		Branch:     strings.TrimPrefix(payload.Ref, "refs/heads/"),
+		Commit:     payload.After,
+	}
+	if push.Repository == "" {
+		push.Repository = payload.Project.PathWithNamespace
+	}
+	seen := make(map[string]bool)
+	for _, c := range payload.Commits {
+		for _, files := range [][]string{c.Added, c.Modified, c.Removed} {
+			for _, f := range files {
+				if !seen[f] {
+					seen[f] = true
+					push.Files = append(push.Files, f)
+				}
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(s.sessionMgr.TriggerWatches(push))
+}
+
+// verifyPushHook checks the webhook's signature or token.
+func (s *Server) verifyPushHook(r *http.Request, body []byte) bool {
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		return hmac.Equal([]byte(token), []byte(s.watchSecret))
+	}
+	mac := hmac.New(sha256.New, []byte(s.watchSecret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Hub-Signature-256")))
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"time"
+
+	"codex-agent-team/internal/agent"
 
 	"nhooyr.io/websocket"
 )
@@ -15,13 +18,32 @@ type Event struct {
 	Data any         `json:"data"`
 }
 
+// ClientMessage is a message sent by a WebSocket client.
+type ClientMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// clientQueueSize bounds the events buffered for a client that is not
+// keeping up. Beyond it output chunks, then the oldest events, are dropped.
+const clientQueueSize = 256
+
+// writeTimeout disconnects a client whose socket stops accepting writes.
+const writeTimeout = 10 * time.Second
+
 // Client represents a WebSocket client connection.
 type Client struct {
 	SessionID string
 	Conn      *websocket.Conn
-	Send      chan Event
+	OnMessage func(ClientMessage) // handles client messages, may be nil
 	hub       *Hub
 	ctx       context.Context
+
+	mu      sync.Mutex
+	queue   []Event       // events waiting for WriteLoop
+	dropped int           // events dropped since the last write
+	closed  bool          // set once the client is unregistered
+	wake    chan struct{} // signals WriteLoop that queue or closed changed
 }
 
 // NewClient creates a new WebSocket client.
@@ -29,9 +51,58 @@ func NewClient(sessionID string, conn *websocket.Conn, hub *Hub) *Client {
 	return &Client{
 		SessionID: sessionID,
 		Conn:      conn,
-		Send:      make(chan Event, 256),
 		hub:       hub,
 		ctx:       context.Background(),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// enqueue queues an event without blocking. Output chunks are merged into
+// a queued chunk of the same stream, and a full queue drops a queued
+// output chunk, or failing that the oldest event, to make room.
+func (c *Client) enqueue(event Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+
+	if n := len(c.queue); n > 0 {
+		if merged, ok := coalesce(c.queue[n-1], event); ok {
+			c.queue[n-1] = merged
+			c.signal()
+			return
+		}
+	}
+	if len(c.queue) >= clientQueueSize {
+		victim := 0
+		for i, queued := range c.queue {
+			if _, ok := queued.Data.(streamChunk); ok {
+				victim = i
+				break
+			}
+		}
+		c.queue = append(c.queue[:victim], c.queue[victim+1:]...)
+		c.dropped++
+	}
+	c.queue = append(c.queue, event)
+	c.signal()
+}
+
+// close stops the client's WriteLoop once queued events are written.
+// It is safe to call more than once.
+func (c *Client) close() {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.signal()
+}
+
+// signal wakes WriteLoop without blocking.
+func (c *Client) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
 	}
 }
 
@@ -43,33 +114,132 @@ func (c *Client) ReadLoop() {
 	}()
 
 	for {
-		_, _, err := c.Conn.Read(c.ctx)
+		_, data, err := c.Conn.Read(c.ctx)
 		if err != nil {
 			break
 		}
-		// We don't expect client messages, just keep the connection alive
+		if c.OnMessage == nil {
+			continue
+		}
+		var msg ClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Invalid client message: %v", err)
+			continue
+		}
+		c.OnMessage(msg)
 	}
 }
 
-// WriteLoop writes events to the WebSocket connection.
+// WriteLoop writes events to the WebSocket connection. When events were
+// dropped it first sends a "stream.dropped" event so the client can
+// refetch state.
 func (c *Client) WriteLoop() {
 	defer c.Conn.Close(websocket.StatusNormalClosure, "")
 
-	for event := range c.Send {
-		data, err := json.Marshal(event)
-		if err != nil {
-			log.Printf("Failed to marshal event: %v", err)
-			continue
-		}
+	if err := c.drain(c.write); err != nil {
+		log.Printf("Failed to write to WebSocket: %v", err)
+	}
+}
 
-		err = c.Conn.Write(c.ctx, websocket.MessageText, data)
-		if err != nil {
-			log.Printf("Failed to write to WebSocket: %v", err)
-			break
+// drain passes queued events to write until the client is closed or a
+// write fails, prefixed by a "stream.dropped" event when events were
+// dropped. WriteLoop and gRPC event streams use it.
+func (c *Client) drain(write func(Event) error) error {
+	for range c.wake {
+		c.mu.Lock()
+		batch, dropped, closed := c.queue, c.dropped, c.closed
+		c.queue, c.dropped = nil, 0
+		c.mu.Unlock()
+
+		if dropped > 0 {
+			batch = append([]Event{{
+				Type: "stream.dropped",
+				Data: map[string]int{"dropped": dropped},
+			}}, batch...)
+		}
+		for _, event := range batch {
+			if err := write(event); err != nil {
+				return err
+			}
+		}
+		if closed {
+			return nil
 		}
 	}
+	return nil
 }
 
+// write sends one event, giving up after writeTimeout.
+func (c *Client) write(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal event: %v", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, writeTimeout)
+	defer cancel()
+	return c.Conn.Write(ctx, websocket.MessageText, data)
+}
+
+// streamChunk is implemented by the data of streamed output events, whose
+// consecutive chunks can be merged into one event.
+type streamChunk interface {
+	// merge appends next to the chunk if both belong to the same stream.
+	// Events are shared between clients, so a new value is returned.
+	merge(next any) (any, bool)
+}
+
+// commandOutput is the data of a "task.command.output" event.
+type commandOutput struct {
+	TaskID  string             `json:"taskId"`
+	AgentID string             `json:"agentId"`
+	Command agent.CommandEvent `json:"command"`
+}
+
+func (a commandOutput) merge(next any) (any, bool) {
+	b, ok := next.(commandOutput)
+	if !ok || a.TaskID != b.TaskID || a.Command.CommandID != b.Command.CommandID {
+		return nil, false
+	}
+	a.Command.Chunk += b.Command.Chunk
+	return a, true
+}
+
+// taskOutput is the data of a "task.output" event, a piece of the agent's
+// reply text.
+type taskOutput struct {
+	TaskID  string `json:"taskId"`
+	AgentID string `json:"agentId"`
+	ItemID  string `json:"itemId"`
+	Delta   string `json:"delta"`
+}
+
+func (a taskOutput) merge(next any) (any, bool) {
+	b, ok := next.(taskOutput)
+	if !ok || a.TaskID != b.TaskID || a.ItemID != b.ItemID {
+		return nil, false
+	}
+	a.Delta += b.Delta
+	return a, true
+}
+
+// coalesce merges next into prev when both are chunks of the same stream.
+func coalesce(prev, next Event) (Event, bool) {
+	chunk, ok := prev.Data.(streamChunk)
+	if !ok || prev.Type != next.Type {
+		return prev, false
+	}
+	merged, ok := chunk.merge(next.Data)
+	if !ok {
+		return prev, false
+	}
+	return Event{Type: prev.Type, Data: merged}, true
+}
+
+// batchInterval is how long stream chunks are collected before they are
+// sent to clients as one event per stream.
+const batchInterval = 50 * time.Millisecond
+
 // Hub manages WebSocket clients and broadcasts events.
 type Hub struct {
 	// Registered clients by session ID
@@ -84,6 +254,9 @@ type Hub struct {
 	// Broadcast events to a session
 	broadcast chan broadcastMsg
 
+	// Stream chunks collected per session until the next batch tick
+	pending map[string][]Event
+
 	mu sync.RWMutex
 }
 
@@ -99,11 +272,15 @@ func NewHub() *Hub {
 		register:  make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast: make(chan broadcastMsg, 256),
+		pending:   make(map[string][]Event),
 	}
 }
 
 // Run starts the hub's event loop.
 func (h *Hub) Run() {
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case client := <-h.register:
@@ -128,26 +305,58 @@ func (h *Hub) Run() {
 				}
 			}
 			h.mu.Unlock()
-			close(client.Send)
+			client.close()
 			log.Printf("Client unregistered for session: %s", client.SessionID)
 
 		case msg := <-h.broadcast:
-			h.mu.RLock()
-			clients := h.clients[msg.SessionID]
-			h.mu.RUnlock()
-
-			for _, client := range clients {
-				select {
-				case client.Send <- msg.Event:
-				default:
-					// Client channel is full, close it
-					h.Unregister(client)
-				}
+			if _, ok := msg.Event.Data.(streamChunk); ok {
+				h.batch(msg.SessionID, msg.Event)
+				continue
+			}
+			// Keep chunks ahead of whatever the stream produced next
+			h.flush(msg.SessionID)
+			h.send(msg.SessionID, msg.Event)
+
+		case <-ticker.C:
+			for sessionID := range h.pending {
+				h.flush(sessionID)
 			}
 		}
 	}
 }
 
+// batch merges a stream chunk into the session's pending chunks.
+func (h *Hub) batch(sessionID string, event Event) {
+	pending := h.pending[sessionID]
+	for i, prev := range pending {
+		if merged, ok := coalesce(prev, event); ok {
+			pending[i] = merged
+			return
+		}
+	}
+	h.pending[sessionID] = append(pending, event)
+}
+
+// flush sends a session's pending chunks.
+func (h *Hub) flush(sessionID string) {
+	pending := h.pending[sessionID]
+	delete(h.pending, sessionID)
+	for _, event := range pending {
+		h.send(sessionID, event)
+	}
+}
+
+// send queues an event for every client of a session.
+func (h *Hub) send(sessionID string, event Event) {
+	h.mu.RLock()
+	clients := h.clients[sessionID]
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.enqueue(event)
+	}
+}
+
 // Register adds a new client.
 func (h *Hub) Register(client *Client) {
 	h.register <- client
@@ -0,0 +1,184 @@
+// Package bench replays recorded sessions under different schedulers and
+// parallelism levels. Agents are simulated: each task takes the time it
+// took when it was recorded, so runs are instant and deterministic.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/task"
+)
+
+// defaultDuration is used for tasks without recorded timings or estimate
+// when no other task of the session has one either.
+const defaultDuration = 5 * time.Minute
+
+// Job is a task of a replayed session.
+type Job struct {
+	ID        string
+	DependsOn []string
+	Files     []string      // planned and changed files
+	Duration  time.Duration // recorded run time, or an estimate
+	Recorded  bool          // Duration was measured, not estimated
+}
+
+// Workload is a recorded session prepared for replay.
+type Workload struct {
+	SessionID string
+	UserTask  string
+	Jobs      []*Job        // sorted by ID
+	Recorded  time.Duration // first start to last completion of the recording, 0 if unknown
+
+	byID     map[string]*Job
+	critical map[string]time.Duration // longest path from a job to a sink, itself included
+}
+
+// recording is the part of a session file or -oneshot report read by Load.
+type recording struct {
+	ID        string      `json:"id"`
+	SessionID string      `json:"sessionId"`
+	UserTask  string      `json:"userTask"`
+	Tasks     []task.Task `json:"tasks"`
+}
+
+// Load reads a session file from the data directory's sessions/ or a
+// -oneshot report.
+func Load(path string) (*Workload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(rec.Tasks) == 0 {
+		return nil, fmt.Errorf("%s records no tasks", path)
+	}
+	id := rec.ID
+	if id == "" {
+		id = rec.SessionID
+	}
+	return NewWorkload(id, rec.UserTask, rec.Tasks, 0)
+}
+
+// NewWorkload prepares tasks for replay. Tasks that did not complete take
+// their planning estimate; without one they take fallback, or the mean
+// recorded duration when fallback is 0.
+func NewWorkload(sessionID, userTask string, tasks []task.Task, fallback time.Duration) (*Workload, error) {
+	w := &Workload{SessionID: sessionID, UserTask: userTask, byID: make(map[string]*Job)}
+
+	var first, last time.Time
+	var total time.Duration
+	var measured int
+	for _, t := range tasks {
+		job := &Job{ID: t.ID, DependsOn: t.DependsOn, Files: t.Files}
+		if t.Result != nil {
+			job.Files = union(job.Files, t.Result.FilesChanged)
+		}
+		if t.StartedAt != nil && t.CompletedAt != nil && t.Status == task.StatusCompleted {
+			job.Duration = t.CompletedAt.Sub(*t.StartedAt)
+			job.Recorded = true
+			total += job.Duration
+			measured++
+			if first.IsZero() || t.StartedAt.Before(first) {
+				first = *t.StartedAt
+			}
+			if t.CompletedAt.After(last) {
+				last = *t.CompletedAt
+			}
+		} else if est, ok := agent.ParseEstimate(t.EstimatedTime); ok {
+			job.Duration = est
+		}
+		w.Jobs = append(w.Jobs, job)
+		w.byID[job.ID] = job
+	}
+
+	switch {
+	case fallback > 0:
+	case measured > 0:
+		fallback = total / time.Duration(measured)
+	default:
+		fallback = defaultDuration
+	}
+	for _, job := range w.Jobs {
+		if job.Duration <= 0 {
+			job.Duration = fallback
+		}
+	}
+	if measured == len(w.Jobs) {
+		w.Recorded = last.Sub(first)
+	}
+	sort.Slice(w.Jobs, func(i, j int) bool { return w.Jobs[i].ID < w.Jobs[j].ID })
+
+	if err := w.computeCriticalPaths(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// computeCriticalPaths fills w.critical, failing on dependency cycles.
+func (w *Workload) computeCriticalPaths() error {
+	dependents := make(map[string][]*Job)
+	for _, job := range w.Jobs {
+		for _, dep := range job.DependsOn {
+			dependents[dep] = append(dependents[dep], job)
+		}
+	}
+
+	w.critical = make(map[string]time.Duration)
+	visiting := make(map[string]bool)
+	var visit func(job *Job) error
+	visit = func(job *Job) error {
+		if _, ok := w.critical[job.ID]; ok {
+			return nil
+		}
+		if visiting[job.ID] {
+			return fmt.Errorf("dependency cycle through task %s", job.ID)
+		}
+		visiting[job.ID] = true
+		var longest time.Duration
+		for _, next := range dependents[job.ID] {
+			if err := visit(next); err != nil {
+				return err
+			}
+			longest = max(longest, w.critical[next.ID])
+		}
+		w.critical[job.ID] = job.Duration + longest
+		return nil
+	}
+	for _, job := range w.Jobs {
+		if err := visit(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CriticalPath returns the longest chain of durations through the
+// workload's dependencies, the lower bound of any schedule.
+func (w *Workload) CriticalPath() time.Duration {
+	var longest time.Duration
+	for _, d := range w.critical {
+		longest = max(longest, d)
+	}
+	return longest
+}
+
+// union returns the distinct entries of a and b.
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var out []string
+	for _, s := range append(append([]string(nil), a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,195 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Scheduler orders the ready jobs of a simulation; the first ones start
+// when slots are free.
+type Scheduler interface {
+	Name() string
+	Order(w *Workload, ready, running []*Job)
+}
+
+// FIFO starts ready jobs by ID, like the executor does.
+type FIFO struct{}
+
+func (FIFO) Name() string { return "fifo" }
+
+func (FIFO) Order(w *Workload, ready, running []*Job) {
+	sort.SliceStable(ready, func(i, j int) bool { return ready[i].ID < ready[j].ID })
+}
+
+// CriticalPath starts the jobs with the longest remaining dependency chain
+// first.
+type CriticalPath struct{}
+
+func (CriticalPath) Name() string { return "critical-path" }
+
+func (CriticalPath) Order(w *Workload, ready, running []*Job) {
+	sort.SliceStable(ready, func(i, j int) bool {
+		if ci, cj := w.critical[ready[i].ID], w.critical[ready[j].ID]; ci != cj {
+			return ci > cj
+		}
+		return ready[i].ID < ready[j].ID
+	})
+}
+
+// FileOverlap is CriticalPath, but defers jobs that touch files of running
+// jobs while other jobs are ready.
+type FileOverlap struct{}
+
+func (FileOverlap) Name() string { return "file-overlap" }
+
+func (FileOverlap) Order(w *Workload, ready, running []*Job) {
+	CriticalPath{}.Order(w, ready, running)
+	sort.SliceStable(ready, func(i, j int) bool {
+		return !overlaps(ready[i], running) && overlaps(ready[j], running)
+	})
+}
+
+// Schedulers lists the built-in schedulers.
+var Schedulers = []Scheduler{FIFO{}, CriticalPath{}, FileOverlap{}}
+
+// SchedulerByName returns the built-in scheduler with the given name.
+func SchedulerByName(name string) (Scheduler, error) {
+	for _, s := range Schedulers {
+		if s.Name() == name {
+			return s, nil
+		}
+	}
+	var names []string
+	for _, s := range Schedulers {
+		names = append(names, s.Name())
+	}
+	return nil, fmt.Errorf("unknown scheduler %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+// Options tunes a simulation.
+type Options struct {
+	// ConflictPenalty lengthens a job started while a running job touches
+	// one of its files, as a fraction of its duration, to account for
+	// resolving the conflict.
+	ConflictPenalty float64
+}
+
+// Result is the outcome of one simulated run.
+type Result struct {
+	Scheduler   string        `json:"scheduler"`
+	Parallel    int           `json:"parallel"`
+	WallClock   time.Duration `json:"wallClock"`
+	Utilization float64       `json:"utilization"` // busy slot time over Parallel*WallClock
+	Conflicts   int           `json:"conflicts"`   // jobs started next to a job sharing files
+	Runs        []JobRun      `json:"runs"`        // in start order
+}
+
+// JobRun is when a job ran in a simulation, relative to its start.
+type JobRun struct {
+	JobID    string        `json:"jobId"`
+	Start    time.Duration `json:"start"`
+	End      time.Duration `json:"end"`
+	Conflict bool          `json:"conflict,omitempty"`
+}
+
+// Simulate replays w with at most parallel jobs running at once.
+func Simulate(w *Workload, s Scheduler, parallel int, opts Options) Result {
+	res := Result{Scheduler: s.Name(), Parallel: parallel}
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	done := make(map[string]bool)
+	started := make(map[string]bool)
+	ends := make(map[*Job]time.Duration)
+	var running []*Job
+	var now, busy time.Duration
+
+	for len(done) < len(w.Jobs) {
+		var ready []*Job
+		for _, job := range w.Jobs {
+			if !started[job.ID] && w.depsDone(job, done) {
+				ready = append(ready, job)
+			}
+		}
+		s.Order(w, ready, running)
+		for _, job := range ready {
+			if len(running) >= parallel {
+				break
+			}
+			d := job.Duration
+			conflict := overlaps(job, running)
+			if conflict {
+				res.Conflicts++
+				d += time.Duration(float64(d) * opts.ConflictPenalty)
+			}
+			res.Runs = append(res.Runs, JobRun{JobID: job.ID, Start: now, End: now + d, Conflict: conflict})
+			started[job.ID] = true
+			ends[job] = now + d
+			busy += d
+			running = append(running, job)
+		}
+
+		// Advance to the next completion
+		next := time.Duration(-1)
+		for _, job := range running {
+			if next < 0 || ends[job] < next {
+				next = ends[job]
+			}
+		}
+		now = next
+		kept := running[:0]
+		for _, job := range running {
+			if ends[job] == now {
+				done[job.ID] = true
+			} else {
+				kept = append(kept, job)
+			}
+		}
+		running = kept
+	}
+
+	res.WallClock = now
+	if now > 0 {
+		res.Utilization = float64(busy) / (float64(now) * float64(parallel))
+	}
+	return res
+}
+
+// Run simulates w under every scheduler at every parallelism level.
+func Run(w *Workload, schedulers []Scheduler, parallels []int, opts Options) []Result {
+	var results []Result
+	for _, p := range parallels {
+		for _, s := range schedulers {
+			results = append(results, Simulate(w, s, p, opts))
+		}
+	}
+	return results
+}
+
+// depsDone reports whether every dependency of job in w has completed.
+// Dependencies missing from the recording do not block.
+func (w *Workload) depsDone(job *Job, done map[string]bool) bool {
+	for _, dep := range job.DependsOn {
+		if _, ok := w.byID[dep]; ok && !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// overlaps reports whether job touches a file of a running job.
+func overlaps(job *Job, running []*Job) bool {
+	for _, other := range running {
+		for _, f := range job.Files {
+			for _, g := range other.Files {
+				if f == g {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
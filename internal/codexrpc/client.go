@@ -3,6 +3,7 @@ package codexrpc
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"io"
 	"sync"
 	"sync/atomic"
@@ -29,6 +30,13 @@ type Client struct {
 	notifyHandler  NotificationHandler
 	requestHandler ServerRequestHandler
 
+	callInterceptors   []CallInterceptor         // guarded by mu
+	notifyInterceptors []NotificationInterceptor // guarded by mu
+
+	limiter callLimiter // in-flight call limit, see SetMaxInFlight
+
+	tracer atomic.Pointer[FrameTracer] // see SetFrameTracer
+
 	done chan struct{}
 	err  error
 }
@@ -38,6 +46,13 @@ type rpcResult struct {
 	Error  *RPCError
 }
 
+// Errors of calls that end because the client stopped: errClientClosed
+// for calls still queued, errClosedResponse for calls sent but unanswered.
+var (
+	errClientClosed   = errors.New("client closed")
+	errClosedResponse = &RPCError{Code: -1, Message: "client closed"}
+)
+
 // NewClient creates a Client from existing reader/writer streams.
 func NewClient(stdin io.Writer, stdout io.Reader) *Client {
 	return &Client{
@@ -9,11 +9,18 @@ import (
 // Initialize performs the handshake with the app-server.
 // It sends the initialize request, then the initialized notification.
 func (c *Client) Initialize(ctx context.Context) (*InitializeResponse, error) {
+	return c.InitializeWithCapabilities(ctx, nil)
+}
+
+// InitializeWithCapabilities performs the handshake declaring caps, e.g.
+// opting into the experimental API for dynamic tools.
+func (c *Client) InitializeWithCapabilities(ctx context.Context, caps *InitializeCapabilities) (*InitializeResponse, error) {
 	params := InitializeParams{
 		ClientInfo: ClientInfo{
 			Name:    "codex-agent-team",
 			Version: "0.1.0",
 		},
+		Capabilities: caps,
 	}
 
 	raw, err := c.Call(ctx, "initialize", params)
@@ -47,6 +54,27 @@ func (c *Client) ThreadStart(ctx context.Context, params ThreadStartParams) (*Th
 	return &resp, nil
 }
 
+// ThreadList returns one page of stored threads.
+func (c *Client) ThreadList(ctx context.Context, params ThreadListParams) (*ThreadListResponse, error) {
+	raw, err := c.Call(ctx, "thread/list", params)
+	if err != nil {
+		return nil, fmt.Errorf("thread/list: %w", err)
+	}
+	var resp ThreadListResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal thread/list response: %w", err)
+	}
+	return &resp, nil
+}
+
+// ThreadArchive archives a thread.
+func (c *Client) ThreadArchive(ctx context.Context, params ThreadArchiveParams) error {
+	if _, err := c.Call(ctx, "thread/archive", params); err != nil {
+		return fmt.Errorf("thread/archive: %w", err)
+	}
+	return nil
+}
+
 // TurnStart sends user input and begins a new turn.
 func (c *Client) TurnStart(ctx context.Context, params TurnStartParams) (*TurnStartResponse, error) {
 	raw, err := c.Call(ctx, "turn/start", params)
@@ -65,3 +93,29 @@ func (c *Client) TurnInterrupt(ctx context.Context, params TurnInterruptParams)
 	_, err := c.Call(ctx, "turn/interrupt", params)
 	return err
 }
+
+// AccountRead returns the account the app-server is logged in with.
+func (c *Client) AccountRead(ctx context.Context, params AccountReadParams) (*AccountReadResponse, error) {
+	raw, err := c.Call(ctx, "account/read", params)
+	if err != nil {
+		return nil, fmt.Errorf("account/read: %w", err)
+	}
+	var resp AccountReadResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal account/read response: %w", err)
+	}
+	return &resp, nil
+}
+
+// ModelList returns one page of the models available to the account.
+func (c *Client) ModelList(ctx context.Context, params ModelListParams) (*ModelListResponse, error) {
+	raw, err := c.Call(ctx, "model/list", params)
+	if err != nil {
+		return nil, fmt.Errorf("model/list: %w", err)
+	}
+	var resp ModelListResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal model/list response: %w", err)
+	}
+	return &resp, nil
+}
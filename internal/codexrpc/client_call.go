@@ -6,8 +6,25 @@ import (
 	"fmt"
 )
 
-// Call sends a JSON-RPC request and waits for the response.
+// Call sends a JSON-RPC request and waits for the response. The call
+// passes through the interceptors added with AddCallInterceptor.
 func (c *Client) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return c.chainCall(c.invoke)(ctx, method, params)
+}
+
+// invoke sends a request and waits for its response, holding one of the
+// client's in-flight slots meanwhile.
+func (c *Client) invoke(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	if err := c.limiter.acquire(ctx, c.done); err != nil {
+		return nil, err
+	}
+	defer c.limiter.release()
+	return c.send(ctx, method, params)
+}
+
+// send writes a request and waits for its response. An error response is
+// returned as an *RPCError.
+func (c *Client) send(ctx context.Context, method string, params any) (json.RawMessage, error) {
 	id := c.nextID.Add(1)
 	idJSON, _ := json.Marshal(id)
 
@@ -49,7 +66,7 @@ func (c *Client) Call(ctx context.Context, method string, params any) (json.RawM
 		return nil, ctx.Err()
 	case result := <-ch:
 		if result.Error != nil {
-			return nil, fmt.Errorf("RPC error %d: %s", result.Error.Code, result.Error.Message)
+			return nil, result.Error
 		}
 		return result.Result, nil
 	case <-c.done:
@@ -82,6 +99,7 @@ func (c *Client) writeMessage(msg any) error {
 	if err != nil {
 		return err
 	}
+	c.trace(FrameSent, data)
 	data = append(data, '\n')
 
 	c.writeMu.Lock()
@@ -36,7 +36,7 @@ func (c *Client) readLoop() {
 			c.err = err
 			// Drain all pending calls with error
 			c.mu.Lock()
-			errResult := &rpcResult{Error: &RPCError{Code: -1, Message: "client closed"}}
+			errResult := &rpcResult{Error: errClosedResponse}
 			for id, ch := range c.pendingCalls {
 				// Non-blocking send to avoid deadlock
 				select {
@@ -51,6 +51,7 @@ func (c *Client) readLoop() {
 		if len(line) <= 1 {
 			continue // skip empty lines
 		}
+		c.trace(FrameReceived, line[:len(line)-1])
 		c.dispatch(line)
 	}
 }
@@ -98,13 +99,11 @@ func (c *Client) dispatch(line []byte) {
 		if json.Unmarshal(line, &notif) != nil {
 			return
 		}
-		if c.notifyHandler != nil {
-			var params json.RawMessage
-			if notif.Params != nil {
-				params = *notif.Params
-			}
-			c.notifyHandler(notif.Method, params)
+		var params json.RawMessage
+		if notif.Params != nil {
+			params = *notif.Params
 		}
+		c.notify(notif.Method, params)
 	}
 }
 
@@ -0,0 +1,69 @@
+package codexrpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CallInvoker sends a request and waits for its response.
+type CallInvoker func(ctx context.Context, method string, params any) (json.RawMessage, error)
+
+// CallInterceptor wraps every Call, like a gRPC unary interceptor. It runs
+// before the request is sent and sees the response or error afterwards;
+// it calls invoker to continue, possibly more than once to retry, or not
+// at all to answer the call itself.
+type CallInterceptor func(ctx context.Context, method string, params any, invoker CallInvoker) (json.RawMessage, error)
+
+// NotificationInterceptor wraps the delivery of every server notification.
+// It calls next to pass the notification on, or drops it by not calling
+// next. next is a no-op when no NotificationHandler is set.
+type NotificationInterceptor func(method string, params json.RawMessage, next NotificationHandler)
+
+// AddCallInterceptor appends an interceptor to the chain of every Call.
+// The first interceptor added is the outermost.
+func (c *Client) AddCallInterceptor(ic CallInterceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callInterceptors = append(c.callInterceptors, ic)
+}
+
+// AddNotificationInterceptor appends an interceptor to the chain of every
+// notification. The first interceptor added is the outermost.
+func (c *Client) AddNotificationInterceptor(ic NotificationInterceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifyInterceptors = append(c.notifyInterceptors, ic)
+}
+
+// chainCall wraps invoke with the call interceptors.
+func (c *Client) chainCall(invoke CallInvoker) CallInvoker {
+	c.mu.Lock()
+	chain := c.callInterceptors
+	c.mu.Unlock()
+	for i := len(chain) - 1; i >= 0; i-- {
+		ic, next := chain[i], invoke
+		invoke = func(ctx context.Context, method string, params any) (json.RawMessage, error) {
+			return ic(ctx, method, params, next)
+		}
+	}
+	return invoke
+}
+
+// notify delivers a notification through the interceptors to the handler.
+func (c *Client) notify(method string, params json.RawMessage) {
+	c.mu.Lock()
+	chain := c.notifyInterceptors
+	c.mu.Unlock()
+
+	deliver := c.notifyHandler
+	if deliver == nil {
+		deliver = func(string, json.RawMessage) {}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		ic, next := chain[i], deliver
+		deliver = func(method string, params json.RawMessage) {
+			ic(method, params, next)
+		}
+	}
+	deliver(method, params)
+}
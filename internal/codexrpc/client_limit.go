@@ -0,0 +1,120 @@
+package codexrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CallStats describes the calls of a client and its in-flight limit.
+type CallStats struct {
+	Limit     int           `json:"limit"`    // 0 when unlimited
+	InFlight  int           `json:"inFlight"` // calls awaiting a response
+	Queued    int           `json:"queued"`   // calls waiting for a slot
+	Calls     uint64        `json:"calls"`    // calls sent so far
+	Waited    uint64        `json:"waited"`   // calls that had to queue
+	TotalWait time.Duration `json:"totalWait"`
+	MaxWait   time.Duration `json:"maxWait"`
+}
+
+// callLimiter bounds the outstanding calls of a client. Calls over the
+// limit wait in FIFO order; a released slot is handed to the oldest.
+type callLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	queue    []chan struct{}
+	stats    CallStats
+}
+
+// SetMaxInFlight limits the calls awaiting a response at once, so fanning
+// out many requests on one connection does not flood the app-server.
+// Further calls queue in FIFO order. n <= 0 removes the limit.
+func (c *Client) SetMaxInFlight(n int) {
+	l := &c.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = max(n, 0)
+	for len(l.queue) > 0 && (l.limit == 0 || l.inFlight < l.limit) {
+		l.inFlight++
+		l.grantLocked()
+	}
+}
+
+// CallStats returns the client's call counters.
+func (c *Client) CallStats() CallStats {
+	l := &c.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := l.stats
+	stats.Limit = l.limit
+	stats.InFlight = l.inFlight
+	stats.Queued = len(l.queue)
+	return stats
+}
+
+// acquire takes a call slot, waiting in line when the limit is reached.
+func (l *callLimiter) acquire(ctx context.Context, done <-chan struct{}) error {
+	l.mu.Lock()
+	if l.limit == 0 || l.inFlight < l.limit {
+		l.inFlight++
+		l.stats.Calls++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.queue = append(l.queue, ready)
+	l.mu.Unlock()
+
+	start := time.Now()
+	var err error
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-done:
+		err = errClientClosed
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		for i, ch := range l.queue {
+			if ch == ready {
+				l.queue = append(l.queue[:i], l.queue[i+1:]...)
+				return err
+			}
+		}
+		// The slot was granted as we gave up; pass it on
+		l.releaseLocked()
+		return err
+	}
+	wait := time.Since(start)
+	l.stats.Calls++
+	l.stats.Waited++
+	l.stats.TotalWait += wait
+	l.stats.MaxWait = max(l.stats.MaxWait, wait)
+	return nil
+}
+
+// release frees a call slot.
+func (l *callLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+}
+
+func (l *callLimiter) releaseLocked() {
+	if len(l.queue) > 0 && (l.limit == 0 || l.inFlight <= l.limit) {
+		l.grantLocked() // the slot passes to the oldest waiter
+		return
+	}
+	l.inFlight--
+}
+
+// grantLocked wakes the oldest waiter, which takes a slot already counted
+// in inFlight.
+func (l *callLimiter) grantLocked() {
+	close(l.queue[0])
+	l.queue = l.queue[1:]
+}
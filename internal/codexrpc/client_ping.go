@@ -0,0 +1,22 @@
+package codexrpc
+
+import (
+	"context"
+	"errors"
+)
+
+// pingMethod is not implemented by the app-server; its error response is
+// enough to show that the server still reads and answers requests.
+const pingMethod = "codexAgentTeam/ping"
+
+// Ping checks that the app-server still answers requests. Any response,
+// including an error, counts as alive. Pings bypass the in-flight limit
+// and the interceptors, so a busy client is not mistaken for a dead one.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.send(ctx, pingMethod, nil)
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr != errClosedResponse {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,28 @@
+package codexrpc
+
+// Directions of a traced frame.
+const (
+	FrameSent     = "send"
+	FrameReceived = "recv"
+)
+
+// FrameTracer observes every JSON-RPC line the client writes or reads,
+// without its trailing newline. It must not retain frame.
+type FrameTracer func(direction string, frame []byte)
+
+// SetFrameTracer traces the raw frames of the client, e.g. for debugging
+// protocol issues. It may be called while the client runs; nil stops
+// tracing.
+func (c *Client) SetFrameTracer(t FrameTracer) {
+	if t == nil {
+		c.tracer.Store(nil)
+		return
+	}
+	c.tracer.Store(&t)
+}
+
+func (c *Client) trace(direction string, frame []byte) {
+	if t := c.tracer.Load(); t != nil {
+		(*t)(direction, frame)
+	}
+}
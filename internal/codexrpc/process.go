@@ -14,6 +14,91 @@ type SpawnOptions struct {
 	BinaryPath string
 	// ListenAddr is the transport address (default: "stdio://").
 	ListenAddr string
+	// Container, if set, runs the app-server inside a container instead
+	// of directly on the host.
+	Container *ContainerOptions
+}
+
+// ContainerOptions configures container-isolated execution of the app-server.
+type ContainerOptions struct {
+	// Runtime is the container CLI (default: "docker").
+	Runtime string
+	// Image is the container image providing the codex2 binary.
+	Image string
+	// BinaryPath is the codex2 path inside the image (default: "codex2").
+	BinaryPath string
+	// Mounts are host directories bind-mounted at the same path.
+	Mounts []string
+	// ReadOnlyMounts are host directories bind-mounted read-only at the
+	// same path, such as the repository's git directory.
+	ReadOnlyMounts []string
+	// Workdir is the working directory inside the container.
+	Workdir string
+	// CPUs limits CPU usage, e.g. "2" (empty for no limit).
+	CPUs string
+	// Memory limits memory usage, e.g. "4g" (empty for no limit).
+	Memory string
+	// Network is the network mode, e.g. "none" or "bridge" (empty for default).
+	Network string
+	// Env lists environment variables passed through from the host.
+	Env []string
+	// Name names the container, so commands can be run in it with Exec
+	// (empty for a generated name).
+	Name string
+}
+
+// cli returns the container runtime CLI.
+func (o *ContainerOptions) cli() string {
+	if o.Runtime == "" {
+		return "docker"
+	}
+	return o.Runtime
+}
+
+// Exec returns a command running argv in dir inside the named container,
+// next to the app-server.
+func (o *ContainerOptions) Exec(ctx context.Context, dir string, argv ...string) *exec.Cmd {
+	args := []string{"exec"}
+	if dir != "" {
+		args = append(args, "-w", dir)
+	}
+	args = append(append(args, o.Name), argv...)
+	return exec.CommandContext(ctx, o.cli(), args...)
+}
+
+// args builds the container runtime arguments for running the app-server.
+func (o *ContainerOptions) args(listenAddr string) []string {
+	args := []string{"run", "--rm", "-i"}
+	if o.Name != "" {
+		args = append(args, "--name", o.Name)
+	}
+	for _, m := range o.Mounts {
+		args = append(args, "-v", m+":"+m)
+	}
+	for _, m := range o.ReadOnlyMounts {
+		args = append(args, "-v", m+":"+m+":ro")
+	}
+	if o.Workdir != "" {
+		args = append(args, "-w", o.Workdir)
+	}
+	if o.CPUs != "" {
+		args = append(args, "--cpus", o.CPUs)
+	}
+	if o.Memory != "" {
+		args = append(args, "--memory", o.Memory)
+	}
+	if o.Network != "" {
+		args = append(args, "--network", o.Network)
+	}
+	for _, e := range o.Env {
+		args = append(args, "-e", e)
+	}
+
+	bin := o.BinaryPath
+	if bin == "" {
+		bin = "codex2"
+	}
+	return append(args, o.Image, bin, "app-server", "--listen", listenAddr)
 }
 
 // Process wraps a running codex2 app-server subprocess and its RPC client.
@@ -32,7 +117,12 @@ func Spawn(ctx context.Context, opts SpawnOptions) (*Process, error) {
 		listenAddr = "stdio://"
 	}
 
-	cmd := exec.CommandContext(ctx, opts.BinaryPath, "app-server", "--listen", listenAddr)
+	var cmd *exec.Cmd
+	if c := opts.Container; c != nil {
+		cmd = exec.CommandContext(ctx, c.cli(), c.args(listenAddr)...)
+	} else {
+		cmd = exec.CommandContext(ctx, opts.BinaryPath, "app-server", "--listen", listenAddr)
+	}
 
 	stdinPipe, err := cmd.StdinPipe()
 	if err != nil {
@@ -4,7 +4,10 @@
 // IMPORTANT: The "jsonrpc":"2.0" header is OMITTED on the wire.
 package codexrpc
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // --- JSON-RPC 2.0 base types (without jsonrpc field) ---
 
@@ -37,6 +40,10 @@ type RPCError struct {
 	Data    *json.RawMessage `json:"data,omitempty"`
 }
 
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
 // Notification is a JSON-RPC notification (no id field).
 type Notification struct {
 	Method string           `json:"method"`
@@ -0,0 +1,60 @@
+package codexrpc
+
+// --- Account ---
+
+// Account types reported by account/read.
+const (
+	AccountAPIKey  = "apiKey"
+	AccountChatGPT = "chatgpt"
+)
+
+// AccountReadParams queries the logged-in account.
+type AccountReadParams struct {
+	RefreshToken bool `json:"refreshToken"`
+}
+
+type AccountReadResponse struct {
+	Account            *Account `json:"account"` // nil when not logged in
+	RequiresOpenaiAuth bool     `json:"requiresOpenaiAuth"`
+}
+
+type Account struct {
+	Type     string `json:"type"` // "apiKey"|"chatgpt"
+	Email    string `json:"email,omitempty"`
+	PlanType string `json:"planType,omitempty"`
+}
+
+// Authenticated reports whether turns can run: an account is logged in,
+// or the configured model provider needs no OpenAI login.
+func (r *AccountReadResponse) Authenticated() bool {
+	return r.Account != nil || !r.RequiresOpenaiAuth
+}
+
+// --- Models ---
+
+// ModelListParams pages through the models available to the account.
+type ModelListParams struct {
+	Cursor *string `json:"cursor,omitempty"`
+	Limit  *int    `json:"limit,omitempty"`
+}
+
+type ModelListResponse struct {
+	Data       []Model `json:"data"`
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+type Model struct {
+	ID                        string                  `json:"id"`
+	Model                     string                  `json:"model"`
+	DisplayName               string                  `json:"displayName"`
+	Description               string                  `json:"description,omitempty"`
+	SupportedReasoningEfforts []ReasoningEffortOption `json:"supportedReasoningEfforts,omitempty"`
+	DefaultReasoningEffort    string                  `json:"defaultReasoningEffort,omitempty"`
+	IsDefault                 bool                    `json:"isDefault"`
+	ContextWindow             int64                   `json:"contextWindow,omitempty"` // tokens, when the app-server reports it
+}
+
+type ReasoningEffortOption struct {
+	ReasoningEffort string `json:"reasoningEffort"` // "minimal"|"low"|"medium"|"high"
+	Description     string `json:"description,omitempty"`
+}
@@ -0,0 +1,35 @@
+package codexrpc
+
+import "encoding/json"
+
+// --- Dynamic tools ---
+
+// MethodDynamicToolCall is the server request that invokes a dynamic tool
+// registered on thread/start.
+const MethodDynamicToolCall = "item/tool/call"
+
+// DynamicToolSpec declares a tool the client implements. Dynamic tools
+// are part of the experimental API.
+type DynamicToolSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"` // JSON Schema of the arguments
+}
+
+type DynamicToolCallParams struct {
+	ThreadID  string          `json:"threadId"`
+	TurnID    string          `json:"turnId"`
+	CallID    string          `json:"callId"`
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type DynamicToolCallResponse struct {
+	ContentItems []DynamicToolContentItem `json:"contentItems"`
+	Success      bool                     `json:"success"`
+}
+
+type DynamicToolContentItem struct {
+	Type string `json:"type"` // "inputText"
+	Text string `json:"text,omitempty"`
+}
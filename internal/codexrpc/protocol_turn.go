@@ -64,16 +64,64 @@ type TurnCompletedNotification struct {
 
 // ItemStartedNotification is emitted when an item begins.
 type ItemStartedNotification struct {
-	ThreadID string `json:"threadId"`
-	TurnID   string `json:"turnId"`
-	ItemID   string `json:"itemId"`
+	ThreadID string      `json:"threadId"`
+	TurnID   string      `json:"turnId"`
+	ItemID   string      `json:"itemId"`
+	Item     *ThreadItem `json:"item,omitempty"`
 }
 
 // ItemCompletedNotification is emitted when an item finishes.
 type ItemCompletedNotification struct {
+	ThreadID string      `json:"threadId"`
+	TurnID   string      `json:"turnId"`
+	ItemID   string      `json:"itemId"`
+	Item     *ThreadItem `json:"item,omitempty"`
+}
+
+// ThreadTokenUsageUpdatedNotification reports cumulative token usage of a
+// thread after each model response.
+type ThreadTokenUsageUpdatedNotification struct {
+	ThreadID   string           `json:"threadId"`
+	TurnID     string           `json:"turnId"`
+	TokenUsage ThreadTokenUsage `json:"tokenUsage"`
+}
+
+// ThreadTokenUsage holds the thread total and the last response's usage.
+type ThreadTokenUsage struct {
+	Total TokenUsageBreakdown `json:"total"`
+	Last  TokenUsageBreakdown `json:"last"`
+}
+
+// TokenUsageBreakdown is a token count split by kind.
+type TokenUsageBreakdown struct {
+	TotalTokens           int64 `json:"totalTokens"`
+	InputTokens           int64 `json:"inputTokens"`
+	CachedInputTokens     int64 `json:"cachedInputTokens"`
+	OutputTokens          int64 `json:"outputTokens"`
+	ReasoningOutputTokens int64 `json:"reasoningOutputTokens"`
+}
+
+// ThreadItem is the item payload carried by item notifications.
+// Only the fields used by commandExecution items are decoded.
+type ThreadItem struct {
+	Type             string  `json:"type"` // "commandExecution"|"agentMessage"|"fileChange"|...
+	ID               string  `json:"id"`
+	Command          string  `json:"command,omitempty"`
+	Cwd              string  `json:"cwd,omitempty"`
+	Status           string  `json:"status,omitempty"` // "inProgress"|"completed"|"failed"|"declined"
+	ExitCode         *int    `json:"exitCode,omitempty"`
+	AggregatedOutput *string `json:"aggregatedOutput,omitempty"`
+}
+
+// ItemTypeCommandExecution is the ThreadItem type for shell commands.
+const ItemTypeCommandExecution = "commandExecution"
+
+// CommandExecutionOutputDelta streams stdout/stderr of a running command.
+type CommandExecutionOutputDelta struct {
 	ThreadID string `json:"threadId"`
 	TurnID   string `json:"turnId"`
 	ItemID   string `json:"itemId"`
+	Delta    string `json:"delta"`
 }
 
 // --- Approval requests (server → client) ---
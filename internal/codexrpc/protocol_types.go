@@ -67,6 +67,12 @@ type ThreadStartParams struct {
 	DeveloperInstructions *string `json:"developerInstructions,omitempty"`
 	Ephemeral             *bool   `json:"ephemeral,omitempty"`
 	Personality           *string `json:"personality,omitempty"`           // "none"|"friendly"|"pragmatic"
+
+	// Config overrides codex config values for the thread, keyed by
+	// dotted path such as "mcp_servers.db".
+	Config map[string]any `json:"config,omitempty"`
+
+	DynamicTools []DynamicToolSpec `json:"dynamicTools,omitempty"` // needs the experimental API
 }
 
 type ThreadStartResponse struct {
@@ -88,3 +94,19 @@ type Thread struct {
 	Source        string  `json:"source"`
 	Path          *string `json:"path,omitempty"`
 }
+
+// ThreadListParams pages through stored threads, most recent first.
+type ThreadListParams struct {
+	Cursor *string `json:"cursor,omitempty"`
+	Limit  *int    `json:"limit,omitempty"`
+}
+
+type ThreadListResponse struct {
+	Data       []Thread `json:"data"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
+}
+
+// ThreadArchiveParams moves a thread out of the active thread list.
+type ThreadArchiveParams struct {
+	ThreadID string `json:"threadId"`
+}
@@ -0,0 +1,328 @@
+// Package e2e drives a full session (decompose, execute, merge) against a
+// temporary git repository and a scripted mock codex app-server, then
+// checks the branches, commits and events it produced. Runs are
+// deterministic and need neither network access nor a codex login.
+//
+// The mock app-server is the test binary itself: Run starts it as the
+// codex binary with ScenarioEnv set, and TestMain serves the scenario
+// instead of running the tests. Run the suite with go test ./internal/e2e.
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/session"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/pkg/agentteam"
+)
+
+// eventWait bounds the wait for events still queued on the bus after the
+// session finished.
+const eventWait = 5 * time.Second
+
+// Config configures a Run.
+type Config struct {
+	// CodexBin is started as the codex app-server; it must call
+	// maybeServeMock, as the package's test binary does.
+	CodexBin string
+
+	// Dir holds the fixture repository and the data directory. When empty
+	// a temporary directory is used and removed after a passing run.
+	Dir string
+
+	// Logf, if set, receives progress messages.
+	Logf func(format string, args ...any)
+}
+
+// Report is the outcome of a Run. The run passed when Failures is empty.
+type Report struct {
+	Scenario  string
+	Dir       string // kept after a failing run for inspection
+	SessionID string
+	Duration  time.Duration
+	Events    int
+	Failures  []string
+}
+
+// OK reports whether every check passed.
+func (r *Report) OK() bool {
+	return len(r.Failures) == 0
+}
+
+func (r *Report) failf(format string, args ...any) {
+	r.Failures = append(r.Failures, fmt.Sprintf(format, args...))
+}
+
+// Run runs sc as a session and checks the result. It returns an error
+// only when the run could not be set up; failed checks are in the report.
+func Run(ctx context.Context, cfg Config, sc *Scenario) (*Report, error) {
+	if err := sc.validate(); err != nil {
+		return nil, err
+	}
+	logf := cfg.Logf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+
+	dir := cfg.Dir
+	temporary := dir == ""
+	if temporary {
+		var err error
+		if dir, err = os.MkdirTemp("", "codex-team-e2e-"); err != nil {
+			return nil, err
+		}
+	}
+	report := &Report{Scenario: sc.Name, Dir: dir}
+	defer func() {
+		if temporary && report.OK() {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	repo := filepath.Join(dir, "repo")
+	if err := createFixture(ctx, repo, sc.Files); err != nil {
+		report.failf("create fixture: %v", err)
+		return report, nil
+	}
+	scenarioPath := filepath.Join(dir, "scenario.json")
+	data, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(scenarioPath, data, 0o644); err != nil {
+		return nil, err
+	}
+	// Agents inherit the environment
+	prev, hadPrev := os.LookupEnv(ScenarioEnv)
+	os.Setenv(ScenarioEnv, scenarioPath)
+	defer func() {
+		if hadPrev {
+			os.Setenv(ScenarioEnv, prev)
+		} else {
+			os.Unsetenv(ScenarioEnv)
+		}
+	}()
+
+	team, err := agentteam.New(cfg.CodexBin, repo, agentteam.Options{DataDir: filepath.Join(dir, "data")})
+	if err != nil {
+		return nil, err
+	}
+	defer team.Close()
+	rec := &recorder{}
+	defer team.Subscribe("e2e", rec)()
+
+	if err := team.Check(ctx); err != nil {
+		report.failf("codex check: %v", err)
+		return report, nil
+	}
+
+	logf("Running scenario %s in %s", sc.Name, dir)
+	start := time.Now()
+	sess, err := team.Run(ctx, sc.UserTask, agentteam.RunConfig{
+		Confirm: true,
+		OnStage: func(_ *agentteam.Session, stage agentteam.Stage) { logf("Stage %s", stage) },
+	})
+	report.Duration = time.Since(start)
+	if sess == nil {
+		report.failf("create session: %v", err)
+		return report, nil
+	}
+	report.SessionID = sess.ID
+	if err != nil {
+		report.failf("run: %v", err)
+	}
+
+	checkTasks(ctx, report, repo, sess, sc)
+	checkFiles(ctx, report, repo, sc)
+	checkEvents(report, rec, sess.ID, sc)
+	report.Events = rec.len()
+	return report, nil
+}
+
+// checkTasks checks that every scripted task completed on its own branch
+// and that its commit reached the target branch.
+func checkTasks(ctx context.Context, r *Report, repo string, sess *agentteam.Session, sc *Scenario) {
+	if sess.Status != session.StatusCompleted {
+		r.failf("session status is %s, want %s", sess.Status, session.StatusCompleted)
+	}
+	tasks := make(map[string]*task.Task)
+	for _, t := range sess.DAG.GetTasks() {
+		tasks[t.ID] = t
+	}
+	if len(tasks) != len(sc.Tasks) {
+		r.failf("plan has %d tasks, want %d", len(tasks), len(sc.Tasks))
+	}
+	for _, st := range sc.Tasks {
+		t, ok := tasks[st.ID]
+		if !ok {
+			r.failf("task %s missing from the plan", st.ID)
+			continue
+		}
+		if t.Status != task.StatusCompleted {
+			r.failf("task %s is %s, want %s: %s", st.ID, t.Status, task.StatusCompleted, t.Error)
+			continue
+		}
+		if t.BranchName == "" || t.ResultCommit == "" {
+			r.failf("task %s has branch %q and commit %q", st.ID, t.BranchName, t.ResultCommit)
+			continue
+		}
+		tip, err := git(ctx, repo, "rev-parse", "--verify", "refs/heads/"+t.BranchName)
+		if err != nil {
+			r.failf("task %s: branch %s: %v", st.ID, t.BranchName, err)
+		} else if tip != t.ResultCommit {
+			r.failf("task %s: branch %s is at %s, want its result %s", st.ID, t.BranchName, tip, t.ResultCommit)
+		}
+		if _, err := git(ctx, repo, "merge-base", "--is-ancestor", t.ResultCommit, "HEAD"); err != nil {
+			r.failf("task %s: commit %s was not merged", st.ID, t.ResultCommit)
+		}
+	}
+}
+
+// checkFiles compares the target branch with the expected files.
+func checkFiles(ctx context.Context, r *Report, repo string, sc *Scenario) {
+	expected := sc.Expected()
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := expected[name]
+		got, err := gitRaw(ctx, repo, "show", "HEAD:"+name)
+		if err != nil {
+			r.failf("file %s missing from HEAD", name)
+		} else if got != want {
+			r.failf("file %s is %q, want %q", name, got, want)
+		}
+	}
+}
+
+// checkEvents checks that each task was reported started and completed,
+// and the session completed, waiting for events still being delivered.
+func checkEvents(r *Report, rec *recorder, sessionID string, sc *Scenario) {
+	var want []string
+	for _, t := range sc.Tasks {
+		want = append(want, events.SourceExecutor+" started "+t.ID, events.SourceExecutor+" completed "+t.ID)
+	}
+	want = append(want, events.SourceSession+" "+events.TypeStatusChanged+" "+string(session.StatusCompleted))
+
+	deadline := time.Now().Add(eventWait)
+	for {
+		missing := rec.missing(sessionID, want)
+		if len(missing) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			for _, m := range missing {
+				r.failf("event %q not published", m)
+			}
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// recorder keeps the events of a run.
+type recorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (rec *recorder) Handle(ev events.Event) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.events = append(rec.events, ev)
+}
+
+func (rec *recorder) len() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.events)
+}
+
+// missing returns the keys of want with no matching event of the session.
+// A key is "<source> <type> <task>", or "<source> status.changed <to>".
+func (rec *recorder) missing(sessionID string, want []string) []string {
+	rec.mu.Lock()
+	seen := make(map[string]bool)
+	for _, ev := range rec.events {
+		if ev.SessionID != sessionID {
+			continue
+		}
+		key := ev.Source + " " + ev.Type + " " + ev.TaskID
+		if change, ok := ev.Data.(session.StatusChange); ok {
+			key = ev.Source + " " + ev.Type + " " + string(change.To)
+		}
+		seen[key] = true
+	}
+	rec.mu.Unlock()
+
+	var out []string
+	for _, key := range want {
+		if !seen[key] {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// createFixture creates a repository at dir whose main branch has one
+// commit with files.
+func createFixture(ctx context.Context, dir string, files map[string]string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "E2E"},
+		{"config", "user.email", "e2e@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if _, err := git(ctx, dir, args...); err != nil {
+			return err
+		}
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	if _, err := git(ctx, dir, "add", "-A"); err != nil {
+		return err
+	}
+	_, err := git(ctx, dir, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+	return err
+}
+
+// git runs git in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := gitRaw(ctx, dir, args...)
+	return strings.TrimSpace(out), err
+}
+
+func gitRaw(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,37 @@
+package e2e
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestMain serves the mock app-server when Run started the test binary as
+// the codex binary, and runs the tests otherwise.
+func TestMain(m *testing.M) {
+	maybeServeMock()
+	os.Exit(m.Run())
+}
+
+func TestDefaultScenario(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	report, err := Run(ctx, Config{CodexBin: self, Dir: t.TempDir(), Logf: t.Logf}, DefaultScenario())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Failures {
+		t.Error(f)
+	}
+	t.Logf("%s: session %s, %d events in %s", report.Scenario, report.SessionID, report.Events, report.Duration.Round(time.Millisecond))
+}
@@ -0,0 +1,246 @@
+package e2e
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"codex-agent-team/internal/codexrpc"
+)
+
+// ScenarioEnv names the scenario file of a process serving as the mock
+// codex app-server. Run sets it for the agents it spawns.
+const ScenarioEnv = "CODEX_AGENT_TEAM_E2E_SCENARIO"
+
+// maybeServeMock turns the process into the mock app-server when it was
+// started as one, i.e. with ScenarioEnv set and "app-server" as its first
+// argument, and exits when stdin closes. TestMain calls it, so the test
+// binary doubles as the codex binary of Run.
+func maybeServeMock() {
+	path := os.Getenv(ScenarioEnv)
+	if path == "" || len(os.Args) < 2 || os.Args[1] != "app-server" {
+		return
+	}
+	sc, err := loadScenario(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mock codex: %v\n", err)
+		os.Exit(1)
+	}
+	if err := newMock(sc, os.Stdout).serve(os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "mock codex: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// mock is a codex app-server that answers turns from a scenario instead
+// of a model: planners get the scenario's plan, workers write their
+// task's files, and resolvers merge both sides of a conflict.
+type mock struct {
+	sc      *Scenario
+	out     *json.Encoder
+	threads map[string]mockThread
+	seq     int
+}
+
+type mockThread struct {
+	cwd string
+}
+
+func newMock(sc *Scenario, w io.Writer) *mock {
+	return &mock{sc: sc, out: json.NewEncoder(w), threads: make(map[string]mockThread)}
+}
+
+// serve answers requests until r is closed. Requests are handled one at a
+// time, so a turn's notifications always follow its response.
+func (m *mock) serve(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return fmt.Errorf("parse message: %w", err)
+		}
+		// Notifications such as "initialized" and responses to approval
+		// requests need no answer
+		if msg.ID == nil || msg.Method == "" {
+			continue
+		}
+		if err := m.handle(msg.ID, msg.Method, msg.Params); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers one request. It fails only when the output can no
+// longer be written.
+func (m *mock) handle(id codexrpc.RequestID, method string, params json.RawMessage) error {
+	switch method {
+	case "initialize":
+		return m.respond(id, codexrpc.InitializeResponse{UserAgent: "codex-agent-team-e2e-mock"})
+	case "account/read":
+		return m.respond(id, codexrpc.AccountReadResponse{RequiresOpenaiAuth: false})
+	case "model/list":
+		return m.respond(id, codexrpc.ModelListResponse{Data: []codexrpc.Model{}})
+	case "thread/archive", "turn/interrupt":
+		return m.respond(id, struct{}{})
+	case "thread/start":
+		var p codexrpc.ThreadStartParams
+		_ = json.Unmarshal(params, &p)
+		m.seq++
+		thread := codexrpc.Thread{ID: fmt.Sprintf("thread-%d", m.seq), Source: "e2e"}
+		if p.Cwd != nil {
+			thread.Cwd = *p.Cwd
+		}
+		m.threads[thread.ID] = mockThread{cwd: thread.Cwd}
+		return m.respond(id, codexrpc.ThreadStartResponse{Thread: thread, Model: "mock", Cwd: thread.Cwd})
+	case "turn/start":
+		var p codexrpc.TurnStartParams
+		_ = json.Unmarshal(params, &p)
+		return m.turn(id, p)
+	default:
+		return m.write(codexrpc.ErrorResponse{ID: id, Error: codexrpc.RPCError{Code: -32601, Message: "method not found: " + method}})
+	}
+}
+
+// turn runs a turn to completion before the next request is read.
+func (m *mock) turn(id codexrpc.RequestID, p codexrpc.TurnStartParams) error {
+	thread, ok := m.threads[p.ThreadID]
+	if !ok {
+		return m.write(codexrpc.ErrorResponse{ID: id, Error: codexrpc.RPCError{Code: -32602, Message: "unknown thread " + p.ThreadID}})
+	}
+	var prompt strings.Builder
+	for _, in := range p.Input {
+		prompt.WriteString(in.Text)
+	}
+
+	m.seq++
+	turn := codexrpc.Turn{ID: fmt.Sprintf("turn-%d", m.seq), Status: "inProgress"}
+	if err := m.respond(id, codexrpc.TurnStartResponse{Turn: turn}); err != nil {
+		return err
+	}
+	if err := m.notify("turn/started", codexrpc.TurnStartedNotification{ThreadID: p.ThreadID, Turn: turn}); err != nil {
+		return err
+	}
+
+	output, turnErr := m.answer(thread, prompt.String())
+	if output != "" {
+		delta := codexrpc.AgentMessageDelta{ThreadID: p.ThreadID, TurnID: turn.ID, ItemID: turn.ID + "-message", Delta: output}
+		if err := m.notify("item/agentMessage/delta", delta); err != nil {
+			return err
+		}
+	}
+	turn.Status = "completed"
+	if turnErr != nil {
+		turn.Status = "failed"
+		turn.Error = &codexrpc.TurnError{Message: turnErr.Error()}
+	}
+	return m.notify("turn/completed", codexrpc.TurnCompletedNotification{ThreadID: p.ThreadID, Turn: turn})
+}
+
+// answer does what the prompt asks in the thread's working directory and
+// returns the agent's reply.
+func (m *mock) answer(thread mockThread, prompt string) (string, error) {
+	switch {
+	case strings.Contains(prompt, "decompose the following task into sub-tasks"):
+		return m.sc.plan()
+	case strings.HasPrefix(prompt, "Please resolve the merge conflicts in "):
+		file, _, _ := strings.Cut(strings.TrimPrefix(prompt, "Please resolve the merge conflicts in "), "\n")
+		file = strings.TrimSuffix(file, ".")
+		if err := resolveUnion(thread.cwd, file); err != nil {
+			return "FAILED: " + err.Error(), nil
+		}
+		return "DONE", nil
+	}
+
+	for _, t := range m.sc.Tasks {
+		if t.Description == "" || !strings.Contains(prompt, t.Description) {
+			continue
+		}
+		for _, name := range t.Requires {
+			if _, err := os.Stat(filepath.Join(thread.cwd, name)); err != nil {
+				return "", fmt.Errorf("%s: required file %s missing from the worktree", t.ID, name)
+			}
+		}
+		changed := t.written()
+		for _, name := range changed {
+			path := filepath.Join(thread.cwd, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return "", err
+			}
+			if err := os.WriteFile(path, []byte(t.Writes[name]), 0o644); err != nil {
+				return "", err
+			}
+		}
+		result, _ := json.MarshalIndent(map[string]any{
+			"summary":      "Completed " + t.Title,
+			"filesChanged": changed,
+		}, "", "  ")
+		return "Done.\n\n```json\n" + string(result) + "\n```\n", nil
+	}
+
+	// Merge assistants and other roles have nothing to do
+	return "DONE", nil
+}
+
+// resolveUnion resolves the conflicts in file by keeping both sides, ours
+// first, and stages it.
+func resolveUnion(dir, file string) error {
+	path := filepath.Join(dir, file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var out []string
+	base := false
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<"), strings.HasPrefix(line, "======="), strings.HasPrefix(line, ">>>>>>>"):
+			base = false
+		case strings.HasPrefix(line, "|||||||"):
+			base = true
+		case !base:
+			out = append(out, line)
+		}
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(out, "")), 0o644); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "add", "--", file)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git add %s: %w: %s", file, err, output)
+	}
+	return nil
+}
+
+func (m *mock) respond(id codexrpc.RequestID, result any) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return m.write(codexrpc.Response{ID: id, Result: raw})
+}
+
+func (m *mock) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	msg := json.RawMessage(raw)
+	return m.write(codexrpc.Notification{Method: method, Params: &msg})
+}
+
+func (m *mock) write(msg any) error {
+	return m.out.Encode(msg)
+}
@@ -0,0 +1,165 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Scenario is a scripted session: the repository it starts from, the user
+// task, and the plan the mock planner returns for it.
+type Scenario struct {
+	Name     string            `json:"name"`
+	UserTask string            `json:"userTask"`
+	Files    map[string]string `json:"files"` // initial commit of the fixture
+	Tasks    []ScriptTask      `json:"tasks"` // in dependency order
+}
+
+// ScriptTask is a task of the scripted plan and what its worker does.
+type ScriptTask struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"` // unique; identifies the task in worker prompts
+	DependsOn   []string `json:"dependsOn,omitempty"`
+
+	// Requires lists files that must be in the worktree when the worker
+	// starts, such as those written by dependencies. The turn fails
+	// otherwise.
+	Requires []string `json:"requires,omitempty"`
+
+	// Writes are the files the worker writes, by path.
+	Writes map[string]string `json:"writes"`
+}
+
+// DefaultScenario is a fan-in of three tasks: two independent ones and a
+// third that builds on both, so it covers parallel execution, dependency
+// merges into a worktree and the final merge.
+func DefaultScenario() *Scenario {
+	return &Scenario{
+		Name:     "greetings",
+		UserTask: "Add greeting files and an index of them",
+		Files: map[string]string{
+			"README.md": "# Greetings\n",
+		},
+		Tasks: []ScriptTask{
+			{
+				ID:          "hello",
+				Title:       "Add hello",
+				Description: "Create hello.txt containing a greeting.",
+				Writes:      map[string]string{"hello.txt": "hello\n"},
+			},
+			{
+				ID:          "bye",
+				Title:       "Add bye",
+				Description: "Create bye.txt containing a farewell.",
+				Writes:      map[string]string{"bye.txt": "bye\n"},
+			},
+			{
+				ID:          "index",
+				Title:       "Add index",
+				Description: "List the greeting files in INDEX and link it from the README.",
+				DependsOn:   []string{"hello", "bye"},
+				Requires:    []string{"hello.txt", "bye.txt"},
+				Writes: map[string]string{
+					"INDEX":     "bye.txt\nhello.txt\n",
+					"README.md": "# Greetings\n\nSee INDEX.\n",
+				},
+			},
+		},
+	}
+}
+
+func loadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc Scenario
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	return &sc, nil
+}
+
+// validate checks that tasks are unique, identifiable in prompts and
+// listed after their dependencies.
+func (sc *Scenario) validate() error {
+	if len(sc.Tasks) == 0 {
+		return fmt.Errorf("scenario %s has no tasks", sc.Name)
+	}
+	seen := make(map[string]bool)
+	for _, t := range sc.Tasks {
+		if t.ID == "" || seen[t.ID] {
+			return fmt.Errorf("task %q: missing or duplicate ID", t.ID)
+		}
+		if t.Description == "" {
+			return fmt.Errorf("task %s: missing description", t.ID)
+		}
+		for _, other := range sc.Tasks {
+			if other.ID != t.ID && strings.Contains(other.Description, t.Description) {
+				return fmt.Errorf("task %s: description is part of that of %s", t.ID, other.ID)
+			}
+		}
+		for _, dep := range t.DependsOn {
+			if !seen[dep] {
+				return fmt.Errorf("task %s: dependency %s must be listed before it", t.ID, dep)
+			}
+		}
+		seen[t.ID] = true
+	}
+	return nil
+}
+
+// plan returns the decomposition the mock planner replies with.
+func (sc *Scenario) plan() (string, error) {
+	type planTask struct {
+		ID          string   `json:"id"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		DependsOn   []string `json:"dependsOn"`
+		Files       []string `json:"files"`
+	}
+	tasks := make([]planTask, 0, len(sc.Tasks))
+	for _, t := range sc.Tasks {
+		tasks = append(tasks, planTask{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			DependsOn:   append([]string{}, t.DependsOn...),
+			Files:       t.written(),
+		})
+	}
+	data, err := json.Marshal(map[string]any{
+		"description":        "Scripted plan for " + sc.Name,
+		"tasks":              tasks,
+		"totalEstimatedTime": "1 min",
+	})
+	return string(data), err
+}
+
+// Expected returns the files of the target branch after a successful run:
+// the initial files overwritten by each task's writes in order.
+func (sc *Scenario) Expected() map[string]string {
+	files := make(map[string]string, len(sc.Files))
+	for name, content := range sc.Files {
+		files[name] = content
+	}
+	for _, t := range sc.Tasks {
+		for name, content := range t.Writes {
+			files[name] = content
+		}
+	}
+	return files
+}
+
+// written returns the paths the task writes, sorted.
+func (t ScriptTask) written() []string {
+	names := make([]string, 0, len(t.Writes))
+	for name := range t.Writes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,121 @@
+// Package events is the in-process event bus. Components publish what
+// happens to sessions, tasks and agents; sinks such as the WebSocket hub,
+// event logs and webhooks subscribe to it.
+//
+// Publishing never blocks. Each sink has its own buffered queue drained by
+// its own goroutine, so a slow sink loses its own events without delaying
+// publishers or other sinks.
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event sources.
+const (
+	SourceAgent    = "agent"    // agent manager notifications
+	SourceSession  = "session"  // session lifecycle
+	SourceExecutor = "executor" // task execution
+)
+
+// Session event types.
+const (
+	TypeStatusChanged = "status.changed" // Data is the status change
+	TypeNotice        = "notice"         // Data is the notice
+)
+
+// Event is one thing that happened. Agent events carry their raw JSON
+// payload as Data.
+type Event struct {
+	Source    string    `json:"source"`
+	Type      string    `json:"type"`
+	SessionID string    `json:"sessionId,omitempty"`
+	TaskID    string    `json:"taskId,omitempty"`
+	AgentID   string    `json:"agentId,omitempty"`
+	TurnID    string    `json:"turnId,omitempty"`
+	Time      time.Time `json:"time"`
+	Data      any       `json:"data,omitempty"`
+}
+
+// Sink receives events from the bus, one at a time and in publish order.
+type Sink interface {
+	Handle(Event)
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(Event)
+
+// Handle calls f(ev).
+func (f SinkFunc) Handle(ev Event) { f(ev) }
+
+// sinkQueueSize bounds the events waiting for one sink.
+const sinkQueueSize = 1024
+
+// Bus fans events out to subscribed sinks.
+type Bus struct {
+	mu      sync.RWMutex
+	subs    map[*subscription]struct{}
+	dropped atomic.Uint64
+}
+
+type subscription struct {
+	name  string
+	queue chan Event
+}
+
+// NewBus creates an event bus without sinks.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*subscription]struct{})}
+}
+
+// Subscribe delivers every subsequently published event to sink until the
+// returned function is called. The name identifies the sink in logs.
+func (b *Bus) Subscribe(name string, sink Sink) func() {
+	sub := &subscription{name: name, queue: make(chan Event, sinkQueueSize)}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		for ev := range sub.queue {
+			sink.Handle(ev)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			b.mu.Unlock()
+			close(sub.queue)
+		})
+	}
+}
+
+// Publish delivers ev to every sink without blocking. Sinks whose queue is
+// full miss the event.
+func (b *Bus) Publish(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		select {
+		case sub.queue <- ev:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped returns how many deliveries were skipped because a sink fell
+// behind.
+func (b *Bus) Dropped() uint64 {
+	return b.dropped.Load()
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Without wraps sink so that events of the given types are skipped, e.g.
+// to keep streamed output out of logs and webhooks.
+func Without(sink Sink, types ...string) Sink {
+	skip := make(map[string]bool, len(types))
+	for _, t := range types {
+		skip[t] = true
+	}
+	return SinkFunc(func(ev Event) {
+		if !skip[ev.Type] {
+			sink.Handle(ev)
+		}
+	})
+}
+
+// LogSink writes a line per event to the standard logger.
+func LogSink() Sink {
+	return SinkFunc(func(ev Event) {
+		log.Printf("event %s %s session=%s task=%s agent=%s", ev.Source, ev.Type, ev.SessionID, ev.TaskID, ev.AgentID)
+	})
+}
+
+// FileSink appends the events of each session to <dir>/<session>.jsonl.
+// Events that belong to no session are not persisted.
+type FileSink struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileSink creates a file sink writing to dir.
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileSink{dir: dir}, nil
+}
+
+// Handle appends ev to its session's log.
+func (s *FileSink) Handle(ev Event) {
+	if ev.SessionID == "" {
+		return
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(s.dir, filepath.Base(ev.SessionID)+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Event log: %v", err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
+
+// webhookTimeout bounds each webhook delivery.
+const webhookTimeout = 5 * time.Second
+
+// WebhookSink POSTs each event as JSON to url. Failed deliveries are
+// logged and not retried.
+func WebhookSink(url string) Sink {
+	client := &http.Client{Timeout: webhookTimeout}
+	return SinkFunc(func(ev Event) {
+		body, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		if err := postJSON(client, url, body); err != nil {
+			log.Printf("Webhook %s: %v", url, err)
+		}
+	})
+}
+
+// postJSON sends body and checks for a 2xx response.
+func postJSON(client *http.Client, url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
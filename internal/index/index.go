@@ -0,0 +1,185 @@
+// Package index is a lightweight code index. It lists a repository's files
+// and extracts top-level symbols with per-language patterns, so plans can
+// be grounded in paths and names that actually exist.
+package index
+
+import (
+	"bufio"
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Limits keep indexing fast on large repositories.
+const (
+	maxFiles     = 20000
+	maxFileBytes = 512 << 10
+)
+
+// Symbol is a named declaration found in a source file.
+type Symbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"` // "func", "type", "class", ...
+	File string `json:"file"` // slash-separated, relative to the root
+	Line int    `json:"line"`
+}
+
+// Index holds the files and symbols of one repository.
+type Index struct {
+	Root    string
+	Files   []string // slash-separated, relative to Root, sorted
+	Symbols []Symbol
+
+	files  map[string]bool
+	dirs   map[string]bool
+	byBase map[string][]string
+}
+
+// symbolPattern extracts a symbol name from its first submatch.
+type symbolPattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var (
+	goPatterns = []symbolPattern{
+		{"func", regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`)},
+		{"type", regexp.MustCompile(`^type\s+([A-Za-z_]\w*)`)},
+	}
+	pyPatterns = []symbolPattern{
+		{"func", regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)`)},
+		{"class", regexp.MustCompile(`^\s*class\s+(\w+)`)},
+	}
+	jsPatterns = []symbolPattern{
+		{"func", regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)`)},
+		{"class", regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`)},
+		{"type", regexp.MustCompile(`^\s*export\s+(?:interface|type|enum)\s+(\w+)`)},
+		{"const", regexp.MustCompile(`^\s*export\s+(?:const|let|var)\s+(\w+)`)},
+	}
+	rustPatterns = []symbolPattern{
+		{"func", regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?fn\s+(\w+)`)},
+		{"type", regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|trait)\s+(\w+)`)},
+	}
+	javaPatterns = []symbolPattern{
+		{"class", regexp.MustCompile(`^\s*(?:public\s+|private\s+|protected\s+)?(?:abstract\s+|final\s+|data\s+|open\s+)*(?:class|interface|enum|object)\s+(\w+)`)},
+	}
+)
+
+// patterns returns the symbol patterns of a file's language.
+func patterns(file string) []symbolPattern {
+	switch path.Ext(file) {
+	case ".go":
+		return goPatterns
+	case ".py":
+		return pyPatterns
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".vue":
+		return jsPatterns
+	case ".rs":
+		return rustPatterns
+	case ".java", ".kt":
+		return javaPatterns
+	}
+	return nil
+}
+
+// Build indexes the repository at root. Tracked files are listed with git
+// when possible so ignored build output is left out.
+func Build(ctx context.Context, root string) (*Index, error) {
+	files, err := listFiles(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &Index{
+		Root:   root,
+		Files:  files,
+		files:  make(map[string]bool, len(files)),
+		dirs:   make(map[string]bool),
+		byBase: make(map[string][]string),
+	}
+	for _, f := range files {
+		x.files[f] = true
+		x.byBase[path.Base(f)] = append(x.byBase[path.Base(f)], f)
+		for dir := path.Dir(f); dir != "." && !x.dirs[dir]; dir = path.Dir(dir) {
+			x.dirs[dir] = true
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		x.Symbols = append(x.Symbols, scanSymbols(root, f)...)
+	}
+	return x, nil
+}
+
+// listFiles returns the sorted files under root.
+func listFiles(ctx context.Context, root string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-files")
+	cmd.Dir = root
+	if out, err := cmd.Output(); err == nil {
+		files := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if len(files) > maxFiles {
+			files = files[:maxFiles]
+		}
+		if len(files) == 1 && files[0] == "" {
+			files = nil
+		}
+		return files, nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel, err := filepath.Rel(root, p); err == nil {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		if len(files) >= maxFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return files, err
+}
+
+// scanSymbols extracts the symbols of one file.
+func scanSymbols(root, file string) []Symbol {
+	pats := patterns(file)
+	if pats == nil {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.Size() > maxFileBytes {
+		return nil
+	}
+
+	var symbols []Symbol
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxFileBytes)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, p := range pats {
+			if m := p.re.FindStringSubmatch(text); m != nil {
+				symbols = append(symbols, Symbol{Name: m[1], Kind: p.kind, File: file, Line: line})
+				break
+			}
+		}
+	}
+	return symbols
+}
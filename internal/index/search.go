@@ -0,0 +1,146 @@
+package index
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Search returns up to limit symbols relevant to a free-text query, best
+// first. A symbol scores for every query word found in its name and, with
+// less weight, in its file path.
+func (x *Index) Search(query string, limit int) []Symbol {
+	words := queryWords(query)
+	if len(words) == 0 || limit <= 0 {
+		return nil
+	}
+
+	type scored struct {
+		Symbol
+		score int
+	}
+	var hits []scored
+	for _, s := range x.Symbols {
+		name := strings.ToLower(s.Name)
+		file := strings.ToLower(s.File)
+		score := 0
+		for _, w := range words {
+			if strings.Contains(name, w) {
+				score += 2
+			}
+			if strings.Contains(file, w) {
+				score++
+			}
+		}
+		if score > 0 {
+			hits = append(hits, scored{s, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	symbols := make([]Symbol, len(hits))
+	for i, h := range hits {
+		symbols[i] = h.Symbol
+	}
+	return symbols
+}
+
+// stopWords are query words too common to say anything about relevance.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"add": true, "from": true, "into": true, "when": true, "should": true, "make": true,
+}
+
+// queryWords splits a query into distinct lowercase words of three or
+// more letters.
+func queryWords(query string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) < 3 || stopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+// FormatSymbols renders symbols as prompt lines, "file:line kind name".
+func FormatSymbols(symbols []Symbol) string {
+	var b strings.Builder
+	for _, s := range symbols {
+		fmt.Fprintf(&b, "%s:%d %s %s\n", s.File, s.Line, s.Kind, s.Name)
+	}
+	return b.String()
+}
+
+// Resolve checks a suggested path against the index. It returns the path
+// itself if the file or directory exists, and a corrected path for a near
+// miss such as a wrong or misspelled directory. A missing file in an
+// existing directory is taken to be a file the task will create and is
+// returned unchanged with ok=false, as is a path nothing resembles.
+func (x *Index) Resolve(p string) (resolved string, ok bool) {
+	clean := path.Clean(strings.TrimPrefix(strings.ReplaceAll(p, "\\", "/"), "./"))
+	if x.files[clean] || x.dirs[clean] {
+		return clean, true
+	}
+	if dir := path.Dir(clean); dir != "." && x.dirs[dir] {
+		return clean, false
+	}
+
+	// Right name, wrong directory: accept a unique file of that name
+	if candidates := x.byBase[path.Base(clean)]; len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	// A typo: the closest file within a small edit distance
+	best, bestDist := "", len(clean)/10+2
+	for _, f := range x.Files {
+		if abs(len(f)-len(clean)) >= bestDist {
+			continue
+		}
+		if d := editDistance(clean, f); d < bestDist {
+			best, bestDist = f, d
+		}
+	}
+	if best != "" {
+		return best, true
+	}
+	return clean, false
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,122 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// githubRef matches "owner/repo#12" and issue or pull request URLs.
+var githubRef = regexp.MustCompile(`^(?:https?://github\.com/)?([\w.-]+)/([\w.-]+)(?:#|/issues/|/pull/)([0-9]+)/?$`)
+
+// githubRemote matches the owner and repository of a GitHub remote URL,
+// over HTTPS or SSH.
+var githubRemote = regexp.MustCompile(`^(?:https?://(?:[^@/]+@)?[^/]+/|ssh://git@[^/]+/|git@[^:]+:)([\w.-]+)/([\w.-]+?)(?:\.git)?/?$`)
+
+// githubTracker reads issues through the GitHub REST API.
+type githubTracker struct {
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+func newGitHub(apiURL, token string) *githubTracker {
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &githubTracker{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (g *githubTracker) Name() string { return GitHub }
+
+func (g *githubTracker) Match(ref string) bool { return githubRef.MatchString(ref) }
+
+// parse splits ref into its repository path and issue number.
+func (g *githubTracker) parse(ref string) (repo, number string, err error) {
+	m := githubRef.FindStringSubmatch(strings.TrimSpace(ref))
+	if m == nil {
+		return "", "", fmt.Errorf("github: expected owner/repo#number or an issue URL, got %q", ref)
+	}
+	return m[1] + "/" + m[2], m[3], nil
+}
+
+func (g *githubTracker) Fetch(ctx context.Context, ref string) (*Issue, error) {
+	repo, number, err := g.parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	base := fmt.Sprintf("%s/repos/%s/issues/%s", g.apiURL, repo, number)
+
+	var issue struct {
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := doJSON(ctx, g.http, http.MethodGet, base, nil, &issue, g.auth); err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+
+	var comments []struct {
+		Body string `json:"body"`
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if err := doJSON(ctx, g.http, http.MethodGet, base+"/comments?per_page=100", nil, &comments, g.auth); err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+
+	out := &Issue{
+		Tracker: GitHub,
+		Key:     repo + "#" + number,
+		URL:     issue.HTMLURL,
+		Title:   issue.Title,
+		Body:    issue.Body,
+	}
+	for _, c := range comments {
+		out.Comments = append(out.Comments, Comment{Author: c.User.Login, Body: c.Body})
+	}
+	return out, nil
+}
+
+func (g *githubTracker) Comment(ctx context.Context, key, body string) error {
+	repo, number, err := g.parse(key)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues/%s/comments", g.apiURL, repo, number)
+	if err := doJSON(ctx, g.http, http.MethodPost, url, map[string]string{"body": body}, nil, g.auth); err != nil {
+		return fmt.Errorf("github: %w", err)
+	}
+	return nil
+}
+
+// OpenPullRequest opens a pull request of head into base on the repository
+// remoteURL points to and returns its URL.
+func (g *githubTracker) OpenPullRequest(ctx context.Context, remoteURL, head, base, title, body string) (string, error) {
+	m := githubRemote.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if m == nil {
+		return "", fmt.Errorf("github: cannot tell the repository of remote %q", remoteURL)
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls", g.apiURL, m[1], m[2])
+
+	req := map[string]string{"title": title, "head": head, "base": base, "body": body}
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := doJSON(ctx, g.http, http.MethodPost, url, req, &pr, g.auth); err != nil {
+		return "", fmt.Errorf("github: %w", err)
+	}
+	return pr.HTMLURL, nil
+}
+
+func (g *githubTracker) auth(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+}
@@ -0,0 +1,224 @@
+// Package issues connects sessions to issue trackers. An issue is fetched
+// to seed a session's task and the session's final report is posted back
+// as a comment.
+//
+// GitHub and Jira are reached through their REST APIs and Linear through
+// its GraphQL API, so no tracker SDK is required. GitHub also opens the
+// pull requests of sessions delivered as a PR stack.
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tracker names.
+const (
+	GitHub = "github"
+	Jira   = "jira"
+	Linear = "linear"
+)
+
+// ErrUnknownTracker is returned for a tracker that is not configured or
+// an issue reference no configured tracker recognizes.
+var ErrUnknownTracker = errors.New("unknown issue tracker")
+
+// Issue is an issue with its discussion.
+type Issue struct {
+	Tracker  string    `json:"tracker"`
+	Key      string    `json:"key"` // e.g. "owner/repo#12", "PROJ-34", "ENG-56"
+	URL      string    `json:"url"`
+	Title    string    `json:"title"`
+	Body     string    `json:"body"`
+	Comments []Comment `json:"comments,omitempty"`
+}
+
+// Comment is one comment of an issue, oldest first.
+type Comment struct {
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+// Tracker fetches issues from and comments on one issue tracker.
+type Tracker interface {
+	// Name returns the tracker name, e.g. GitHub.
+	Name() string
+	// Match reports whether ref looks like an issue of this tracker.
+	Match(ref string) bool
+	// Fetch returns the issue ref points to.
+	Fetch(ctx context.Context, ref string) (*Issue, error)
+	// Comment posts body as a comment on the issue with the given key.
+	Comment(ctx context.Context, key, body string) error
+}
+
+// PullRequestOpener opens pull requests on the code host of a git remote.
+type PullRequestOpener interface {
+	// OpenPullRequest opens a pull request of head into base on the
+	// repository remoteURL points to and returns its URL.
+	OpenPullRequest(ctx context.Context, remoteURL, head, base, title, body string) (string, error)
+}
+
+// Config configures the trackers. A tracker is enabled when its
+// credentials are set.
+type Config struct {
+	GitHubToken  string // token with issues read and write access
+	GitHubAPIURL string // default https://api.github.com
+
+	JiraURL   string // site URL, e.g. https://example.atlassian.net
+	JiraUser  string // account email, used with JiraToken for basic auth
+	JiraToken string // API token
+
+	LinearAPIKey string // personal API key
+}
+
+// Registry holds the configured trackers by name.
+type Registry struct {
+	trackers map[string]Tracker
+}
+
+// NewRegistry creates the trackers enabled by cfg.
+func NewRegistry(cfg Config) *Registry {
+	r := &Registry{trackers: make(map[string]Tracker)}
+	if cfg.GitHubToken != "" {
+		r.Add(newGitHub(cfg.GitHubAPIURL, cfg.GitHubToken))
+	}
+	if cfg.JiraURL != "" && cfg.JiraToken != "" {
+		r.Add(newJira(cfg.JiraURL, cfg.JiraUser, cfg.JiraToken))
+	}
+	if cfg.LinearAPIKey != "" {
+		r.Add(newLinear(cfg.LinearAPIKey))
+	}
+	return r
+}
+
+// Add registers a tracker, replacing one with the same name.
+func (r *Registry) Add(t Tracker) {
+	r.trackers[t.Name()] = t
+}
+
+// PullRequests returns the configured tracker that opens pull requests.
+func (r *Registry) PullRequests() (PullRequestOpener, bool) {
+	for _, name := range r.Names() {
+		if p, ok := r.trackers[name].(PullRequestOpener); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the configured trackers, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.trackers))
+	for name := range r.trackers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the tracker with the given name.
+func (r *Registry) Get(name string) (Tracker, error) {
+	t, ok := r.trackers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTracker, name)
+	}
+	return t, nil
+}
+
+// Resolve returns the tracker named name or, when name is empty, the only
+// configured tracker that recognizes ref.
+func (r *Registry) Resolve(name, ref string) (Tracker, error) {
+	if name != "" {
+		return r.Get(name)
+	}
+	var matched []Tracker
+	for _, t := range r.trackers {
+		if t.Match(ref) {
+			matched = append(matched, t)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("%w for %q", ErrUnknownTracker, ref)
+	case 1:
+		return matched[0], nil
+	default:
+		return nil, fmt.Errorf("%q matches several trackers, name one", ref)
+	}
+}
+
+// UserTask renders the issue as the task of a session.
+func UserTask(issue *Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", issue.Title)
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		fmt.Fprintf(&b, "%s\n\n", body)
+	}
+	if len(issue.Comments) > 0 {
+		b.WriteString("Discussion:\n")
+		for _, c := range issue.Comments {
+			fmt.Fprintf(&b, "\n%s wrote:\n%s\n", c.Author, strings.TrimSpace(c.Body))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Source: %s %s", issue.Key, issue.URL)
+	return b.String()
+}
+
+// keyPattern matches Jira and Linear issue keys such as "ENG-123".
+var keyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]+-[0-9]+$`)
+
+// requestTimeout bounds each tracker API request.
+const requestTimeout = 15 * time.Second
+
+// doJSON sends a JSON request and decodes a 2xx JSON response into out.
+// auth sets the authentication headers.
+func doJSON(ctx context.Context, client *http.Client, method, url string, body, out any, auth func(*http.Request)) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	auth(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, strings.TrimSpace(string(raw)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("%s %s: decode response: %w", method, url, err)
+		}
+	}
+	return nil
+}
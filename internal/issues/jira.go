@@ -0,0 +1,105 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// jiraTracker reads issues through the Jira REST API v2, whose text
+// fields are plain wiki markup rather than documents.
+type jiraTracker struct {
+	siteURL string
+	user    string
+	token   string
+	http    *http.Client
+}
+
+func newJira(siteURL, user, token string) *jiraTracker {
+	return &jiraTracker{
+		siteURL: strings.TrimSuffix(siteURL, "/"),
+		user:    user,
+		token:   token,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (j *jiraTracker) Name() string { return Jira }
+
+func (j *jiraTracker) Match(ref string) bool {
+	if strings.HasPrefix(ref, j.siteURL+"/browse/") {
+		return true
+	}
+	return keyPattern.MatchString(ref)
+}
+
+// parse returns the issue key of a key or browse URL.
+func (j *jiraTracker) parse(ref string) (string, error) {
+	key := strings.TrimPrefix(strings.TrimSpace(ref), j.siteURL+"/browse/")
+	if !keyPattern.MatchString(key) {
+		return "", fmt.Errorf("jira: expected an issue key such as PROJ-12, got %q", ref)
+	}
+	return key, nil
+}
+
+func (j *jiraTracker) Fetch(ctx context.Context, ref string) (*Issue, error) {
+	key, err := j.parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var issue struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+			Comment     struct {
+				Comments []struct {
+					Body   string `json:"body"`
+					Author struct {
+						DisplayName string `json:"displayName"`
+					} `json:"author"`
+				} `json:"comments"`
+			} `json:"comment"`
+		} `json:"fields"`
+	}
+	u := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary,description,comment", j.siteURL, url.PathEscape(key))
+	if err := doJSON(ctx, j.http, http.MethodGet, u, nil, &issue, j.auth); err != nil {
+		return nil, fmt.Errorf("jira: %w", err)
+	}
+
+	out := &Issue{
+		Tracker: Jira,
+		Key:     issue.Key,
+		URL:     j.siteURL + "/browse/" + issue.Key,
+		Title:   issue.Fields.Summary,
+		Body:    issue.Fields.Description,
+	}
+	for _, c := range issue.Fields.Comment.Comments {
+		out.Comments = append(out.Comments, Comment{Author: c.Author.DisplayName, Body: c.Body})
+	}
+	return out, nil
+}
+
+func (j *jiraTracker) Comment(ctx context.Context, key, body string) error {
+	key, err := j.parse(key)
+	if err != nil {
+		return err
+	}
+	u := fmt.Sprintf("%s/rest/api/2/issue/%s/comment", j.siteURL, url.PathEscape(key))
+	if err := doJSON(ctx, j.http, http.MethodPost, u, map[string]string{"body": body}, nil, j.auth); err != nil {
+		return fmt.Errorf("jira: %w", err)
+	}
+	return nil
+}
+
+func (j *jiraTracker) auth(req *http.Request) {
+	if j.user != "" {
+		req.SetBasicAuth(j.user, j.token)
+	} else {
+		// Data Center personal access token
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+}
@@ -0,0 +1,161 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// linearAPIURL is the Linear GraphQL endpoint.
+const linearAPIURL = "https://api.linear.app/graphql"
+
+// linearURL matches issue URLs such as https://linear.app/team/issue/ENG-12/title.
+var linearURL = regexp.MustCompile(`^https://linear\.app/[\w-]+/issue/([A-Z][A-Z0-9]+-[0-9]+)`)
+
+// linearTracker reads issues through the Linear GraphQL API.
+type linearTracker struct {
+	apiKey string
+	http   *http.Client
+}
+
+func newLinear(apiKey string) *linearTracker {
+	return &linearTracker{apiKey: apiKey, http: &http.Client{Timeout: requestTimeout}}
+}
+
+func (l *linearTracker) Name() string { return Linear }
+
+func (l *linearTracker) Match(ref string) bool {
+	return linearURL.MatchString(ref) || keyPattern.MatchString(ref)
+}
+
+// parse returns the issue identifier of an identifier or issue URL.
+func (l *linearTracker) parse(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if m := linearURL.FindStringSubmatch(ref); m != nil {
+		return m[1], nil
+	}
+	if !keyPattern.MatchString(ref) {
+		return "", fmt.Errorf("linear: expected an issue identifier such as ENG-12, got %q", ref)
+	}
+	return ref, nil
+}
+
+const linearIssueQuery = `query($id: String!) {
+  issue(id: $id) {
+    id identifier title description url
+    comments { nodes { body createdAt user { name } } }
+  }
+}`
+
+type linearIssue struct {
+	ID          string `json:"id"`
+	Identifier  string `json:"identifier"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	Comments    struct {
+		Nodes []struct {
+			Body      string `json:"body"`
+			CreatedAt string `json:"createdAt"`
+			User      *struct {
+				Name string `json:"name"`
+			} `json:"user"`
+		} `json:"nodes"`
+	} `json:"comments"`
+}
+
+// fetch returns the raw issue, including its internal ID.
+func (l *linearTracker) fetch(ctx context.Context, identifier string) (*linearIssue, error) {
+	var data struct {
+		Issue *linearIssue `json:"issue"`
+	}
+	if err := l.query(ctx, linearIssueQuery, map[string]any{"id": identifier}, &data); err != nil {
+		return nil, err
+	}
+	if data.Issue == nil {
+		return nil, fmt.Errorf("linear: issue %s not found", identifier)
+	}
+	return data.Issue, nil
+}
+
+func (l *linearTracker) Fetch(ctx context.Context, ref string) (*Issue, error) {
+	identifier, err := l.parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	issue, err := l.fetch(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Issue{
+		Tracker: Linear,
+		Key:     issue.Identifier,
+		URL:     issue.URL,
+		Title:   issue.Title,
+		Body:    issue.Description,
+	}
+	// Comments come newest first
+	nodes := issue.Comments.Nodes
+	for i := len(nodes) - 1; i >= 0; i-- {
+		author := "unknown"
+		if nodes[i].User != nil {
+			author = nodes[i].User.Name
+		}
+		out.Comments = append(out.Comments, Comment{Author: author, Body: nodes[i].Body})
+	}
+	return out, nil
+}
+
+const linearCommentMutation = `mutation($issueId: String!, $body: String!) {
+  commentCreate(input: {issueId: $issueId, body: $body}) { success }
+}`
+
+func (l *linearTracker) Comment(ctx context.Context, key, body string) error {
+	identifier, err := l.parse(key)
+	if err != nil {
+		return err
+	}
+	issue, err := l.fetch(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	var data struct {
+		CommentCreate struct {
+			Success bool `json:"success"`
+		} `json:"commentCreate"`
+	}
+	vars := map[string]any{"issueId": issue.ID, "body": body}
+	if err := l.query(ctx, linearCommentMutation, vars, &data); err != nil {
+		return err
+	}
+	if !data.CommentCreate.Success {
+		return fmt.Errorf("linear: comment on %s was not created", identifier)
+	}
+	return nil
+}
+
+// query runs a GraphQL operation and decodes its data into out.
+func (l *linearTracker) query(ctx context.Context, query string, vars map[string]any, out any) error {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	body := map[string]any{"query": query, "variables": vars}
+	if err := doJSON(ctx, l.http, http.MethodPost, linearAPIURL, body, &resp, l.auth); err != nil {
+		return fmt.Errorf("linear: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("linear: %s", resp.Errors[0].Message)
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+func (l *linearTracker) auth(req *http.Request) {
+	req.Header.Set("Authorization", l.apiKey)
+}
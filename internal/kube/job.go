@@ -0,0 +1,288 @@
+// Package kube runs tasks as Kubernetes Jobs.
+//
+// Each task becomes a Job whose pod clones the repository from a git remote
+// reachable from the cluster, merges its dependency branches, runs codex in
+// non-interactive mode and pushes the result branch back. The backend talks
+// to the cluster through kubectl so no client libraries are required.
+package kube
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/task"
+)
+
+// Config configures the Kubernetes Job backend.
+type Config struct {
+	Kubectl      string // kubectl binary (default: "kubectl")
+	Namespace    string // namespace for task Jobs
+	Image        string // image with git and codex installed
+	CodexBin     string // codex path inside the image (default: "codex2")
+	Remote       string // git remote URL reachable from both host and cluster
+	BackoffLimit int    // pod retries before the task fails
+	CPU          string // CPU request/limit, e.g. "2"
+	Memory       string // memory request/limit, e.g. "4Gi"
+	SecretName   string // optional secret with codex/git credentials, exposed as env
+}
+
+// Runner executes tasks as Kubernetes Jobs. It implements task.Runner.
+type Runner struct {
+	cfg      Config
+	repoPath string
+}
+
+// NewRunner creates a Job runner for the repository at repoPath.
+func NewRunner(cfg Config, repoPath string) *Runner {
+	if cfg.Kubectl == "" {
+		cfg.Kubectl = "kubectl"
+	}
+	if cfg.CodexBin == "" {
+		cfg.CodexBin = "codex2"
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = "default"
+	}
+	return &Runner{cfg: cfg, repoPath: repoPath}
+}
+
+// jobNameInvalid matches characters not allowed in Kubernetes names.
+var jobNameInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// jobName derives a DNS-1123 compliant Job name from a branch name.
+func jobName(branch string) string {
+	name := jobNameInvalid.ReplaceAllString(strings.ToLower(branch), "-")
+	name = strings.Trim("codex-"+name, "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+// RunTask pushes the task's inputs to the remote, runs the Job, streams its
+// logs to output and fetches the resulting branch into the local repo.
+func (r *Runner) RunTask(ctx context.Context, t *task.Task, depBranches []string, prompt string, output func(string)) (string, error) {
+	base, err := r.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	t.BaseCommit = base
+
+	// The pod can only see what is on the remote
+	refspecs := []string{base + ":refs/heads/" + t.BranchName + "-base"}
+	for _, dep := range depBranches {
+		refspecs = append(refspecs, dep+":refs/heads/"+dep)
+	}
+	if _, err := r.git(ctx, append([]string{"push", "--force", r.cfg.Remote}, refspecs...)...); err != nil {
+		return "", fmt.Errorf("push task inputs: %w", err)
+	}
+
+	name := jobName(t.BranchName)
+	manifest, err := r.jobManifest(name, t, depBranches, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	// Replace any leftover Job from a previous attempt
+	_, _ = r.kubectl(ctx, "", "delete", "job", name, "--ignore-not-found", "--wait=true")
+	if _, err := r.kubectl(ctx, string(manifest), "apply", "-f", "-"); err != nil {
+		return "", fmt.Errorf("create job: %w", err)
+	}
+
+	go r.streamLogs(ctx, name, output)
+
+	if err := r.waitForJob(ctx, name, output); err != nil {
+		return "", err
+	}
+
+	// Bring the pushed result branch back for the merge phase
+	if _, err := r.git(ctx, "fetch", r.cfg.Remote, "+refs/heads/"+t.BranchName+":refs/heads/"+t.BranchName); err != nil {
+		return "", fmt.Errorf("fetch result branch: %w", err)
+	}
+	head, err := r.git(ctx, "rev-parse", t.BranchName)
+	if err != nil {
+		return "", err
+	}
+	if head == base {
+		return "", nil
+	}
+	return head, nil
+}
+
+// waitForJob polls the Job until it succeeds or exhausts its retries.
+// Pod failures below the backoff limit are reported as retries.
+func (r *Runner) waitForJob(ctx context.Context, name string, output func(string)) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	lastFailed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			_, _ = r.kubectl(context.Background(), "", "delete", "job", name, "--ignore-not-found")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		out, err := r.kubectl(ctx, "", "get", "job", name, "-o", "json")
+		if err != nil {
+			continue
+		}
+		var job struct {
+			Status struct {
+				Succeeded  int `json:"succeeded"`
+				Failed     int `json:"failed"`
+				Conditions []struct {
+					Type    string `json:"type"`
+					Status  string `json:"status"`
+					Message string `json:"message"`
+				} `json:"conditions"`
+			} `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(out), &job); err != nil {
+			continue
+		}
+
+		if job.Status.Failed > lastFailed {
+			lastFailed = job.Status.Failed
+			output(fmt.Sprintf("pod failed, retry %d/%d", lastFailed, r.cfg.BackoffLimit))
+		}
+		if job.Status.Succeeded > 0 {
+			return nil
+		}
+		for _, c := range job.Status.Conditions {
+			if c.Type == "Failed" && c.Status == "True" {
+				return fmt.Errorf("job %s failed: %s", name, c.Message)
+			}
+		}
+	}
+}
+
+// streamLogs forwards the Job's log lines to output until it finishes.
+func (r *Runner) streamLogs(ctx context.Context, name string, output func(string)) {
+	// Logs are only available once the pod is running
+	for attempt := 0; attempt < 60; attempt++ {
+		cmd := exec.CommandContext(ctx, r.cfg.Kubectl, "-n", r.cfg.Namespace, "logs", "-f", "job/"+name)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			return
+		}
+		scanner := bufio.NewScanner(stdout)
+		lines := 0
+		for scanner.Scan() {
+			output(scanner.Text())
+			lines++
+		}
+		if cmd.Wait() == nil || lines > 0 || ctx.Err() != nil {
+			return
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// jobScript is the pod entrypoint. Inputs arrive as environment variables.
+const jobScript = `set -eu
+git clone --quiet --no-checkout "$REPO_URL" /workspace
+cd /workspace
+git checkout --quiet -b "$BRANCH" "origin/$BRANCH-base"
+for dep in $DEP_BRANCHES; do
+  git merge --no-ff -m "Merge $dep" "origin/$dep"
+done
+"$CODEX_BIN" exec --full-auto --cd /workspace "$PROMPT"
+git add -A
+git -c user.name=codex-agent-team -c user.email=codex-agent-team@localhost \
+  commit --quiet -m "Task $TASK_ID: $TASK_TITLE" || true
+git push --force origin "HEAD:refs/heads/$BRANCH"
+`
+
+// jobManifest renders the Job manifest as JSON.
+func (r *Runner) jobManifest(name string, t *task.Task, depBranches []string, prompt string) ([]byte, error) {
+	env := []map[string]any{
+		{"name": "REPO_URL", "value": r.cfg.Remote},
+		{"name": "BRANCH", "value": t.BranchName},
+		{"name": "DEP_BRANCHES", "value": strings.Join(depBranches, " ")},
+		{"name": "CODEX_BIN", "value": r.cfg.CodexBin},
+		{"name": "PROMPT", "value": prompt},
+		{"name": "TASK_ID", "value": t.ID},
+		{"name": "TASK_TITLE", "value": t.Title},
+	}
+
+	container := map[string]any{
+		"name":    "codex",
+		"image":   r.cfg.Image,
+		"command": []string{"/bin/sh", "-c", jobScript},
+		"env":     env,
+	}
+	if r.cfg.SecretName != "" {
+		container["envFrom"] = []map[string]any{
+			{"secretRef": map[string]string{"name": r.cfg.SecretName}},
+		}
+	}
+	if r.cfg.CPU != "" || r.cfg.Memory != "" {
+		limits := map[string]string{}
+		if r.cfg.CPU != "" {
+			limits["cpu"] = r.cfg.CPU
+		}
+		if r.cfg.Memory != "" {
+			limits["memory"] = r.cfg.Memory
+		}
+		container["resources"] = map[string]any{"requests": limits, "limits": limits}
+	}
+
+	job := map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": r.cfg.Namespace,
+			"labels": map[string]string{
+				"app.kubernetes.io/managed-by": "codex-agent-team",
+				"codex-agent-team/task":        jobNameInvalid.ReplaceAllString(strings.ToLower(t.ID), "-"),
+			},
+		},
+		"spec": map[string]any{
+			"backoffLimit":            r.cfg.BackoffLimit,
+			"ttlSecondsAfterFinished": 3600,
+			"template": map[string]any{
+				"spec": map[string]any{
+					"restartPolicy": "Never",
+					"containers":    []any{container},
+				},
+			},
+		},
+	}
+	return json.Marshal(job)
+}
+
+// kubectl runs a kubectl command in the configured namespace.
+func (r *Runner) kubectl(ctx context.Context, stdin string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, r.cfg.Kubectl, append([]string{"-n", r.cfg.Namespace}, args...)...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("kubectl %s: %w: %s", args[0], err, string(output))
+	}
+	return string(output), nil
+}
+
+// git runs a git command in the local repository.
+func (r *Runner) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
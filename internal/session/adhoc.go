@@ -0,0 +1,195 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/codexrpc"
+)
+
+// ErrAgentBusy is returned when a message is sent to an ad-hoc agent that
+// is still working on the previous one.
+var ErrAgentBusy = errors.New("agent is busy")
+
+// ErrInvalidAgentConfig is returned for an ad-hoc agent that cannot be
+// spawned as described.
+var ErrInvalidAgentConfig = errors.New("invalid agent config")
+
+// AdHocConfig describes a standalone agent.
+type AdHocConfig struct {
+	Role         agent.Role `json:"role,omitempty"`    // default: worker
+	Cwd          string     `json:"cwd"`               // repository or worktree to work in
+	Sandbox      string     `json:"sandbox,omitempty"` // default: by role
+	Instructions string     `json:"instructions,omitempty"`
+}
+
+// AdHocAgent is a standalone agent spawned outside any session, for quick
+// one-off investigations without a task graph.
+type AdHocAgent struct {
+	ID        string           `json:"id"`
+	Role      agent.Role       `json:"role"`
+	Cwd       string           `json:"cwd"`
+	Sandbox   string           `json:"sandbox,omitempty"`
+	TenantID  string           `json:"tenantId,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+	State     agent.AgentState `json:"state"`
+	Output    string           `json:"output,omitempty"` // output of the last message
+	Error     string           `json:"error,omitempty"`  // why the last message failed
+
+	mu   sync.Mutex
+	busy bool
+}
+
+// SpawnAgent starts a standalone agent. It runs on the manager context, so
+// it outlives the request that spawned it until StopAgent or Shutdown.
+func (m *Manager) SpawnAgent(cfg AdHocConfig, tenantID string) (*AdHocAgent, error) {
+	if cfg.Cwd == "" {
+		return nil, fmt.Errorf("%w: cwd is required", ErrInvalidAgentConfig)
+	}
+	switch cfg.Role {
+	case "":
+		cfg.Role = agent.RoleWorker
+	case agent.RoleWorker, agent.RoleOrchestrator, agent.RoleMerger:
+	default:
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidAgentConfig, cfg.Role)
+	}
+	switch cfg.Sandbox {
+	case "", codexrpc.SandboxReadOnly, codexrpc.SandboxWorkspaceWrite, codexrpc.SandboxDangerFullAccess:
+	default:
+		return nil, fmt.Errorf("%w: unknown sandbox %q", ErrInvalidAgentConfig, cfg.Sandbox)
+	}
+
+	a := &AdHocAgent{
+		ID:        "adhoc-" + agent.GenerateID(),
+		Role:      cfg.Role,
+		Cwd:       cfg.Cwd,
+		Sandbox:   cfg.Sandbox,
+		TenantID:  tenantID,
+		CreatedAt: time.Now(),
+	}
+	_, err := m.agentMgr.SpawnAgent(m.ctx, agent.AgentConfig{
+		ID:                    a.ID,
+		Role:                  cfg.Role,
+		Cwd:                   cfg.Cwd,
+		SandboxMode:           cfg.Sandbox,
+		DeveloperInstructions: cfg.Instructions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.adhocMu.Lock()
+	m.adhoc[a.ID] = a
+	m.adhocMu.Unlock()
+	return m.Agent(a.ID)
+}
+
+// Agent returns a snapshot of an ad-hoc agent.
+func (m *Manager) Agent(id string) (*AdHocAgent, error) {
+	m.adhocMu.Lock()
+	a, ok := m.adhoc[id]
+	m.adhocMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", id)
+	}
+	return a.snapshot(m.agentMgr), nil
+}
+
+// Agents returns snapshots of the ad-hoc agents, oldest first.
+func (m *Manager) Agents() []*AdHocAgent {
+	m.adhocMu.Lock()
+	agents := make([]*AdHocAgent, 0, len(m.adhoc))
+	for _, a := range m.adhoc {
+		agents = append(agents, a.snapshot(m.agentMgr))
+	}
+	m.adhocMu.Unlock()
+	sort.Slice(agents, func(i, k int) bool { return agents[i].CreatedAt.Before(agents[k].CreatedAt) })
+	return agents
+}
+
+// MessageAgent sends message to an ad-hoc agent as a new turn and returns
+// without waiting for it. The reply is available from Agent once the
+// agent's state is no longer running.
+func (m *Manager) MessageAgent(id, message string) error {
+	m.adhocMu.Lock()
+	a, ok := m.adhoc[id]
+	m.adhocMu.Unlock()
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+
+	a.mu.Lock()
+	if a.busy {
+		a.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrAgentBusy, id)
+	}
+	a.busy = true
+	a.Error = ""
+	a.mu.Unlock()
+
+	turn, err := m.agentMgr.SendTask(m.ctx, id, "", message)
+	if err != nil {
+		a.finish(err)
+		return err
+	}
+	go func() {
+		a.finish(m.agentMgr.WaitForCompletion(m.ctx, turn))
+	}()
+	return nil
+}
+
+// finish records the outcome of the message in progress.
+func (a *AdHocAgent) finish(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.busy = false
+	if err != nil {
+		a.Error = err.Error()
+	}
+}
+
+// StopAgent stops an ad-hoc agent and forgets it.
+func (m *Manager) StopAgent(id string) error {
+	m.adhocMu.Lock()
+	_, ok := m.adhoc[id]
+	delete(m.adhoc, id)
+	m.adhocMu.Unlock()
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	return m.agentMgr.StopAgent(id)
+}
+
+// stopAgents stops every ad-hoc agent.
+func (m *Manager) stopAgents() {
+	m.adhocMu.Lock()
+	ids := make([]string, 0, len(m.adhoc))
+	for id := range m.adhoc {
+		ids = append(ids, id)
+	}
+	m.adhocMu.Unlock()
+	for _, id := range ids {
+		_ = m.StopAgent(id)
+	}
+}
+
+// snapshot returns a copy of a with its current state and output.
+func (a *AdHocAgent) snapshot(agentMgr *agent.Manager) *AdHocAgent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return &AdHocAgent{
+		ID:        a.ID,
+		Role:      a.Role,
+		Cwd:       a.Cwd,
+		Sandbox:   a.Sandbox,
+		TenantID:  a.TenantID,
+		CreatedAt: a.CreatedAt,
+		State:     agentMgr.GetState(a.ID),
+		Output:    agentMgr.GetOutput(a.ID),
+		Error:     a.Error,
+	}
+}
@@ -0,0 +1,225 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codex-agent-team/internal/task"
+)
+
+// maxCollectBytes bounds the files collected from one task.
+const maxCollectBytes = 512 << 20
+
+// ErrArtifactNotFound is returned for a collected file that does not exist.
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// ArtifactStore keeps files collected from task worktrees, under
+// <dir>/<session>/<task>/<path>, so they survive worktree cleanup.
+type ArtifactStore struct {
+	dir string
+}
+
+// NewArtifactStore creates an artifact store in dataDir.
+func NewArtifactStore(dataDir string) (*ArtifactStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	return &ArtifactStore{dir: dataDir}, nil
+}
+
+// collector returns the task.Collector storing files of a session.
+func (s *ArtifactStore) collector(sessionID string) task.Collector {
+	return &artifactCollector{store: s, sessionID: sessionID}
+}
+
+// Open opens a collected file of a task.
+func (s *ArtifactStore) Open(sessionID, taskID, rel string) (*os.File, error) {
+	p, ok := s.path(sessionID, taskID, rel)
+	if !ok {
+		return nil, ErrArtifactNotFound
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrArtifactNotFound
+	}
+	return f, err
+}
+
+// Delete removes every file collected for a session.
+func (s *ArtifactStore) Delete(sessionID string) error {
+	return os.RemoveAll(filepath.Join(s.dir, filepath.Base(sessionID)))
+}
+
+// taskDir returns the directory of a task's collected files, refusing IDs
+// that would leave the store.
+func (s *ArtifactStore) taskDir(sessionID, taskID string) (string, bool) {
+	for _, id := range []string{sessionID, taskID} {
+		if id == "" || id == "." || id == ".." || id != filepath.Base(id) {
+			return "", false
+		}
+	}
+	return filepath.Join(s.dir, sessionID, taskID), true
+}
+
+// path resolves a collected file, refusing paths outside the task's
+// directory.
+func (s *ArtifactStore) path(sessionID, taskID, rel string) (string, bool) {
+	dir, ok := s.taskDir(sessionID, taskID)
+	clean := path.Clean("/" + rel)[1:]
+	if !ok || clean == "" {
+		return "", false
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), true
+}
+
+// artifactCollector copies files of a session's worktrees into the store.
+type artifactCollector struct {
+	store     *ArtifactStore
+	sessionID string
+}
+
+// Collect implements task.Collector. Files of an earlier attempt of the
+// task are replaced.
+func (c *artifactCollector) Collect(taskID, dir string, patterns []string) ([]task.CollectedFile, error) {
+	dest, ok := c.store.taskDir(c.sessionID, taskID)
+	if !ok {
+		return nil, fmt.Errorf("invalid task ID %q", taskID)
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, err
+	}
+
+	var files []task.CollectedFile
+	var skipped []string
+	var total int64
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matchAny(patterns, rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if total+info.Size() > maxCollectBytes {
+			skipped = append(skipped, rel)
+			return nil
+		}
+		if err := copyFile(p, filepath.Join(dest, filepath.FromSlash(rel))); err != nil {
+			return fmt.Errorf("copy %s: %w", rel, err)
+		}
+		total += info.Size()
+		files = append(files, task.CollectedFile{Path: rel, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return files, err
+	}
+	if len(skipped) > 0 {
+		return files, fmt.Errorf("skipped %s: over the %d MB limit", strings.Join(skipped, ", "), maxCollectBytes>>20)
+	}
+	return files, nil
+}
+
+// matchAny reports whether rel matches one of the globs. Besides the
+// path.Match syntax, a "**" segment matches any number of directories.
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if matchGlob(strings.Split(path.Clean(p), "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against pattern segments.
+func matchGlob(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchGlob(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// copyFile copies the regular file src to dst, creating dst's directory.
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CollectedArtifact is a collected file of one of a session's tasks.
+type CollectedArtifact struct {
+	TaskID string `json:"taskId"`
+	task.CollectedFile
+}
+
+// CollectedArtifacts lists the files collected from the session's tasks,
+// by task ID and path.
+func (s *Session) CollectedArtifacts() []CollectedArtifact {
+	out := []CollectedArtifact{}
+	for _, t := range s.DAG.Snapshot() {
+		for _, f := range t.Collected {
+			out = append(out, CollectedArtifact{TaskID: t.ID, CollectedFile: f})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TaskID != out[j].TaskID {
+			return out[i].TaskID < out[j].TaskID
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// OpenArtifact opens a file collected from one of the session's tasks.
+func (s *Session) OpenArtifact(taskID, rel string) (*os.File, error) {
+	if s.mgr == nil || s.mgr.artifacts == nil {
+		return nil, ErrArtifactNotFound
+	}
+	return s.mgr.artifacts.Open(s.ID, taskID, rel)
+}
@@ -0,0 +1,176 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/events"
+)
+
+// ErrAlreadyRunning is returned when triggering a schedule or watch whose
+// previous run has not finished.
+var ErrAlreadyRunning = errors.New("previous run has not finished")
+
+// Kinds of automated runs.
+const (
+	AutoRunSchedule = "schedule"
+	AutoRunWatch    = "watch"
+)
+
+// TypeAutoRun is the session event published when a scheduled or
+// watch-triggered run finishes. Data is the AutoRun.
+const TypeAutoRun = "autorun.finished"
+
+// RunSpec describes the session an automated run creates. The session's
+// task is either UserTask, decomposed on each run, or the template named
+// Template instantiated with Params.
+type RunSpec struct {
+	RepoPath string            `json:"repoPath"`
+	UserTask string            `json:"userTask,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+
+	Confirm   bool `json:"confirm,omitempty"`   // run plans exceeding the execution gate
+	AutoStash bool `json:"autoStash,omitempty"` // allow a dirty checkout
+	NoMerge   bool `json:"noMerge,omitempty"`   // leave the results unmerged for review
+	Priority  int  `json:"priority,omitempty"`  // scheduling priority of the session
+
+	Notify RunNotify `json:"notify"`
+}
+
+// RunNotify configures how the outcome of an automated run is reported.
+type RunNotify struct {
+	Webhook      string `json:"webhook,omitempty"`      // receives the autorun.finished event as JSON
+	OnlyFailures bool   `json:"onlyFailures,omitempty"` // skip successful runs
+}
+
+// AutoRun is the outcome of one run of a schedule or watch.
+type AutoRun struct {
+	Kind       string        `json:"kind"` // AutoRunSchedule or AutoRunWatch
+	Name       string        `json:"name"`
+	Trigger    string        `json:"trigger"` // what started the run, e.g. "cron" or the changed paths
+	SessionID  string        `json:"sessionId,omitempty"`
+	Status     SessionStatus `json:"status,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	StartedAt  time.Time     `json:"startedAt"`
+	FinishedAt *time.Time    `json:"finishedAt,omitempty"`
+}
+
+// validate checks that the spec names exactly one task source.
+func (spec *RunSpec) validate(name string) error {
+	if (spec.UserTask == "") == (spec.Template == "") {
+		return fmt.Errorf("%s: exactly one of userTask and template must be set", name)
+	}
+	if spec.RepoPath == "" {
+		return fmt.Errorf("%s: repoPath is required", name)
+	}
+	return nil
+}
+
+// startAutoRun runs spec in the background. At most one run per kind and
+// name is in progress; record is called with the finished run.
+func (m *Manager) startAutoRun(kind, name, trigger string, spec RunSpec, record func(AutoRun)) (AutoRun, error) {
+	key := kind + "/" + name
+	m.autoRunMu.Lock()
+	if m.autoRuns[key] {
+		m.autoRunMu.Unlock()
+		return AutoRun{}, ErrAlreadyRunning
+	}
+	m.autoRuns[key] = true
+	m.autoRunMu.Unlock()
+
+	run := AutoRun{Kind: kind, Name: name, Trigger: trigger, StartedAt: time.Now()}
+	go func() {
+		defer func() {
+			m.autoRunMu.Lock()
+			delete(m.autoRuns, key)
+			m.autoRunMu.Unlock()
+		}()
+		run := m.runSpec(m.ctx, spec, run)
+		now := time.Now()
+		run.FinishedAt = &now
+		record(run)
+		m.finishAutoRun(spec, run)
+	}()
+	return run, nil
+}
+
+// runSpec creates the session of an automated run and drives it through
+// decomposition, execution and merge.
+func (m *Manager) runSpec(ctx context.Context, spec RunSpec, run AutoRun) AutoRun {
+	fail := func(err error) AutoRun {
+		run.Error = err.Error()
+		return run
+	}
+
+	if err := m.CheckCodex(ctx, agent.Runtime{}); err != nil {
+		return fail(err)
+	}
+
+	var sess *Session
+	var err error
+	if spec.Template != "" {
+		if m.templates == nil {
+			return fail(fmt.Errorf("template store unavailable"))
+		}
+		var tmpl *Template
+		if tmpl, err = m.templates.Get(spec.Template); err != nil {
+			return fail(fmt.Errorf("template %s: %w", spec.Template, err))
+		}
+		sess, err = m.CreateFromTemplate(ctx, tmpl, spec.Params, spec.RepoPath)
+	} else {
+		sess, err = m.CreateWithPath(ctx, spec.UserTask, spec.RepoPath)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	run.SessionID = sess.ID
+	if spec.Priority != 0 {
+		sess.SetPriority(spec.Priority)
+	}
+	sess.notify("Started by %s %s (%s)", run.Kind, run.Name, run.Trigger)
+
+	if spec.UserTask != "" {
+		if _, err := sess.Decompose(ctx, false); err != nil {
+			run.Status = sess.Status
+			return fail(err)
+		}
+	}
+
+	if spec.Confirm {
+		sess.Confirm()
+	}
+	opts := RunOptions{AutoStash: spec.AutoStash}
+	err = sess.Execute(ctx, opts)
+	if err == nil && !spec.NoMerge {
+		err = sess.Merge(ctx, opts)
+	}
+	run.Status = sess.Status
+	if err != nil {
+		return fail(err)
+	}
+	return run
+}
+
+// finishAutoRun publishes a finished run and notifies about it.
+func (m *Manager) finishAutoRun(spec RunSpec, run AutoRun) {
+	if run.Error != "" {
+		log.Printf("%s %s: run failed: %s", run.Kind, run.Name, run.Error)
+	}
+
+	ev := events.Event{
+		Source:    events.SourceSession,
+		Type:      TypeAutoRun,
+		SessionID: run.SessionID,
+		Data:      run,
+	}
+	m.bus.Publish(ev)
+	if spec.Notify.Webhook != "" && (run.Error != "" || !spec.Notify.OnlyFailures) {
+		ev.Time = *run.FinishedAt
+		events.WebhookSink(spec.Notify.Webhook).Handle(ev)
+	}
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"codex-agent-team/internal/agent"
+)
+
+// DecompositionCache stores orchestrator results keyed by repo HEAD,
+// user task and orchestrator prompt version.
+type DecompositionCache struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewDecompositionCache creates a new decomposition cache.
+func NewDecompositionCache(dataDir string) (*DecompositionCache, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	return &DecompositionCache{dir: dataDir}, nil
+}
+
+// decompositionKey hashes the inputs that determine a decomposition.
+func decompositionKey(headCommit, userTask string) string {
+	h := sha256.New()
+	h.Write([]byte(headCommit))
+	h.Write([]byte{0})
+	h.Write([]byte(userTask))
+	h.Write([]byte{0})
+	h.Write([]byte(agent.OrchestratorPromptVersion))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached decomposition for the given inputs, if any.
+func (c *DecompositionCache) Get(headCommit, userTask string) (*agent.TaskDecomposition, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	bytes, err := os.ReadFile(c.entryPath(decompositionKey(headCommit, userTask)))
+	if err != nil {
+		return nil, false
+	}
+	var decomp agent.TaskDecomposition
+	if err := json.Unmarshal(bytes, &decomp); err != nil {
+		return nil, false
+	}
+	return &decomp, true
+}
+
+// Put stores a decomposition for the given inputs.
+func (c *DecompositionCache) Put(headCommit, userTask string, decomp *agent.TaskDecomposition) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bytes, err := json.MarshalIndent(decomp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.entryPath(decompositionKey(headCommit, userTask)), bytes, 0644)
+}
+
+// entryPath returns the file path for a cache key.
+func (c *DecompositionCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
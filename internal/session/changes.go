@@ -0,0 +1,25 @@
+package session
+
+import (
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/worktree"
+)
+
+// refreshChanges recomputes the lines changed by the session's completed
+// tasks.
+func (s *Session) refreshChanges() {
+	var total *worktree.ChangeStat
+	for _, t := range s.DAG.Snapshot() {
+		if t.Status != task.StatusCompleted || t.Changes == nil {
+			continue
+		}
+		if total == nil {
+			total = &worktree.ChangeStat{}
+		}
+		total.Add(*t.Changes)
+	}
+
+	s.mu.Lock()
+	s.Changes = total
+	s.mu.Unlock()
+}
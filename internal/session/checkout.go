@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoTargetBranch is returned when the repository has no branch checked
+// out and no target branch is configured to merge into.
+var ErrNoTargetBranch = errors.New("no branch is checked out and no target branch is configured")
+
+// checkout is the working tree the merge phase operates in.
+type checkout struct {
+	Dir    string // primary checkout or integration worktree
+	Branch string // branch merged into, empty when unknown
+	Shared bool   // Dir is the primary checkout, which may hold local changes
+}
+
+// mergeCheckout returns the working tree merges go into. That is the
+// primary checkout when it has the target branch checked out; for a bare
+// repository, a detached HEAD or another configured target branch it is an
+// integration worktree of the target branch, so the server never needs a
+// primary working checkout.
+func (s *Session) mergeCheckout(ctx context.Context) (checkout, error) {
+	target, shared := s.mergeTarget(ctx)
+	if shared {
+		return checkout{Dir: s.RepoPath, Branch: target, Shared: true}, nil
+	}
+	if target == "" {
+		return checkout{}, ErrNoTargetBranch
+	}
+	dir, err := s.worktreeMgr.IntegrationWorktree(ctx, target)
+	if err != nil {
+		return checkout{}, fmt.Errorf("integration worktree: %w", err)
+	}
+	return checkout{Dir: dir, Branch: target}, nil
+}
+
+// mergeTarget returns the branch merges go into and whether the primary
+// checkout has it checked out.
+func (s *Session) mergeTarget(ctx context.Context) (string, bool) {
+	if s.worktreeMgr == nil {
+		return "", true
+	}
+	current, _ := s.worktreeMgr.CurrentBranch(ctx)
+	target := current
+	if s.mgr != nil && s.mgr.opts.TargetBranch != "" {
+		target = s.mgr.opts.TargetBranch
+	}
+	if s.worktreeMgr.IsBare(ctx) {
+		return target, false
+	}
+	return target, current != "" && target == current
+}
+
+// readDir returns a directory with the repository's files for agents that
+// only read them: the repository itself unless it is bare.
+func (s *Session) readDir(ctx context.Context) string {
+	if s.worktreeMgr == nil || !s.worktreeMgr.IsBare(ctx) {
+		return s.RepoPath
+	}
+	co, err := s.mergeCheckout(ctx)
+	if err != nil {
+		s.notify("bare repository: no checkout to read files from: %v", err)
+		return s.RepoPath
+	}
+	return co.Dir
+}
+
+// pushMerged pushes the merged target branch to the configured remote.
+func (s *Session) pushMerged(ctx context.Context, co checkout) error {
+	if s.mgr == nil || s.mgr.opts.PushRemote == "" {
+		return nil
+	}
+	if co.Branch == "" {
+		return ErrNoTargetBranch
+	}
+	if err := s.worktreeMgr.PushBranch(ctx, s.mgr.opts.PushRemote, co.Branch); err != nil {
+		return err
+	}
+	s.notify("merge: pushed %s to %s", co.Branch, s.mgr.opts.PushRemote)
+	return nil
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/codexrpc"
+)
+
+// Codex status checks start an app-server, so their results are reused.
+const (
+	codexCheckTimeout = 20 * time.Second
+	codexStatusMaxAge = 5 * time.Minute // before a session is created
+	codexModelsMaxAge = 5 * time.Minute // model lists
+)
+
+// ErrCodexUnavailable is returned when an app-server cannot be queried.
+var ErrCodexUnavailable = errors.New("codex app-server unavailable")
+
+// ModelList is the models offered to the agents of one role.
+type ModelList struct {
+	Role          agent.Role       `json:"role"`
+	Binary        string           `json:"binary"`
+	ModelProvider string           `json:"modelProvider,omitempty"` // empty for the codex config default
+	Models        []codexrpc.Model `json:"models"`
+}
+
+// Models lists the models available to each role, as reported by the
+// app-server of the role's codex binary. rt selects a registered binary
+// and provider for every role, as for a session.
+func (m *Manager) Models(ctx context.Context, roles []agent.Role, rt agent.Runtime) ([]ModelList, error) {
+	lists := make([]ModelList, 0, len(roles))
+	for _, role := range roles {
+		bin := rt.Binary
+		if bin == "" {
+			bin = m.agentMgr.RoleBinary(role)
+		}
+		status := m.CodexStatus(ctx, bin, codexModelsMaxAge)
+		if status.Error != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrCodexUnavailable, bin, status.Error)
+		}
+		lists = append(lists, ModelList{
+			Role:          role,
+			Binary:        bin,
+			ModelProvider: rt.ModelProvider,
+			Models:        append([]codexrpc.Model{}, status.Models...),
+		})
+	}
+	return lists, nil
+}
+
+// CodexStatus returns the login state and models of a codex binary, ""
+// for the default one, checking again when the last result is older than
+// maxAge.
+func (m *Manager) CodexStatus(ctx context.Context, bin string, maxAge time.Duration) agent.CodexStatus {
+	ctx, cancel := context.WithTimeout(ctx, codexCheckTimeout)
+	defer cancel()
+	return m.agentMgr.CodexStatus(ctx, bin, maxAge)
+}
+
+// CodexStatuses checks the default codex binary and every registered one.
+func (m *Manager) CodexStatuses(ctx context.Context, maxAge time.Duration) []agent.CodexStatus {
+	names := make([]string, 0, len(m.opts.CodexBinaries))
+	for name := range m.opts.CodexBinaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := []agent.CodexStatus{m.CodexStatus(ctx, "", maxAge)}
+	for _, name := range names {
+		statuses = append(statuses, m.CodexStatus(ctx, m.opts.CodexBinaries[name], maxAge))
+	}
+	return statuses
+}
+
+// CheckCodex fails with agent.ErrNotAuthenticated when the binary of rt is
+// known to have no login, so sessions fail before their first turn.
+func (m *Manager) CheckCodex(ctx context.Context, rt agent.Runtime) error {
+	status := m.CodexStatus(ctx, rt.Binary, codexStatusMaxAge)
+	if status.Err() != nil {
+		// A login since the last check takes effect at once
+		status = m.CodexStatus(ctx, rt.Binary, 0)
+	}
+	return status.Err()
+}
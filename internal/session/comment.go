@@ -0,0 +1,14 @@
+package session
+
+import "codex-agent-team/internal/task"
+
+// AddTaskComment leaves a human comment on a task and persists it. The
+// next agent working on the task, such as a resumed attempt, sees it.
+func (s *Session) AddTaskComment(taskID, author, body string) (task.Comment, error) {
+	c, err := s.DAG.AddComment(taskID, author, body)
+	if err != nil {
+		return c, err
+	}
+	s.save()
+	return c, nil
+}
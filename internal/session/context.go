@@ -0,0 +1,79 @@
+package session
+
+import "context"
+
+// Context returns the context long-running operations of the session run
+// on. Unlike a request context it survives the client that started the
+// operation, and is cancelled only when the session is archived or
+// deleted, or the manager shuts down.
+func (s *Session) Context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.runCtx == nil {
+		parent := context.Background()
+		if s.mgr != nil {
+			parent = s.mgr.ctx
+		}
+		s.runCtx, s.cancelRun = context.WithCancel(parent)
+	}
+	return s.runCtx
+}
+
+// cancelRuns cancels the operations running on the session context.
+func (s *Session) cancelRuns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancelRun != nil {
+		s.cancelRun()
+	}
+}
+
+// Shutdown cancels the background operations of every session and stops
+// the ad-hoc agents.
+func (m *Manager) Shutdown() {
+	m.stop()
+	m.stopAgents()
+}
+
+// DecomposeAsync starts decomposition on the session context and calls
+// done with the result. The status transition happens before it returns,
+// so a session that cannot be decomposed is reported synchronously.
+func (s *Session) DecomposeAsync(force bool, done func(*DecomposeResult, error)) (Job, error) {
+	job, err := s.beginJob("decompose")
+	if err != nil {
+		return Job{}, err
+	}
+	if err := s.transition(StatusDecomposing); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+	go func() {
+		result, err := s.decompose(s.Context(), force)
+		s.endJob(job, err)
+		done(result, err)
+	}()
+	return s.snapshot(job), nil
+}
+
+// MergeAsync validates that the session may merge, then merges on the
+// session context in the background and calls done with the result.
+func (s *Session) MergeAsync(ctx context.Context, opts RunOptions, done func(error)) (Job, error) {
+	job, err := s.beginJob("merge")
+	if err != nil {
+		return Job{}, err
+	}
+	if err := s.requireStatus(StatusMerging); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+	if err := s.checkClean(ctx, opts); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+	go func() {
+		err := s.merge(s.Context(), opts)
+		s.endJob(job, err)
+		done(err)
+	}()
+	return s.snapshot(job), nil
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/task"
+)
+
+// ErrConfirmationRequired is returned when execution is started without
+// confirming an estimate that exceeds the execution gate.
+var ErrConfirmationRequired = errors.New("execution estimate exceeds the configured thresholds; confirm it first")
+
+// ExecutionGate sets the thresholds above which a run must be confirmed
+// before it executes. Zero thresholds are not enforced.
+type ExecutionGate struct {
+	MaxTasks        int     // tasks to run
+	MaxAgentMinutes float64 // summed task estimates
+	MaxTokens       int64   // rough token cost
+	TokensPerMinute int64   // token cost of one agent-minute (default 20000)
+}
+
+// Defaults used when the plan or the gate leaves them open.
+const (
+	defaultTokensPerMinute = 20000
+	defaultTaskMinutes     = 15 // tasks without a usable estimate
+)
+
+// Estimate is the expected cost of executing a session's plan.
+type Estimate struct {
+	Tasks        int      `json:"tasks"`
+	AgentMinutes float64  `json:"agentMinutes"`
+	Tokens       int64    `json:"tokens"`
+	Unestimated  int      `json:"unestimated"`       // tasks counted at the default duration
+	Exceeds      []string `json:"exceeds,omitempty"` // thresholds the estimate is above
+	NeedsConfirm bool     `json:"needsConfirmation"` // execution is gated until Confirm
+	Confirmed    bool     `json:"confirmed"`         // an estimate at least this large was confirmed
+}
+
+// Estimate computes the cost of running the session's incomplete tasks
+// from their estimated times.
+func (s *Session) Estimate() Estimate {
+	gate := ExecutionGate{}
+	if s.mgr != nil {
+		gate = s.mgr.opts.Gate
+	}
+	perMinute := gate.TokensPerMinute
+	if perMinute <= 0 {
+		perMinute = defaultTokensPerMinute
+	}
+
+	var est Estimate
+	for _, t := range s.DAG.GetTasks() {
+		if t.Status == task.StatusCompleted {
+			continue
+		}
+		est.Tasks++
+		minutes := float64(defaultTaskMinutes)
+		if d, ok := agent.ParseEstimate(t.EstimatedTime); ok {
+			minutes = d.Minutes()
+		} else {
+			est.Unestimated++
+		}
+		est.AgentMinutes += minutes
+	}
+	est.Tokens = int64(est.AgentMinutes * float64(perMinute))
+
+	if gate.MaxTasks > 0 && est.Tasks > gate.MaxTasks {
+		est.Exceeds = append(est.Exceeds, fmt.Sprintf("%d tasks > %d", est.Tasks, gate.MaxTasks))
+	}
+	if gate.MaxAgentMinutes > 0 && est.AgentMinutes > gate.MaxAgentMinutes {
+		est.Exceeds = append(est.Exceeds, fmt.Sprintf("%.0f agent-minutes > %.0f", est.AgentMinutes, gate.MaxAgentMinutes))
+	}
+	if gate.MaxTokens > 0 && est.Tokens > gate.MaxTokens {
+		est.Exceeds = append(est.Exceeds, fmt.Sprintf("~%d tokens > %d", est.Tokens, gate.MaxTokens))
+	}
+	est.NeedsConfirm = len(est.Exceeds) > 0
+
+	s.mu.RLock()
+	confirmed := s.confirmed
+	s.mu.RUnlock()
+	est.Confirmed = confirmed != nil && est.Tasks <= confirmed.Tasks && est.AgentMinutes <= confirmed.AgentMinutes
+	return est
+}
+
+// Confirm approves executing the current plan at its current estimate.
+// A later plan that is larger needs a new confirmation.
+func (s *Session) Confirm() Estimate {
+	est := s.Estimate()
+	s.mu.Lock()
+	s.confirmed = &est
+	s.mu.Unlock()
+	est.Confirmed = true
+	return est
+}
+
+// checkConfirmed gates execution of an estimate above the thresholds.
+func (s *Session) checkConfirmed() error {
+	est := s.Estimate()
+	if est.NeedsConfirm && !est.Confirmed {
+		return fmt.Errorf("%w (%s)", ErrConfirmationRequired, strings.Join(est.Exceeds, ", "))
+	}
+	return nil
+}
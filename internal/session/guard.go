@@ -0,0 +1,81 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDirtyRepo is returned when the primary checkout has uncommitted
+// changes and the operation was not asked to stash them.
+var ErrDirtyRepo = errors.New("repository has uncommitted changes")
+
+// RunOptions controls how Execute and Merge treat the primary checkout.
+type RunOptions struct {
+	// AutoStash allows a dirty checkout: Merge stashes local changes
+	// before merging and restores them afterwards.
+	AutoStash bool
+
+	// RetryWithContext shows the agents of failed tasks that Resume runs
+	// again why the previous attempt failed, its transcript and its diff.
+	RetryWithContext bool
+}
+
+// checkClean refuses to proceed when the primary checkout is dirty, unless
+// AutoStash is set or merges do not go into the primary checkout.
+func (s *Session) checkClean(ctx context.Context, opts RunOptions) error {
+	if opts.AutoStash {
+		return nil
+	}
+	if _, shared := s.mergeTarget(ctx); !shared {
+		return nil
+	}
+	files, err := s.worktreeMgr.ChangedFiles(ctx, s.RepoPath)
+	if err != nil {
+		return fmt.Errorf("check repository status: %w", err)
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("%w: %s (commit them or use autoStash)", ErrDirtyRepo, summarizeFiles(files))
+	}
+	return nil
+}
+
+// withStash runs fn with local changes in the primary checkout stashed
+// when AutoStash is set and the checkout is dirty.
+func (s *Session) withStash(ctx context.Context, opts RunOptions, co checkout, fn func() error) error {
+	if !opts.AutoStash || !co.Shared {
+		return fn()
+	}
+
+	files, err := s.worktreeMgr.ChangedFiles(ctx, s.RepoPath)
+	if err != nil {
+		return fmt.Errorf("check repository status: %w", err)
+	}
+	if len(files) == 0 {
+		return fn()
+	}
+
+	if err := s.worktreeMgr.Stash(ctx, s.RepoPath, "codex-agent-team autostash "+s.ID); err != nil {
+		return err
+	}
+
+	fnErr := fn()
+
+	if err := s.worktreeMgr.StashPop(ctx, s.RepoPath); err != nil {
+		if fnErr != nil {
+			return fnErr
+		}
+		return fmt.Errorf("restore stashed changes (they remain in git stash): %w", err)
+	}
+	return fnErr
+}
+
+// summarizeFiles lists the first few files of a change set.
+func summarizeFiles(files []string) string {
+	const max = 5
+	if len(files) <= max {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(files[:max], ", "), len(files)-max)
+}
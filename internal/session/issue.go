@@ -0,0 +1,24 @@
+package session
+
+// IssueLink identifies the tracker issue a session was created from. The
+// session's final report is posted back to it.
+type IssueLink struct {
+	Tracker string `json:"tracker"`
+	Key     string `json:"key"`
+	URL     string `json:"url"`
+}
+
+// SetIssue links the session to an issue and persists it.
+func (s *Session) SetIssue(link *IssueLink) {
+	s.mu.Lock()
+	s.Issue = link
+	s.mu.Unlock()
+	s.save()
+}
+
+// IssueLink returns the issue the session was created from, or nil.
+func (s *Session) IssueLink() *IssueLink {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Issue
+}
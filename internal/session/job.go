@@ -0,0 +1,128 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// JobStatus is the state of a background operation.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job tracks one background operation of a session: a decomposition,
+// execution, resume or merge.
+type Job struct {
+	ID         string     `json:"id"`
+	SessionID  string     `json:"sessionId"`
+	Kind       string     `json:"kind"`
+	Status     JobStatus  `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	err error // what the job failed with
+}
+
+// Err returns the error a failed job ended with, and nil otherwise.
+func (j Job) Err() error {
+	return j.err
+}
+
+// maxFinishedJobs is how many finished jobs a session remembers.
+const maxFinishedJobs = 20
+
+// beginJob registers a running job. Only one job may run per session at a
+// time, which also keeps a double-clicked merge from running twice.
+func (s *Session) beginJob(kind string) (*Job, error) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.Status == JobRunning {
+			return nil, fmt.Errorf("%w: %s job %s is still running", ErrInvalidTransition, j.Kind, j.ID)
+		}
+	}
+	if s.jobs == nil {
+		s.jobs = make(map[string]*Job)
+	}
+	job := &Job{
+		ID:        fmt.Sprintf("job-%d", time.Now().UnixNano()),
+		SessionID: s.ID,
+		Kind:      kind,
+		Status:    JobRunning,
+		StartedAt: time.Now(),
+	}
+	s.jobs[job.ID] = job
+	return job, nil
+}
+
+// abortJob forgets a job that failed to start.
+func (s *Session) abortJob(job *Job) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	delete(s.jobs, job.ID)
+}
+
+// endJob records the outcome of a job and prunes old finished jobs.
+func (s *Session) endJob(job *Job, err error) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = JobSucceeded
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+		job.err = err
+	}
+
+	var finished []*Job
+	for _, j := range s.jobs {
+		if j.Status != JobRunning {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) > maxFinishedJobs {
+		sort.Slice(finished, func(i, k int) bool { return finished[i].StartedAt.Before(finished[k].StartedAt) })
+		for _, j := range finished[:len(finished)-maxFinishedJobs] {
+			delete(s.jobs, j.ID)
+		}
+	}
+}
+
+// snapshot returns a copy of job that is safe to read.
+func (s *Session) snapshot(job *Job) Job {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	return *job
+}
+
+// Job returns a job of the session by ID.
+func (s *Session) Job(id string) (Job, bool) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Jobs returns the session's jobs, oldest first.
+func (s *Session) Jobs() []Job {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, *j)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].StartedAt.Before(jobs[k].StartedAt) })
+	return jobs
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"codex-agent-team/internal/events"
+)
+
+// ErrRepoLocked is returned when another process holds the repository lock.
+var ErrRepoLocked = errors.New("repository is locked by another process")
+
+// lockFileName is the advisory lock file created inside the .git directory.
+const lockFileName = "codex-agent-team.lock"
+
+// Notice is an informational message about a session, such as a warning.
+type Notice struct {
+	SessionID string `json:"sessionId"`
+	Message   string `json:"message"`
+}
+
+// repoMutex returns the in-process mutex serializing merges into repoPath.
+func (m *Manager) repoMutex(repoPath string) *sync.Mutex {
+	m.lockMu.Lock()
+	defer m.lockMu.Unlock()
+
+	mu, ok := m.repoLocks[repoPath]
+	if !ok {
+		mu = &sync.Mutex{}
+		m.repoLocks[repoPath] = mu
+	}
+	return mu
+}
+
+// notify publishes a notice on the event bus.
+func (s *Session) notify(format string, args ...any) {
+	if s.bus == nil {
+		return
+	}
+	s.bus.Publish(events.Event{
+		Source:    events.SourceSession,
+		Type:      events.TypeNotice,
+		SessionID: s.ID,
+		Data:      Notice{SessionID: s.ID, Message: fmt.Sprintf(format, args...)},
+	})
+}
+
+// warnConcurrentSessions emits a notice for every other active session
+// that will merge into the same repository.
+func (s *Session) warnConcurrentSessions() {
+	if s.mgr == nil {
+		return
+	}
+	for _, other := range s.mgr.ListAll() {
+		if other == s || other.RepoPath != s.RepoPath {
+			continue
+		}
+		other.mu.RLock()
+		status := other.Status
+		other.mu.RUnlock()
+		if status == StatusRunning || status == StatusMerging {
+			s.notify("session %s (%s) also targets %s; merges will be serialized", other.ID, status, s.RepoPath)
+		}
+	}
+}
+
+// lockRepo serializes merge phases on the session's repository. It takes
+// the in-process mutex, then an advisory lock file guarding against other
+// server processes. The returned func releases both.
+func (s *Session) lockRepo(ctx context.Context) (func(), error) {
+	var mu *sync.Mutex
+	if s.mgr != nil {
+		mu = s.mgr.repoMutex(s.RepoPath)
+		locked := make(chan struct{})
+		go func() {
+			mu.Lock()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+		case <-ctx.Done():
+			// Release the lock once the pending Lock eventually succeeds
+			go func() {
+				<-locked
+				mu.Unlock()
+			}()
+			return nil, ctx.Err()
+		}
+	}
+
+	unlockFile, err := acquireLockFile(s.RepoPath, s.ID)
+	if err != nil {
+		if mu != nil {
+			mu.Unlock()
+		}
+		return nil, err
+	}
+
+	return func() {
+		unlockFile()
+		if mu != nil {
+			mu.Unlock()
+		}
+	}, nil
+}
+
+// acquireLockFile creates the advisory lock file in the repository's .git
+// directory, or in the repository itself when it is bare. A lock left
+// behind by a dead process is taken over. Repositories without a .git
+// directory are not file-locked.
+func acquireLockFile(repoPath, owner string) (func(), error) {
+	gitDir := filepath.Join(repoPath, ".git")
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		if !isBareDir(repoPath) {
+			return func() {}, nil
+		}
+		gitDir = repoPath
+	}
+	path := filepath.Join(gitDir, lockFileName)
+	content := fmt.Sprintf("%d %s\n", os.Getpid(), owner)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, _ = f.WriteString(content)
+			f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if lockHolderAlive(string(data)) {
+			return nil, fmt.Errorf("%w: %s", ErrRepoLocked, strings.TrimSpace(string(data)))
+		}
+		// Stale lock from a crashed process
+		_ = os.Remove(path)
+	}
+	return nil, fmt.Errorf("%w: could not take over stale lock", ErrRepoLocked)
+}
+
+// isBareDir reports whether dir looks like a bare repository.
+func isBareDir(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// lockHolderAlive reports whether the process recorded in a lock file is
+// still running.
+func lockHolderAlive(content string) bool {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return false
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil || pid <= 0 {
+		return false
+	}
+	if pid == os.Getpid() {
+		return true
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
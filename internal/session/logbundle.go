@@ -0,0 +1,147 @@
+package session
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/worktree"
+)
+
+// WriteLogBundle writes a zip archive for bug reports about the session:
+//
+//	session.json            the session
+//	tasks.json              its tasks
+//	events.jsonl            its recorded events
+//	server.log              the entries of serverLog naming the session or its agents
+//	transcripts/<task>.txt  the agent output of each task
+//	rpc/<agent>.jsonl       the JSON-RPC frames of each agent, when traced
+//	git.txt                 the output of diagnostic git commands in its repository
+//
+// Parts that cannot be read are left out; only write errors are returned.
+func (s *Session) WriteLogBundle(ctx context.Context, w io.Writer, serverLog []string) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	add := func(name string, data []byte) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	addJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil
+		}
+		return add(name, append(data, '\n'))
+	}
+
+	if err := addJSON("session.json", s); err != nil {
+		return err
+	}
+	if err := addJSON("tasks.json", s.TaskViews()); err != nil {
+		return err
+	}
+
+	var evs []TimelineEvent
+	if s.mgr != nil && s.mgr.timelines != nil {
+		evs, _ = s.mgr.timelines.Load(s.ID)
+	}
+	var events []byte
+	for _, ev := range evs {
+		if line, err := json.Marshal(ev); err == nil {
+			events = append(append(events, line...), '\n')
+		}
+	}
+	if err := add("events.jsonl", events); err != nil {
+		return err
+	}
+
+	// IDs whose mention ties a log entry to the session; task IDs such
+	// as "1" are too short to tell
+	keys := []string{s.ID}
+	agents := make(map[string]bool)
+	var branches []string
+	tasks := s.DAG.Snapshot()
+	for _, t := range tasks {
+		if t.AgentID != "" {
+			agents[t.AgentID] = true
+		}
+		if t.BranchName != "" {
+			branches = append(branches, t.BranchName)
+		}
+	}
+	for _, ev := range evs {
+		if ev.AgentID != "" {
+			agents[ev.AgentID] = true
+		}
+	}
+	s.mu.RLock()
+	if s.Orchestrator != nil {
+		for _, id := range s.Orchestrator.AgentIDs() {
+			agents[id] = true
+		}
+	}
+	if s.Merger != nil {
+		for _, id := range s.Merger.AgentIDs() {
+			agents[id] = true
+		}
+	}
+	if s.Verification != nil {
+		for _, fix := range s.Verification.Fixes {
+			agents[fix.AgentID] = true
+		}
+	}
+	s.mu.RUnlock()
+	agentIDs := make([]string, 0, len(agents))
+	for id := range agents {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+	keys = append(keys, agentIDs...)
+	var matched strings.Builder
+	for _, line := range serverLog {
+		for _, key := range keys {
+			if strings.Contains(line, key) {
+				matched.WriteString(line)
+				break
+			}
+		}
+	}
+	if err := add("server.log", []byte(matched.String())); err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		if out, ok := s.Transcript(t.ID); ok && out != "" {
+			if err := add("transcripts/"+filepath.Base(t.ID)+".txt", []byte(out)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, id := range agentIDs {
+		path := s.agentMgr.RPCTraceFile(id)
+		if path == "" {
+			break
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			if err := add("rpc/"+filepath.Base(path), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := add("git.txt", worktree.Report(ctx, s.RepoPath, branches...)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,38 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+
+	"codex-agent-team/internal/agent"
+)
+
+// ErrMergeConflict matches merge failures caused by conflicts the merger
+// agent could not resolve.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeError is returned when task branches could not be merged.
+type MergeError struct {
+	Branches  []string // branches left unmerged
+	Conflicts []string // files whose conflicts stayed unresolved
+}
+
+func newMergeError(result *agent.MergeResult) *MergeError {
+	conflicts := result.UnresolvedFiles
+	if len(conflicts) == 0 {
+		conflicts = result.Conflicts
+	}
+	return &MergeError{Branches: result.FailedBranches, Conflicts: conflicts}
+}
+
+func (e *MergeError) Error() string {
+	if len(e.Conflicts) > 0 {
+		return fmt.Sprintf("merge failed for branches: %v (unresolved conflicts in %s)", e.Branches, summarizeFiles(e.Conflicts))
+	}
+	return fmt.Sprintf("merge failed for branches: %v", e.Branches)
+}
+
+// Is reports conflicts as ErrMergeConflict.
+func (e *MergeError) Is(target error) bool {
+	return target == ErrMergeConflict && len(e.Conflicts) > 0
+}
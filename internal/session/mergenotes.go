@@ -0,0 +1,204 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/agent"
+)
+
+// maxConventions bounds the resolution conventions kept per repository;
+// the oldest are dropped first.
+const maxConventions = 30
+
+// maxHotspots bounds the conflict-prone files shown to merger agents.
+const maxHotspots = 10
+
+// MergeNotes is what earlier merges taught about a repository: files that
+// keep conflicting and conventions for resolving their conflicts. Merger
+// agents of later sessions get them in their instructions.
+type MergeNotes struct {
+	RepoPath    string         `json:"repoPath"`
+	Conventions []string       `json:"conventions"` // oldest first
+	Hotspots    map[string]int `json:"hotspots"`    // conflicts seen per file
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// Render formats the notes for a merger agent's instructions, or returns
+// "" when there is nothing to tell.
+func (n *MergeNotes) Render() string {
+	var b strings.Builder
+	if len(n.Conventions) > 0 {
+		b.WriteString("Resolution conventions of this repository:\n")
+		for _, c := range n.Conventions {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	files := make([]string, 0, len(n.Hotspots))
+	for f := range n.Hotspots {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if n.Hotspots[files[i]] != n.Hotspots[files[j]] {
+			return n.Hotspots[files[i]] > n.Hotspots[files[j]]
+		}
+		return files[i] < files[j]
+	})
+	if len(files) > maxHotspots {
+		files = files[:maxHotspots]
+	}
+	if len(files) > 0 {
+		b.WriteString("Files that conflicted in earlier merges:\n")
+		for _, f := range files {
+			fmt.Fprintf(&b, "- %s (%d)\n", f, n.Hotspots[f])
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// MergeNotesStore keeps one MergeNotes file per repository.
+type MergeNotesStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewMergeNotesStore creates a merge notes store in dataDir.
+func NewMergeNotesStore(dataDir string) (*MergeNotesStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	return &MergeNotesStore{dir: dataDir}, nil
+}
+
+// Get returns the notes of a repository, empty when it has none.
+func (s *MergeNotesStore) Get(repoPath string) *MergeNotes {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(repoPath)
+}
+
+// SetConventions replaces the resolution conventions of a repository,
+// keeping its conflict counts.
+func (s *MergeNotesStore) SetConventions(repoPath string, conventions []string) (*MergeNotes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notes := s.load(repoPath)
+	notes.Conventions = []string{}
+	addConventions(notes, conventions)
+	return notes, s.save(notes)
+}
+
+// Learn adds what a merge phase showed: the files it had conflicts in and
+// the conventions the merger agent reported following.
+func (s *MergeNotesStore) Learn(repoPath string, result *agent.MergeResult) error {
+	if len(result.ResolvedFiles) == 0 && len(result.UnresolvedFiles) == 0 && len(result.Conventions) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notes := s.load(repoPath)
+	for _, f := range append(append([]string{}, result.ResolvedFiles...), result.UnresolvedFiles...) {
+		notes.Hotspots[f]++
+	}
+	addConventions(notes, result.Conventions)
+	return s.save(notes)
+}
+
+// addConventions appends conventions not already known, dropping the
+// oldest beyond maxConventions.
+func addConventions(notes *MergeNotes, conventions []string) {
+	known := make(map[string]bool, len(notes.Conventions))
+	for _, c := range notes.Conventions {
+		known[strings.ToLower(c)] = true
+	}
+	for _, c := range conventions {
+		c = strings.TrimSpace(c)
+		if c == "" || known[strings.ToLower(c)] {
+			continue
+		}
+		known[strings.ToLower(c)] = true
+		notes.Conventions = append(notes.Conventions, c)
+	}
+	if len(notes.Conventions) > maxConventions {
+		notes.Conventions = notes.Conventions[len(notes.Conventions)-maxConventions:]
+	}
+}
+
+// load reads the notes of a repository; the caller holds s.mu.
+func (s *MergeNotesStore) load(repoPath string) *MergeNotes {
+	notes := &MergeNotes{RepoPath: repoPath}
+	if data, err := os.ReadFile(s.path(repoPath)); err == nil {
+		_ = json.Unmarshal(data, notes)
+	}
+	if notes.Conventions == nil {
+		notes.Conventions = []string{}
+	}
+	if notes.Hotspots == nil {
+		notes.Hotspots = make(map[string]int)
+	}
+	return notes
+}
+
+// save writes the notes of a repository; the caller holds s.mu.
+func (s *MergeNotesStore) save(notes *MergeNotes) error {
+	notes.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(notes.RepoPath), data, 0644)
+}
+
+// path returns the notes file of a repository, named by a hash of its path.
+func (s *MergeNotesStore) path(repoPath string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(repoPath)))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// MergeNotes returns what earlier merges taught about a repository, or nil
+// when notes are not stored.
+func (m *Manager) MergeNotes(repoPath string) *MergeNotes {
+	if m.mergeNotes == nil {
+		return nil
+	}
+	return m.mergeNotes.Get(repoPath)
+}
+
+// SetMergeConventions replaces the resolution conventions shown to merger
+// agents working on a repository.
+func (m *Manager) SetMergeConventions(repoPath string, conventions []string) (*MergeNotes, error) {
+	if m.mergeNotes == nil {
+		return nil, fmt.Errorf("merge notes are not stored")
+	}
+	return m.mergeNotes.SetConventions(repoPath, conventions)
+}
+
+// applyMergeNotes shows the repository's merge notes to the session's
+// merger agent.
+func (s *Session) applyMergeNotes() {
+	if s.mgr == nil || s.mgr.mergeNotes == nil {
+		return
+	}
+	s.Merger.SetNotes(s.mgr.mergeNotes.Get(s.RepoPath).Render())
+}
+
+// learnFromMerge records the conflicts and conventions of a merge phase in
+// the repository's merge notes.
+func (s *Session) learnFromMerge(result *agent.MergeResult) {
+	if s.mgr == nil || s.mgr.mergeNotes == nil {
+		return
+	}
+	if err := s.mgr.mergeNotes.Learn(s.RepoPath, result); err != nil {
+		s.notify("merge: saving merge notes: %v", err)
+	}
+}
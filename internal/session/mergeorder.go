@@ -0,0 +1,76 @@
+package session
+
+import (
+	"sort"
+
+	"codex-agent-team/internal/task"
+)
+
+// mergeOrder sorts the tasks to merge so dependencies come before their
+// dependents. Within a DAG level, tasks touching files no sibling touches
+// go first and the likeliest conflicts last, so conflict resolution sees as
+// much of the final tree as possible.
+func (s *Session) mergeOrder(tasks []task.Task, taskIDs []string) []string {
+	depth := make(map[string]int)
+	if levels, err := s.DAG.Levels(); err == nil {
+		for i, level := range levels {
+			for _, t := range level {
+				depth[t.ID] = i
+			}
+		}
+	}
+
+	files := make(map[string][]string)
+	for _, t := range tasks {
+		files[t.ID] = touchedFiles(t)
+	}
+	overlap := make(map[string]int)
+	for i, a := range taskIDs {
+		for _, b := range taskIDs[i+1:] {
+			if depth[a] != depth[b] {
+				continue
+			}
+			n := sharedFiles(files[a], files[b])
+			overlap[a] += n
+			overlap[b] += n
+		}
+	}
+
+	out := append([]string(nil), taskIDs...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if depth[a] != depth[b] {
+			return depth[a] < depth[b]
+		}
+		if overlap[a] != overlap[b] {
+			return overlap[a] < overlap[b]
+		}
+		return a < b
+	})
+	return out
+}
+
+// touchedFiles returns the files a task changed, or the files it was
+// planned to touch when its result does not list them.
+func touchedFiles(t task.Task) []string {
+	if t.Result != nil && len(t.Result.FilesChanged) > 0 {
+		return t.Result.FilesChanged
+	}
+	return t.Files
+}
+
+// sharedFiles counts the files present in both lists.
+func sharedFiles(a, b []string) int {
+	set := make(map[string]bool, len(a))
+	for _, f := range a {
+		set[f] = true
+	}
+	n := 0
+	for _, f := range b {
+		if set[f] {
+			n++
+			delete(set, f)
+		}
+	}
+	return n
+}
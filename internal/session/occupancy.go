@@ -0,0 +1,193 @@
+package session
+
+import (
+	"sort"
+	"time"
+
+	"codex-agent-team/internal/task"
+)
+
+// Bottlenecks reported by Occupancy.
+const (
+	BottleneckParallel = "maxParallel" // ready tasks waited while every session slot was busy
+	BottleneckDAG      = "dag"         // slots were free but dependencies kept tasks from being ready
+	BottleneckAgents   = "agents"      // slots were free but ready tasks waited for the shared agent pool
+)
+
+// Occupancy describes how a session used its parallel slots, derived from
+// its task timings. Compare SaturatedMs with DAGBoundMs to tell whether
+// raising maxParallel would help or the DAG is too narrow.
+type Occupancy struct {
+	SessionID   string              `json:"sessionId"`
+	MaxParallel int                 `json:"maxParallel"`
+	DAGWidth    int                 `json:"dagWidth"` // tasks in the widest DAG level
+	Agents      []AgentOccupancy    `json:"agents"`
+	Series      []ParallelismSample `json:"series"` // step function, one sample per change
+
+	WallClockMs    int64   `json:"wallClockMs"`    // first task start to last task end
+	CriticalPathMs int64   `json:"criticalPathMs"` // longest dependency chain of task run times
+	BusyMs         int64   `json:"busyMs"`         // summed task run time
+	PeakParallel   int     `json:"peakParallel"`
+	AvgParallel    float64 `json:"avgParallel"`
+	Utilization    float64 `json:"utilization"` // BusyMs over MaxParallel*WallClockMs
+
+	SaturatedMs int64  `json:"saturatedMs"` // all slots busy with ready tasks waiting
+	DAGBoundMs  int64  `json:"dagBoundMs"`  // free slots and no ready task
+	PoolBoundMs int64  `json:"poolBoundMs"` // free slots and ready tasks waiting
+	Bottleneck  string `json:"bottleneck,omitempty"`
+}
+
+// AgentOccupancy is the time one agent spent running its task.
+type AgentOccupancy struct {
+	AgentID   string `json:"agentId"`
+	TaskID    string `json:"taskId"`
+	Intervals []Span `json:"intervals"` // the task run, or its turns when recorded
+	BusyMs    int64  `json:"busyMs"`
+}
+
+// ParallelismSample is the number of running and waiting tasks from Time
+// until the next sample.
+type ParallelismSample struct {
+	Time    time.Time `json:"time"`
+	Running int       `json:"running"`
+	Waiting int       `json:"waiting"` // ready but not started
+}
+
+// maxParallel returns the number of tasks the session runs at once.
+func (s *Session) maxParallel() int {
+	if s.mgr != nil {
+		return s.mgr.opts.MaxParallel
+	}
+	return defaultMaxParallel
+}
+
+// Occupancy computes the session's slot usage. Tasks still running or
+// waiting are counted up to now.
+func (s *Session) Occupancy() (*Occupancy, error) {
+	tl, err := s.Timeline()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	occ := &Occupancy{
+		SessionID:   s.ID,
+		MaxParallel: s.maxParallel(),
+		Agents:      []AgentOccupancy{},
+		Series:      []ParallelismSample{},
+	}
+	if levels, err := s.DAG.Levels(); err == nil {
+		for _, level := range levels {
+			occ.DAGWidth = max(occ.DAGWidth, len(level))
+		}
+	}
+
+	type delta struct {
+		at               time.Time
+		running, waiting int
+	}
+	var deltas []delta
+	runs := make(map[string]time.Duration)
+	for _, t := range tl.Tasks {
+		end := now
+		if t.CompletedAt != nil {
+			end = *t.CompletedAt
+		}
+		if t.StartedAt != nil && (t.CompletedAt != nil || t.Status == string(task.StatusRunning)) {
+			start := *t.StartedAt
+			deltas = append(deltas, delta{start, 1, 0}, delta{end, -1, 0})
+			runs[t.TaskID] = end.Sub(start)
+
+			intervals := t.Turns
+			if len(intervals) == 0 {
+				intervals = []Span{{Name: t.TaskID, Start: start, End: t.CompletedAt}}
+			}
+			occ.Agents = append(occ.Agents, AgentOccupancy{
+				AgentID:   t.AgentID,
+				TaskID:    t.TaskID,
+				Intervals: intervals,
+				BusyMs:    end.Sub(start).Milliseconds(),
+			})
+		}
+
+		// Waiting lasts from the last "ready" event until the task started
+		if t.ReadyAt != nil {
+			switch {
+			case t.StartedAt != nil && !t.StartedAt.Before(*t.ReadyAt):
+				deltas = append(deltas, delta{*t.ReadyAt, 0, 1}, delta{*t.StartedAt, 0, -1})
+			case t.Status == string(task.StatusReady):
+				deltas = append(deltas, delta{*t.ReadyAt, 0, 1}, delta{now, 0, -1})
+			}
+		}
+	}
+	occ.CriticalPathMs = criticalPath(s.TaskViews(), runs).Milliseconds()
+
+	sort.SliceStable(deltas, func(i, j int) bool { return deltas[i].at.Before(deltas[j].at) })
+	var running, waiting int
+	var first, last time.Time
+	for i, d := range deltas {
+		running += d.running
+		waiting += d.waiting
+		if i+1 < len(deltas) && deltas[i+1].at.Equal(d.at) {
+			continue
+		}
+		if n := len(occ.Series); n == 0 || occ.Series[n-1].Running != running || occ.Series[n-1].Waiting != waiting {
+			occ.Series = append(occ.Series, ParallelismSample{Time: d.at, Running: running, Waiting: waiting})
+		}
+		occ.PeakParallel = max(occ.PeakParallel, running)
+		if i+1 == len(deltas) || running == 0 && waiting == 0 {
+			continue
+		}
+
+		// Attribute the interval until the next change
+		if first.IsZero() {
+			first = d.at
+		}
+		next := deltas[i+1].at
+		last = next
+		dt := next.Sub(d.at).Milliseconds()
+		occ.BusyMs += int64(running) * dt
+		switch {
+		case running >= occ.MaxParallel && waiting > 0:
+			occ.SaturatedMs += dt
+		case running < occ.MaxParallel && waiting > 0:
+			occ.PoolBoundMs += dt
+		case running < occ.MaxParallel:
+			occ.DAGBoundMs += dt
+		}
+	}
+
+	if !first.IsZero() {
+		occ.WallClockMs = last.Sub(first).Milliseconds()
+	}
+	if occ.WallClockMs > 0 {
+		occ.AvgParallel = float64(occ.BusyMs) / float64(occ.WallClockMs)
+		occ.Utilization = occ.AvgParallel / float64(occ.MaxParallel)
+	}
+	switch {
+	case occ.SaturatedMs == 0 && occ.DAGBoundMs == 0 && occ.PoolBoundMs == 0:
+	case occ.SaturatedMs >= occ.DAGBoundMs && occ.SaturatedMs >= occ.PoolBoundMs:
+		occ.Bottleneck = BottleneckParallel
+	case occ.PoolBoundMs > occ.DAGBoundMs:
+		occ.Bottleneck = BottleneckAgents
+	default:
+		occ.Bottleneck = BottleneckDAG
+	}
+	return occ, nil
+}
+
+// criticalPath returns the longest chain of run times along the
+// dependencies of views, which must be in topological order.
+func criticalPath(views []task.TaskView, runs map[string]time.Duration) time.Duration {
+	finish := make(map[string]time.Duration, len(views))
+	var longest time.Duration
+	for _, v := range views {
+		var start time.Duration
+		for _, dep := range v.DependsOn {
+			start = max(start, finish[dep.ID])
+		}
+		finish[v.ID] = start + runs[v.ID]
+		longest = max(longest, finish[v.ID])
+	}
+	return longest
+}
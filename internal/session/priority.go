@@ -0,0 +1,225 @@
+package session
+
+import (
+	"context"
+	"sync"
+
+	"codex-agent-team/internal/task"
+)
+
+// agentSlots is the agent pool shared by all sessions. Waiting tasks are
+// granted slots by session priority, then in arrival order. While a session
+// at or above the urgent priority is executing, sessions of lower priority
+// start no new tasks: their running tasks finish, and the freed slots go to
+// the urgent session.
+type agentSlots struct {
+	mu      sync.Mutex
+	max     int // slots in the pool, 0 for no limit
+	used    int
+	urgent  int // priority that pauses lower-priority sessions, 0 to disable
+	waiters []*slotWaiter
+	active  map[*Session]int // executing sessions and their priorities
+}
+
+// slotWaiter is a task waiting for a slot.
+type slotWaiter struct {
+	priority int
+	granted  chan struct{}
+	queued   func(task.QueueInfo) // optional, see task.QueueLimiter
+	reported task.QueueInfo       // last place passed to queued
+}
+
+// newAgentSlots creates the pool, or returns nil when neither a limit nor
+// an urgent priority is configured.
+func newAgentSlots(max, urgent int) *agentSlots {
+	if max <= 0 && urgent <= 0 {
+		return nil
+	}
+	return &agentSlots{max: max, urgent: urgent, active: make(map[*Session]int)}
+}
+
+// paused reports whether sessions of the given priority must wait for an
+// urgent session. Callers hold p.mu.
+func (p *agentSlots) paused(priority int) bool {
+	for _, other := range p.active {
+		if other >= p.urgent && other > priority {
+			return true
+		}
+	}
+	return false
+}
+
+// free reports whether a slot is available. Callers hold p.mu.
+func (p *agentSlots) free() bool {
+	return p.max <= 0 || p.used < p.max
+}
+
+// acquire blocks until a slot is granted to a task of the given priority.
+// queued, if set, is told the task's place while it waits.
+func (p *agentSlots) acquire(ctx context.Context, priority int, queued func(task.QueueInfo)) error {
+	p.mu.Lock()
+	if len(p.waiters) == 0 && p.free() && !p.paused(priority) {
+		p.used++
+		p.mu.Unlock()
+		return nil
+	}
+	w := &slotWaiter{priority: priority, granted: make(chan struct{}), queued: queued}
+	i := len(p.waiters)
+	for i > 0 && p.waiters[i-1].priority < priority {
+		i--
+	}
+	p.waiters = append(p.waiters, nil)
+	copy(p.waiters[i+1:], p.waiters[i:])
+	p.waiters[i] = w
+	p.dispatch()
+	p.mu.Unlock()
+
+	select {
+	case <-w.granted:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case <-w.granted:
+		// Granted while giving up: hand the slot to the next waiter.
+		p.used--
+	default:
+		p.remove(w)
+	}
+	p.dispatch()
+	return ctx.Err()
+}
+
+// release returns a slot to the pool.
+func (p *agentSlots) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.used--
+	p.dispatch()
+}
+
+// dispatch grants free slots to waiters in priority order, then tells the
+// remaining waiters their place. Callers hold p.mu.
+func (p *agentSlots) dispatch() {
+	for len(p.waiters) > 0 && p.free() {
+		w := p.waiters[0]
+		if p.paused(w.priority) {
+			// Every later waiter has the same or a lower priority.
+			break
+		}
+		p.waiters = p.waiters[1:]
+		p.used++
+		close(w.granted)
+	}
+	for i, w := range p.waiters {
+		if w.queued == nil {
+			continue
+		}
+		q := task.QueueInfo{Reason: task.QueueAgents, Position: i + 1, Slots: p.max}
+		if p.paused(w.priority) {
+			q.Reason = task.QueuePaused
+		}
+		if q != w.reported {
+			w.reported = q
+			w.queued(q)
+		}
+	}
+}
+
+// remove drops a waiter from the queue. Callers hold p.mu.
+func (p *agentSlots) remove(w *slotWaiter) {
+	for i, other := range p.waiters {
+		if other == w {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// begin records that a session is executing. Sessions at or above the
+// urgent priority pause lower-priority sessions until the returned
+// function is called.
+func (p *agentSlots) begin(sess *Session) func() {
+	if p.urgent <= 0 {
+		return func() {}
+	}
+	p.mu.Lock()
+	p.active[sess] = sess.GetPriority()
+	p.mu.Unlock()
+	return func() {
+		p.mu.Lock()
+		delete(p.active, sess)
+		p.dispatch()
+		p.mu.Unlock()
+	}
+}
+
+// reprioritize applies a priority change of an executing session.
+func (p *agentSlots) reprioritize(sess *Session, priority int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.active[sess]; ok {
+		p.active[sess] = priority
+		p.dispatch()
+	}
+}
+
+// sessionLimiter holds a session's tasks to the shared agent pool, and to
+// the tenant quota when there is one.
+type sessionLimiter struct {
+	slots  *agentSlots
+	sess   *Session
+	tenant task.Limiter
+}
+
+// Acquire implements task.Limiter.
+func (l *sessionLimiter) Acquire(ctx context.Context) error {
+	return l.AcquireQueued(ctx, nil)
+}
+
+// AcquireQueued implements task.QueueLimiter. The tenant slot is taken
+// first so a task never holds a pool slot while its tenant is over quota.
+func (l *sessionLimiter) AcquireQueued(ctx context.Context, queued func(task.QueueInfo)) error {
+	if l.tenant != nil {
+		if err := l.tenant.Acquire(ctx); err != nil {
+			return err
+		}
+	}
+	if err := l.slots.acquire(ctx, l.sess.GetPriority(), queued); err != nil {
+		if l.tenant != nil {
+			l.tenant.Release()
+		}
+		return err
+	}
+	return nil
+}
+
+// Release implements task.Limiter.
+func (l *sessionLimiter) Release() {
+	l.slots.release()
+	if l.tenant != nil {
+		l.tenant.Release()
+	}
+}
+
+// GetPriority returns the session's scheduling priority.
+func (s *Session) GetPriority() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Priority
+}
+
+// SetPriority changes the session's scheduling priority and persists it.
+// Tasks already waiting for a slot keep the priority they asked with.
+func (s *Session) SetPriority(priority int) {
+	s.mu.Lock()
+	s.Priority = priority
+	s.mu.Unlock()
+	if s.mgr != nil && s.mgr.slots != nil {
+		s.mgr.slots.reprioritize(s, priority)
+	}
+	s.save()
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"time"
+
+	"codex-agent-team/internal/agent"
+)
+
+// MergeRecord is the provenance of the session's last merge phase: where
+// the target branch stood before it and which commit brought in each
+// task, so every merged commit traces back to a task and its transcript.
+type MergeRecord struct {
+	Strategy       string       `json:"strategy"` // strategy that ran, see agent.MergeResult
+	Fallback       bool         `json:"octopusFallback,omitempty"`
+	TargetBranch   string       `json:"targetBranch"`
+	BaseCommit     string       `json:"baseCommit,omitempty"`
+	Tasks          []MergedTask `json:"tasks"` // in merge order
+	FailedBranches []string     `json:"failedBranches,omitempty"`
+	Unresolved     []string     `json:"unresolvedFiles,omitempty"`  // conflicted files the merger agent gave up on
+	Invalid        []string     `json:"validationErrors,omitempty"` // rejected conflict resolutions
+	MergedAt       time.Time    `json:"mergedAt"`
+}
+
+// MergedTask is the merge of one task's branch.
+type MergedTask struct {
+	TaskID string `json:"taskId"`
+	agent.BranchMerge
+}
+
+// recordMerge stores the provenance of a merge phase and persists it.
+// branchMap maps task IDs to their branches.
+func (s *Session) recordMerge(plan *agent.MergePlan, result *agent.MergeResult, branchMap map[string]string) {
+	taskOf := make(map[string]string, len(branchMap))
+	for id, branch := range branchMap {
+		taskOf[branch] = id
+	}
+	rec := &MergeRecord{
+		Strategy:       result.Strategy,
+		Fallback:       result.OctopusFallback,
+		TargetBranch:   plan.TargetBranch,
+		BaseCommit:     result.BaseCommit,
+		Tasks:          []MergedTask{},
+		FailedBranches: result.FailedBranches,
+		Unresolved:     result.UnresolvedFiles,
+		Invalid:        result.ValidationErrors,
+		MergedAt:       time.Now(),
+	}
+	for _, m := range result.Merges {
+		rec.Tasks = append(rec.Tasks, MergedTask{TaskID: taskOf[m.Branch], BranchMerge: m})
+	}
+
+	s.mu.Lock()
+	s.LastMerge = rec
+	if result.BaseCommit != "" {
+		// The merger's reading is taken after checking out the target
+		s.PreMergeCommit = result.BaseCommit
+	}
+	s.mu.Unlock()
+	s.save()
+
+	if result.OctopusFallback {
+		s.notify("merge: octopus merge of %d branches failed, merged them one at a time", len(plan.Branches))
+	} else {
+		s.notify("merge: %d of %d branches merged with the %s strategy", result.MergedCount, len(plan.Branches), result.Strategy)
+	}
+}
+
+// MergeProvenance returns the record of the last merge phase, or nil.
+func (s *Session) MergeProvenance() *MergeRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LastMerge
+}
@@ -0,0 +1,171 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"codex-agent-team/internal/issues"
+	"codex-agent-team/internal/task"
+)
+
+// ErrNoPullRequests is returned for a PR stack when no code host is
+// configured to open pull requests.
+var ErrNoPullRequests = errors.New("no code host is configured to open pull requests")
+
+// defaultPRRemote is the remote PR stack branches are pushed to.
+const defaultPRRemote = "origin"
+
+// StackedPR is one pull request of a session delivered as a PR stack.
+type StackedPR struct {
+	TaskID  string `json:"taskId"`
+	Branch  string `json:"branch"` // pushed branch holding the task's commits
+	Base    string `json:"base"`   // branch the pull request merges into
+	Commits int    `json:"commits"`
+	URL     string `json:"url"`
+}
+
+// SetPullRequests makes sessions able to deliver their work as a stack of
+// pull requests opened through p.
+func (m *Manager) SetPullRequests(p issues.PullRequestOpener) {
+	m.pullRequests = p
+}
+
+// PRStackAsync validates that the session may merge, then delivers it as a
+// PR stack on the session context in the background and calls done with
+// the result.
+func (s *Session) PRStackAsync(done func([]StackedPR, error)) (Job, error) {
+	if s.mgr == nil || s.mgr.pullRequests == nil {
+		return Job{}, ErrNoPullRequests
+	}
+	job, err := s.beginJob("pr-stack")
+	if err != nil {
+		return Job{}, err
+	}
+	if err := s.requireStatus(StatusMerging); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+	go func() {
+		stack, err := s.prStack(s.Context())
+		s.endJob(job, err)
+		done(stack, err)
+	}()
+	return s.snapshot(job), nil
+}
+
+// prStack delivers the completed tasks as chained pull requests instead of
+// merging them locally. In merge order, each task's own commits are
+// replayed onto the previous task's stack branch (the checked-out branch
+// for the first), pushed, and opened as a pull request into it, so every
+// pull request shows one task's changes. Merges of dependency branches are
+// not replayed: the stack already contains the dependencies.
+func (s *Session) prStack(ctx context.Context) ([]StackedPR, error) {
+	remote := s.mgr.opts.PRRemote
+	if remote == "" {
+		remote = defaultPRRemote
+	}
+	remoteURL, err := s.worktreeMgr.RemoteURL(ctx, remote)
+	if err != nil {
+		_ = s.transition(StatusFailed)
+		return nil, err
+	}
+
+	unlock, err := s.lockRepo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	target, _ := s.mergeTarget(ctx)
+	if target == "" {
+		_ = s.transition(StatusFailed)
+		return nil, ErrNoTargetBranch
+	}
+
+	tasks := s.DAG.Snapshot()
+	byID := make(map[string]task.Task, len(tasks))
+	var taskIDs []string
+	for _, t := range tasks {
+		byID[t.ID] = t
+		if t.Status == task.StatusCompleted && t.BranchName != "" && !t.NoChanges {
+			taskIDs = append(taskIDs, t.ID)
+		}
+	}
+
+	stack := []StackedPR{}
+	base, baseCommit := target, "HEAD"
+	for _, id := range s.mergeOrder(tasks, taskIDs) {
+		t := byID[id]
+		pr, err := s.stackTask(ctx, t, remote, remoteURL, base, baseCommit, target)
+		if err != nil {
+			s.setPRStack(stack)
+			_ = s.transition(StatusFailed)
+			return stack, fmt.Errorf("task %s: %w", id, err)
+		}
+		if pr == nil {
+			s.notify("pr stack: task %s has no commits of its own, skipping", id)
+			continue
+		}
+		stack = append(stack, *pr)
+		s.notify("pr stack: opened %s for task %s", pr.URL, id)
+		base, baseCommit = pr.Branch, pr.Branch
+	}
+
+	s.setPRStack(stack)
+	if err := s.transition(StatusCompleted); err != nil {
+		return stack, err
+	}
+	return stack, nil
+}
+
+// stackTask replays a task's own commits onto baseCommit in a scratch
+// worktree, pushes the result and opens its pull request into base. It
+// returns nil when the task has no commits of its own.
+func (s *Session) stackTask(ctx context.Context, t task.Task, remote, remoteURL, base, baseCommit, target string) (*StackedPR, error) {
+	exclude := append([]string{target}, s.DAG.GetDependencyBranches(t.ID)...)
+	if t.BaseCommit != "" {
+		exclude = append(exclude, t.BaseCommit)
+	}
+	commits, err := s.worktreeMgr.OwnCommits(ctx, t.BranchName, exclude)
+	if err != nil || len(commits) == 0 {
+		return nil, err
+	}
+
+	// Stack branches are rebuilt from scratch on every run
+	branch := "stack-" + t.BranchName
+	_ = s.worktreeMgr.ForceRemove(ctx, s.worktreeMgr.GetPath(branch))
+	_ = s.worktreeMgr.DeleteBranch(ctx, branch)
+	wt, err := s.worktreeMgr.Create(ctx, branch, baseCommit)
+	if err != nil {
+		return nil, err
+	}
+	pickErr := s.worktreeMgr.CherryPick(ctx, wt.Path, commits)
+	_ = s.worktreeMgr.ForceRemove(ctx, wt.Path)
+	if pickErr != nil {
+		return nil, fmt.Errorf("replay onto %s: %w", base, pickErr)
+	}
+	if err := s.worktreeMgr.Push(ctx, remote, branch); err != nil {
+		return nil, err
+	}
+
+	body := t.Description
+	if base != target {
+		body += fmt.Sprintf("\n\nStacked on `%s`; merge that pull request first.", base)
+	}
+	body += fmt.Sprintf("\n\nTask %s of session %s.", t.ID, s.ID)
+	url, err := s.mgr.pullRequests.OpenPullRequest(ctx, remoteURL, branch, base, t.Title, strings.TrimSpace(body))
+	if err != nil {
+		return nil, err
+	}
+	return &StackedPR{TaskID: t.ID, Branch: branch, Base: base, Commits: len(commits), URL: url}, nil
+}
+
+// setPRStack records and persists the session's pull requests.
+func (s *Session) setPRStack(stack []StackedPR) {
+	s.mu.Lock()
+	s.PRStack = stack
+	s.mu.Unlock()
+	s.save()
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"codex-agent-team/internal/task"
+)
+
+// QueueStatus summarizes a session's ready tasks that wait for a slot.
+type QueueStatus struct {
+	Tasks    int            `json:"tasks"`    // ready tasks waiting
+	NextTask string         `json:"nextTask"` // the waiting task that starts first
+	Next     task.QueueInfo `json:"next"`     // its place in the queue
+}
+
+// refreshQueue recomputes the session's queue summary from its tasks.
+func (s *Session) refreshQueue() {
+	var status *QueueStatus
+	for _, t := range s.DAG.Snapshot() {
+		if t.Status != task.StatusReady || t.Queue == nil {
+			continue
+		}
+		if status == nil {
+			status = &QueueStatus{}
+		}
+		status.Tasks++
+		// A task waiting on the shared pool is ahead of the session's own
+		// queue, which waits behind it.
+		if status.NextTask == "" || queuedAhead(*t.Queue, status.Next) {
+			status.NextTask, status.Next = t.ID, *t.Queue
+		}
+	}
+
+	s.mu.Lock()
+	s.Queue = status
+	s.mu.Unlock()
+}
+
+// queuedAhead reports whether a task at place a starts before one at b.
+func queuedAhead(a, b task.QueueInfo) bool {
+	if (a.Reason == task.QueueSession) != (b.Reason == task.QueueSession) {
+		return b.Reason == task.QueueSession
+	}
+	return a.Position < b.Position
+}
+
+// QueueStatus returns the session's queue summary, or nil when no task
+// waits for a slot.
+func (s *Session) QueueStatus() *QueueStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Queue
+}
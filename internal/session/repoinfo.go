@@ -0,0 +1,45 @@
+package session
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// RepoInfo describes the checkout a session operates on.
+type RepoInfo struct {
+	Path   string `json:"path"`             // resolved repository path
+	Head   string `json:"head,omitempty"`   // commit HEAD points to
+	Branch string `json:"branch,omitempty"` // branch merges go into
+	Bare   bool   `json:"bare,omitempty"`
+	Dirty  bool   `json:"dirty"` // primary checkout has uncommitted changes
+}
+
+// RefreshRepo updates Repo from the repository's current state, so
+// responses show which checkout and commit the session works on. A change
+// bumps the session's revision, and with it its ETag.
+func (s *Session) RefreshRepo(ctx context.Context) {
+	info := &RepoInfo{Path: s.RepoPath}
+	if abs, err := filepath.Abs(s.RepoPath); err == nil {
+		info.Path = abs
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			info.Path = resolved
+		}
+	}
+	if s.worktreeMgr != nil {
+		info.Head, _ = s.worktreeMgr.HeadCommit(ctx)
+		info.Branch, _ = s.mergeTarget(ctx)
+		info.Bare = s.worktreeMgr.IsBare(ctx)
+		if !info.Bare {
+			files, _ := s.worktreeMgr.ChangedFiles(ctx, s.RepoPath)
+			info.Dirty = len(files) > 0
+		}
+	}
+
+	s.mu.Lock()
+	changed := s.Repo == nil || *s.Repo != *info
+	s.Repo = info
+	s.mu.Unlock()
+	if changed {
+		s.bumpRevision()
+	}
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"codex-agent-team/internal/events"
+)
+
+// revisionEpoch tells revisions of different server runs apart, since
+// revision counters start over when sessions are loaded.
+var revisionEpoch = time.Now().UnixNano()
+
+// Revision returns the session's revision, which changes whenever the
+// session is saved or publishes an event, and a channel closed on the
+// next change.
+func (s *Session) Revision() (uint64, <-chan struct{}) {
+	s.revMu.Lock()
+	defer s.revMu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.revision, s.changed
+}
+
+// ETag returns an entity tag for the session's current revision.
+func (s *Session) ETag() string {
+	rev, _ := s.Revision()
+	return fmt.Sprintf(`"%x-%d"`, revisionEpoch, rev)
+}
+
+// bumpRevision records a change and wakes the callers waiting for one.
+func (s *Session) bumpRevision() {
+	s.revMu.Lock()
+	defer s.revMu.Unlock()
+	s.revision++
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+// trackRevisions bumps the revision of the session an event belongs to.
+func (m *Manager) trackRevisions(ev events.Event) {
+	if ev.SessionID == "" {
+		return
+	}
+	if sess, ok := m.Get(ev.SessionID); ok {
+		sess.bumpRevision()
+	}
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoMergeToRollback is returned when the session has no failed merge
+// phase whose starting commit is known.
+var ErrNoMergeToRollback = errors.New("no failed merge to roll back")
+
+// recordPreMerge remembers the checkout's HEAD as the merge phase starts,
+// so a failed phase can be rolled back to it.
+func (s *Session) recordPreMerge(ctx context.Context, co checkout) {
+	head, err := s.worktreeMgr.HeadCommitAt(ctx, co.Dir)
+	if err != nil {
+		s.notify("merge: cannot record the pre-merge commit, rollback will be unavailable: %v", err)
+		return
+	}
+	s.mu.Lock()
+	s.PreMergeCommit = head
+	s.mu.Unlock()
+	s.save()
+}
+
+// RollbackMerge resets the checkout's branch to the commit it was at when
+// a failed merge phase started, dropping the branches it did merge, and
+// returns the session to merging so the merge can be run again. It returns
+// the commit the branch was reset to.
+func (s *Session) RollbackMerge(ctx context.Context, opts RunOptions) (string, error) {
+	if err := s.requireStatus(StatusFailed); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	commit := s.PreMergeCommit
+	s.mu.RUnlock()
+	if commit == "" {
+		return "", ErrNoMergeToRollback
+	}
+
+	unlock, err := s.lockRepo(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	co, err := s.mergeCheckout(ctx)
+	if err != nil {
+		return "", fmt.Errorf("rollback: %w", err)
+	}
+	// A merge left in progress is aborted first; there is usually none
+	_ = s.worktreeMgr.AbortMerge(ctx, co.Dir)
+	if err := s.checkClean(ctx, opts); err != nil {
+		return "", err
+	}
+	err = s.withStash(ctx, opts, co, func() error {
+		return s.worktreeMgr.ResetHard(ctx, co.Dir, commit)
+	})
+	if err != nil {
+		return "", fmt.Errorf("rollback: %w", err)
+	}
+
+	s.mu.Lock()
+	s.PreMergeCommit = ""
+	s.LastMerge = nil
+	s.Verification = nil
+	s.mu.Unlock()
+	s.notify("merge rolled back to %s", shortSHA(commit))
+	if err := s.transition(StatusMerging); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
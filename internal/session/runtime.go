@@ -0,0 +1,95 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"codex-agent-team/internal/agent"
+)
+
+// ErrUnknownRuntime is returned for a codex binary or model provider that
+// is not registered with the server.
+var ErrUnknownRuntime = errors.New("unknown codex runtime")
+
+// Runtimes lists the codex binaries and model providers sessions may
+// select, by name.
+type Runtimes struct {
+	Binaries       []string `json:"binaries"`
+	ModelProviders []string `json:"modelProviders"`
+}
+
+// Runtimes returns the registered codex binaries and model providers.
+func (m *Manager) Runtimes() Runtimes {
+	rts := Runtimes{
+		Binaries:       make([]string, 0, len(m.opts.CodexBinaries)),
+		ModelProviders: append([]string{}, m.opts.ModelProviders...),
+	}
+	for name := range m.opts.CodexBinaries {
+		rts.Binaries = append(rts.Binaries, name)
+	}
+	sort.Strings(rts.Binaries)
+	return rts
+}
+
+// ResolveRuntime looks up a registered codex binary and model provider.
+// Empty names select the server defaults.
+func (m *Manager) ResolveRuntime(codex, provider string) (agent.Runtime, error) {
+	var rt agent.Runtime
+	if codex != "" {
+		bin, ok := m.opts.CodexBinaries[codex]
+		if !ok {
+			return rt, fmt.Errorf("%w: codex binary %q is not registered", ErrUnknownRuntime, codex)
+		}
+		rt.Binary = bin
+	}
+	if provider != "" {
+		if !slices.Contains(m.opts.ModelProviders, provider) {
+			return rt, fmt.Errorf("%w: model provider %q is not registered", ErrUnknownRuntime, provider)
+		}
+		rt.ModelProvider = provider
+	}
+	return rt, nil
+}
+
+// SetRuntime makes every agent of the session run with the named codex
+// binary and model provider. It must be called before the session is
+// decomposed, so a plan is never split across accounts.
+func (s *Session) SetRuntime(codex, provider string) error {
+	rt, err := s.mgr.ResolveRuntime(codex, provider)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.Status != StatusCreated {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: runtime can only be set on a new session", ErrInvalidTransition)
+	}
+	s.Codex, s.ModelProvider = codex, provider
+	s.mu.Unlock()
+	s.applyRuntime(rt)
+
+	if s.store != nil {
+		return s.store.Save(s)
+	}
+	return nil
+}
+
+// applyRuntime configures the session's agents to run with rt.
+func (s *Session) applyRuntime(rt agent.Runtime) {
+	s.mu.Lock()
+	s.runtime = rt
+	s.mu.Unlock()
+	s.Orchestrator.SetRuntime(rt)
+	s.Merger.SetRuntime(rt)
+}
+
+// Runtime returns the resolved codex binary and model provider of the
+// session's agents.
+func (s *Session) Runtime() agent.Runtime {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.runtime
+}
@@ -0,0 +1,249 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/cron"
+)
+
+// Schedule creates and runs a session whenever its cron expression fires,
+// e.g. a nightly dependency update.
+type Schedule struct {
+	Name     string `json:"name"`
+	Cron     string `json:"cron"`               // five-field expression or @daily etc.
+	Timezone string `json:"timezone,omitempty"` // IANA zone of Cron (default: server local time)
+	RunSpec
+	Paused bool `json:"paused,omitempty"`
+
+	CreatedAt string   `json:"createdAt"`
+	LastRun   *AutoRun `json:"lastRun,omitempty"`
+}
+
+// Normalize validates the schedule.
+func (sc *Schedule) Normalize() error {
+	if !templateNamePattern.MatchString(sc.Name) {
+		return fmt.Errorf("invalid schedule name %q", sc.Name)
+	}
+	if _, err := sc.expr(); err != nil {
+		return err
+	}
+	if _, err := sc.location(); err != nil {
+		return err
+	}
+	if err := sc.RunSpec.validate("schedule " + sc.Name); err != nil {
+		return err
+	}
+	if sc.CreatedAt == "" {
+		sc.CreatedAt = time.Now().Format(timeFormat)
+	}
+	return nil
+}
+
+// expr parses the cron expression.
+func (sc *Schedule) expr() (*cron.Expr, error) {
+	return cron.Parse(sc.Cron)
+}
+
+// location returns the time zone the cron expression is evaluated in.
+func (sc *Schedule) location() (*time.Location, error) {
+	if sc.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(sc.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s: %w", sc.Name, err)
+	}
+	return loc, nil
+}
+
+// Next returns when the schedule next fires after t, or the zero time if
+// it is paused or never fires.
+func (sc *Schedule) Next(t time.Time) time.Time {
+	expr, err := sc.expr()
+	loc, locErr := sc.location()
+	if sc.Paused || err != nil || locErr != nil {
+		return time.Time{}
+	}
+	return expr.Next(t.In(loc))
+}
+
+// due reports whether the schedule fires at the minute containing t.
+func (sc *Schedule) due(t time.Time) bool {
+	expr, err := sc.expr()
+	loc, locErr := sc.location()
+	if sc.Paused || err != nil || locErr != nil {
+		return false
+	}
+	return expr.Match(t.In(loc))
+}
+
+// ScheduleStore handles schedule persistence to disk.
+type ScheduleStore struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewScheduleStore creates a new schedule store.
+func NewScheduleStore(dataDir string) (*ScheduleStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	return &ScheduleStore{dir: dataDir}, nil
+}
+
+// Save saves a schedule to disk, replacing any schedule with the same name.
+func (s *ScheduleStore) Save(sc *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bytes, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.schedulePath(sc.Name), bytes, 0644)
+}
+
+// Get loads a schedule by name.
+func (s *ScheduleStore) Get(name string) (*Schedule, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid schedule name %q", name)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bytes, err := os.ReadFile(s.schedulePath(name))
+	if err != nil {
+		return nil, err
+	}
+	var sc Schedule
+	if err := json.Unmarshal(bytes, &sc); err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// List loads all schedules from disk.
+func (s *ScheduleStore) List() ([]*Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var schedules []*Schedule
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		bytes, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var sc Schedule
+		if err := json.Unmarshal(bytes, &sc); err != nil {
+			continue
+		}
+		schedules = append(schedules, &sc)
+	}
+	return schedules, nil
+}
+
+// Delete removes a schedule from disk.
+func (s *ScheduleStore) Delete(name string) error {
+	if !templateNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid schedule name %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.Remove(s.schedulePath(name))
+}
+
+// schedulePath returns the file path for a schedule.
+func (s *ScheduleStore) schedulePath(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// Schedules returns the schedule store.
+func (m *Manager) Schedules() *ScheduleStore {
+	return m.schedules
+}
+
+// StartScheduler runs due schedules every minute until the manager shuts
+// down.
+func (m *Manager) StartScheduler() {
+	go func() {
+		for {
+			now := time.Now()
+			timer := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+			select {
+			case <-m.ctx.Done():
+				timer.Stop()
+				return
+			case tick := <-timer.C:
+				m.runDueSchedules(tick)
+			}
+		}
+	}()
+}
+
+// runDueSchedules starts the schedules firing at the minute of t.
+func (m *Manager) runDueSchedules(t time.Time) {
+	if m.schedules == nil {
+		return
+	}
+	schedules, err := m.schedules.List()
+	if err != nil {
+		log.Printf("Scheduler: %v", err)
+		return
+	}
+	for _, sc := range schedules {
+		if !sc.due(t) {
+			continue
+		}
+		if _, err := m.startSchedule(sc, "cron"); err != nil {
+			log.Printf("Scheduler: %s: %v", sc.Name, err)
+		}
+	}
+}
+
+// RunSchedule starts a run of the named schedule in the background now,
+// regardless of its cron expression. A schedule runs at most once at a
+// time.
+func (m *Manager) RunSchedule(name string) (AutoRun, error) {
+	if m.schedules == nil {
+		return AutoRun{}, fmt.Errorf("schedule store unavailable")
+	}
+	sc, err := m.schedules.Get(name)
+	if err != nil {
+		return AutoRun{}, err
+	}
+	return m.startSchedule(sc, "manual")
+}
+
+// startSchedule starts a run of sc and records it as the schedule's last run.
+func (m *Manager) startSchedule(sc *Schedule, trigger string) (AutoRun, error) {
+	return m.startAutoRun(AutoRunSchedule, sc.Name, trigger, sc.RunSpec, func(run AutoRun) {
+		// Reload so edits made during the run are kept
+		current, err := m.schedules.Get(sc.Name)
+		if err != nil {
+			return
+		}
+		current.LastRun = &run
+		if err := m.schedules.Save(current); err != nil {
+			log.Printf("Scheduler: %s: %v", sc.Name, err)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package session
+
+// SetNote writes a scratchpad entry visible to every task of the session
+// that starts afterwards.
+func (s *Session) SetNote(key, value string) {
+	s.Scratchpad.Set(key, value, "")
+	s.save()
+}
+
+// DeleteNote removes a scratchpad entry and reports whether it existed.
+func (s *Session) DeleteNote(key string) bool {
+	if !s.Scratchpad.Delete(key) {
+		return false
+	}
+	s.save()
+	return true
+}
@@ -2,13 +2,20 @@ package session
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/codexrpc"
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/issues"
 	"codex-agent-team/internal/task"
 	"codex-agent-team/internal/worktree"
 )
@@ -18,8 +25,10 @@ type Session struct {
 	ID           string
 	UserTask     string
 	RepoPath     string
+	TenantID     string // owning tenant, empty when tenants are not configured
 	Status       SessionStatus
 	DAG          *task.DAG
+	Scratchpad   *task.Scratchpad // context shared between the session's tasks
 	Orchestrator *agent.Orchestrator
 	Merger       *agent.Merger
 	Executor     *task.Executor
@@ -27,10 +36,43 @@ type Session struct {
 	StartedAt    *time.Time
 	CompletedAt  *time.Time
 
+	Codex         string // registered codex binary, empty for the default
+	ModelProvider string // registered model provider, empty for the default
+
+	Issue *IssueLink // tracker issue the session was created from
+
+	Priority int          // higher-priority sessions get agent slots first
+	Queue    *QueueStatus // ready tasks waiting for a slot, nil when none
+
+	Verification *Verification // tests run on the merged result, nil when disabled
+
+	Changes *worktree.ChangeStat // summed over completed tasks, nil before any
+
+	PreMergeCommit string       // checkout HEAD when the last merge phase started, cleared on success
+	LastMerge      *MergeRecord // provenance of the last merge phase, nil before any
+	PRStack        []StackedPR  // pull requests opened instead of merging locally
+
+	Repo *RepoInfo // checkout the session operates on, see RefreshRepo
+
 	mu          sync.RWMutex
+	runCtx      context.Context    // owns background operations, see Context
+	cancelRun   context.CancelFunc // cancels runCtx
+	jobsMu      sync.Mutex
+	jobs        map[string]*Job // background operations by ID
 	agentMgr    *agent.Manager
 	worktreeMgr *worktree.Manager
 	store       *Store
+	cache       *DecompositionCache
+	newRunner   func(repoPath string) task.Runner
+	bus         *events.Bus
+	mgr         *Manager
+
+	confirmed *Estimate     // estimate approved through Confirm
+	runtime   agent.Runtime // resolved Codex and ModelProvider
+
+	revMu    sync.Mutex
+	revision uint64        // bumped on every change, see Revision
+	changed  chan struct{} // closed on the next change, nil until waited on
 }
 
 // SessionStatus represents the current status of a session.
@@ -44,6 +86,7 @@ const (
 	StatusCompleted   SessionStatus = "completed"
 	StatusFailed      SessionStatus = "failed"
 	StatusMerging     SessionStatus = "merging"
+	StatusArchived    SessionStatus = "archived"
 )
 
 // Manager manages multiple sessions.
@@ -51,24 +94,173 @@ type Manager struct {
 	mu       sync.RWMutex
 	sessions map[string]*Session
 	agentMgr *agent.Manager
-	wtMgr    *worktree.Manager
-	store    *Store
+	wtMgr     *worktree.Manager
+	store     *Store
+	templates *TemplateStore
+	cache     *DecompositionCache
+	newRunner func(repoPath string) task.Runner
+	limiter   func(tenantID string) task.Limiter
+	opts      Options
+	bus       *events.Bus
+	ctx       context.Context    // parent of every session context
+	stop      context.CancelFunc // cancels ctx on Shutdown
+
+	lockMu    sync.Mutex
+	repoLocks map[string]*sync.Mutex // per-repo merge serialization
+
+	adhocMu sync.Mutex
+	adhoc   map[string]*AdHocAgent // standalone agents by ID
+
+	schedules *ScheduleStore
+	watches   *WatchStore
+	autoRunMu sync.Mutex
+	autoRuns  map[string]bool // schedules and watches with a run in progress, by kind/name
+	watchMu   sync.Mutex
+	heads     map[string]string // last HEAD seen per watched repository
+
+	slots *agentSlots // agent pool shared by all sessions, nil when unlimited
+
+	timelines  *TimelineStore
+	artifacts  *ArtifactStore
+	mergeNotes *MergeNotesStore
+
+	pullRequests issues.PullRequestOpener // opens PR stacks, nil when not configured
 }
 
+// Options configures a Manager. The zero value uses the defaults.
+type Options struct {
+	DataDir      string // sessions, templates and caches (default: user cache dir)
+	WorktreeRoot string // where task worktrees are created (default: <repo>/.worktrees)
+	CloneMode    bool   // use temporary clones instead of git worktrees
+	MaxParallel  int    // tasks run at once per session (default: 3)
+
+	MaxAgents      int // tasks run at once across all sessions (0 for no limit)
+	UrgentPriority int // sessions at or above pause lower-priority scheduling (0 to disable)
+
+	RoleBinaries         map[agent.Role]string // per-role codex binaries
+	RoleSandboxes        map[agent.Role]string // per-role sandbox overrides
+	RoleEphemeral        map[agent.Role]bool   // per-role ephemeral thread overrides
+	ThreadApprovalPolicy string                // codex approval policy of new threads
+
+	RoleMCPServers map[agent.Role]map[string]agent.MCPServer // per-role MCP servers by name
+	TestCommand    agent.TestCommand                         // backs the run_tests tool of workers
+
+	VerifyMerge bool // run TestCommand on the merged result
+	FixAttempts int  // fixer agents tried when the merged result fails its tests
+
+	OctopusThreshold int  // branches above which merges try an octopus merge first (default: 3)
+	NoOctopus        bool // always merge branches one at a time
+	ResolveAttempts  int  // merger agent prompts per conflicted file (default: 2)
+
+	ResolveCheck agent.TestCommand // validates resolved conflicts before the merge is committed
+
+	PRRemote string // remote PR stack branches are pushed to (default: origin)
+
+	TargetBranch string // branch merges go into (default: the checked-out branch)
+	PushRemote   string // remote the merged target branch is pushed to, empty to not push
+
+	CodeIndex  bool             // ground decompositions in a symbol index of the repo
+	PlanLimits agent.PlanLimits // guardrails on decomposition size
+
+	Gate ExecutionGate // estimates that need confirmation before execution
+
+	CodexBinaries  map[string]string // codex binaries sessions may select, by name
+	ModelProviders []string          // model providers sessions may select
+
+	MaxInFlightCalls int                   // outstanding app-server calls per agent (0 for no limit)
+	Heartbeat        agent.HeartbeatPolicy // liveness pings of agent app-servers
+	RPCTraceDir      string                // records agents' JSON-RPC frames here (empty to disable)
+}
+
+// defaultMaxParallel is the number of tasks a session runs at once.
+const defaultMaxParallel = 3
+
 // NewManager creates a new Session Manager.
 func NewManager(codexBin, repoPath string) *Manager {
-	cacheDir, _ := os.UserCacheDir()
-	store, _ := NewStore(filepath.Join(cacheDir, "codex-agent-team", "sessions"))
+	return NewManagerWithOptions(codexBin, repoPath, Options{})
+}
+
+// NewManagerWithOptions creates a new Session Manager with explicit options.
+func NewManagerWithOptions(codexBin, repoPath string, opts Options) *Manager {
+	dataDir := opts.DataDir
+	if dataDir == "" {
+		cacheDir, _ := os.UserCacheDir()
+		dataDir = filepath.Join(cacheDir, "codex-agent-team")
+	}
+	if opts.MaxParallel <= 0 {
+		opts.MaxParallel = defaultMaxParallel
+	}
+	store, _ := NewStore(filepath.Join(dataDir, "sessions"))
+	templates, _ := NewTemplateStore(filepath.Join(dataDir, "templates"))
+	cache, _ := NewDecompositionCache(filepath.Join(dataDir, "decompositions"))
+	schedules, _ := NewScheduleStore(filepath.Join(dataDir, "schedules"))
+	watches, _ := NewWatchStore(filepath.Join(dataDir, "watches"))
+	timelines, _ := NewTimelineStore(filepath.Join(dataDir, "timelines"))
+	artifacts, _ := NewArtifactStore(filepath.Join(dataDir, "artifacts"))
+	mergeNotes, _ := NewMergeNotesStore(filepath.Join(dataDir, "mergenotes"))
+
+	agentMgr := agent.NewManager(codexBin)
+	for role, bin := range opts.RoleBinaries {
+		agentMgr.SetRoleBinary(role, bin)
+	}
+	for role, mode := range opts.RoleSandboxes {
+		agentMgr.SetRoleSandbox(role, mode)
+	}
+	for role, ephemeral := range opts.RoleEphemeral {
+		agentMgr.SetRoleEphemeral(role, ephemeral)
+	}
+	agentMgr.SetTestCommand(opts.TestCommand)
+	for role, servers := range opts.RoleMCPServers {
+		agentMgr.SetRoleMCPServers(role, servers)
+	}
+	agentMgr.SetThreadApprovalPolicy(opts.ThreadApprovalPolicy)
+	agentMgr.SetMaxInFlightCalls(opts.MaxInFlightCalls)
+	agentMgr.SetHeartbeat(opts.Heartbeat)
+	agentMgr.SetRPCTraceDir(opts.RPCTraceDir)
+
 	mgr := &Manager{
-		sessions: make(map[string]*Session),
-		agentMgr: agent.NewManager(codexBin),
-		wtMgr:    worktree.NewManager(repoPath),
-		store:    store,
+		sessions:  make(map[string]*Session),
+		agentMgr:  agentMgr,
+		store:     store,
+		templates: templates,
+		cache:     cache,
+		bus:       events.NewBus(),
+		repoLocks: make(map[string]*sync.Mutex),
+		adhoc:     make(map[string]*AdHocAgent),
+		opts:      opts,
+
+		schedules: schedules,
+		watches:   watches,
+		autoRuns:  make(map[string]bool),
+		heads:     make(map[string]string),
+
+		slots: newAgentSlots(opts.MaxAgents, opts.UrgentPriority),
+
+		timelines:  timelines,
+		artifacts:  artifacts,
+		mergeNotes: mergeNotes,
+	}
+	mgr.ctx, mgr.stop = context.WithCancel(context.Background())
+	mgr.wtMgr = mgr.newWorktreeManager(repoPath)
+	if timelines != nil {
+		mgr.bus.Subscribe("timeline", events.SinkFunc(mgr.recordTimeline))
 	}
+	mgr.bus.Subscribe("revisions", events.SinkFunc(mgr.trackRevisions))
 	mgr.loadSessions()
+	go mgr.drainAgentEvents()
 	return mgr
 }
 
+// newWorktreeManager creates a worktree manager honoring WorktreeRoot.
+func (m *Manager) newWorktreeManager(repoPath string) *worktree.Manager {
+	wtMgr := worktree.NewManager(repoPath)
+	if m.opts.WorktreeRoot != "" {
+		wtMgr.SetRoot(m.opts.WorktreeRoot)
+	}
+	wtMgr.SetCloneMode(m.opts.CloneMode)
+	return wtMgr
+}
+
 // loadSessions restores sessions from disk.
 func (m *Manager) loadSessions() {
 	if m.store == nil {
@@ -84,10 +276,16 @@ func (m *Manager) loadSessions() {
 			ID:        data.ID,
 			UserTask:  data.UserTask,
 			RepoPath:  data.RepoPath,
+			TenantID:  data.TenantID,
 			Status:    data.Status,
 			DAG:       task.NewDAG(),
+			Scratchpad: task.NewScratchpad(),
 			agentMgr:  m.agentMgr,
 			store:     m.store,
+			cache:     m.cache,
+			newRunner: m.newRunner,
+			bus:       m.bus,
+			mgr:       m,
 		CreatedAt: parseTime(data.CreatedAt),
 		}
 		if data.StartedAt != nil {
@@ -98,12 +296,62 @@ func (m *Manager) loadSessions() {
 			t := parseTime(*data.CompletedAt)
 			sess.CompletedAt = &t
 		}
+		// Restore the DAG checkpoint
+		for i := range data.Tasks {
+			t := data.Tasks[i]
+			_ = sess.DAG.AddTask(&t)
+		}
+		sess.Scratchpad.Restore(data.Scratchpad)
+		// Work in flight when the process died cannot continue; it can be resumed
+		interrupted := sess.Status
+		if interrupted == StatusRunning || interrupted == StatusDecomposing {
+			sess.Status = StatusFailed
+		}
 		// Recreate worktree manager and agents for active sessions
-		sess.worktreeMgr = worktree.NewManager(data.RepoPath)
-		sess.Orchestrator = agent.NewOrchestrator(m.agentMgr)
-		sess.Merger = agent.NewMerger(m.agentMgr, sess.worktreeMgr)
+		sess.worktreeMgr = m.newWorktreeManager(data.RepoPath)
+		sess.Orchestrator = m.newOrchestrator()
+		sess.Merger = m.newMerger(sess.worktreeMgr)
+		// The resolved runtime is kept, so a session keeps its binary even
+		// if the registry changes
+		sess.Codex, sess.ModelProvider = data.Codex, data.ModelProvider
+		sess.applyRuntime(data.Runtime)
+		sess.Issue = data.Issue
+		sess.Priority = data.Priority
+		sess.Verification = data.Verification
+		sess.PreMergeCommit = data.PreMergeCommit
+		sess.LastMerge = data.LastMerge
+		sess.PRStack = data.PRStack
+		sess.refreshChanges()
 		m.sessions[data.ID] = sess
+		if sess.Status != interrupted {
+			sess.save()
+			sess.publishStatus(interrupted, sess.Status)
+		}
+	}
+}
+
+// newOrchestrator creates an orchestrator configured by the options.
+func (m *Manager) newOrchestrator() *agent.Orchestrator {
+	o := agent.NewOrchestrator(m.agentMgr)
+	o.SetCodeIndex(m.opts.CodeIndex)
+	o.SetLimits(m.opts.PlanLimits)
+	return o
+}
+
+// newMerger creates a merger configured by the options.
+func (m *Manager) newMerger(wtMgr *worktree.Manager) *agent.Merger {
+	mg := agent.NewMerger(m.agentMgr, wtMgr)
+	switch {
+	case m.opts.NoOctopus:
+		mg.SetOctopusThreshold(-1)
+	case m.opts.OctopusThreshold > 0:
+		mg.SetOctopusThreshold(m.opts.OctopusThreshold)
+	}
+	if m.opts.ResolveAttempts > 0 {
+		mg.SetResolveAttempts(m.opts.ResolveAttempts)
 	}
+	mg.SetCheckCommand(m.opts.ResolveCheck)
+	return mg
 }
 
 // parseTime parses a time string.
@@ -125,14 +373,19 @@ func (m *Manager) Create(ctx context.Context, userTask string) (*Session, error)
 		RepoPath: m.wtMgr.GetRepoPath(),
 		Status:   StatusCreated,
 		DAG:      task.NewDAG(),
+		Scratchpad: task.NewScratchpad(),
 		CreatedAt: time.Now(),
 		agentMgr:    m.agentMgr,
 		worktreeMgr: m.wtMgr,
 		store:       m.store,
+		cache:       m.cache,
+		newRunner:   m.newRunner,
+		bus:         m.bus,
+		mgr:         m,
 	}
 
-	sess.Orchestrator = agent.NewOrchestrator(m.agentMgr)
-	sess.Merger = agent.NewMerger(m.agentMgr, m.wtMgr)
+	sess.Orchestrator = m.newOrchestrator()
+	sess.Merger = m.newMerger(m.wtMgr)
 
 	m.sessions[id] = sess
 	if m.store != nil {
@@ -141,23 +394,58 @@ func (m *Manager) Create(ctx context.Context, userTask string) (*Session, error)
 	return sess, nil
 }
 
+// DecomposeResult describes how a decomposition was obtained.
+type DecomposeResult struct {
+	Cached   bool     `json:"cached"`   // plan came from the decomposition cache
+	Warnings []string `json:"warnings"` // problems repaired in the plan
+}
+
 // Decompose decomposes the user task into sub-tasks.
-func (s *Session) Decompose(ctx context.Context) error {
+// A cached plan for the same repo HEAD and task is reused unless force is set.
+// Invalid dependencies emitted by the orchestrator are dropped and reported
+// as warnings, as are corrected or missing files; a dependency cycle fails
+// the decomposition.
+func (s *Session) Decompose(ctx context.Context, force bool) (*DecomposeResult, error) {
+	if err := s.transition(StatusDecomposing); err != nil {
+		return nil, err
+	}
+	return s.decompose(ctx, force)
+}
+
+// decompose runs the decomposition of a session already decomposing.
+func (s *Session) decompose(ctx context.Context, force bool) (*DecomposeResult, error) {
+	// The cache is skipped entirely when HEAD cannot be resolved
+	head := ""
+	if s.cache != nil {
+		head, _ = s.worktreeMgr.HeadCommit(ctx)
+	}
+
+	var decomp *agent.TaskDecomposition
+	var cached bool
+	if head != "" && !force {
+		decomp, cached = s.cache.Get(head, s.UserTask)
+	}
+
+	if !cached {
+		var err error
+		decomp, err = s.Orchestrator.Decompose(ctx, s.readDir(ctx), s.UserTask)
+		if err != nil {
+			_ = s.transition(StatusFailed)
+			return nil, fmt.Errorf("decompose: %w", err)
+		}
+		if head != "" {
+			_ = s.cache.Put(head, s.UserTask, decomp)
+		}
+	}
+
+	// A new plan needs a new confirmation
 	s.mu.Lock()
-	s.Status = StatusDecomposing
-	now := time.Now()
-	s.StartedAt = &now
+	s.confirmed = nil
 	s.mu.Unlock()
-	s.save()
 
-	decomp, err := s.Orchestrator.Decompose(ctx, s.RepoPath, s.UserTask)
-	if err != nil {
-		s.mu.Lock()
-		s.Status = StatusFailed
-		s.mu.Unlock()
-		s.save()
-		return fmt.Errorf("decompose: %w", err)
-	}
+	result := &DecomposeResult{Cached: cached, Warnings: decomp.Sanitize()}
+	result.Warnings = append(result.Warnings, s.Orchestrator.ResolveFiles(ctx, s.readDir(ctx), decomp)...)
+	result.Warnings = append(result.Warnings, s.Orchestrator.CheckLimits(decomp)...)
 
 	// Convert suggestions to Tasks and add to DAG
 	for _, sug := range decomp.Tasks {
@@ -167,86 +455,459 @@ func (s *Session) Decompose(ctx context.Context) error {
 			Description: sug.Description,
 			Status:      task.StatusPending,
 			DependsOn:   sug.DependsOn,
+			Files:       sug.Files,
+			Outputs:     sug.Outputs,
+			Collect:     sug.Collect,
 			CreatedAt:   time.Now(),
 		}
+		t.EstimatedTime = sug.EstimatedTime
 		if err := s.DAG.AddTask(t); err != nil {
-			return fmt.Errorf("add task: %w", err)
+			_ = s.transition(StatusFailed)
+			return nil, fmt.Errorf("add task: %w", err)
 		}
 	}
 
-	s.mu.Lock()
-	s.Status = StatusReady
-	s.mu.Unlock()
-	s.save()
+	if s.DAG.HasCycle() {
+		_ = s.transition(StatusFailed)
+		return nil, fmt.Errorf("decompose: dependency cycle in plan")
+	}
 
-	return nil
+	if err := s.transition(StatusReady); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-// Execute starts executing the task DAG.
-func (s *Session) Execute(ctx context.Context) error {
-	s.mu.Lock()
-	s.Status = StatusRunning
-	s.mu.Unlock()
+// Execute executes the task DAG and blocks until it finishes.
+// It refuses to start on a dirty checkout unless opts.AutoStash is set.
+func (s *Session) Execute(ctx context.Context, opts RunOptions) error {
+	if err := s.checkConfirmed(); err != nil {
+		return err
+	}
+	if err := s.checkClean(ctx, opts); err != nil {
+		return err
+	}
+	if err := s.transition(StatusRunning); err != nil {
+		return err
+	}
+	s.warnConcurrentSessions()
+	return s.runExecutor(ctx)
+}
+
+// ExecuteAsync validates that execution may start, then runs the DAG in the
+// background on the session context and calls done with the result. The
+// returned job tracks the run.
+func (s *Session) ExecuteAsync(ctx context.Context, opts RunOptions, done func(error)) (Job, error) {
+	if err := s.checkConfirmed(); err != nil {
+		return Job{}, err
+	}
+	job, err := s.beginJob("execute")
+	if err != nil {
+		return Job{}, err
+	}
+	if err := s.checkClean(ctx, opts); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+	if err := s.transition(StatusRunning); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+	s.warnConcurrentSessions()
+	go func() {
+		err := s.runExecutor(s.Context())
+		s.endJob(job, err)
+		done(err)
+	}()
+	return s.snapshot(job), nil
+}
+
+// Resume continues a failed or interrupted execution in the background,
+// on the session context.
+// Completed tasks are kept; every other task is reset, its leftover
+// worktree and branch removed, and the DAG is run again. With
+// opts.RetryWithContext, failed tasks start from their failed attempt.
+func (s *Session) Resume(ctx context.Context, opts RunOptions, done func(error)) (Job, error) {
+	if len(s.DAG.GetTasks()) == 0 {
+		return Job{}, fmt.Errorf("%w: session has no tasks to resume", ErrInvalidTransition)
+	}
+	job, err := s.beginJob("resume")
+	if err != nil {
+		return Job{}, err
+	}
+	if err := s.checkClean(ctx, opts); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+	if err := s.transition(StatusRunning); err != nil {
+		s.abortJob(job)
+		return Job{}, err
+	}
+
+	for _, t := range s.DAG.ResetIncomplete() {
+		var attempt *task.Attempt
+		if opts.RetryWithContext && t.Status == task.StatusFailed {
+			attempt = &task.Attempt{FailedAt: time.Now()}
+			if t.FailedAttempt != nil {
+				*attempt = *t.FailedAttempt
+			}
+			attempt.Error = t.Error
+		}
+		s.DAG.SetFailedAttempt(t.ID, attempt)
+		if t.AgentID != "" {
+			_ = s.agentMgr.StopAgent(t.AgentID)
+		}
+		if t.WorktreePath != "" {
+			_ = s.worktreeMgr.ForceRemove(ctx, t.WorktreePath)
+		}
+		if t.BranchName != "" {
+			_ = s.worktreeMgr.DeleteBranch(ctx, t.BranchName)
+		}
+	}
 	s.save()
 
-	s.Executor = task.NewExecutor(s.DAG, s.agentMgr, s.worktreeMgr, 3)
+	go func() {
+		err := s.runExecutor(s.Context())
+		s.endJob(job, err)
+		done(err)
+	}()
+	return s.snapshot(job), nil
+}
+
+// checkpointInterval is how often the DAG is persisted during execution.
+const checkpointInterval = 5 * time.Second
 
-	if err := s.Executor.Run(ctx); err != nil {
-		s.mu.Lock()
-		s.Status = StatusFailed
-		s.mu.Unlock()
-		s.save()
+// runExecutor runs the DAG; the session must already be running.
+// The DAG is checkpointed periodically so a crash can be resumed.
+func (s *Session) runExecutor(ctx context.Context) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(checkpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.save()
+			}
+		}
+	}()
+
+	s.Executor = task.NewExecutor(s.DAG, s.agentMgr, s.worktreeMgr, s.maxParallel())
+	if s.newRunner != nil {
+		s.Executor.SetRunner(s.newRunner(s.RepoPath))
+	}
+	tenant := s.mgr.tenantLimiter(s.TenantID)
+	if s.mgr != nil && s.mgr.slots != nil {
+		s.Executor.SetLimiter(&sessionLimiter{slots: s.mgr.slots, sess: s, tenant: tenant})
+		defer s.mgr.slots.begin(s)()
+	} else if tenant != nil {
+		s.Executor.SetLimiter(tenant)
+	}
+	s.Executor.SetScratchpad(s.Scratchpad)
+	s.Executor.SetRuntime(s.runtime)
+	if s.mgr != nil && s.mgr.artifacts != nil {
+		s.Executor.SetCollector(s.mgr.artifacts.collector(s.ID))
+	}
+
+	done := make(chan struct{})
+	go s.forwardExecutionEvents(s.Executor, done)
+	err := s.Executor.Run(ctx)
+	close(done)
+	if err != nil {
+		_ = s.transition(StatusFailed)
 		return err
 	}
 
-	s.mu.Lock()
-	s.Status = StatusMerging
-	s.mu.Unlock()
-	s.save()
+	return s.transition(StatusMerging)
+}
 
-	return nil
+// forwardExecutionEvents publishes the executor's task lifecycle events on
+// the bus until done is closed and the buffered events are drained.
+func (s *Session) forwardExecutionEvents(e *task.Executor, done <-chan struct{}) {
+	publish := func(ev task.ExecutionEvent) {
+		if s.bus == nil {
+			return
+		}
+		out := events.Event{
+			Source:    events.SourceExecutor,
+			Type:      ev.EventType,
+			SessionID: s.ID,
+			TaskID:    ev.TaskID,
+			Data:      ev.Data,
+		}
+		if t, ok := s.DAG.Get(ev.TaskID); ok {
+			out.AgentID = t.AgentID
+		}
+		s.bus.Publish(out)
+	}
+	track := func(ev task.ExecutionEvent) {
+		switch ev.EventType {
+		case "queued", "started":
+			s.refreshQueue()
+		case "completed":
+			s.refreshChanges()
+		}
+		publish(ev)
+	}
+
+	for {
+		select {
+		case ev := <-e.Events():
+			track(ev)
+		case <-done:
+			for {
+				select {
+				case ev := <-e.Events():
+					track(ev)
+				default:
+					s.refreshQueue()
+					return
+				}
+			}
+		}
+	}
 }
 
 // Merge merges all worktree branches back to main.
-func (s *Session) Merge(ctx context.Context) error {
-	// Get all completed tasks
-	tasks := s.DAG.GetTasks()
+// With opts.AutoStash, local changes in the primary checkout are stashed
+// for the duration of the merge; otherwise a dirty checkout is refused.
+func (s *Session) Merge(ctx context.Context, opts RunOptions) error {
+	if err := s.requireStatus(StatusMerging); err != nil {
+		return err
+	}
+	if err := s.checkClean(ctx, opts); err != nil {
+		return err
+	}
+	return s.merge(ctx, opts)
+}
+
+// unmergedTasks drops tasks whose branch has no commits beyond the
+// target branch, since merging them would only add an empty merge commit.
+// Branches that cannot be checked are kept.
+func (s *Session) unmergedTasks(ctx context.Context, co checkout, taskIDs []string, branchMap map[string]string) []string {
+	if s.worktreeMgr == nil {
+		return taskIDs
+	}
+	target := co.Branch
+	if target == "" {
+		target = "HEAD"
+	}
+	var out []string
+	for _, id := range taskIDs {
+		branch := branchMap[id]
+		if merged, err := s.worktreeMgr.IsAncestor(ctx, branch, target); err == nil && merged {
+			delete(branchMap, id)
+			s.notify("Task %s: branch %s has nothing to merge, skipping", id, branch)
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+// merge merges the completed task branches; the caller has checked the
+// session status and the checkout.
+func (s *Session) merge(ctx context.Context, opts RunOptions) error {
+	// Get all completed tasks; those that changed nothing have nothing to merge
+	tasks := s.DAG.Snapshot()
 
 	branchMap := make(map[string]string)
 	var taskIDs []string
 	for _, t := range tasks {
-		if t.Status == task.StatusCompleted && t.BranchName != "" {
+		if t.Status == task.StatusCompleted && t.BranchName != "" && !t.NoChanges {
 			taskIDs = append(taskIDs, t.ID)
 			branchMap[t.ID] = t.BranchName
 		}
 	}
 
-	plan := s.Merger.CreateMergePlan(taskIDs, branchMap)
+	// Only one merge phase may touch the repository at a time
+	unlock, err := s.lockRepo(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	result, err := s.Merger.Merge(ctx, s.RepoPath, plan)
+	co, err := s.mergeCheckout(ctx)
 	if err != nil {
-		s.mu.Lock()
-		s.Status = StatusFailed
-		s.mu.Unlock()
-		s.save()
+		_ = s.transition(StatusFailed)
 		return fmt.Errorf("merge: %w", err)
 	}
+	s.recordPreMerge(ctx, co)
+	s.applyMergeNotes()
+	taskIDs = s.mergeOrder(tasks, s.unmergedTasks(ctx, co, taskIDs, branchMap))
+	plan := s.Merger.CreateMergePlan(taskIDs, branchMap)
+	if co.Branch != "" {
+		plan.TargetBranch = co.Branch
+	}
+
+	var result *agent.MergeResult
+	var verification *Verification
+	var mergeErr error
+	stashErr := s.withStash(ctx, opts, co, func() error {
+		result, mergeErr = s.Merger.Merge(ctx, co.Dir, plan)
+		if mergeErr == nil && result != nil && result.Success {
+			// Verify before local changes come back into the checkout
+			verification = s.verifyMerge(ctx, co.Dir)
+		}
+		return mergeErr
+	})
+	if result != nil {
+		s.recordMerge(plan, result, branchMap)
+		s.learnFromMerge(result)
+	}
+	if mergeErr != nil || result == nil {
+		_ = s.transition(StatusFailed)
+		if mergeErr == nil {
+			mergeErr = stashErr
+		}
+		return fmt.Errorf("merge: %w", mergeErr)
+	}
 
 	if !result.Success {
-		s.mu.Lock()
-		s.Status = StatusFailed
-		s.mu.Unlock()
-		s.save()
-		return fmt.Errorf("merge failed for branches: %v", result.FailedBranches)
+		_ = s.transition(StatusFailed)
+		return newMergeError(result)
+	}
+	if verification != nil && !verification.Passed {
+		_ = s.transition(StatusFailed)
+		return fmt.Errorf("post-merge tests failed after %d fix attempts", len(verification.Fixes))
+	}
+	if err := s.pushMerged(ctx, co); err != nil {
+		_ = s.transition(StatusFailed)
+		return fmt.Errorf("merge: %w", err)
 	}
 
 	s.mu.Lock()
-	s.Status = StatusCompleted
-	now := time.Now()
-	s.CompletedAt = &now
+	s.PreMergeCommit = ""
 	s.mu.Unlock()
-	s.save()
+	if err := s.transition(StatusCompleted); err != nil {
+		return err
+	}
+	// The merge itself succeeded; only restoring local changes failed
+	return stashErr
+}
 
+// TaskViews returns the session's tasks in topological order, including
+// the live state of their agents.
+func (s *Session) TaskViews() []task.TaskView {
+	return s.DAG.Views(func(agentID string) string {
+		return string(s.agentMgr.GetState(agentID))
+	})
+}
+
+// Transcript returns a task's agent output: the recorded output once the
+// task has finished, or the live output of its running agent.
+func (s *Session) Transcript(taskID string) (string, bool) {
+	for _, t := range s.DAG.Snapshot() {
+		if t.ID != taskID {
+			continue
+		}
+		if len(t.Output) > 0 {
+			return strings.Join(t.Output, ""), true
+		}
+		return s.agentMgr.GetOutput(t.AgentID), true
+	}
+	return "", false
+}
+
+// TaskLevels returns the session's tasks grouped into parallel waves.
+func (s *Session) TaskLevels() ([][]task.TaskView, error) {
+	levels, err := s.DAG.Levels()
+	if err != nil {
+		return nil, err
+	}
+
+	views := make(map[string]task.TaskView)
+	for _, v := range s.TaskViews() {
+		views[v.ID] = v
+	}
+
+	result := make([][]task.TaskView, len(levels))
+	for i, level := range levels {
+		result[i] = make([]task.TaskView, 0, len(level))
+		for _, t := range level {
+			result[i] = append(result[i], views[t.ID])
+		}
+	}
+	return result, nil
+}
+
+// ErrTaskNotFound is returned for a task ID that is not in the session.
+var ErrTaskNotFound = errors.New("task not found")
+
+// InterruptTask stops the current turn of a running task's agent,
+// optionally steering it with a follow-up instruction.
+func (s *Session) InterruptTask(ctx context.Context, taskID, instruction string) error {
+	t, ok := s.DAG.Get(taskID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if t.Status != task.StatusRunning || t.AgentID == "" {
+		return fmt.Errorf("task %s is not running", taskID)
+	}
+	return s.agentMgr.InterruptTurn(ctx, t.AgentID, instruction)
+}
+
+// Release frees the runtime resources held by the session: it stops task
+// agents and removes task worktrees, optionally deleting task branches too.
+func (s *Session) Release(ctx context.Context, deleteBranches bool) {
+	s.cancelRuns()
+	for _, t := range s.DAG.GetTasks() {
+		if t.AgentID != "" {
+			_ = s.agentMgr.StopAgent(t.AgentID)
+		}
+		if t.WorktreePath != "" {
+			if _, err := os.Stat(t.WorktreePath); err == nil {
+				_ = s.worktreeMgr.ForceRemove(ctx, t.WorktreePath)
+			}
+		}
+		if deleteBranches && t.BranchName != "" {
+			_ = s.worktreeMgr.DeleteBranch(ctx, t.BranchName)
+		}
+	}
+}
+
+// Archive frees runtime resources but keeps the session record.
+func (s *Session) Archive(ctx context.Context) error {
+	if err := s.transition(StatusArchived); err != nil {
+		return err
+	}
+	s.Release(ctx, false)
+	return nil
+}
+
+// Delete releases the session's resources and removes it entirely.
+func (m *Manager) Delete(ctx context.Context, id string, deleteBranches bool) error {
+	m.mu.Lock()
+	sess, ok := m.sessions[id]
+	if ok {
+		delete(m.sessions, id)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("session %s not found", id)
+	}
+
+	sess.Release(ctx, deleteBranches)
+
+	if m.store != nil {
+		if err := m.store.Delete(id); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("delete session data: %w", err)
+		}
+	}
+	if m.timelines != nil {
+		if err := m.timelines.Delete(id); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("delete session timeline: %w", err)
+		}
+	}
+	if m.artifacts != nil {
+		if err := m.artifacts.Delete(id); err != nil {
+			return fmt.Errorf("delete session artifacts: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -267,7 +928,7 @@ func (m *Manager) CreateWithPath(ctx context.Context, userTask, repoPath string)
 	id := fmt.Sprintf("session-%d", time.Now().UnixNano())
 
 	// Create a new worktree manager for this session's repo
-	wtMgr := worktree.NewManager(repoPath)
+	wtMgr := m.newWorktreeManager(repoPath)
 
 	sess := &Session{
 		ID:       id,
@@ -275,14 +936,19 @@ func (m *Manager) CreateWithPath(ctx context.Context, userTask, repoPath string)
 		RepoPath: repoPath,
 		Status:   StatusCreated,
 		DAG:      task.NewDAG(),
+		Scratchpad: task.NewScratchpad(),
 		CreatedAt: time.Now(),
 		agentMgr:    m.agentMgr,
 		worktreeMgr: wtMgr,
 		store:       m.store,
+		cache:       m.cache,
+		newRunner:   m.newRunner,
+		bus:         m.bus,
+		mgr:         m,
 	}
 
-	sess.Orchestrator = agent.NewOrchestrator(m.agentMgr)
-	sess.Merger = agent.NewMerger(m.agentMgr, wtMgr)
+	sess.Orchestrator = m.newOrchestrator()
+	sess.Merger = m.newMerger(wtMgr)
 
 	m.sessions[id] = sess
 	if m.store != nil {
@@ -291,6 +957,63 @@ func (m *Manager) CreateWithPath(ctx context.Context, userTask, repoPath string)
 	return sess, nil
 }
 
+// Clone creates a new session that re-runs the plan of an existing one.
+// The task DAG is copied as-is, with fresh branches and worktrees.
+func (m *Manager) Clone(ctx context.Context, id string) (*Session, error) {
+	src, ok := m.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	if len(src.DAG.GetTasks()) == 0 {
+		return nil, fmt.Errorf("session %s has no tasks to clone", id)
+	}
+
+	sess, err := m.CreateWithPath(ctx, src.UserTask, src.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := sess.SetRuntime(src.Codex, src.ModelProvider); err != nil {
+		return nil, err
+	}
+
+	sess.mu.Lock()
+	sess.DAG = src.DAG.Clone(sess.ID + "-")
+	sess.Priority = src.GetPriority()
+	sess.mu.Unlock()
+	if err := sess.transition(StatusReady); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+// Templates returns the template store, or nil if it is unavailable.
+func (m *Manager) Templates() *TemplateStore {
+	return m.templates
+}
+
+// CreateFromTemplate creates a ready-to-execute session from a template.
+func (m *Manager) CreateFromTemplate(ctx context.Context, tmpl *Template, params map[string]string, repoPath string) (*Session, error) {
+	userTask, dag, err := tmpl.Instantiate(params)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := m.CreateWithPath(ctx, userTask, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.mu.Lock()
+	sess.DAG = dag.Clone(sess.ID + "-")
+	sess.mu.Unlock()
+	if err := sess.transition(StatusReady); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
 // ListAll returns all sessions.
 func (m *Manager) ListAll() []*Session {
 	m.mu.RLock()
@@ -303,9 +1026,177 @@ func (m *Manager) ListAll() []*Session {
 	return sessions
 }
 
+// SetContainerConfig runs agents of the given role inside containers.
+func (m *Manager) SetContainerConfig(role agent.Role, cfg agent.ContainerConfig) {
+	m.agentMgr.SetContainerConfig(role, cfg)
+}
+
+// SetStallPolicy configures stuck-turn detection for all agents.
+func (m *Manager) SetStallPolicy(p agent.StallPolicy) {
+	m.agentMgr.SetStallPolicy(p)
+}
+
+// SetRunnerFactory makes sessions execute tasks through a remote backend
+// created per repository. It only affects sessions created afterwards.
+func (m *Manager) SetRunnerFactory(f func(repoPath string) task.Runner) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.newRunner = f
+}
+
+// SetLimiterFactory bounds concurrently running tasks per tenant. f may
+// return nil for tenants without a quota.
+func (m *Manager) SetLimiterFactory(f func(tenantID string) task.Limiter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limiter = f
+}
+
+// tenantLimiter returns the task limiter for a tenant, or nil.
+func (m *Manager) tenantLimiter(tenantID string) task.Limiter {
+	if m == nil || tenantID == "" {
+		return nil
+	}
+	m.mu.RLock()
+	f := m.limiter
+	m.mu.RUnlock()
+	if f == nil {
+		return nil
+	}
+	return f(tenantID)
+}
+
+// SetTenant assigns the session to a tenant and persists it.
+func (s *Session) SetTenant(tenantID string) {
+	s.mu.Lock()
+	s.TenantID = tenantID
+	s.mu.Unlock()
+	s.save()
+}
+
+// Bus returns the event bus carrying session, task and agent events.
+func (m *Manager) Bus() *events.Bus {
+	return m.bus
+}
+
+// AgentCallStats returns the app-server call counters of running agents.
+func (m *Manager) AgentCallStats() codexrpc.CallStats {
+	return m.agentMgr.CallStats()
+}
+
+// DroppedAgentEvents returns how many events were lost because an agent
+// event consumer or a bus sink fell behind.
+func (m *Manager) DroppedAgentEvents() uint64 {
+	return m.agentMgr.DroppedEvents() + m.bus.Dropped()
+}
+
+// drainAgentEvents publishes the agent manager's events on the bus,
+// attributed to the owning session and task, for the lifetime of the
+// manager. Agents thus never depend on a consumer being present. The
+// task a turn works on wins over the task the agent was assigned to.
+func (m *Manager) drainAgentEvents() {
+	for ev := range m.agentMgr.Events() {
+		out := events.Event{
+			Source:  events.SourceAgent,
+			Type:    ev.EventType,
+			TaskID:  ev.TaskID,
+			AgentID: ev.AgentID,
+			TurnID:  ev.TurnID,
+		}
+		if sess, taskID, ok := m.FindByAgent(ev.AgentID); ok {
+			out.SessionID = sess.ID
+			if out.TaskID == "" {
+				out.TaskID = taskID
+			}
+		}
+		if ev.Data != nil {
+			out.Data = json.RawMessage(ev.Data)
+		}
+		m.bus.Publish(out)
+	}
+}
+
+// FindByAgent returns the session owning the given agent and, for task
+// agents, the task it works on. Orchestrator, merger and fixer agents
+// belong to their session with an empty task ID.
+func (m *Manager) FindByAgent(agentID string) (*Session, string, bool) {
+	m.mu.RLock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, sess := range m.sessions {
+		sessions = append(sessions, sess)
+	}
+	m.mu.RUnlock()
+
+	for _, sess := range sessions {
+		for _, t := range sess.DAG.GetTasks() {
+			if t.AgentID == agentID {
+				return sess, t.ID, true
+			}
+		}
+	}
+	for _, sess := range sessions {
+		if sess.ownsAgent(agentID) {
+			return sess, "", true
+		}
+	}
+	return nil, "", false
+}
+
+// ownsAgent reports whether agentID is one of the session's orchestrator,
+// merger or fixer agents.
+func (s *Session) ownsAgent(agentID string) bool {
+	if sessionID, ok := fixerSession(agentID); ok {
+		return sessionID == s.ID
+	}
+	s.mu.RLock()
+	orch, merger := s.Orchestrator, s.Merger
+	s.mu.RUnlock()
+	if orch != nil && slices.Contains(orch.AgentIDs(), agentID) {
+		return true
+	}
+	return merger != nil && slices.Contains(merger.AgentIDs(), agentID)
+}
+
 // save persists the session to disk.
 func (s *Session) save() {
+	s.bumpRevision()
 	if s.store != nil {
 		s.store.Save(s)
 	}
 }
+
+// SetApprovalPolicy configures how agent approval requests are answered.
+func (m *Manager) SetApprovalPolicy(p agent.ApprovalPolicy) {
+	m.agentMgr.SetApprovalPolicy(p)
+}
+
+// PendingApproval is an approval request raised by one of a session's
+// agents. TaskID is empty for agents not working on a task.
+type PendingApproval struct {
+	*agent.ApprovalRequest
+	TaskID string `json:"taskId"`
+}
+
+// PendingApprovals returns the session's approval requests still waiting
+// for a decision, oldest first.
+func (m *Manager) PendingApprovals(sess *Session) []PendingApproval {
+	pending := []PendingApproval{}
+	for _, req := range m.agentMgr.PendingApprovals() {
+		owner, taskID, ok := m.FindByAgent(req.AgentID)
+		if ok && owner == sess {
+			pending = append(pending, PendingApproval{ApprovalRequest: req, TaskID: taskID})
+		}
+	}
+	return pending
+}
+
+// DecideApproval answers a pending approval request raised by one of the
+// session's agents.
+func (m *Manager) DecideApproval(sess *Session, approvalID, decision string) error {
+	for _, p := range m.PendingApprovals(sess) {
+		if p.ID == approvalID {
+			return m.agentMgr.DecideApproval(approvalID, decision)
+		}
+	}
+	return agent.ErrApprovalNotFound
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/bench"
+	"codex-agent-team/internal/task"
+)
+
+// Simulation is the projected execution of a session's incomplete tasks,
+// computed from their estimated durations without spawning agents.
+type Simulation struct {
+	MaxParallel    int             `json:"maxParallel"`
+	Waves          [][]string      `json:"waves"` // task IDs by DAG level
+	Tasks          []SimulatedTask `json:"tasks"` // in projected start order
+	WallClockMs    int64           `json:"wallClockMs"`
+	CriticalPathMs int64           `json:"criticalPathMs"`
+	AgentMinutes   float64         `json:"agentMinutes"`
+	Utilization    float64         `json:"utilization"`
+	Worktrees      int             `json:"worktrees"`   // worktrees and branches the run creates
+	Unestimated    int             `json:"unestimated"` // tasks counted at the default duration
+}
+
+// SimulatedTask is the projected run of one task, relative to the start
+// of execution.
+type SimulatedTask struct {
+	TaskID  string `json:"taskId"`
+	Title   string `json:"title"`
+	Wave    int    `json:"wave"`
+	StartMs int64  `json:"startMs"`
+	EndMs   int64  `json:"endMs"`
+}
+
+// Simulate walks the session's incomplete tasks the way the executor
+// would, with at most maxParallel running at once (0 for the session's
+// limit). Tasks without an estimate take the default task duration.
+func (s *Session) Simulate(maxParallel int) (*Simulation, error) {
+	if maxParallel <= 0 {
+		maxParallel = s.maxParallel()
+	}
+	levels, err := s.DAG.Levels()
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []task.Task
+	titles := make(map[string]string)
+	for _, t := range s.DAG.Snapshot() {
+		if t.Status != task.StatusCompleted {
+			tasks = append(tasks, t)
+			titles[t.ID] = t.Title
+		}
+	}
+	sim := &Simulation{
+		MaxParallel: maxParallel,
+		Waves:       [][]string{},
+		Tasks:       []SimulatedTask{},
+		Worktrees:   len(tasks),
+	}
+	if len(tasks) == 0 {
+		return sim, nil
+	}
+
+	wave := make(map[string]int)
+	for _, level := range levels {
+		var ids []string
+		for _, t := range level {
+			if _, ok := titles[t.ID]; ok {
+				wave[t.ID] = len(sim.Waves)
+				ids = append(ids, t.ID)
+			}
+		}
+		if len(ids) > 0 {
+			sim.Waves = append(sim.Waves, ids)
+		}
+	}
+
+	// Projections ignore timings of earlier attempts
+	for i := range tasks {
+		tasks[i].StartedAt, tasks[i].CompletedAt = nil, nil
+	}
+	w, err := bench.NewWorkload(s.ID, s.UserTask, tasks, defaultTaskMinutes*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range w.Jobs {
+		sim.AgentMinutes += job.Duration.Minutes()
+	}
+	for _, t := range tasks {
+		if _, ok := agent.ParseEstimate(t.EstimatedTime); !ok {
+			sim.Unestimated++
+		}
+	}
+
+	res := bench.Simulate(w, bench.FIFO{}, maxParallel, bench.Options{})
+	for _, run := range res.Runs {
+		sim.Tasks = append(sim.Tasks, SimulatedTask{
+			TaskID:  run.JobID,
+			Title:   titles[run.JobID],
+			Wave:    wave[run.JobID],
+			StartMs: run.Start.Milliseconds(),
+			EndMs:   run.End.Milliseconds(),
+		})
+	}
+	sim.WallClockMs = res.WallClock.Milliseconds()
+	sim.CriticalPathMs = w.CriticalPath().Milliseconds()
+	sim.Utilization = res.Utilization
+	return sim, nil
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"codex-agent-team/internal/events"
+)
+
+// ErrInvalidTransition is returned when an operation is not allowed in the
+// session's current status.
+var ErrInvalidTransition = errors.New("invalid session status transition")
+
+// StatusChange describes a session status transition.
+type StatusChange struct {
+	SessionID string        `json:"sessionId"`
+	From      SessionStatus `json:"from"`
+	To        SessionStatus `json:"to"`
+}
+
+// sessionTransitions lists the statuses reachable from each status.
+// StatusMerging means execution finished and the merge is pending.
+var sessionTransitions = map[SessionStatus][]SessionStatus{
+	StatusCreated:     {StatusDecomposing, StatusReady, StatusArchived},
+	StatusDecomposing: {StatusReady, StatusFailed},
+	StatusReady:       {StatusRunning, StatusArchived},
+	StatusRunning:     {StatusMerging, StatusFailed},
+	StatusMerging:     {StatusCompleted, StatusFailed, StatusArchived},
+	StatusCompleted:   {StatusArchived},
+	StatusFailed:      {StatusRunning, StatusMerging, StatusArchived}, // running: resume, merging: merge rollback
+	StatusArchived:    {},
+}
+
+// canTransition reports whether from -> to is a valid transition.
+func canTransition(from, to SessionStatus) bool {
+	for _, s := range sessionTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition validates and applies a status change, persists the session
+// and publishes a StatusChange.
+func (s *Session) transition(to SessionStatus) error {
+	s.mu.Lock()
+	from := s.Status
+	if !canTransition(from, to) {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	s.Status = to
+	now := time.Now()
+	switch to {
+	case StatusDecomposing:
+		s.StartedAt = &now
+	case StatusCompleted:
+		s.CompletedAt = &now
+	}
+	s.mu.Unlock()
+
+	s.save()
+	s.publishStatus(from, to)
+	return nil
+}
+
+// requireStatus returns ErrInvalidTransition unless the session is in want.
+func (s *Session) requireStatus(want SessionStatus) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.Status != want {
+		return fmt.Errorf("%w: session is %s, expected %s", ErrInvalidTransition, s.Status, want)
+	}
+	return nil
+}
+
+// publishStatus publishes a StatusChange on the event bus.
+func (s *Session) publishStatus(from, to SessionStatus) {
+	if s.bus == nil {
+		return
+	}
+	s.bus.Publish(events.Event{
+		Source:    events.SourceSession,
+		Type:      events.TypeStatusChanged,
+		SessionID: s.ID,
+		Data:      StatusChange{SessionID: s.ID, From: from, To: to},
+	})
+}
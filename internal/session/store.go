@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/task"
 )
 
 // Store handles session persistence to disk.
@@ -26,10 +29,27 @@ type sessionData struct {
 	ID          string        `json:"id"`
 	UserTask    string        `json:"userTask"`
 	RepoPath    string        `json:"repoPath"`
+	TenantID    string        `json:"tenantId,omitempty"`
 	Status      SessionStatus `json:"status"`
 	CreatedAt   string        `json:"createdAt"`
 	StartedAt   *string       `json:"startedAt,omitempty"`
 	CompletedAt *string       `json:"completedAt,omitempty"`
+	Tasks       []task.Task   `json:"tasks,omitempty"` // DAG checkpoint
+	Scratchpad  []task.Entry  `json:"scratchpad,omitempty"`
+
+	Codex         string        `json:"codex,omitempty"`
+	ModelProvider string        `json:"modelProvider,omitempty"`
+	Runtime       agent.Runtime `json:"runtime"` // resolved at creation
+
+	Issue *IssueLink `json:"issue,omitempty"`
+
+	Priority int `json:"priority,omitempty"`
+
+	Verification *Verification `json:"verification,omitempty"`
+
+	PreMergeCommit string       `json:"preMergeCommit,omitempty"`
+	LastMerge      *MergeRecord `json:"lastMerge,omitempty"`
+	PRStack        []StackedPR  `json:"prStack,omitempty"`
 }
 
 // Save saves a session to disk.
@@ -44,8 +64,27 @@ func (s *Store) Save(sess *Session) error {
 		ID:        sess.ID,
 		UserTask:  sess.UserTask,
 		RepoPath:  sess.RepoPath,
+		TenantID:  sess.TenantID,
 		Status:    sess.Status,
 		CreatedAt: sess.CreatedAt.Format(timeFormat),
+		Tasks:     sess.DAG.Snapshot(),
+
+		Codex:         sess.Codex,
+		ModelProvider: sess.ModelProvider,
+		Runtime:       sess.runtime,
+
+		Issue: sess.Issue,
+
+		Priority: sess.Priority,
+
+		Verification: sess.Verification,
+
+		PreMergeCommit: sess.PreMergeCommit,
+		LastMerge:      sess.LastMerge,
+		PRStack:        sess.PRStack,
+	}
+	if sess.Scratchpad != nil {
+		data.Scratchpad = sess.Scratchpad.Entries()
 	}
 
 	if sess.StartedAt != nil {
@@ -0,0 +1,261 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/task"
+)
+
+// Template is a reusable, parameterized decomposition.
+// Text fields may contain {{param}} placeholders that are substituted
+// when a session is instantiated from the template.
+type Template struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	UserTask    string         `json:"userTask"`
+	Tasks       []TemplateTask `json:"tasks"`
+	Params      []string       `json:"params"`
+	CreatedAt   string         `json:"createdAt"`
+}
+
+// TemplateTask is a task definition inside a template.
+type TemplateTask struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	DependsOn   []string `json:"dependsOn"`
+	Outputs     []string `json:"outputs,omitempty"`
+	Collect     []string `json:"collect,omitempty"`
+}
+
+// paramPattern matches {{param}} placeholders.
+var paramPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// templateNamePattern restricts names to something safe for a file name.
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidName reports whether name may name a template, schedule or watch.
+func ValidName(name string) bool {
+	return templateNamePattern.MatchString(name)
+}
+
+// NewTemplateFromSession builds a template from a session's task DAG.
+// Each key of replace is rewritten to a {{value}} placeholder, turning
+// concrete names into template parameters.
+func NewTemplateFromSession(name, description string, sess *Session, replace map[string]string) (*Template, error) {
+	tasks := sess.DAG.GetTasks()
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("session %s has no tasks", sess.ID)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	// Longest literals go first so "api-server" wins over "api", and a
+	// single Replacer pass never rewrites an inserted placeholder.
+	literals := make([]string, 0, len(replace))
+	for literal := range replace {
+		if literal != "" {
+			literals = append(literals, literal)
+		}
+	}
+	sort.Slice(literals, func(i, j int) bool {
+		if len(literals[i]) != len(literals[j]) {
+			return len(literals[i]) > len(literals[j])
+		}
+		return literals[i] < literals[j]
+	})
+	pairs := make([]string, 0, 2*len(literals))
+	for _, literal := range literals {
+		pairs = append(pairs, literal, "{{"+replace[literal]+"}}")
+	}
+	replacer := strings.NewReplacer(pairs...)
+	parameterize := replacer.Replace
+
+	tmpl := &Template{
+		Name:        name,
+		Description: description,
+		UserTask:    parameterize(sess.UserTask),
+	}
+	for _, t := range tasks {
+		tmpl.Tasks = append(tmpl.Tasks, TemplateTask{
+			ID:          t.ID,
+			Title:       parameterize(t.Title),
+			Description: parameterize(t.Description),
+			DependsOn:   append([]string(nil), t.DependsOn...),
+			Outputs:     append([]string(nil), t.Outputs...),
+			Collect:     append([]string(nil), t.Collect...),
+		})
+	}
+	return tmpl, nil
+}
+
+// Normalize validates the template and collects its parameters.
+func (t *Template) Normalize() error {
+	if !templateNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name %q", t.Name)
+	}
+	if len(t.Tasks) == 0 {
+		return fmt.Errorf("template %s has no tasks", t.Name)
+	}
+
+	seen := make(map[string]bool)
+	collect := func(s string) {
+		for _, m := range paramPattern.FindAllStringSubmatch(s, -1) {
+			seen[m[1]] = true
+		}
+	}
+	collect(t.UserTask)
+	for _, tt := range t.Tasks {
+		collect(tt.Title)
+		collect(tt.Description)
+	}
+
+	t.Params = t.Params[:0]
+	for p := range seen {
+		t.Params = append(t.Params, p)
+	}
+	sort.Strings(t.Params)
+
+	if t.CreatedAt == "" {
+		t.CreatedAt = time.Now().Format(timeFormat)
+	}
+	return nil
+}
+
+// Instantiate substitutes params and returns the user task and a fresh DAG.
+func (t *Template) Instantiate(params map[string]string) (string, *task.DAG, error) {
+	var missing []string
+	for _, p := range t.Params {
+		if _, ok := params[p]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("missing template params: %s", strings.Join(missing, ", "))
+	}
+
+	substitute := func(s string) string {
+		return paramPattern.ReplaceAllStringFunc(s, func(m string) string {
+			return params[paramPattern.FindStringSubmatch(m)[1]]
+		})
+	}
+
+	dag := task.NewDAG()
+	now := time.Now()
+	for _, tt := range t.Tasks {
+		err := dag.AddTask(&task.Task{
+			ID:          tt.ID,
+			Title:       substitute(tt.Title),
+			Description: substitute(tt.Description),
+			Status:      task.StatusPending,
+			DependsOn:   append([]string(nil), tt.DependsOn...),
+			Outputs:     append([]string(nil), tt.Outputs...),
+			Collect:     append([]string(nil), tt.Collect...),
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("add task %s: %w", tt.ID, err)
+		}
+	}
+	return substitute(t.UserTask), dag, nil
+}
+
+// TemplateStore handles template persistence to disk.
+type TemplateStore struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewTemplateStore creates a new template store.
+func NewTemplateStore(dataDir string) (*TemplateStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	return &TemplateStore{dir: dataDir}, nil
+}
+
+// Save saves a template to disk, replacing any template with the same name.
+func (s *TemplateStore) Save(t *Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bytes, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.templatePath(t.Name), bytes, 0644)
+}
+
+// Get loads a template by name.
+func (s *TemplateStore) Get(name string) (*Template, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bytes, err := os.ReadFile(s.templatePath(name))
+	if err != nil {
+		return nil, err
+	}
+	var t Template
+	if err := json.Unmarshal(bytes, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List loads all templates from disk.
+func (s *TemplateStore) List() ([]*Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var templates []*Template
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		bytes, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var t Template
+		if err := json.Unmarshal(bytes, &t); err != nil {
+			continue
+		}
+		templates = append(templates, &t)
+	}
+	return templates, nil
+}
+
+// Delete removes a template from disk.
+func (s *TemplateStore) Delete(name string) error {
+	if !templateNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid template name %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.Remove(s.templatePath(name))
+}
+
+// templatePath returns the file path for a template.
+func (s *TemplateStore) templatePath(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
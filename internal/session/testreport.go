@@ -0,0 +1,25 @@
+package session
+
+import (
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/testreport"
+)
+
+// TestReport aggregates the test and coverage reports of the session's
+// completed tasks. It returns nil when no task left any.
+func (s *Session) TestReport() *testreport.Report {
+	var reports []*testreport.Report
+	for _, t := range s.DAG.Snapshot() {
+		if t.Status != task.StatusCompleted || t.Result == nil || t.Result.Tests == nil {
+			continue
+		}
+		// Name sources after their task; every worktree has the same paths
+		r := *t.Result.Tests
+		r.Sources = make([]string, len(t.Result.Tests.Sources))
+		for i, src := range t.Result.Tests.Sources {
+			r.Sources[i] = t.ID + ":" + src
+		}
+		reports = append(reports, &r)
+	}
+	return testreport.Merge(reports...)
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"codex-agent-team/internal/codexrpc"
+)
+
+// threadCallTimeout bounds thread management, which starts an app-server.
+const threadCallTimeout = 2 * time.Minute
+
+// Threads returns one page of the threads stored by a registered codex
+// binary, "" for the default one.
+func (m *Manager) Threads(ctx context.Context, codex, cursor string, limit int) (*codexrpc.ThreadListResponse, error) {
+	rt, err := m.ResolveRuntime(codex, "")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, threadCallTimeout)
+	defer cancel()
+	return m.agentMgr.ListThreads(ctx, rt.Binary, cursor, limit)
+}
+
+// ActiveThreads returns the thread IDs of the running agents.
+func (m *Manager) ActiveThreads() map[string]bool {
+	return m.agentMgr.ActiveThreads()
+}
+
+// ArchiveThreads archives threads of a registered codex binary. Threads
+// of running agents are refused.
+func (m *Manager) ArchiveThreads(ctx context.Context, codex string, ids []string) ([]string, error) {
+	rt, err := m.ResolveRuntime(codex, "")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, threadCallTimeout)
+	defer cancel()
+	return m.agentMgr.ArchiveThreads(ctx, rt.Binary, ids)
+}
+
+// ArchiveIdleThreads archives the threads of a registered codex binary
+// that were not updated within idle, skipping those of running agents.
+// Short-lived task agents leave one thread each behind. With dryRun the
+// threads are only listed.
+func (m *Manager) ArchiveIdleThreads(ctx context.Context, codex string, idle time.Duration, dryRun bool) ([]string, error) {
+	rt, err := m.ResolveRuntime(codex, "")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, threadCallTimeout)
+	defer cancel()
+	return m.agentMgr.ArchiveIdleThreads(ctx, rt.Binary, time.Now().Add(-idle), dryRun)
+}
@@ -0,0 +1,324 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/codexrpc"
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/task"
+)
+
+// timelineTypes are the bus events recorded on session timelines, by
+// source. Streamed output is left out.
+var timelineTypes = map[string]map[string]bool{
+	events.SourceSession: {
+		events.TypeStatusChanged: true,
+		TypeVerified:             true,
+	},
+	events.SourceExecutor: {
+		"ready": true, "queued": true, "started": true, "unchanged": true, "completed": true, "failed": true,
+	},
+	events.SourceAgent: {
+		"spawned": true, "stopped": true, "turn/started": true, "turn/completed": true,
+		agent.EventCommandStarted: true, agent.EventCommandCompleted: true,
+		agent.EventTestsRun: true, agent.EventAgentStalled: true,
+		agent.EventApprovalRequested: true, agent.EventApprovalResolved: true,
+	},
+}
+
+// Timeline is a chronological view of a session's run, suitable for a
+// Gantt chart: phases and tasks are spans, events are points in time.
+type Timeline struct {
+	SessionID string          `json:"sessionId"`
+	Phases    []Span          `json:"phases"` // session statuses, in order
+	Tasks     []TaskSpan      `json:"tasks"`  // in topological order
+	Events    []TimelineEvent `json:"events"` // oldest first
+}
+
+// Span is a named interval. End is nil while it lasts.
+type Span struct {
+	Name  string     `json:"name"`
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// TaskSpan is the run of one task: when it became ready, started and
+// finished, and the agent turns in between.
+type TaskSpan struct {
+	TaskID      string     `json:"taskId"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	AgentID     string     `json:"agentId,omitempty"`
+	ReadyAt     *time.Time `json:"readyAt,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	Commit      string     `json:"commit,omitempty"`
+	Turns       []Span     `json:"turns,omitempty"` // named by turn ID
+}
+
+// TimelineEvent is one recorded event with a one-line summary.
+type TimelineEvent struct {
+	Time    time.Time       `json:"time"`
+	Source  string          `json:"source"`
+	Type    string          `json:"type"`
+	TaskID  string          `json:"taskId,omitempty"`
+	AgentID string          `json:"agentId,omitempty"`
+	TurnID  string          `json:"turnId,omitempty"`
+	Summary string          `json:"summary"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// TimelineStore persists the timeline events of each session as JSON
+// lines, so timelines survive restarts.
+type TimelineStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewTimelineStore creates a timeline store in dataDir.
+func NewTimelineStore(dataDir string) (*TimelineStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	return &TimelineStore{dir: dataDir}, nil
+}
+
+// Append records an event on its session's timeline.
+func (s *TimelineStore) Append(ev TimelineEvent, sessionID string) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path(sessionID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Load returns the recorded events of a session, oldest first.
+func (s *TimelineStore) Load(sessionID string) ([]TimelineEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path(sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var evs []TimelineEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 4<<20)
+	for scanner.Scan() {
+		var ev TimelineEvent
+		if json.Unmarshal(scanner.Bytes(), &ev) == nil {
+			evs = append(evs, ev)
+		}
+	}
+	return evs, scanner.Err()
+}
+
+// Delete removes a session's timeline.
+func (s *TimelineStore) Delete(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.Remove(s.path(sessionID))
+}
+
+// path returns the file of a session's timeline.
+func (s *TimelineStore) path(sessionID string) string {
+	return filepath.Join(s.dir, filepath.Base(sessionID)+".jsonl")
+}
+
+// recordTimeline is the bus sink writing session timelines.
+func (m *Manager) recordTimeline(ev events.Event) {
+	if ev.SessionID == "" || !timelineTypes[ev.Source][ev.Type] {
+		return
+	}
+	data, _ := ev.Data.(json.RawMessage)
+	if data == nil && ev.Data != nil {
+		data, _ = json.Marshal(ev.Data)
+	}
+	out := TimelineEvent{
+		Time:    ev.Time,
+		Source:  ev.Source,
+		Type:    ev.Type,
+		TaskID:  ev.TaskID,
+		AgentID: ev.AgentID,
+		TurnID:  ev.TurnID,
+		Summary: summarizeEvent(ev.Source, ev.Type, data),
+		Data:    data,
+	}
+	_ = m.timelines.Append(out, ev.SessionID)
+}
+
+// summarizeEvent describes a timeline event in one line.
+func summarizeEvent(source, typ string, data json.RawMessage) string {
+	switch source + " " + typ {
+	case events.SourceSession + " " + events.TypeStatusChanged:
+		var c StatusChange
+		_ = json.Unmarshal(data, &c)
+		return fmt.Sprintf("session %s → %s", c.From, c.To)
+	case events.SourceSession + " " + TypeVerified:
+		var v Verification
+		_ = json.Unmarshal(data, &v)
+		switch {
+		case v.Resolved:
+			return fmt.Sprintf("post-merge tests fixed after %d attempts", len(v.Fixes))
+		case v.Passed:
+			return "post-merge tests passed"
+		}
+		return "post-merge tests failed"
+	case events.SourceExecutor + " queued":
+		var q task.QueueInfo
+		_ = json.Unmarshal(data, &q)
+		return fmt.Sprintf("task queued (%s, position %d)", q.Reason, q.Position)
+	case events.SourceExecutor + " unchanged":
+		return "task finished without changes"
+	case events.SourceExecutor + " failed":
+		var f task.Failure
+		_ = json.Unmarshal(data, &f)
+		return fmt.Sprintf("task failed (%s): %s", f.Kind, f.Error)
+	case events.SourceAgent + " turn/completed":
+		var n codexrpc.TurnCompletedNotification
+		_ = json.Unmarshal(data, &n)
+		return "turn " + n.Turn.Status
+	case events.SourceAgent + " " + agent.EventCommandStarted:
+		var c agent.CommandEvent
+		_ = json.Unmarshal(data, &c)
+		return "$ " + c.Command
+	case events.SourceAgent + " " + agent.EventCommandCompleted:
+		var c agent.CommandEvent
+		_ = json.Unmarshal(data, &c)
+		if c.ExitCode != nil {
+			return fmt.Sprintf("$ %s (exit %d)", c.Command, *c.ExitCode)
+		}
+		return fmt.Sprintf("$ %s (%s)", c.Command, c.Status)
+	case events.SourceAgent + " " + agent.EventTestsRun:
+		var r agent.TestRun
+		_ = json.Unmarshal(data, &r)
+		if r.Passed {
+			return "tests passed"
+		}
+		return fmt.Sprintf("tests failed (exit %d)", r.ExitCode)
+	case events.SourceAgent + " spawned":
+		return "agent spawned"
+	case events.SourceAgent + " stopped":
+		return "agent stopped"
+	case events.SourceAgent + " turn/started":
+		return "turn started"
+	case events.SourceAgent + " " + agent.EventAgentStalled:
+		return "agent stalled"
+	case events.SourceAgent + " " + agent.EventApprovalRequested:
+		return "approval requested"
+	case events.SourceAgent + " " + agent.EventApprovalResolved:
+		return "approval resolved"
+	}
+	return "task " + typ
+}
+
+// Timeline builds the session's timeline from its recorded events and
+// its tasks.
+func (s *Session) Timeline() (*Timeline, error) {
+	var evs []TimelineEvent
+	if s.mgr != nil && s.mgr.timelines != nil {
+		var err error
+		if evs, err = s.mgr.timelines.Load(s.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	created := s.CreatedAt
+	s.mu.RUnlock()
+
+	tl := &Timeline{SessionID: s.ID, Phases: []Span{{Name: string(StatusCreated), Start: created}}}
+	readyAt := make(map[string]time.Time)
+	turns := make(map[string][]Span)
+	for _, ev := range evs {
+		switch {
+		case ev.Source == events.SourceSession && ev.Type == events.TypeStatusChanged:
+			var c StatusChange
+			if json.Unmarshal(ev.Data, &c) == nil {
+				at := ev.Time
+				tl.Phases[len(tl.Phases)-1].End = &at
+				tl.Phases = append(tl.Phases, Span{Name: string(c.To), Start: at})
+			}
+		case ev.Source == events.SourceExecutor && ev.Type == "ready":
+			readyAt[ev.TaskID] = ev.Time
+		case ev.Source == events.SourceAgent && ev.Type == "turn/started" && ev.TaskID != "":
+			turns[ev.TaskID] = append(turns[ev.TaskID], Span{Name: ev.TurnID, Start: ev.Time})
+		case ev.Source == events.SourceAgent && ev.Type == "turn/completed" && ev.TaskID != "":
+			spans := turns[ev.TaskID]
+			for i := len(spans) - 1; i >= 0; i-- {
+				if spans[i].Name == ev.TurnID && spans[i].End == nil {
+					at := ev.Time
+					spans[i].End = &at
+					break
+				}
+			}
+		}
+	}
+
+	for _, v := range s.TaskViews() {
+		span := TaskSpan{
+			TaskID:      v.ID,
+			Title:       v.Title,
+			Status:      string(v.Status),
+			AgentID:     v.AgentID,
+			StartedAt:   v.StartedAt,
+			CompletedAt: v.CompletedAt,
+			Commit:      v.ResultCommit,
+			Turns:       turns[v.ID],
+		}
+		if at, ok := readyAt[v.ID]; ok {
+			span.ReadyAt = &at
+		}
+		tl.Tasks = append(tl.Tasks, span)
+
+		// Commits are not events of their own; place them where the task
+		// finished
+		if v.ResultCommit != "" && v.CompletedAt != nil {
+			evs = append(evs, TimelineEvent{
+				Time:    *v.CompletedAt,
+				Source:  events.SourceExecutor,
+				Type:    "commit",
+				TaskID:  v.ID,
+				AgentID: v.AgentID,
+				Summary: "committed " + shortSHA(v.ResultCommit),
+			})
+		}
+	}
+
+	sort.SliceStable(evs, func(i, j int) bool { return evs[i].Time.Before(evs[j].Time) })
+	tl.Events = evs
+	if tl.Events == nil {
+		tl.Events = []TimelineEvent{}
+	}
+	return tl, nil
+}
+
+// shortSHA abbreviates a commit SHA for display.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,154 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/codexrpc"
+	"codex-agent-team/internal/events"
+)
+
+// TypeVerified is the bus event published once a merge has been verified.
+// Its data is the session's Verification.
+const TypeVerified = "session.verified"
+
+// Verification is the outcome of running the test command on the merged
+// result. When the first run fails, fixer agents get up to the configured
+// number of attempts; Resolved tells whether one of them succeeded.
+type Verification struct {
+	Runs     []agent.TestRun `json:"runs"`  // the first run, then one after each fix
+	Fixes    []Fix           `json:"fixes"` // fixer attempts, in order
+	Passed   bool            `json:"passed"`
+	Resolved bool            `json:"resolved"` // broken by the merge and fixed by a fixer
+}
+
+// Fix is one attempt of a fixer agent at repairing the merged result.
+type Fix struct {
+	AgentID string `json:"agentId"`
+	Commit  string `json:"commit,omitempty"` // empty when the agent changed nothing
+	Error   string `json:"error,omitempty"`
+}
+
+// verifyMerge runs the test command on the merged checkout in dir and lets
+// fixer agents repair a failure. It returns nil when verification is
+// disabled. The caller holds the repository lock.
+func (s *Session) verifyMerge(ctx context.Context, dir string) *Verification {
+	if s.mgr == nil || !s.mgr.opts.VerifyMerge {
+		return nil
+	}
+	run, ok := s.agentMgr.RunTests(dir)
+	if !ok {
+		return nil
+	}
+
+	v := &Verification{Runs: []agent.TestRun{run}, Passed: run.Passed}
+	for attempt := 1; !v.Passed && attempt <= s.mgr.opts.FixAttempts; attempt++ {
+		s.notify("Post-merge tests failed; fixer attempt %d of %d", attempt, s.mgr.opts.FixAttempts)
+		fix := s.runFixer(ctx, dir, run, attempt)
+		v.Fixes = append(v.Fixes, fix)
+		if ctx.Err() != nil {
+			break
+		}
+		run, _ = s.agentMgr.RunTests(dir)
+		v.Runs = append(v.Runs, run)
+		v.Passed = run.Passed
+	}
+	v.Resolved = v.Passed && len(v.Fixes) > 0
+
+	s.mu.Lock()
+	s.Verification = v
+	s.mu.Unlock()
+	if s.bus != nil {
+		s.bus.Publish(events.Event{
+			Source:    events.SourceSession,
+			Type:      TypeVerified,
+			SessionID: s.ID,
+			Data:      *v,
+		})
+	}
+	return v
+}
+
+// runFixer spawns a worker in the merged checkout in dir, shows it the
+// failing test output and commits what it changed.
+func (s *Session) runFixer(ctx context.Context, dir string, failed agent.TestRun, attempt int) Fix {
+	fix := Fix{AgentID: fixerID(s.ID, attempt)}
+	fail := func(err error) Fix {
+		fix.Error = err.Error()
+		return fix
+	}
+
+	if _, err := s.agentMgr.SpawnAgent(ctx, agent.AgentConfig{
+		ID:          fix.AgentID,
+		Role:        agent.RoleWorker,
+		Cwd:         dir,
+		SandboxMode: codexrpc.SandboxWorkspaceWrite,
+		Runtime:     s.runtime,
+	}); err != nil {
+		return fail(fmt.Errorf("spawn fixer: %w", err))
+	}
+	defer s.agentMgr.StopAgent(fix.AgentID)
+
+	turn, err := s.agentMgr.SendTask(ctx, fix.AgentID, "", fixerPrompt(s.UserTask, failed))
+	if err != nil {
+		return fail(fmt.Errorf("send task: %w", err))
+	}
+	if err := s.agentMgr.WaitForCompletion(ctx, turn); err != nil {
+		return fail(fmt.Errorf("fixer execution: %w", err))
+	}
+
+	msg := fmt.Sprintf("Fix post-merge test failures (attempt %d)", attempt)
+	commitCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	commit, err := s.worktreeMgr.CommitChanges(commitCtx, dir, msg)
+	if err != nil {
+		return fail(fmt.Errorf("commit fix: %w", err))
+	}
+	fix.Commit = commit
+	return fix
+}
+
+// fixerID names the fixer agent of a session's verification attempt.
+func fixerID(sessionID string, attempt int) string {
+	return fmt.Sprintf("fixer-%s-%d", sessionID, attempt)
+}
+
+// fixerSession returns the session a fixer agent ID belongs to.
+func fixerSession(agentID string) (string, bool) {
+	rest, ok := strings.CutPrefix(agentID, "fixer-")
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndex(rest, "-")
+	if i <= 0 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(rest[i+1:]); err != nil {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// fixerPrompt asks a fixer agent to repair the tests the merge broke.
+func fixerPrompt(userTask string, failed agent.TestRun) string {
+	status := fmt.Sprintf("exited with code %d", failed.ExitCode)
+	if failed.Error != "" {
+		status = failed.Error
+	}
+	return fmt.Sprintf(`The changes of several parallel tasks were just merged into this branch, and the test command now fails.
+
+The tasks implemented: %s
+
+Test command: %s
+It %s. The end of its output:
+`+"```"+`
+%s
+`+"```"+`
+
+Fix the breakage with the smallest change that makes the tests pass. Keep the merged features; do not revert them or disable tests. Run the tests to confirm before you finish.`,
+		userTask, failed.Command, status, failed.Output)
+}
@@ -0,0 +1,399 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"codex-agent-team/internal/worktree"
+)
+
+// Watch runs a session whenever files matching its paths change, e.g.
+// regenerating API clients when the OpenAPI spec changes. Changes are
+// reported by push webhooks or found by polling the repository's HEAD.
+type Watch struct {
+	Name       string   `json:"name"`
+	Paths      []string `json:"paths"`                // globs relative to the repository root; ** spans directories
+	Branch     string   `json:"branch,omitempty"`     // only pushes to this branch (webhooks only)
+	Repository string   `json:"repository,omitempty"` // only pushes to this repository, e.g. "owner/repo" (webhooks only)
+	RunSpec
+	Paused bool `json:"paused,omitempty"`
+
+	CreatedAt string   `json:"createdAt"`
+	LastRun   *AutoRun `json:"lastRun,omitempty"`
+}
+
+// Push describes changed files reported for a repository.
+type Push struct {
+	Repository string   // e.g. "owner/repo", empty when unknown
+	Branch     string   // branch name without refs/heads/
+	Commit     string   // new head commit
+	Files      []string // changed paths relative to the repository root
+}
+
+// Normalize validates the watch.
+func (w *Watch) Normalize() error {
+	if !templateNamePattern.MatchString(w.Name) {
+		return fmt.Errorf("invalid watch name %q", w.Name)
+	}
+	if len(w.Paths) == 0 {
+		return fmt.Errorf("watch %s: paths are required", w.Name)
+	}
+	for _, p := range w.Paths {
+		if _, err := globPattern(p); err != nil {
+			return fmt.Errorf("watch %s: %w", w.Name, err)
+		}
+	}
+	if err := w.RunSpec.validate("watch " + w.Name); err != nil {
+		return err
+	}
+	if w.CreatedAt == "" {
+		w.CreatedAt = time.Now().Format(timeFormat)
+	}
+	return nil
+}
+
+// Match returns the files matching the watch's paths.
+func (w *Watch) Match(files []string) []string {
+	var matched []string
+	for _, p := range w.Paths {
+		re, err := globPattern(p)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if re.MatchString(strings.TrimPrefix(f, "/")) {
+				matched = append(matched, f)
+			}
+		}
+	}
+	return matched
+}
+
+// globPattern compiles a path glob: * and ? stay within a directory, **
+// matches across directories and a trailing / matches everything below.
+func globPattern(glob string) (*regexp.Regexp, error) {
+	glob = strings.TrimPrefix(glob, "/")
+	if glob == "" {
+		return nil, fmt.Errorf("empty path pattern")
+	}
+	if strings.HasSuffix(glob, "/") {
+		glob += "**"
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// "**/" also matches no directory at all
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// WatchStore handles watch persistence to disk.
+type WatchStore struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewWatchStore creates a new watch store.
+func NewWatchStore(dataDir string) (*WatchStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	return &WatchStore{dir: dataDir}, nil
+}
+
+// Save saves a watch to disk, replacing any watch with the same name.
+func (s *WatchStore) Save(w *Watch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bytes, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.watchPath(w.Name), bytes, 0644)
+}
+
+// Get loads a watch by name.
+func (s *WatchStore) Get(name string) (*Watch, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid watch name %q", name)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bytes, err := os.ReadFile(s.watchPath(name))
+	if err != nil {
+		return nil, err
+	}
+	var w Watch
+	if err := json.Unmarshal(bytes, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// List loads all watches from disk.
+func (s *WatchStore) List() ([]*Watch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var watches []*Watch
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		bytes, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var w Watch
+		if err := json.Unmarshal(bytes, &w); err != nil {
+			continue
+		}
+		watches = append(watches, &w)
+	}
+	return watches, nil
+}
+
+// Delete removes a watch from disk.
+func (s *WatchStore) Delete(name string) error {
+	if !templateNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid watch name %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.Remove(s.watchPath(name))
+}
+
+// watchPath returns the file path for a watch.
+func (s *WatchStore) watchPath(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// Watches returns the watch store.
+func (m *Manager) Watches() *WatchStore {
+	return m.watches
+}
+
+// RunWatch starts a run of the named watch now, without a change.
+func (m *Manager) RunWatch(name string) (AutoRun, error) {
+	if m.watches == nil {
+		return AutoRun{}, fmt.Errorf("watch store unavailable")
+	}
+	w, err := m.watches.Get(name)
+	if err != nil {
+		return AutoRun{}, err
+	}
+	return m.startWatch(w, "manual")
+}
+
+// TriggerWatches starts the watches whose paths match a push and returns
+// the runs started. Watches still running from an earlier change are
+// skipped.
+func (m *Manager) TriggerWatches(push Push) []AutoRun {
+	if m.watches == nil {
+		return nil
+	}
+	watches, err := m.watches.List()
+	if err != nil {
+		log.Printf("Watch: %v", err)
+		return nil
+	}
+
+	runs := []AutoRun{}
+	for _, w := range watches {
+		if w.Paused ||
+			(w.Branch != "" && push.Branch != "" && w.Branch != push.Branch) ||
+			(w.Repository != "" && push.Repository != "" && !strings.EqualFold(w.Repository, push.Repository)) {
+			continue
+		}
+		matched := w.Match(push.Files)
+		if len(matched) == 0 {
+			continue
+		}
+		run, err := m.startWatch(w, describePush(push, matched))
+		if err != nil {
+			log.Printf("Watch %s: %v", w.Name, err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// describePush summarizes the files that triggered a watch.
+func describePush(push Push, matched []string) string {
+	const maxFiles = 5
+	files := matched
+	if len(files) > maxFiles {
+		files = files[:maxFiles]
+	}
+	desc := strings.Join(files, ", ")
+	if len(matched) > maxFiles {
+		desc += fmt.Sprintf(" and %d more", len(matched)-maxFiles)
+	}
+	if push.Commit != "" {
+		desc = fmt.Sprintf("%.7s changed %s", push.Commit, desc)
+	}
+	return desc
+}
+
+// startWatch starts a run of w and records it as the watch's last run.
+func (m *Manager) startWatch(w *Watch, trigger string) (AutoRun, error) {
+	return m.startAutoRun(AutoRunWatch, w.Name, trigger, w.RunSpec, func(run AutoRun) {
+		// The run's own merge must not trigger the watches again
+		m.resetHead(w.RepoPath)
+
+		current, err := m.watches.Get(w.Name)
+		if err != nil {
+			return
+		}
+		current.LastRun = &run
+		if err := m.watches.Save(current); err != nil {
+			log.Printf("Watch %s: %v", w.Name, err)
+		}
+	})
+}
+
+// StartWatcher polls the repositories of the stored watches every
+// interval and triggers the watches matching files changed by new commits.
+func (m *Manager) StartWatcher(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.pollWatches()
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// pollWatches compares each watched repository's HEAD with the last one
+// seen. Repositories with a watch run in progress are skipped until it
+// finishes.
+func (m *Manager) pollWatches() {
+	if m.watches == nil {
+		return
+	}
+	watches, err := m.watches.List()
+	if err != nil {
+		log.Printf("Watch: %v", err)
+		return
+	}
+
+	repos := make(map[string]bool)
+	for _, w := range watches {
+		if !w.Paused {
+			repos[w.RepoPath] = repos[w.RepoPath] || m.autoRunActive(AutoRunWatch, w.Name)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, time.Minute)
+	defer cancel()
+	for repo, busy := range repos {
+		if busy {
+			continue
+		}
+		wt := worktree.NewManager(repo)
+		head, err := wt.HeadCommit(ctx)
+		if err != nil {
+			continue
+		}
+
+		m.watchMu.Lock()
+		prev := m.heads[repo]
+		m.heads[repo] = head
+		m.watchMu.Unlock()
+		if prev == "" || prev == head {
+			continue
+		}
+
+		files, err := wt.DiffFiles(ctx, repo, prev, head)
+		if err != nil {
+			log.Printf("Watch: %s: %v", repo, err)
+			continue
+		}
+		m.triggerRepoWatches(repo, Push{Commit: head, Files: files})
+	}
+}
+
+// triggerRepoWatches triggers the watches of one repository.
+func (m *Manager) triggerRepoWatches(repo string, push Push) {
+	watches, err := m.watches.List()
+	if err != nil {
+		return
+	}
+	for _, w := range watches {
+		if w.Paused || w.RepoPath != repo {
+			continue
+		}
+		if matched := w.Match(push.Files); len(matched) > 0 {
+			if _, err := m.startWatch(w, describePush(push, matched)); err != nil {
+				log.Printf("Watch %s: %v", w.Name, err)
+			}
+		}
+	}
+}
+
+// resetHead makes the poller take the repository's current HEAD as seen.
+func (m *Manager) resetHead(repo string) {
+	head, err := worktree.NewManager(repo).HeadCommit(m.ctx)
+	if err != nil {
+		return
+	}
+	m.watchMu.Lock()
+	if _, polled := m.heads[repo]; polled {
+		m.heads[repo] = head
+	}
+	m.watchMu.Unlock()
+}
+
+// autoRunActive reports whether a run of the given schedule or watch is
+// in progress.
+func (m *Manager) autoRunActive(kind, name string) bool {
+	m.autoRunMu.Lock()
+	defer m.autoRunMu.Unlock()
+	return m.autoRuns[kind+"/"+name]
+}
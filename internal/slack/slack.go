@@ -0,0 +1,305 @@
+// Package slack posts pending agent approvals to a Slack channel as
+// interactive messages and decodes the button clicks sent back by Slack.
+//
+// Only the Web API (chat.postMessage, chat.update) and the interactivity
+// request format are used, so no Slack SDK is required.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiURL is the Slack Web API base URL.
+const apiURL = "https://slack.com/api/"
+
+// maxRequestAge bounds the age of signed interaction requests to prevent replays.
+const maxRequestAge = 5 * time.Minute
+
+// ErrInvalidSignature is returned for interaction requests not signed by Slack.
+var ErrInvalidSignature = errors.New("invalid slack signature")
+
+// Config configures the Slack integration.
+type Config struct {
+	Token         string // bot token with chat:write
+	Channel       string // channel ID approvals are posted to
+	SigningSecret string // app signing secret used to verify interactions
+}
+
+// Approval is a pending approval to post.
+type Approval struct {
+	SessionID string
+	TaskID    string
+	ID        string
+	Method    string
+	Params    json.RawMessage
+}
+
+// Action is a button click decoded from an interaction payload.
+type Action struct {
+	SessionID  string
+	ApprovalID string
+	Decision   string
+	User       string
+}
+
+// postedMessage locates a message posted for an approval.
+type postedMessage struct {
+	channel string
+	ts      string
+	text    string
+}
+
+// Client posts approval messages and tracks them so they can be updated
+// once the approval is resolved.
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	mu       sync.Mutex
+	messages map[string]postedMessage // by approval ID
+	deciders map[string]string        // Slack user who decided, by approval ID
+}
+
+// NewClient creates a Slack client.
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:      cfg,
+		http:     &http.Client{Timeout: 10 * time.Second},
+		messages: make(map[string]postedMessage),
+		deciders: make(map[string]string),
+	}
+}
+
+// PostApproval posts an interactive message with Approve/Decline buttons.
+func (c *Client) PostApproval(ctx context.Context, a Approval) error {
+	text := describe(a)
+	value := func(decision string) string {
+		return strings.Join([]string{a.SessionID, a.ID, decision}, "|")
+	}
+
+	var resp struct {
+		Channel string `json:"channel"`
+		TS      string `json:"ts"`
+	}
+	err := c.call(ctx, "chat.postMessage", map[string]any{
+		"channel": c.cfg.Channel,
+		"text":    text,
+		"blocks": []any{
+			map[string]any{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": text},
+			},
+			map[string]any{
+				"type": "actions",
+				"elements": []any{
+					button("approve", "Approve", "primary", value("accept")),
+					button("decline", "Decline", "danger", value("decline")),
+				},
+			},
+		},
+	}, &resp)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.messages[a.ID] = postedMessage{channel: resp.Channel, ts: resp.TS, text: text}
+	c.mu.Unlock()
+	return nil
+}
+
+// ResolveApproval replaces the buttons of a posted approval with its outcome.
+func (c *Client) ResolveApproval(ctx context.Context, approvalID, decision string, timedOut bool) error {
+	c.mu.Lock()
+	msg, ok := c.messages[approvalID]
+	user := c.deciders[approvalID]
+	delete(c.messages, approvalID)
+	delete(c.deciders, approvalID)
+	c.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	outcome := fmt.Sprintf("*%s*", decision)
+	switch {
+	case timedOut:
+		outcome += " (timed out, default applied)"
+	case user != "":
+		outcome += fmt.Sprintf(" by <@%s>", user)
+	}
+	text := msg.text + "\n" + outcome
+
+	return c.call(ctx, "chat.update", map[string]any{
+		"channel": msg.channel,
+		"ts":      msg.ts,
+		"text":    text,
+		"blocks": []any{
+			map[string]any{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": text},
+			},
+		},
+	}, nil)
+}
+
+// ParseInteraction verifies a Slack interaction request and decodes the
+// clicked button. The request body is consumed.
+func (c *Client) ParseInteraction(r *http.Request) (*Action, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.verify(r.Header, body); err != nil {
+		return nil, err
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("parse form: %w", err)
+	}
+
+	var payload struct {
+		Type string `json:"type"`
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		Actions []struct {
+			Value string `json:"value"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		return nil, fmt.Errorf("parse payload: %w", err)
+	}
+	if payload.Type != "block_actions" || len(payload.Actions) == 0 {
+		return nil, fmt.Errorf("unsupported interaction %q", payload.Type)
+	}
+
+	parts := strings.Split(payload.Actions[0].Value, "|")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed action value %q", payload.Actions[0].Value)
+	}
+
+	action := &Action{
+		SessionID:  parts[0],
+		ApprovalID: parts[1],
+		Decision:   parts[2],
+		User:       payload.User.ID,
+	}
+
+	c.mu.Lock()
+	c.deciders[action.ApprovalID] = action.User
+	c.mu.Unlock()
+	return action, nil
+}
+
+// verify checks the request signature computed with the signing secret.
+func (c *Client) verify(h http.Header, body []byte) error {
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || time.Since(time.Unix(sec, 0)).Abs() > maxRequestAge {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.cfg.SigningSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(h.Get("X-Slack-Signature"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// call invokes a Web API method and decodes the response into out.
+func (c *Client) call(ctx context.Context, method string, body any, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+method, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("%s: decode response: %w", method, err)
+	}
+	if !status.OK {
+		return fmt.Errorf("%s: %s", method, status.Error)
+	}
+	if out != nil {
+		return json.Unmarshal(raw, out)
+	}
+	return nil
+}
+
+// button builds a Block Kit button element.
+func button(actionID, label, style, value string) map[string]any {
+	return map[string]any{
+		"type":      "button",
+		"action_id": actionID,
+		"style":     style,
+		"value":     value,
+		"text":      map[string]string{"type": "plain_text", "text": label},
+	}
+}
+
+// describe renders the approval as mrkdwn text.
+func describe(a Approval) string {
+	var params struct {
+		Command *string `json:"command"`
+		Cwd     *string `json:"cwd"`
+		Reason  *string `json:"reason"`
+	}
+	_ = json.Unmarshal(a.Params, &params)
+
+	var b strings.Builder
+	if a.Method == "command/approval" {
+		b.WriteString("*Command approval*")
+	} else {
+		b.WriteString("*File change approval*")
+	}
+	fmt.Fprintf(&b, " for task `%s` (session `%s`)", a.TaskID, a.SessionID)
+	if params.Command != nil {
+		fmt.Fprintf(&b, "\n```%s```", *params.Command)
+	}
+	if params.Cwd != nil {
+		fmt.Fprintf(&b, "\nin `%s`", *params.Cwd)
+	}
+	if params.Reason != nil && *params.Reason != "" {
+		fmt.Fprintf(&b, "\n>%s", *params.Reason)
+	}
+	return b.String()
+}
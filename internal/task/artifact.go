@@ -0,0 +1,83 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Artifact is a file a task declared as an output and produced. Dependent
+// tasks are guaranteed to find it in their worktree and are told about it
+// in their prompt.
+type Artifact struct {
+	Path   string `json:"path"`             // relative to the repository root
+	TaskID string `json:"taskId"`           // task that produced it
+	Commit string `json:"commit,omitempty"` // result commit holding it
+}
+
+// collectArtifacts checks that every declared output of t exists in its
+// worktree. The caller fills in the commit once the changes are committed.
+func collectArtifacts(t *Task) ([]Artifact, error) {
+	var artifacts []Artifact
+	var missing []string
+	for _, path := range t.Outputs {
+		if _, err := os.Stat(filepath.Join(t.WorktreePath, path)); err != nil {
+			missing = append(missing, path)
+			continue
+		}
+		artifacts = append(artifacts, Artifact{Path: path, TaskID: t.ID})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("declared outputs not produced: %s", strings.Join(missing, ", "))
+	}
+	return artifacts, nil
+}
+
+// provideArtifacts makes sure the artifacts of upstream tasks exist in the
+// worktree of t. Merged dependency branches normally carry them already;
+// anything lost on the way is checked out from the producing commit.
+func (e *Executor) provideArtifacts(ctx context.Context, t *Task, artifacts []Artifact) error {
+	for _, a := range artifacts {
+		if _, err := os.Stat(filepath.Join(t.WorktreePath, a.Path)); err == nil || a.Commit == "" {
+			continue
+		}
+		if err := e.worktreeMgr.CheckoutFile(ctx, t.WorktreePath, a.Commit, a.Path); err != nil {
+			return fmt.Errorf("artifact %s of %s: %w", a.Path, a.TaskID, err)
+		}
+	}
+	return nil
+}
+
+// formatArtifacts renders upstream artifacts as a prompt section, or "" if
+// there are none.
+func formatArtifacts(artifacts []Artifact) string {
+	if len(artifacts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nArtifacts produced by the tasks this one depends on, present in your working directory. Build on them instead of recreating them:\n")
+	for _, a := range artifacts {
+		fmt.Fprintf(&b, "- %s (from task %s)\n", a.Path, a.TaskID)
+	}
+	return b.String()
+}
+
+// formatOutputs tells the worker which files it must produce, or "" if the
+// task declares none.
+func formatOutputs(outputs []string) string {
+	if len(outputs) == 0 {
+		return ""
+	}
+	return "\n\nOther tasks depend on these files; make sure you create or update them: " + strings.Join(outputs, ", ")
+}
+
+// formatFiles points the worker at the files the plan expects it to touch,
+// or "" if the plan named none.
+func formatFiles(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	return "\n\nFiles the plan expects this task to touch: " + strings.Join(files, ", ")
+}
@@ -0,0 +1,115 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Limits on what a failed attempt keeps for the next one.
+const (
+	attemptOutputBytes = 4 << 10
+	attemptDiffBytes   = 8 << 10
+)
+
+// Attempt records what a failed run of a task left behind, so a retry
+// can start from it instead of starting blind.
+type Attempt struct {
+	Error    string    `json:"error,omitempty"`  // why the attempt failed
+	Output   string    `json:"output,omitempty"` // tail of the agent transcript
+	Diff     string    `json:"diff,omitempty"`   // the discarded changes, truncated
+	Files    []string  `json:"files,omitempty"`  // files the attempt touched
+	FailedAt time.Time `json:"failedAt"`
+
+	TestsFailed bool `json:"testsFailed,omitempty"` // the attempt's last run_tests call failed
+}
+
+// captureAttempt collects the transcript tail and the changes of a task
+// whose agent is about to be stopped after a failure.
+func (e *Executor) captureAttempt(t *Task, agentID string) *Attempt {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	a := &Attempt{
+		Output:   tail(e.agentMgr.GetOutput(agentID), attemptOutputBytes),
+		FailedAt: time.Now(),
+	}
+	if runs := e.agentMgr.TestRuns(agentID); len(runs) > 0 {
+		a.TestsFailed = !runs[len(runs)-1].Passed
+	}
+	if t.WorktreePath == "" {
+		return a
+	}
+	a.Files, _ = e.worktreeMgr.ChangedFiles(ctx, t.WorktreePath)
+	from := t.BaseCommit
+	if from == "" {
+		from = "HEAD"
+	}
+	if diff, err := e.worktreeMgr.Diff(ctx, t.WorktreePath, from); err == nil {
+		if len(diff) > attemptDiffBytes {
+			diff = diff[:attemptDiffBytes] + "\n[diff truncated]\n"
+		}
+		a.Diff = diff
+	}
+	return a
+}
+
+// SetFailedAttempt records the failed attempt of a task, or forgets it
+// when a is nil.
+func (d *DAG) SetFailedAttempt(taskID string, a *Attempt) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.FailedAttempt = a
+	}
+}
+
+// FailedAttempt returns the recorded failed attempt of a task, or nil.
+func (d *DAG) FailedAttempt(taskID string) *Attempt {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		return t.FailedAttempt
+	}
+	return nil
+}
+
+// tail returns at most n trailing bytes of s, starting at a line boundary
+// when one is available.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 && i < len(s)-1 {
+		s = s[i+1:]
+	}
+	return s
+}
+
+// formatAttempt tells the worker why the previous attempt at its task
+// failed and what it tried, or "" if there was none.
+func formatAttempt(a *Attempt) string {
+	if a == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nA previous attempt at this task failed")
+	if a.Error != "" {
+		fmt.Fprintf(&b, " because: %s", a.Error)
+	}
+	b.WriteString(". Learn from it instead of repeating it; its changes were discarded.\n")
+	if len(a.Files) > 0 {
+		fmt.Fprintf(&b, "\nFiles it touched: %s\n", strings.Join(a.Files, ", "))
+	}
+	if a.Output != "" {
+		fmt.Fprintf(&b, "\nThe end of its transcript:\n```\n%s\n```\n", strings.TrimSpace(a.Output))
+	}
+	if a.Diff != "" {
+		fmt.Fprintf(&b, "\nIts changes:\n```diff\n%s\n```\n", strings.TrimSpace(a.Diff))
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"codex-agent-team/internal/worktree"
+)
+
+// changeStat computes the diffstat of a task's result commit against the
+// commit it built on: BaseCommit, or the last dependency merged on top of
+// it, so changes of dependencies are not counted. It returns nil when the
+// task committed nothing or git fails.
+func (e *Executor) changeStat(ctx context.Context, t *Task) *worktree.ChangeStat {
+	if t.ResultCommit == "" {
+		return nil
+	}
+	from := t.BaseCommit
+	if n := len(t.MergedCommits); n > 0 {
+		from = t.MergedCommits[n-1]
+	}
+	if from == "" {
+		from = t.ResultCommit + "^"
+	}
+	// Remote tasks have no worktree; their result is in the repository
+	dir := t.WorktreePath
+	if dir == "" {
+		dir = e.worktreeMgr.GetRepoPath()
+	}
+	stat, err := e.worktreeMgr.DiffStat(ctx, dir, from, t.ResultCommit)
+	if err != nil {
+		return nil
+	}
+	c := worktree.ParseShortStat(stat)
+	return &c
+}
+
+// SetTaskChanges records the diffstat of a task's result.
+func (d *DAG) SetTaskChanges(taskID string, c *worktree.ChangeStat) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.Changes = c
+	}
+}
+
+// TaskChanges returns the diffstat of a task's result, or nil.
+func (d *DAG) TaskChanges(taskID string) *worktree.ChangeStat {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		return t.Changes
+	}
+	return nil
+}
+
+// markUnchanged flags a task whose agent finished without changing
+// anything, so its branch is left out of the merge, and warns about it
+// with an "unchanged" event.
+func (e *Executor) markUnchanged(t *Task) {
+	e.dag.SetTaskNoChanges(t.ID)
+	e.emit(ExecutionEvent{
+		TaskID:    t.ID,
+		EventType: "unchanged",
+		Data:      fmt.Sprintf("task %s finished without changing any files; its branch will not be merged", t.ID),
+	})
+}
+
+// SetTaskNoChanges flags a task as having produced no changes.
+func (d *DAG) SetTaskNoChanges(taskID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.NoChanges = true
+	}
+}
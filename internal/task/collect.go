@@ -0,0 +1,50 @@
+package task
+
+import "fmt"
+
+// CollectedFile is a file copied out of a task's worktree into session
+// storage after the task completed.
+type CollectedFile struct {
+	Path string `json:"path"` // relative to the worktree root
+	Size int64  `json:"size"`
+}
+
+// Collector copies the files of a worktree that match a task's collect
+// globs into storage that outlives the worktree. It returns what it
+// stored, even when it also reports an error for files it had to skip.
+type Collector interface {
+	Collect(taskID, dir string, patterns []string) ([]CollectedFile, error)
+}
+
+// SetCollector stores the files matching each task's Collect globs with c
+// once the task completes.
+func (e *Executor) SetCollector(c Collector) {
+	e.collector = c
+}
+
+// collectFiles stores the files t asked to keep. Failing to collect does
+// not fail the task; it is reported as task output.
+func (e *Executor) collectFiles(t *Task) {
+	if e.collector == nil || len(t.Collect) == 0 {
+		return
+	}
+	files, err := e.collector.Collect(t.ID, t.WorktreePath, t.Collect)
+	e.dag.SetTaskCollected(t.ID, files)
+	if err != nil {
+		e.emit(ExecutionEvent{
+			TaskID:    t.ID,
+			EventType: "output",
+			Data:      fmt.Sprintf("collecting artifacts: %v", err),
+		})
+	}
+}
+
+// SetTaskCollected records the files collected from a task's worktree.
+func (d *DAG) SetTaskCollected(taskID string, files []CollectedFile) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.Collected = files
+	}
+}
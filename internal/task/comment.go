@@ -0,0 +1,72 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// promptComments is the number of recent comments shown to a worker.
+const promptComments = 5
+
+// Comment is a note a human left on a task, for example guidance for the
+// next attempt after a failure.
+type Comment struct {
+	ID        string    `json:"id"`
+	Author    string    `json:"author,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AddComment appends a comment to a task and returns it with its ID and
+// time set.
+func (d *DAG) AddComment(taskID, author, body string) (Comment, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t, ok := d.tasks[taskID]
+	if !ok {
+		return Comment{}, fmt.Errorf("task %s not found", taskID)
+	}
+	c := Comment{
+		ID:        fmt.Sprintf("comment-%d", len(t.Comments)+1),
+		Author:    author,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+	t.Comments = append(t.Comments, c)
+	return c, nil
+}
+
+// Comments returns the comments on a task, oldest first.
+func (d *DAG) Comments(taskID string) ([]Comment, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	t, ok := d.tasks[taskID]
+	if !ok {
+		return nil, false
+	}
+	return append([]Comment(nil), t.Comments...), true
+}
+
+// formatComments shows the worker the most recent human comments on its
+// task, or "" if there are none.
+func formatComments(comments []Comment) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	if len(comments) > promptComments {
+		comments = comments[len(comments)-promptComments:]
+	}
+	var b strings.Builder
+	b.WriteString("\n\nComments a reviewer left on this task, oldest first. Take them into account:\n")
+	for _, c := range comments {
+		author := c.Author
+		if author == "" {
+			author = "reviewer"
+		}
+		fmt.Fprintf(&b, "- %s (%s): %s\n", author, c.CreatedAt.Format(time.RFC3339), c.Body)
+	}
+	return b.String()
+}
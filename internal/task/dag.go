@@ -2,6 +2,9 @@ package task
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -41,12 +44,13 @@ func (d *DAG) Get(id string) (*Task, bool) {
 	return t, ok
 }
 
-// ReadyTasks returns all tasks whose dependencies have been satisfied.
-func (d *DAG) ReadyTasks() []*Task {
-	d.mu.RLock()
-	defer d.mu.RUnlock()
+// PromoteReady marks every pending task whose dependencies have all
+// completed as ready, and returns the tasks that were promoted.
+func (d *DAG) PromoteReady() []*Task {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
-	var ready []*Task
+	var promoted []*Task
 
 	for _, t := range d.tasks {
 		if t.Status != StatusPending {
@@ -62,18 +66,45 @@ func (d *DAG) ReadyTasks() []*Task {
 			}
 		}
 
-		if allDepsCompleted && len(t.DependsOn) > 0 {
-			// Has dependencies and all are completed
-			ready = append(ready, t)
-		} else if len(t.DependsOn) == 0 && t.Status == StatusPending {
-			// No dependencies, ready to run
+		if allDepsCompleted {
+			t.Status = StatusReady
+			promoted = append(promoted, t)
+		}
+	}
+
+	sortByID(promoted)
+	return promoted
+}
+
+// ReadyTasks returns all tasks in the ready state, waiting to be dispatched.
+func (d *DAG) ReadyTasks() []*Task {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var ready []*Task
+	for _, t := range d.tasks {
+		if t.Status == StatusReady {
 			ready = append(ready, t)
 		}
 	}
 
+	sortByID(ready)
 	return ready
 }
 
+// SetTaskRunning atomically marks a task as running and stamps StartedAt.
+func (d *DAG) SetTaskRunning(taskID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.Status = StatusRunning
+		now := time.Now()
+		t.StartedAt = &now
+		t.Queue = nil
+	}
+}
+
 // UpdateStatus updates the status of a task.
 func (d *DAG) UpdateStatus(id string, status TaskStatus) {
 	d.mu.Lock()
@@ -156,6 +187,61 @@ func (d *DAG) AllCompleted() bool {
 	return true
 }
 
+// DiagnoseDeadlock reports whether execution can no longer make progress:
+// no task is running or ready, yet some are still pending. The returned
+// diagnostics list each blocked task and the dependencies holding it back.
+func (d *DAG) DiagnoseDeadlock() ([]string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var pending []*Task
+	for _, t := range d.tasks {
+		switch t.Status {
+		case StatusRunning, StatusReady:
+			return nil, false
+		case StatusPending:
+			pending = append(pending, t)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, false
+	}
+	sortByID(pending)
+
+	var diagnostics []string
+	for _, t := range pending {
+		var reasons []string
+		for _, depID := range t.DependsOn {
+			dep, ok := d.tasks[depID]
+			switch {
+			case !ok:
+				reasons = append(reasons, depID+" (missing)")
+			case dep.Status != StatusCompleted:
+				reasons = append(reasons, fmt.Sprintf("%s (%s)", depID, dep.Status))
+			}
+		}
+		if len(reasons) == 0 {
+			// Would be ready; no deadlock after all
+			return nil, false
+		}
+		diagnostics = append(diagnostics, fmt.Sprintf("%s blocked by %s", t.ID, strings.Join(reasons, ", ")))
+	}
+	return diagnostics, true
+}
+
+// CancelPending marks every pending task as cancelled with the given reason.
+func (d *DAG) CancelPending(reason string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, t := range d.tasks {
+		if t.Status == StatusPending {
+			t.Status = StatusCancelled
+			t.Error = reason
+		}
+	}
+}
+
 // HasFailed checks if any task has failed.
 func (d *DAG) HasFailed() bool {
 	d.mu.RLock()
@@ -170,6 +256,7 @@ func (d *DAG) HasFailed() bool {
 }
 
 // TopologicalOrder returns tasks in topological order using Kahn's algorithm.
+// Ties are broken by task ID so the order is stable across calls.
 func (d *DAG) TopologicalOrder() ([]*Task, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -177,7 +264,12 @@ func (d *DAG) TopologicalOrder() ([]*Task, error) {
 	if d.hasCycleLocked() {
 		return nil, errors.New("cycle detected in DAG")
 	}
+	return d.topologicalOrderLocked(), nil
+}
 
+// topologicalOrderLocked 是 TopologicalOrder 的内部实现，调用方必须已持有锁且 DAG 无环。
+// 引用不存在任务的依赖被忽略。
+func (d *DAG) topologicalOrderLocked() []*Task {
 	// Calculate in-degree for each task
 	inDegree := make(map[string]int)
 	for _, t := range d.tasks {
@@ -186,9 +278,11 @@ func (d *DAG) TopologicalOrder() ([]*Task, error) {
 
 	// Count incoming edges
 	for _, t := range d.tasks {
-		for range t.DependsOn {
+		for _, depID := range t.DependsOn {
 			// Edge from depID to t.ID means t.ID has an incoming edge
-			inDegree[t.ID]++
+			if _, ok := d.tasks[depID]; ok {
+				inDegree[t.ID]++
+			}
 		}
 	}
 
@@ -199,6 +293,7 @@ func (d *DAG) TopologicalOrder() ([]*Task, error) {
 			queue = append(queue, d.tasks[id])
 		}
 	}
+	sortByID(queue)
 
 	// Process nodes
 	result := make([]*Task, 0, len(d.tasks))
@@ -209,20 +304,61 @@ func (d *DAG) TopologicalOrder() ([]*Task, error) {
 		result = append(result, current)
 
 		// Reduce in-degree for dependent tasks
+		var next []*Task
 		for _, t := range d.tasks {
 			for _, depID := range t.DependsOn {
 				if depID == current.ID {
 					inDegree[t.ID]--
 					if inDegree[t.ID] == 0 {
-						queue = append(queue, t)
+						next = append(next, t)
 					}
 					break
 				}
 			}
 		}
+		sortByID(next)
+		queue = append(queue, next...)
+	}
+
+	return result
+}
+
+// Levels groups tasks by dependency depth: level 0 holds tasks without
+// dependencies, level n tasks whose deepest dependency is at level n-1.
+// Each level is a wave of tasks that can run in parallel.
+func (d *DAG) Levels() ([][]*Task, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.hasCycleLocked() {
+		return nil, errors.New("cycle detected in DAG")
+	}
+
+	depth := make(map[string]int)
+	var levels [][]*Task
+	for _, t := range d.topologicalOrderLocked() {
+		level := 0
+		for _, depID := range t.DependsOn {
+			if dl, ok := depth[depID]; ok && dl+1 > level {
+				level = dl + 1
+			}
+		}
+		depth[t.ID] = level
+		for len(levels) <= level {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], t)
+	}
+
+	for _, level := range levels {
+		sortByID(level)
 	}
+	return levels, nil
+}
 
-	return result, nil
+// sortByID sorts tasks by ID.
+func sortByID(tasks []*Task) {
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
 }
 
 // SetTaskCompleted atomically marks a task as completed with timestamp.
@@ -245,6 +381,8 @@ func (d *DAG) SetTaskFailed(taskID string, errMsg string) {
 	if t, ok := d.tasks[taskID]; ok {
 		t.Status = StatusFailed
 		t.Error = errMsg
+		now := time.Now()
+		t.CompletedAt = &now
 	}
 }
 
@@ -258,6 +396,58 @@ func (d *DAG) UpdateTaskResult(taskID string, commitSHA string) {
 	}
 }
 
+// SetTaskBranch 设置任务分支名
+func (d *DAG) SetTaskBranch(taskID, branch string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.BranchName = branch
+	}
+}
+
+// SetTaskWorktree 记录任务的 worktree 路径和起始 commit
+func (d *DAG) SetTaskWorktree(taskID, path, baseCommit string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.WorktreePath = path
+		t.BaseCommit = baseCommit
+	}
+}
+
+// AddMergedCommit 追加一个合并依赖分支产生的 commit
+func (d *DAG) AddMergedCommit(taskID, commitSHA string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.MergedCommits = append(t.MergedCommits, commitSHA)
+	}
+}
+
+// SetTaskAgent 记录执行任务的代理及其线程
+func (d *DAG) SetTaskAgent(taskID, agentID, threadID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.AgentID = agentID
+		t.ThreadID = threadID
+	}
+}
+
+// SetTaskOutcome 记录任务的结构化执行结果
+func (d *DAG) SetTaskOutcome(taskID string, result *TaskResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.Result = result
+	}
+}
+
 // GetTasks returns all tasks in the DAG.
 func (d *DAG) GetTasks() []*Task {
 	d.mu.RLock()
@@ -270,6 +460,118 @@ func (d *DAG) GetTasks() []*Task {
 	return tasks
 }
 
+// GetDependencyHeads 获取任务所有依赖任务的结果 commit（无提交时退回分支名），
+// 顺序与 GetDependencyBranches 一致
+func (d *DAG) GetDependencyHeads(taskID string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	task, ok := d.tasks[taskID]
+	if !ok {
+		return nil
+	}
+
+	var heads []string
+	for _, depID := range task.DependsOn {
+		if depTask, exists := d.tasks[depID]; exists && depTask.BranchName != "" {
+			if depTask.ResultCommit != "" {
+				heads = append(heads, depTask.ResultCommit)
+			} else {
+				heads = append(heads, depTask.BranchName)
+			}
+		}
+	}
+
+	return heads
+}
+
+// Clone returns a new DAG with the same tasks and dependencies, reset to
+// pending. Execution state (agents, worktrees, commits, results) is dropped;
+// branchPrefix, if set, is prepended to each task's branch name.
+func (d *DAG) Clone(branchPrefix string) *DAG {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	clone := NewDAG()
+	now := time.Now()
+	for id, t := range d.tasks {
+		c := &Task{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      StatusPending,
+			DependsOn:   append([]string(nil), t.DependsOn...),
+			Files:       append([]string(nil), t.Files...),
+			Outputs:     append([]string(nil), t.Outputs...),
+			Collect:     append([]string(nil), t.Collect...),
+			CreatedAt:   now,
+		}
+		c.EstimatedTime = t.EstimatedTime
+		if branchPrefix != "" {
+			c.BranchName = branchPrefix + "task-" + t.ID
+		}
+		clone.tasks[id] = c
+	}
+	return clone
+}
+
+// Snapshot returns copies of all tasks, safe to serialize while the DAG
+// keeps executing.
+func (d *DAG) Snapshot() []Task {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	tasks := make([]Task, 0, len(d.tasks))
+	for _, t := range d.tasks {
+		c := *t
+		c.DependsOn = append([]string(nil), t.DependsOn...)
+		c.MergedCommits = append([]string(nil), t.MergedCommits...)
+		c.Output = append([]string(nil), t.Output...)
+		c.Files = append([]string(nil), t.Files...)
+		c.Outputs = append([]string(nil), t.Outputs...)
+		c.Artifacts = append([]Artifact(nil), t.Artifacts...)
+		c.Collect = append([]string(nil), t.Collect...)
+		c.Collected = append([]CollectedFile(nil), t.Collected...)
+		c.Comments = append([]Comment(nil), t.Comments...)
+		tasks = append(tasks, c)
+	}
+	return tasks
+}
+
+// ResetIncomplete returns every task that did not complete to pending so a
+// resumed run picks it up again. Completed tasks keep their results.
+// The returned copies describe the tasks as they were before the reset.
+func (d *DAG) ResetIncomplete() []Task {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var reset []Task
+	for _, t := range d.tasks {
+		if t.Status == StatusCompleted || t.Status == StatusPending {
+			continue
+		}
+		reset = append(reset, *t)
+		t.Status = StatusPending
+		t.Error = ""
+		t.AgentID = ""
+		t.ThreadID = ""
+		t.WorktreePath = ""
+		t.BaseCommit = ""
+		t.ResultCommit = ""
+		t.MergedCommits = nil
+		t.StartedAt = nil
+		t.CompletedAt = nil
+		t.Result = nil
+		t.Artifacts = nil
+		t.Collected = nil
+		t.Changes = nil
+		t.NoChanges = false
+		t.FailureKind = ""
+		t.FailureDetail = ""
+	}
+	return reset
+}
+
 // GetDependencyBranches 获取任务所有依赖任务的分支名
 func (d *DAG) GetDependencyBranches(taskID string) []string {
 	d.mu.RLock()
@@ -289,3 +591,60 @@ func (d *DAG) GetDependencyBranches(taskID string) []string {
 
 	return branches
 }
+
+// Ancestors returns the IDs of every task the given task transitively
+// depends on, sorted.
+func (d *DAG) Ancestors(taskID string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var visit func(id string)
+	visit = func(id string) {
+		t, ok := d.tasks[id]
+		if !ok {
+			return
+		}
+		for _, depID := range t.DependsOn {
+			if !seen[depID] {
+				seen[depID] = true
+				visit(depID)
+			}
+		}
+	}
+	visit(taskID)
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SetTaskArtifacts records the artifacts a task produced.
+func (d *DAG) SetTaskArtifacts(taskID string, artifacts []Artifact) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.Artifacts = artifacts
+	}
+}
+
+// UpstreamArtifacts returns the artifacts produced by every task the given
+// task transitively depends on, ordered by task ID.
+func (d *DAG) UpstreamArtifacts(taskID string) []Artifact {
+	ancestors := d.Ancestors(taskID)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var artifacts []Artifact
+	for _, id := range ancestors {
+		if t, ok := d.tasks[id]; ok {
+			artifacts = append(artifacts, t.Artifacts...)
+		}
+	}
+	return artifacts
+}
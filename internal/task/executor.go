@@ -2,7 +2,9 @@ package task
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,21 +16,42 @@ import (
 // Executor executes a DAG of tasks using multiple agents.
 type Executor struct {
 	dag         *DAG
-	agentMgr    *agent.Manager
-	worktreeMgr *worktree.Manager
+	agentMgr    agent.Agents
+	worktreeMgr worktree.Git
 	maxParallel int
 	eventCh     chan ExecutionEvent
+	runner      Runner  // optional remote backend; nil runs agents locally
+	limiter     Limiter // optional quota shared with other executors
+
+	scratch *Scratchpad // optional context shared between tasks
+	runtime agent.Runtime
+
+	collector Collector // optional storage for files matching Task.Collect
+}
+
+// Runner executes a task somewhere other than a local worktree, such as a
+// cluster. It returns the result commit SHA, or "" if nothing changed.
+// output receives progress lines as they are produced.
+type Runner interface {
+	RunTask(ctx context.Context, t *Task, depBranches []string, prompt string, output func(string)) (string, error)
+}
+
+// Limiter bounds the number of tasks running at once across executors,
+// for example all sessions of one tenant.
+type Limiter interface {
+	Acquire(ctx context.Context) error
+	Release()
 }
 
 // ExecutionEvent represents an event during task execution.
 type ExecutionEvent struct {
 	TaskID    string
-	EventType string // "started", "completed", "failed", "output"
+	EventType string // "ready", "queued", "started", "unchanged", "completed", "failed", "output"
 	Data      interface{}
 }
 
 // NewExecutor creates a new Executor.
-func NewExecutor(dag *DAG, agentMgr *agent.Manager, wtMgr *worktree.Manager, maxParallel int) *Executor {
+func NewExecutor(dag *DAG, agentMgr agent.Agents, wtMgr worktree.Git, maxParallel int) *Executor {
 	if maxParallel <= 0 {
 		maxParallel = 1
 	}
@@ -41,16 +64,64 @@ func NewExecutor(dag *DAG, agentMgr *agent.Manager, wtMgr *worktree.Manager, max
 	}
 }
 
-// Events returns the event channel.
+// SetRunner routes task execution through a remote backend.
+func (e *Executor) SetRunner(r Runner) {
+	e.runner = r
+}
+
+// SetLimiter makes every task also hold a slot of l while it runs.
+func (e *Executor) SetLimiter(l Limiter) {
+	e.limiter = l
+}
+
+// SetScratchpad shares p between the tasks: upstream notes are shown to
+// downstream tasks, and notes reported by workers are written to p.
+func (e *Executor) SetScratchpad(p *Scratchpad) {
+	e.scratch = p
+}
+
+// SetRuntime makes worker agents run with the given codex binary and
+// model provider.
+func (e *Executor) SetRuntime(rt agent.Runtime) {
+	e.runtime = rt
+}
+
+// prompt builds the worker prompt of t, including the artifacts and the
+// scratchpad entries of the tasks it depends on, recent comments on t and
+// the failed attempt a retry was given.
+func (e *Executor) prompt(t *Task) string {
+	prompt := buildWorkerPrompt(t.Description) + formatFiles(t.Files) + formatOutputs(t.Outputs) +
+		formatArtifacts(e.dag.UpstreamArtifacts(t.ID))
+	if comments, ok := e.dag.Comments(t.ID); ok {
+		prompt += formatComments(comments)
+	}
+	prompt += formatAttempt(e.dag.FailedAttempt(t.ID))
+	if e.scratch != nil {
+		prompt += formatScratchpad(e.scratch.visibleTo(e.dag.Ancestors(t.ID)))
+	}
+	return prompt
+}
+
+// Events returns the event channel. Events that do not fit in its buffer
+// are dropped, so execution never waits for a consumer.
 func (e *Executor) Events() <-chan ExecutionEvent {
 	return e.eventCh
 }
 
+// emit publishes an execution event without blocking.
+func (e *Executor) emit(ev ExecutionEvent) {
+	select {
+	case e.eventCh <- ev:
+	default:
+	}
+}
+
 // Run executes the DAG until all tasks complete or fail.
 func (e *Executor) Run(ctx context.Context) error {
 	// Create cancellable context for cascading cancellation
 	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	defer e.dag.ClearQueued()
 
 	sem := make(chan struct{}, e.maxParallel)
 	var wg sync.WaitGroup
@@ -65,8 +136,23 @@ func (e *Executor) Run(ctx context.Context) error {
 			break
 		}
 
+		for _, t := range e.dag.PromoteReady() {
+			e.emit(ExecutionEvent{
+				TaskID:    t.ID,
+				EventType: "ready",
+			})
+		}
+
 		ready := e.dag.ReadyTasks()
 		if len(ready) == 0 {
+			// Nothing running and nothing can start: fail instead of spinning
+			if diagnostics, deadlocked := e.dag.DiagnoseDeadlock(); deadlocked {
+				wg.Wait()
+				msg := strings.Join(diagnostics, "; ")
+				e.dag.CancelPending("deadlock: " + msg)
+				return fmt.Errorf("deadlocked DAG: %s", msg)
+			}
+
 			// Wait for a running task to complete
 			select {
 			case <-runCtx.Done():
@@ -76,32 +162,52 @@ func (e *Executor) Run(ctx context.Context) error {
 			}
 		}
 
-		for _, task := range ready {
-			// Update status to running via DAG (thread-safe)
-			e.dag.UpdateStatus(task.ID, StatusRunning)
+		for i, task := range ready {
+			// Acquire semaphore, queueing the remaining ready tasks behind it
+			select {
+			case sem <- struct{}{}:
+			default:
+				for pos, t := range ready[i:] {
+					e.markQueued(t, QueueInfo{Reason: QueueSession, Position: pos + 1, Slots: e.maxParallel})
+				}
+				sem <- struct{}{}
+			}
+			if e.limiter != nil {
+				if err := e.acquireLimiter(runCtx, task); err != nil {
+					<-sem
+					wg.Wait()
+					return err
+				}
+			}
 
-			// Acquire semaphore
-			sem <- struct{}{}
+			// Mark running and stamp StartedAt via DAG (thread-safe)
+			e.dag.SetTaskRunning(task.ID)
 			wg.Add(1)
 
 			go func(t *Task) {
 				defer wg.Done()
 				defer func() { <-sem }()
+				if e.limiter != nil {
+					defer e.limiter.Release()
+				}
 
 				err := e.executeTask(runCtx, t)
 				if err != nil {
+					failure := e.classify(t.ID, err)
 					e.dag.SetTaskFailed(t.ID, err.Error())
-					e.eventCh <- ExecutionEvent{
+					e.dag.SetTaskFailure(t.ID, failure.Kind, failure.Detail)
+					e.emit(ExecutionEvent{
 						TaskID:    t.ID,
 						EventType: "failed",
-						Data:      err.Error(),
-					}
+						Data:      failure,
+					})
 				} else {
 					e.dag.SetTaskCompleted(t.ID)
-					e.eventCh <- ExecutionEvent{
+					e.emit(ExecutionEvent{
 						TaskID:    t.ID,
 						EventType: "completed",
-					}
+						Data:      e.dag.TaskChanges(t.ID),
+					})
 				}
 			}(task)
 		}
@@ -119,88 +225,252 @@ func (e *Executor) Run(ctx context.Context) error {
 func (e *Executor) executeTask(ctx context.Context, t *Task) error {
 	agentID := "agent-" + t.ID
 
-	e.eventCh <- ExecutionEvent{
+	e.emit(ExecutionEvent{
 		TaskID:    t.ID,
 		EventType: "started",
-	}
+	})
 
 	// 1. Prepare branch name
 	if t.BranchName == "" {
-		t.BranchName = "task-" + t.ID
+		e.dag.SetTaskBranch(t.ID, "task-"+t.ID)
 	}
 
-	// 2. Create worktree (path derived from branchName inside Create)
-	wt, err := e.worktreeMgr.Create(ctx, t.BranchName, "")
+	if e.runner != nil {
+		return e.executeRemote(ctx, t)
+	}
+
+	// 2. Create worktree (path derived from branchName inside Create).
+	// With dependencies, chain it from the first dependency's result commit
+	// so only the remaining dependencies need merging.
+	depBranches := e.dag.GetDependencyBranches(t.ID)
+	depHeads := e.dag.GetDependencyHeads(t.ID)
+	base := ""
+	if len(depHeads) > 0 {
+		base = depHeads[0]
+		depBranches = depBranches[1:]
+	}
+
+	wt, err := e.worktreeMgr.Create(ctx, t.BranchName, base)
 	if err != nil {
 		return fmt.Errorf("create worktree: %w", err)
 	}
-	t.WorktreePath = wt.Path
-	t.BaseCommit = wt.Commit
+	e.dag.SetTaskWorktree(t.ID, wt.Path, wt.Commit)
 
-	// 3. Merge all dependency task branches
-	depBranches := e.dag.GetDependencyBranches(t.ID)
+	// 3. Merge the remaining dependency branches, resolving conflicts with an agent
 	for _, depBranch := range depBranches {
-		commitSHA, mergeErr := e.worktreeMgr.Merge(ctx, t.WorktreePath, depBranch)
+		commitSHA, mergeErr := e.mergeDependency(ctx, t, depBranch)
 		if mergeErr != nil {
 			e.cleanupWorktree(t.WorktreePath)
 			return fmt.Errorf("merge dependency branch %s: %w", depBranch, mergeErr)
 		}
 		if commitSHA != "" {
-			t.MergedCommits = append(t.MergedCommits, commitSHA)
+			e.dag.AddMergedCommit(t.ID, commitSHA)
 		}
 	}
 
+	// Make sure upstream artifacts survived the merges
+	if err := e.provideArtifacts(ctx, t, e.dag.UpstreamArtifacts(t.ID)); err != nil {
+		e.cleanupWorktree(t.WorktreePath)
+		return err
+	}
+
 	// 4. Spawn agent for this task
 	agentCfg := agent.AgentConfig{
 		ID:          agentID,
 		Role:        agent.RoleWorker,
 		Cwd:         t.WorktreePath,
 		SandboxMode: codexrpc.SandboxWorkspaceWrite,
+		Runtime:     e.runtime,
 	}
 
-	_, err = e.agentMgr.SpawnAgent(ctx, agentCfg)
+	inst, err := e.agentMgr.SpawnAgent(ctx, agentCfg)
 	if err != nil {
 		e.cleanupWorktree(t.WorktreePath)
 		return fmt.Errorf("spawn agent: %w", err)
 	}
-	t.AgentID = agentID
+	e.dag.SetTaskAgent(t.ID, agentID, inst.ThreadID)
 
 	// 5. Send task to agent
-	err = e.agentMgr.SendTask(ctx, agentID, t.Description)
+	turn, err := e.agentMgr.SendTask(ctx, agentID, t.ID, e.prompt(t))
 	if err != nil {
-		e.cleanup(agentID, t.WorktreePath)
+		e.cleanup(t, agentID)
 		return fmt.Errorf("send task: %w", err)
 	}
 
 	// 6. Wait for agent to complete
-	err = e.agentMgr.WaitForCompletion(ctx, agentID)
+	err = e.agentMgr.WaitForCompletion(ctx, turn)
 	if err != nil {
-		e.cleanup(agentID, t.WorktreePath)
+		e.cleanup(t, agentID)
 		return fmt.Errorf("agent execution: %w", err)
 	}
 
-	// 7. Commit agent's changes
+	// 7. Collect the reported result and the files git actually saw change
+	output := e.agentMgr.GetOutput(agentID)
+	changed, err := e.worktreeMgr.ChangedFiles(ctx, t.WorktreePath)
+	if err != nil {
+		e.cleanup(t, agentID)
+		return fmt.Errorf("list changed files: %w", err)
+	}
+
+	// Every declared output must exist before dependents can rely on it
+	artifacts, err := collectArtifacts(t)
+	if err != nil {
+		e.cleanup(t, agentID)
+		return err
+	}
+
+	// 8. Commit agent's changes
 	commitMsg := fmt.Sprintf("Task %s: %s", t.ID, t.Title)
 	commitSHA, err := e.worktreeMgr.CommitChanges(ctx, t.WorktreePath, commitMsg)
 	if err != nil {
-		e.cleanup(agentID, t.WorktreePath)
+		e.cleanup(t, agentID)
 		return fmt.Errorf("commit changes: %w", err)
 	}
 	if commitSHA != "" {
-		t.ResultCommit = commitSHA
 		e.dag.UpdateTaskResult(t.ID, commitSHA)
+	} else {
+		e.markUnchanged(t)
+	}
+	for i := range artifacts {
+		// Unchanged outputs are found in the commit the task started from
+		artifacts[i].Commit = t.ResultCommit
+		if artifacts[i].Commit == "" {
+			artifacts[i].Commit = t.BaseCommit
+		}
+	}
+	e.dag.SetTaskArtifacts(t.ID, artifacts)
+	changes := e.changeStat(ctx, t)
+	e.dag.SetTaskChanges(t.ID, changes)
+	result := e.buildResult(output, changed, changes)
+	result.TestRuns = e.agentMgr.TestRuns(agentID)
+	result.Tests = scanTestReports(t.WorktreePath, result.TestRuns)
+	e.dag.SetTaskOutcome(t.ID, result)
+	e.collectFiles(t)
+	if e.scratch != nil {
+		for key, value := range result.Notes {
+			e.scratch.Set(key, value, t.ID)
+		}
 	}
 
-	// 8. Cleanup: stop agent (worktree kept for merge)
+	// 9. Cleanup: stop agent (worktree kept for merge)
 	_ = e.agentMgr.StopAgent(agentID)
 
 	return nil
 }
 
-// cleanup stops the agent and removes the worktree on failure.
-func (e *Executor) cleanup(agentID string, worktreePath string) {
+// mergeDependency merges a dependency branch into the task's worktree.
+// On conflict a resolver agent is spawned in the worktree before giving up.
+func (e *Executor) mergeDependency(ctx context.Context, t *Task, depBranch string) (string, error) {
+	commitSHA, conflicts, err := e.worktreeMgr.TryMerge(ctx, t.WorktreePath, depBranch)
+	if !errors.Is(err, worktree.ErrConflict) {
+		return commitSHA, err
+	}
+
+	e.emit(ExecutionEvent{
+		TaskID:    t.ID,
+		EventType: "output",
+		Data:      fmt.Sprintf("conflicts merging %s: %s", depBranch, strings.Join(conflicts, ", ")),
+	})
+
+	merger := agent.NewMerger(e.agentMgr, e.worktreeMgr)
+	merger.SetRuntime(e.runtime)
+	resolved, resolveErr := merger.ResolveConflicts(ctx, t.WorktreePath, depBranch, conflicts)
+	if resolveErr == nil && resolved {
+		// Trust the agent only if git agrees nothing is left unmerged
+		if remaining, _, cerr := e.worktreeMgr.HasConflicts(ctx, t.WorktreePath); cerr == nil && !remaining {
+			msg := fmt.Sprintf("Merge %s (conflicts resolved by agent)", depBranch)
+			return e.worktreeMgr.CommitChanges(ctx, t.WorktreePath, msg)
+		}
+	}
+
+	_ = e.worktreeMgr.AbortMerge(ctx, t.WorktreePath)
+	if resolveErr != nil {
+		return "", fmt.Errorf("resolve conflicts: %w", resolveErr)
+	}
+	return "", fmt.Errorf("%w: unresolved in %s", worktree.ErrConflict, strings.Join(conflicts, ", "))
+}
+
+// executeRemote runs the task through the configured Runner. Remote tasks
+// have no local worktree: their result branch is fetched into the
+// repository, and the result is built from the streamed output and the
+// diff of the result commit.
+func (e *Executor) executeRemote(ctx context.Context, t *Task) error {
+	var mu sync.Mutex
+	var lines []string
+	output := func(line string) {
+		mu.Lock()
+		lines = append(lines, line)
+		mu.Unlock()
+		e.emit(ExecutionEvent{
+			TaskID:    t.ID,
+			EventType: "output",
+			Data:      line,
+		})
+	}
+
+	// The runner fills in the base commit; keep its writes off the shared task
+	remote := *t
+	depBranches := e.dag.GetDependencyBranches(t.ID)
+	commitSHA, err := e.runner.RunTask(ctx, &remote, depBranches, e.prompt(t), output)
+	e.dag.SetTaskWorktree(t.ID, "", remote.BaseCommit)
+	if err != nil {
+		return fmt.Errorf("remote execution: %w", err)
+	}
+
+	var changed []string
+	if commitSHA != "" {
+		e.dag.UpdateTaskResult(t.ID, commitSHA)
+		from := remote.BaseCommit
+		if from == "" {
+			from = commitSHA + "^"
+		}
+		changed, _ = e.worktreeMgr.DiffFiles(ctx, e.worktreeMgr.GetRepoPath(), from, commitSHA)
+	} else {
+		e.markUnchanged(t)
+	}
+	changes := e.changeStat(ctx, t)
+	e.dag.SetTaskChanges(t.ID, changes)
+
+	mu.Lock()
+	out := strings.Join(lines, "\n")
+	mu.Unlock()
+	result := e.buildResult(out, changed, changes)
+	e.dag.SetTaskOutcome(t.ID, result)
+	if e.scratch != nil {
+		for key, value := range result.Notes {
+			e.scratch.Set(key, value, t.ID)
+		}
+	}
+	return nil
+}
+
+// buildResult parses the worker's result block and reconciles it with git.
+// When the agent reported nothing usable, the result is computed from git.
+func (e *Executor) buildResult(output string, changed []string, changes *worktree.ChangeStat) *TaskResult {
+	result, err := parseTaskResult(output)
+	if err != nil {
+		result = &TaskResult{Computed: true}
+	}
+	reconcileResult(result, changed)
+
+	if changes != nil {
+		result.DiffStat = changes.Summary
+	}
+	if result.Computed {
+		result.Summary = result.DiffStat
+		if result.Summary == "" {
+			result.Summary = "no changes"
+		}
+	}
+	return result
+}
+
+// cleanup records the failed attempt, then stops the agent and removes the
+// worktree.
+func (e *Executor) cleanup(t *Task, agentID string) {
+	e.dag.SetFailedAttempt(t.ID, e.captureAttempt(t, agentID))
 	_ = e.agentMgr.StopAgent(agentID)
-	_ = e.worktreeMgr.Remove(context.Background(), worktreePath)
+	_ = e.worktreeMgr.Remove(context.Background(), t.WorktreePath)
 }
 
 // cleanupWorktree removes worktree only (before agent is spawned).
@@ -0,0 +1,190 @@
+package task_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/agent/agenttest"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/worktree/worktreetest"
+)
+
+const repo = "/repo"
+
+func newDAG(t *testing.T, tasks ...*task.Task) *task.DAG {
+	t.Helper()
+	dag := task.NewDAG()
+	for _, tk := range tasks {
+		tk.Status = task.StatusPending
+		if err := dag.AddTask(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dag
+}
+
+// writeTaskFile makes every task write <task ID>.txt in its worktree.
+func writeTaskFile(t *testing.T, g *worktreetest.Git) func(agent.AgentConfig, string, string) agenttest.Reply {
+	return func(cfg agent.AgentConfig, taskID, message string) agenttest.Reply {
+		if err := g.WriteFile(cfg.Cwd, taskID+".txt", taskID+"\n"); err != nil {
+			t.Error(err)
+		}
+		return agenttest.Reply{Output: "done " + taskID + "\n"}
+	}
+}
+
+func TestExecutorChainsDependencies(t *testing.T) {
+	g := worktreetest.New(repo, "main", map[string]string{"README": "demo\n"})
+	agents := agenttest.New(writeTaskFile(t, g))
+	dag := newDAG(t,
+		&task.Task{ID: "a", Title: "A"},
+		&task.Task{ID: "b", Title: "B", DependsOn: []string{"a"}},
+		&task.Task{ID: "c", Title: "C", DependsOn: []string{"a"}},
+		&task.Task{ID: "d", Title: "D", DependsOn: []string{"b", "c"}},
+	)
+
+	if err := task.NewExecutor(dag, agents, g, 2).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(map[string]int)
+	for i, msg := range agents.Sent() {
+		order[msg.TaskID] = i
+	}
+	if len(order) != 4 {
+		t.Fatalf("tasks sent: %v", agents.Sent())
+	}
+	for _, dep := range [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}} {
+		if order[dep[0]] > order[dep[1]] {
+			t.Errorf("%s ran before its dependency %s", dep[1], dep[0])
+		}
+	}
+
+	tasks := make(map[string]*task.Task)
+	for _, tk := range dag.GetTasks() {
+		if tk.Status != task.StatusCompleted {
+			t.Errorf("task %s is %s: %s", tk.ID, tk.Status, tk.Error)
+		}
+		tasks[tk.ID] = tk
+	}
+
+	// b starts from a's result; d starts from b's and merges c
+	if b := tasks["b"]; b.BaseCommit != tasks["a"].ResultCommit {
+		t.Errorf("b based on %s, want a's result %s", b.BaseCommit, tasks["a"].ResultCommit)
+	}
+	d := tasks["d"]
+	if d.BaseCommit != tasks["b"].ResultCommit {
+		t.Errorf("d based on %s, want b's result %s", d.BaseCommit, tasks["b"].ResultCommit)
+	}
+	if len(d.MergedCommits) != 1 {
+		t.Errorf("d merged %v, want c's branch", d.MergedCommits)
+	}
+	files := g.Files(d.ResultCommit)
+	for _, name := range []string{"README", "a.txt", "b.txt", "c.txt", "d.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("d's result lacks %s", name)
+		}
+	}
+	if d.Result == nil || len(d.Result.FilesChanged) != 1 || d.Result.FilesChanged[0] != "d.txt" {
+		t.Errorf("d's result = %+v, want d.txt changed", d.Result)
+	}
+}
+
+func TestExecutorBoundsParallelism(t *testing.T) {
+	g := worktreetest.New(repo, "main", map[string]string{"README": "demo\n"})
+	write := writeTaskFile(t, g)
+	var mu sync.Mutex
+	running, peak := 0, 0
+	agents := agenttest.New(func(cfg agent.AgentConfig, taskID, message string) agenttest.Reply {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return write(cfg, taskID, message)
+	})
+	dag := newDAG(t,
+		&task.Task{ID: "a"}, &task.Task{ID: "b"}, &task.Task{ID: "c"},
+		&task.Task{ID: "d"}, &task.Task{ID: "e"},
+	)
+
+	if err := task.NewExecutor(dag, agents, g, 2).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if peak != 2 {
+		t.Errorf("%d tasks ran at once, want 2", peak)
+	}
+	if n := len(agents.Sent()); n != 5 {
+		t.Errorf("%d tasks sent, want 5", n)
+	}
+}
+
+func TestExecutorRetriesWithFailedAttempt(t *testing.T) {
+	ctx := context.Background()
+	g := worktreetest.New(repo, "main", map[string]string{"README": "demo\n"})
+	attempts := 0
+	agents := agenttest.New(func(cfg agent.AgentConfig, taskID, message string) agenttest.Reply {
+		attempts++
+		if err := g.WriteFile(cfg.Cwd, "a.txt", "attempt\n"); err != nil {
+			t.Error(err)
+		}
+		if attempts == 1 {
+			return agenttest.Reply{Output: "ran out of ideas\n", Err: errors.New("turn failed")}
+		}
+		return agenttest.Reply{Output: "done\n"}
+	})
+	dag := newDAG(t, &task.Task{ID: "a", Title: "A"}, &task.Task{ID: "b", Title: "B", DependsOn: []string{"a"}})
+
+	if err := task.NewExecutor(dag, agents, g, 1).Run(ctx); err == nil {
+		t.Fatal("run with a failing task succeeded")
+	}
+	a, _ := dag.Get("a")
+	if a.Status != task.StatusFailed || !strings.Contains(a.Error, "turn failed") {
+		t.Fatalf("a is %s (%q), want failed", a.Status, a.Error)
+	}
+	attempt := dag.FailedAttempt("a")
+	if attempt == nil || attempt.Output != "ran out of ideas\n" || len(attempt.Files) != 1 || attempt.Files[0] != "a.txt" {
+		t.Fatalf("failed attempt = %+v", attempt)
+	}
+	if b, _ := dag.Get("b"); b.Status != task.StatusPending {
+		t.Errorf("b is %s, want it left pending", b.Status)
+	}
+
+	// Resume the way a session does with retryWithContext
+	for _, tk := range dag.ResetIncomplete() {
+		if tk.Status == task.StatusFailed {
+			retry := *tk.FailedAttempt
+			retry.Error = tk.Error
+			dag.SetFailedAttempt(tk.ID, &retry)
+		}
+		if err := g.DeleteBranch(ctx, tk.BranchName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := task.NewExecutor(dag, agents, g, 1).Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := agents.Sent()
+	if len(sent) != 3 {
+		t.Fatalf("sent %d tasks, want a twice and b once", len(sent))
+	}
+	retry := sent[1]
+	if retry.TaskID != "a" || !strings.Contains(retry.Text, "A previous attempt at this task failed because: agent execution: turn failed") ||
+		!strings.Contains(retry.Text, "ran out of ideas") {
+		t.Errorf("retry prompt lacks the failed attempt:\n%s", retry.Text)
+	}
+	for _, tk := range dag.GetTasks() {
+		if tk.Status != task.StatusCompleted {
+			t.Errorf("task %s is %s after the retry", tk.ID, tk.Status)
+		}
+	}
+}
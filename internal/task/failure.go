@@ -0,0 +1,146 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/worktree"
+)
+
+// FailureKind classifies why a task failed.
+type FailureKind string
+
+const (
+	FailureSpawn         FailureKind = "spawn"          // the agent's app-server could not start
+	FailureAuth          FailureKind = "auth"           // codex is not logged in or was refused
+	FailureTurn          FailureKind = "turn"           // codex reported the turn as failed
+	FailureMergeConflict FailureKind = "merge_conflict" // dependency branches conflict
+	FailureTests         FailureKind = "tests"          // the task's last test run failed
+	FailureTimeout       FailureKind = "timeout"        // the agent stalled or stopped answering
+	FailureCancelled     FailureKind = "cancelled"      // stopped because the run was cancelled
+	FailureOther         FailureKind = "other"
+)
+
+// Remediation actions, named after the API call that carries them out.
+const (
+	RemedyResume            = "resume"            // POST .../resume
+	RemedyResumeWithContext = "resumeWithContext" // POST .../resume?retryWithContext=true
+	RemedyLogin             = "login"             // run `codex login`, then resume
+	RemedyNone              = "none"              // nothing to do for this task
+)
+
+// Remediation suggests what to do about a failure.
+type Remediation struct {
+	Action string `json:"action"`
+	Hint   string `json:"hint"`
+}
+
+// Failure is the data of a "failed" execution event.
+type Failure struct {
+	Error       string      `json:"error"`
+	Kind        FailureKind `json:"kind"`
+	Detail      string      `json:"detail,omitempty"` // codex stderr or turn error details
+	Remediation Remediation `json:"remediation"`
+}
+
+// failureDetailBytes bounds the codex stderr kept with a failure.
+const failureDetailBytes = 4 << 10
+
+// authMarkers are substrings of codex errors caused by a missing or
+// rejected login.
+var authMarkers = []string{"401", "unauthorized", "not logged in", "codex login", "invalid api key", "authentication"}
+
+// classifyFailure types the error a task failed with. a is the failed
+// attempt recorded for the task, or nil.
+func classifyFailure(err error, a *Attempt) Failure {
+	f := Failure{Error: err.Error(), Kind: FailureOther}
+
+	var spawnErr *agent.SpawnError
+	var turnErr *agent.TurnError
+	switch {
+	case errors.As(err, &spawnErr):
+		f.Kind = FailureSpawn
+		f.Detail = tail(strings.TrimSpace(spawnErr.Stderr), failureDetailBytes)
+	case errors.As(err, &turnErr):
+		f.Kind = FailureTurn
+		f.Detail = turnErr.Details
+	case errors.Is(err, worktree.ErrConflict):
+		f.Kind = FailureMergeConflict
+	case errors.Is(err, agent.ErrAgentStalled), errors.Is(err, agent.ErrAgentUnresponsive),
+		errors.Is(err, context.DeadlineExceeded):
+		f.Kind = FailureTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, agent.ErrAgentStopped):
+		f.Kind = FailureCancelled
+	}
+
+	text := strings.ToLower(f.Error + "\n" + f.Detail)
+	if errors.Is(err, agent.ErrNotAuthenticated) {
+		f.Kind = FailureAuth
+	} else if f.Kind == FailureSpawn || f.Kind == FailureTurn {
+		for _, marker := range authMarkers {
+			if strings.Contains(text, marker) {
+				f.Kind = FailureAuth
+				break
+			}
+		}
+	}
+	if a != nil && a.TestsFailed && (f.Kind == FailureTurn || f.Kind == FailureOther) {
+		f.Kind = FailureTests
+	}
+
+	f.Remediation = remediate(f.Kind)
+	return f
+}
+
+// remediate suggests the action most likely to fix a failure of kind k.
+func remediate(k FailureKind) Remediation {
+	switch k {
+	case FailureSpawn:
+		return Remediation{RemedyResume, "Check that the codex binary starts (see the detail for its stderr), then resume the session."}
+	case FailureAuth:
+		return Remediation{RemedyLogin, "Run `codex login` for the session's codex binary, then resume the session."}
+	case FailureTurn:
+		return Remediation{RemedyResumeWithContext, "Resume with retryWithContext so the next agent sees why this attempt failed."}
+	case FailureMergeConflict:
+		return Remediation{RemedyResumeWithContext, "The task's dependencies changed the same lines. Leave a comment on how to combine them, then resume with retryWithContext."}
+	case FailureTests:
+		return Remediation{RemedyResumeWithContext, "Resume with retryWithContext so the next agent starts from the failing test output."}
+	case FailureTimeout:
+		return Remediation{RemedyResume, "Resume the session; if the task stalls again, split it or raise the stall timeout."}
+	case FailureCancelled:
+		return Remediation{RemedyNone, "The task was stopped because the run was cancelled or another task failed."}
+	}
+	return Remediation{RemedyResume, "Inspect the error, then resume the session."}
+}
+
+// classify types the error a task failed with, using the attempt recorded
+// when its agent was stopped if that happened during this run.
+func (e *Executor) classify(taskID string, err error) Failure {
+	return classifyFailure(err, e.dag.currentAttempt(taskID))
+}
+
+// currentAttempt returns the failed attempt of a task if it was recorded
+// after the task last started, or nil.
+func (d *DAG) currentAttempt(taskID string) *Attempt {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	t, ok := d.tasks[taskID]
+	if !ok || t.FailedAttempt == nil || t.StartedAt == nil || t.FailedAttempt.FailedAt.Before(*t.StartedAt) {
+		return nil
+	}
+	return t.FailedAttempt
+}
+
+// SetTaskFailure records the classification of a task's failure.
+func (d *DAG) SetTaskFailure(taskID string, kind FailureKind, detail string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.tasks[taskID]; ok {
+		t.FailureKind = kind
+		t.FailureDetail = detail
+	}
+}
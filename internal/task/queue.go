@@ -0,0 +1,121 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"codex-agent-team/internal/agent"
+)
+
+// Reasons a ready task has not started.
+const (
+	QueueSession = "session" // the session runs its maximum of parallel tasks
+	QueueAgents  = "agents"  // the shared agent pool is full
+	QueuePaused  = "paused"  // a higher-priority urgent session is executing
+)
+
+// QueueInfo describes why a ready task has not started yet.
+type QueueInfo struct {
+	Reason         string    `json:"reason"`
+	Position       int       `json:"position,omitempty"`       // 1 for the next task to start
+	Slots          int       `json:"slots,omitempty"`          // slots shared by the queue
+	Since          time.Time `json:"since"`                    // when the task started waiting
+	ExpectedWaitMs int64     `json:"expectedWaitMs,omitempty"` // rough estimate, 0 when unknown
+}
+
+// QueueLimiter is a Limiter that reports a task's place in its queue while
+// the task waits for a slot.
+type QueueLimiter interface {
+	Limiter
+	// AcquireQueued is Acquire, calling queued whenever the task starts
+	// waiting or its place in the queue changes. Only Reason, Position and
+	// Slots are set.
+	AcquireQueued(ctx context.Context, queued func(QueueInfo)) error
+}
+
+// SetTaskQueued records why a ready task waits and returns the recorded
+// state. It reports whether the reason or position changed; the time the
+// task started waiting is kept.
+func (d *DAG) SetTaskQueued(taskID string, q QueueInfo) (QueueInfo, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t, ok := d.tasks[taskID]
+	if !ok || t.Status != StatusReady {
+		return q, false
+	}
+	if t.Queue != nil {
+		if t.Queue.Reason == q.Reason && t.Queue.Position == q.Position {
+			return *t.Queue, false
+		}
+		q.Since = t.Queue.Since
+	}
+	t.Queue = &q
+	return q, true
+}
+
+// ClearQueued forgets the queue state of every task.
+func (d *DAG) ClearQueued() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, t := range d.tasks {
+		t.Queue = nil
+	}
+}
+
+// averageDuration returns the mean run time of completed tasks, falling
+// back to the mean planning estimate. It is 0 when neither is known.
+func (d *DAG) averageDuration() time.Duration {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var total, estimated time.Duration
+	var n, m int
+	for _, t := range d.tasks {
+		if t.Status == StatusCompleted && t.StartedAt != nil && t.CompletedAt != nil {
+			total += t.CompletedAt.Sub(*t.StartedAt)
+			n++
+		}
+		if est, ok := agent.ParseEstimate(t.EstimatedTime); ok {
+			estimated += est
+			m++
+		}
+	}
+	switch {
+	case n > 0:
+		return total / time.Duration(n)
+	case m > 0:
+		return estimated / time.Duration(m)
+	}
+	return 0
+}
+
+// markQueued records that a task waits and announces changes with a
+// "queued" event. The expected wait assumes every task ahead takes the
+// average task duration.
+func (e *Executor) markQueued(t *Task, q QueueInfo) {
+	q.Since = time.Now()
+	if q.Reason != QueuePaused && q.Position > 0 && q.Slots > 0 {
+		rounds := (q.Position + q.Slots - 1) / q.Slots
+		q.ExpectedWaitMs = (e.dag.averageDuration() * time.Duration(rounds)).Milliseconds()
+	}
+	q, changed := e.dag.SetTaskQueued(t.ID, q)
+	if !changed {
+		return
+	}
+	e.emit(ExecutionEvent{
+		TaskID:    t.ID,
+		EventType: "queued",
+		Data:      q,
+	})
+}
+
+// acquireLimiter takes a slot of the executor's limiter for t, recording
+// the task's place in the limiter's queue when it reports one.
+func (e *Executor) acquireLimiter(ctx context.Context, t *Task) error {
+	if ql, ok := e.limiter.(QueueLimiter); ok {
+		return ql.AcquireQueued(ctx, func(q QueueInfo) { e.markQueued(t, q) })
+	}
+	return e.limiter.Acquire(ctx)
+}
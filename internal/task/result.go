@@ -0,0 +1,105 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/testreport"
+)
+
+// resultInstructions is appended to every worker prompt so the agent ends
+// its turn with a machine-readable result block.
+const resultInstructions = `
+
+When you are finished, end your reply with a JSON result block in exactly this format:
+` + "```json" + `
+{
+  "summary": "One or two sentences describing what you changed",
+  "filesChanged": ["path/to/file.go"],
+  "testsRun": ["go test ./..."],
+  "followUps": ["Anything left undone or worth doing next"],
+  "notes": {"name": "Decisions other tasks must follow, such as API contracts or naming"}
+}
+` + "```" + `
+Leave "notes" empty unless later tasks depend on a decision you made.`
+
+// buildWorkerPrompt appends the result contract to a task description.
+func buildWorkerPrompt(description string) string {
+	return description + resultInstructions
+}
+
+// parseTaskResult extracts the last JSON result block from the agent output.
+func parseTaskResult(output string) (*TaskResult, error) {
+	jsonStr := ""
+
+	// Prefer the last fenced block, since the contract asks for it at the end
+	if start := strings.LastIndex(output, "```json"); start >= 0 {
+		body := output[start+len("```json"):]
+		if end := strings.Index(body, "```"); end >= 0 {
+			jsonStr = strings.TrimSpace(body[:end])
+		}
+	}
+
+	// Fall back to the trailing {...} object
+	if jsonStr == "" {
+		end := strings.LastIndex(output, "}")
+		start := strings.LastIndex(output[:max(end, 0)], "\n{")
+		if start < 0 && strings.HasPrefix(output, "{") {
+			start = 0
+		}
+		if end < 0 || start < 0 {
+			return nil, errors.New("no result block found")
+		}
+		jsonStr = strings.TrimSpace(output[start : end+1])
+	}
+
+	var result TaskResult
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return nil, fmt.Errorf("unmarshal result: %w", err)
+	}
+	return &result, nil
+}
+
+// reconcileResult validates the reported files against the files git
+// actually saw change. Files the agent claimed but did not touch are
+// dropped, and files it forgot to mention are added.
+func reconcileResult(result *TaskResult, actual []string) {
+	actualSet := make(map[string]bool, len(actual))
+	for _, f := range actual {
+		actualSet[f] = true
+	}
+
+	seen := make(map[string]bool)
+	files := make([]string, 0, len(actual))
+	for _, f := range result.FilesChanged {
+		f = strings.TrimPrefix(f, "./")
+		if actualSet[f] && !seen[f] {
+			files = append(files, f)
+			seen[f] = true
+		}
+	}
+
+	var missing []string
+	for _, f := range actual {
+		if !seen[f] {
+			missing = append(missing, f)
+		}
+	}
+	sort.Strings(missing)
+
+	result.FilesChanged = append(files, missing...)
+}
+
+// scanTestReports reads the test and coverage reports left in a worktree
+// and the go test -json output of the task's test runs.
+func scanTestReports(worktreePath string, runs []agent.TestRun) *testreport.Report {
+	outputs := make([]string, 0, len(runs))
+	for _, run := range runs {
+		outputs = append(outputs, run.Output)
+	}
+	return testreport.Scan(worktreePath, outputs)
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one value on a session's scratchpad. TaskID names the task
+// that wrote it and is empty for entries written through the API.
+type Entry struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	TaskID    string    `json:"taskId,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Scratchpad is a session-scoped key-value store that keeps parallel
+// workers consistent. Workers publish decisions such as API contracts or
+// names through the notes of their result block, and the Executor shows
+// downstream tasks what their upstream tasks decided.
+type Scratchpad struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewScratchpad creates an empty scratchpad.
+func NewScratchpad() *Scratchpad {
+	return &Scratchpad{entries: make(map[string]Entry)}
+}
+
+// Set writes a value on behalf of taskID, replacing any previous value.
+func (p *Scratchpad) Set(key, value, taskID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries[key] = Entry{Key: key, Value: value, TaskID: taskID, UpdatedAt: time.Now()}
+}
+
+// Delete removes a key and reports whether it existed.
+func (p *Scratchpad) Delete(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.entries[key]
+	delete(p.entries, key)
+	return ok
+}
+
+// Entries returns all entries sorted by key.
+func (p *Scratchpad) Entries() []Entry {
+	return p.filter(func(Entry) bool { return true })
+}
+
+// Restore replaces the contents with previously saved entries.
+func (p *Scratchpad) Restore(entries []Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		p.entries[e.Key] = e
+	}
+}
+
+// MarshalJSON encodes the scratchpad as its sorted entries.
+func (p *Scratchpad) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Entries())
+}
+
+// visibleTo returns the entries relevant to a task: those written through
+// the API and those written by the given upstream tasks.
+func (p *Scratchpad) visibleTo(upstream []string) []Entry {
+	authors := make(map[string]bool, len(upstream))
+	for _, id := range upstream {
+		authors[id] = true
+	}
+	return p.filter(func(e Entry) bool { return e.TaskID == "" || authors[e.TaskID] })
+}
+
+func (p *Scratchpad) filter(keep func(Entry) bool) []Entry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	entries := make([]Entry, 0, len(p.entries))
+	for _, e := range p.entries {
+		if keep(e) {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// formatScratchpad renders entries as a prompt section, or "" if empty.
+func formatScratchpad(entries []Entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nShared context decided elsewhere in this session. Stay consistent with it:\n")
+	for _, e := range entries {
+		source := "session"
+		if e.TaskID != "" {
+			source = "task " + e.TaskID
+		}
+		fmt.Fprintf(&b, "- %s (from %s): %s\n", e.Key, source, e.Value)
+	}
+	return b.String()
+}
@@ -1,6 +1,12 @@
 package task
 
-import "time"
+import (
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/testreport"
+	"codex-agent-team/internal/worktree"
+)
 
 // TaskStatus represents the current status of a task.
 type TaskStatus string
@@ -22,6 +28,7 @@ type Task struct {
 	Status       TaskStatus `json:"status"`
 	DependsOn    []string   `json:"dependsOn"`    // 依赖的任务 ID 列表
 	AgentID      string     `json:"agentId"`      // 分配的代理 ID
+	ThreadID     string     `json:"threadId,omitempty"` // 代理的 Codex 线程 ID
 	WorktreePath string     `json:"worktreePath"` // Git worktree 路径
 	BranchName   string     `json:"branchName"`   // Git 分支名
 
@@ -35,4 +42,45 @@ type Task struct {
 	CompletedAt  *time.Time `json:"completedAt,omitempty"`
 	Error        string     `json:"error,omitempty"`
 	Output       []string   `json:"output"` // 代理输出
+
+	Result *TaskResult `json:"result,omitempty"` // 结构化执行结果
+
+	Files     []string   `json:"files,omitempty"`     // 规划时预计涉及的文件
+	Outputs   []string   `json:"outputs,omitempty"`   // 声明的产物路径，供下游任务使用
+	Artifacts []Artifact `json:"artifacts,omitempty"` // 已产出的产物
+
+	Collect   []string        `json:"collect,omitempty"`   // 完成后收集到会话存储的文件 glob，如 "bin/*"
+	Collected []CollectedFile `json:"collected,omitempty"` // 已收集的文件，工作树清理后仍可下载
+
+	EstimatedTime string `json:"estimatedTime,omitempty"` // 规划时估计的耗时，如 "5-10 min"
+
+	Queue *QueueInfo `json:"queue,omitempty"` // 就绪但等待执行槽位时的排队情况
+
+	Comments []Comment `json:"comments,omitempty"` // 人工留下的评论，重试时提供给代理
+
+	FailedAttempt *Attempt `json:"failedAttempt,omitempty"` // 上一次失败的尝试，带上下文重试时提供给代理
+
+	Changes   *worktree.ChangeStat `json:"changes,omitempty"`   // 结果相对基准 commit 的改动统计
+	NoChanges bool                 `json:"noChanges,omitempty"` // 代理完成但没有可提交的改动，分支不参与合并
+
+	FailureKind   FailureKind `json:"failureKind,omitempty"`   // 失败分类
+	FailureDetail string      `json:"failureDetail,omitempty"` // codex stderr 或 turn 错误详情
+}
+
+// TaskResult is the structured outcome reported by a worker agent.
+// Computed is set when the agent did not report a usable result block
+// and the fields were derived from git instead.
+type TaskResult struct {
+	Summary      string   `json:"summary"`
+	FilesChanged []string `json:"filesChanged"`
+	TestsRun     []string `json:"testsRun"`
+	FollowUps    []string `json:"followUps"`
+	DiffStat     string   `json:"diffStat,omitempty"`
+	Computed     bool     `json:"computed,omitempty"`
+
+	Notes map[string]string `json:"notes,omitempty"` // decisions shared on the scratchpad
+
+	TestRuns []agent.TestRun `json:"testRuns,omitempty"` // run_tests calls, as recorded by the server
+
+	Tests *testreport.Report `json:"tests,omitempty"` // test and coverage reports found in the worktree
 }
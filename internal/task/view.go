@@ -0,0 +1,102 @@
+package task
+
+import "time"
+
+// TaskView is the API representation of a task. Its schema is stable and
+// carries everything the UI needs without cross-referencing other tasks.
+type TaskView struct {
+	ID           string           `json:"id"`
+	Title        string           `json:"title"`
+	Description  string           `json:"description"`
+	Status       TaskStatus       `json:"status"`
+	DependsOn    []TaskDependency `json:"dependsOn"`
+	BranchName   string           `json:"branchName"`
+	WorktreePath string           `json:"worktreePath"`
+	AgentID      string           `json:"agentId"`
+	AgentState   string           `json:"agentState"`
+	BaseCommit   string           `json:"baseCommit"`
+	ResultCommit string           `json:"resultCommit"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	StartedAt    *time.Time       `json:"startedAt"`
+	CompletedAt  *time.Time       `json:"completedAt"`
+	DurationMs   int64            `json:"durationMs"`
+	DiffStat     string           `json:"diffStat"`
+	Error        string           `json:"error"`
+	Result       *TaskResult      `json:"result"`
+
+	NoChanges bool `json:"noChanges,omitempty"` // completed without changing anything
+}
+
+// TaskDependency is a resolved dependency edge. Missing is set when the
+// referenced task does not exist in the DAG.
+type TaskDependency struct {
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Status  TaskStatus `json:"status"`
+	Missing bool       `json:"missing,omitempty"`
+}
+
+// Views returns all tasks as TaskViews in topological order. agentState,
+// if non-nil, resolves the live state of a task's agent.
+func (d *DAG) Views(agentState func(agentID string) string) []TaskView {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var ordered []*Task
+	if d.hasCycleLocked() {
+		ordered = make([]*Task, 0, len(d.tasks))
+		for _, t := range d.tasks {
+			ordered = append(ordered, t)
+		}
+		sortByID(ordered)
+	} else {
+		ordered = d.topologicalOrderLocked()
+	}
+
+	views := make([]TaskView, 0, len(ordered))
+	for _, t := range ordered {
+		v := TaskView{
+			ID:           t.ID,
+			Title:        t.Title,
+			Description:  t.Description,
+			Status:       t.Status,
+			DependsOn:    make([]TaskDependency, 0, len(t.DependsOn)),
+			BranchName:   t.BranchName,
+			WorktreePath: t.WorktreePath,
+			AgentID:      t.AgentID,
+			BaseCommit:   t.BaseCommit,
+			ResultCommit: t.ResultCommit,
+			CreatedAt:    t.CreatedAt,
+			StartedAt:    t.StartedAt,
+			CompletedAt:  t.CompletedAt,
+			Error:        t.Error,
+			Result:       t.Result,
+			NoChanges:    t.NoChanges,
+		}
+		for _, depID := range t.DependsOn {
+			dep := TaskDependency{ID: depID}
+			if depTask, ok := d.tasks[depID]; ok {
+				dep.Title = depTask.Title
+				dep.Status = depTask.Status
+			} else {
+				dep.Missing = true
+			}
+			v.DependsOn = append(v.DependsOn, dep)
+		}
+		if t.StartedAt != nil {
+			end := time.Now()
+			if t.CompletedAt != nil {
+				end = *t.CompletedAt
+			}
+			v.DurationMs = end.Sub(*t.StartedAt).Milliseconds()
+		}
+		if t.Result != nil {
+			v.DiffStat = t.Result.DiffStat
+		}
+		if agentState != nil && t.AgentID != "" {
+			v.AgentState = agentState(t.AgentID)
+		}
+		views = append(views, v)
+	}
+	return views
+}
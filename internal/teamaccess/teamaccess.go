@@ -0,0 +1,10 @@
+// Package teamaccess gives the server and the CLI the session manager
+// behind an agentteam.Team, which the public API keeps to itself so that
+// no internal types leak to embedders.
+package teamaccess
+
+import "codex-agent-team/internal/session"
+
+// Manager returns the session manager of an *agentteam.Team. It is set
+// by package agentteam.
+var Manager func(team any) *session.Manager
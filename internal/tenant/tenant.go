@@ -0,0 +1,184 @@
+// Package tenant isolates teams sharing one server. Each tenant has its own
+// API key, registered repositories, token budget and concurrent agent quota.
+//
+// Tenants are declared in a JSON file:
+//
+//	[
+//	  {"id": "team-a", "apiKey": "...", "repos": ["/srv/repos/a"],
+//	   "maxConcurrentAgents": 4, "tokenBudget": 5000000}
+//	]
+package tenant
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrBudgetExhausted is returned when a tenant has used its token budget.
+var ErrBudgetExhausted = errors.New("token budget exhausted")
+
+// Tenant is an organization with isolated repos, sessions and quotas.
+type Tenant struct {
+	ID                  string   `json:"id"`
+	Name                string   `json:"name,omitempty"`
+	APIKey              string   `json:"apiKey"`
+	Repos               []string `json:"repos"`                         // repositories the tenant may work on
+	MaxConcurrentAgents int      `json:"maxConcurrentAgents,omitempty"` // 0 for no limit
+	TokenBudget         int64    `json:"tokenBudget,omitempty"`         // 0 for no limit
+}
+
+// AllowsRepo reports whether path is one of the tenant's repositories or
+// lies inside one.
+func (t *Tenant) AllowsRepo(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, repo := range t.Repos {
+		if abs == repo || strings.HasPrefix(abs, repo+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds the configured tenants and tracks their resource usage.
+type Registry struct {
+	tenants   map[string]*Tenant
+	usagePath string
+
+	mu    sync.Mutex
+	used  map[string]int64         // tokens used by tenant ID
+	slots map[string]chan struct{} // concurrent agent slots by tenant ID
+}
+
+// Load reads the tenant file. Token usage is persisted next to it so
+// budgets survive restarts.
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tenants []*Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	r := &Registry{
+		tenants:   make(map[string]*Tenant),
+		usagePath: strings.TrimSuffix(path, filepath.Ext(path)) + ".usage.json",
+		used:      make(map[string]int64),
+		slots:     make(map[string]chan struct{}),
+	}
+	for _, t := range tenants {
+		if t.ID == "" || t.APIKey == "" {
+			return nil, fmt.Errorf("tenant %q: id and apiKey are required", t.ID)
+		}
+		if _, dup := r.tenants[t.ID]; dup {
+			return nil, fmt.Errorf("duplicate tenant %q", t.ID)
+		}
+		for i, repo := range t.Repos {
+			if t.Repos[i], err = filepath.Abs(repo); err != nil {
+				return nil, fmt.Errorf("tenant %q: repo %q: %w", t.ID, repo, err)
+			}
+		}
+		if t.MaxConcurrentAgents > 0 {
+			r.slots[t.ID] = make(chan struct{}, t.MaxConcurrentAgents)
+		}
+		r.tenants[t.ID] = t
+	}
+
+	if data, err := os.ReadFile(r.usagePath); err == nil {
+		_ = json.Unmarshal(data, &r.used)
+	}
+	return r, nil
+}
+
+// Get returns a tenant by ID.
+func (r *Registry) Get(id string) (*Tenant, bool) {
+	t, ok := r.tenants[id]
+	return t, ok
+}
+
+// Authenticate returns the tenant owning apiKey.
+func (r *Registry) Authenticate(apiKey string) (*Tenant, bool) {
+	if apiKey == "" {
+		return nil, false
+	}
+	for _, t := range r.tenants {
+		if subtle.ConstantTimeCompare([]byte(t.APIKey), []byte(apiKey)) == 1 {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// AddTokens records tokens used by a tenant's agents.
+func (r *Registry) AddTokens(tenantID string, n int64) {
+	if _, ok := r.tenants[tenantID]; !ok || n <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.used[tenantID] += n
+	if data, err := json.MarshalIndent(r.used, "", "  "); err == nil {
+		_ = os.WriteFile(r.usagePath, data, 0644)
+	}
+}
+
+// TokensUsed returns the tokens a tenant has used so far.
+func (r *Registry) TokensUsed(tenantID string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.used[tenantID]
+}
+
+// CheckBudget returns ErrBudgetExhausted once a tenant used its budget.
+func (r *Registry) CheckBudget(tenantID string) error {
+	t, ok := r.tenants[tenantID]
+	if !ok || t.TokenBudget <= 0 {
+		return nil
+	}
+	if r.TokensUsed(tenantID) >= t.TokenBudget {
+		return fmt.Errorf("%w: tenant %s used %d of %d tokens", ErrBudgetExhausted, tenantID, r.TokensUsed(tenantID), t.TokenBudget)
+	}
+	return nil
+}
+
+// Limiter returns the concurrent agent limiter shared by all of a
+// tenant's sessions, or nil if the tenant has no quota.
+func (r *Registry) Limiter(tenantID string) *Limiter {
+	slots, ok := r.slots[tenantID]
+	if !ok {
+		return nil
+	}
+	return &Limiter{slots: slots}
+}
+
+// Limiter bounds the number of agents a tenant runs at once.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// Acquire blocks until an agent slot is free or ctx is done.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire.
+func (l *Limiter) Release() {
+	<-l.slots
+}
@@ -0,0 +1,161 @@
+package testreport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// parseGoTestJSON counts the test results of go test -json output. Package
+// level events and subtests' parents are counted like any test.
+func parseGoTestJSON(r *Report, data []byte) bool {
+	var found bool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var ev struct {
+			Action  string
+			Package string
+			Test    string
+		}
+		if json.Unmarshal(line, &ev) != nil || ev.Test == "" {
+			continue
+		}
+		switch ev.Action {
+		case "pass", "fail", "skip":
+			found = true
+			r.addTest(ev.Package+"."+ev.Test, ev.Action == "pass", ev.Action == "skip")
+		}
+	}
+	return found
+}
+
+// junitCase is a <testcase> of a JUnit report.
+type junitCase struct {
+	Name      string    `xml:"name,attr"`
+	Classname string    `xml:"classname,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+	Skipped   *struct{} `xml:"skipped"`
+}
+
+// junitSuite is a <testsuite>, possibly nested.
+type junitSuite struct {
+	Cases  []junitCase  `xml:"testcase"`
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+// parseJUnit counts the test cases of a JUnit XML report, rooted at
+// either <testsuites> or a single <testsuite>.
+func parseJUnit(r *Report, data []byte) bool {
+	var root junitSuite
+	if xml.Unmarshal(data, &root) != nil {
+		return false
+	}
+	var found bool
+	var walk func(s junitSuite)
+	walk = func(s junitSuite) {
+		for _, c := range s.Cases {
+			found = true
+			name := c.Name
+			if c.Classname != "" {
+				name = c.Classname + "." + c.Name
+			}
+			r.addTest(name, c.Failure == nil && c.Error == nil, c.Skipped != nil)
+		}
+		for _, child := range s.Suites {
+			walk(child)
+		}
+	}
+	walk(root)
+	return found
+}
+
+// parseLcov reads line coverage from an lcov tracefile.
+func parseLcov(r *Report, data []byte) bool {
+	var found bool
+	var file string
+	var c Coverage
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file, c = strings.TrimPrefix(line, "SF:"), Coverage{}
+		case strings.HasPrefix(line, "DA:"):
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				continue
+			}
+			c.Total++
+			if hits, err := strconv.Atoi(fields[1]); err == nil && hits > 0 {
+				c.Covered++
+			}
+		case line == "end_of_record" && file != "":
+			r.addCoverage(file, c)
+			found = true
+			file = ""
+		}
+	}
+	return found
+}
+
+// parseGoCover reads statement coverage from a Go cover profile. Blocks
+// listed more than once, as with -coverpkg, count as covered if any
+// listing is.
+func parseGoCover(r *Report, data []byte) bool {
+	lines := strings.Split(string(data), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "mode:") {
+		return false
+	}
+
+	type block struct {
+		stmts   int
+		covered bool
+	}
+	blocks := make(map[string]map[string]*block)
+	for _, line := range lines[1:] {
+		// file.go:12.3,14.5 2 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		colon := strings.LastIndex(fields[0], ":")
+		if colon < 0 {
+			continue
+		}
+		stmts, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		file, pos := fields[0][:colon], fields[0][colon+1:]
+		if blocks[file] == nil {
+			blocks[file] = make(map[string]*block)
+		}
+		b := blocks[file][pos]
+		if b == nil {
+			b = &block{stmts: stmts}
+			blocks[file][pos] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+
+	for file, bs := range blocks {
+		var c Coverage
+		for _, b := range bs {
+			c.Total += b.stmts
+			if b.covered {
+				c.Covered += b.stmts
+			}
+		}
+		r.addCoverage(file, c)
+	}
+	return len(blocks) > 0
+}
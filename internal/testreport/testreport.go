@@ -0,0 +1,181 @@
+// Package testreport reads the test and coverage reports tasks leave in
+// their worktrees (go test -json, JUnit XML, lcov and Go cover profiles)
+// and aggregates them into one quality summary.
+package testreport
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxFailures bounds the failed test names kept in a report.
+const maxFailures = 20
+
+// maxReportBytes skips report files larger than this.
+const maxReportBytes = 64 << 20
+
+// Report summarizes test results and coverage.
+type Report struct {
+	Sources  []string `json:"sources"` // report files read, relative to the worktree
+	Tests    int      `json:"tests"`
+	Passed   int      `json:"passed"`
+	Failed   int      `json:"failed"`
+	Skipped  int      `json:"skipped"`
+	Failures []string `json:"failures,omitempty"` // names of failed tests, first ones only
+
+	// Coverage is counted in lines for lcov and statements for Go cover
+	// profiles, per source file.
+	Files    map[string]Coverage `json:"files,omitempty"`
+	Total    int                 `json:"total"`
+	Covered  int                 `json:"covered"`
+	Coverage float64             `json:"coverage"` // percent, 0 when nothing was measured
+}
+
+// Coverage is the coverage of one source file.
+type Coverage struct {
+	Total   int `json:"total"`
+	Covered int `json:"covered"`
+}
+
+// Empty reports whether r holds neither test results nor coverage.
+func (r *Report) Empty() bool {
+	return r == nil || r.Tests == 0 && len(r.Files) == 0
+}
+
+// addTest counts one test result.
+func (r *Report) addTest(name string, passed, skipped bool) {
+	r.Tests++
+	switch {
+	case skipped:
+		r.Skipped++
+	case passed:
+		r.Passed++
+	default:
+		r.Failed++
+		if len(r.Failures) < maxFailures {
+			r.Failures = append(r.Failures, name)
+		}
+	}
+}
+
+// addCoverage records the coverage of a source file, keeping the better
+// measurement when the file was seen before.
+func (r *Report) addCoverage(file string, c Coverage) {
+	if r.Files == nil {
+		r.Files = make(map[string]Coverage)
+	}
+	if old, ok := r.Files[file]; !ok || c.Covered*max(old.Total, 1) > old.Covered*max(c.Total, 1) {
+		r.Files[file] = c
+	}
+}
+
+// finish recomputes the coverage totals from the per-file coverage.
+func (r *Report) finish() {
+	r.Total, r.Covered, r.Coverage = 0, 0, 0
+	for _, c := range r.Files {
+		r.Total += c.Total
+		r.Covered += c.Covered
+	}
+	if r.Total > 0 {
+		r.Coverage = float64(r.Covered) * 100 / float64(r.Total)
+	}
+	sort.Strings(r.Sources)
+}
+
+// Merge aggregates reports of several tasks: test counts add up, and each
+// source file keeps its best coverage, since every task measured the whole
+// repository. nil reports are skipped; it returns nil when all are.
+func Merge(reports ...*Report) *Report {
+	var out *Report
+	for _, r := range reports {
+		if r == nil {
+			continue
+		}
+		if out == nil {
+			out = &Report{Sources: []string{}}
+		}
+		out.Sources = append(out.Sources, r.Sources...)
+		out.Tests += r.Tests
+		out.Passed += r.Passed
+		out.Failed += r.Failed
+		out.Skipped += r.Skipped
+		for _, name := range r.Failures {
+			if len(out.Failures) < maxFailures {
+				out.Failures = append(out.Failures, name)
+			}
+		}
+		for file, c := range r.Files {
+			out.addCoverage(file, c)
+		}
+	}
+	if out != nil {
+		out.finish()
+	}
+	return out
+}
+
+// Scan reads the reports found in dir, plus go test -json output captured
+// from test runs. It returns nil when there are none.
+func Scan(dir string, outputs []string) *Report {
+	r := &Report{Sources: []string{}}
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules", "vendor":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		parse := parserFor(d.Name())
+		if parse == nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxReportBytes {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		if parse(r, data) {
+			rel, _ := filepath.Rel(dir, p)
+			r.Sources = append(r.Sources, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	for _, out := range outputs {
+		if parseGoTestJSON(r, []byte(out)) {
+			r.Sources = append(r.Sources, "run_tests")
+		}
+	}
+
+	if r.Empty() {
+		return nil
+	}
+	r.finish()
+	return r
+}
+
+// parserFor returns the parser of a report file by its name, or nil if
+// the file is not a known report.
+func parserFor(name string) func(*Report, []byte) bool {
+	lower := strings.ToLower(name)
+	ext := filepath.Ext(lower)
+	switch {
+	case lower == "lcov.info" || ext == ".lcov":
+		return parseLcov
+	case lower == "coverage.out" || lower == "cover.out" || ext == ".coverprofile":
+		return parseGoCover
+	case ext == ".xml" && (strings.Contains(lower, "junit") || strings.HasPrefix(lower, "test-")):
+		return parseJUnit
+	case (ext == ".json" || ext == ".jsonl") && strings.Contains(lower, "test"):
+		return parseGoTestJSON
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SetCloneMode 让 Create 用 git clone --shared 创建的临时克隆代替 git worktree，
+// 用于 git worktree 不可用或行为异常的环境（旧版 git、部分网络文件系统）。
+// 分支语义不变：创建时在主仓库建立同名分支，克隆中每次产生提交后推送回主仓库，
+// 因此合并、diff 等基于主仓库分支的操作无需区分两种模式。
+func (m *Manager) SetCloneMode(on bool) {
+	m.clones = on
+}
+
+// isClone 判断 path 是否为克隆模式创建的临时克隆
+func (m *Manager) isClone(path string) bool {
+	if !m.clones || filepath.Clean(path) == filepath.Clean(m.repoPath) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(path, ".git"))
+	// worktree 中的 .git 是文件，克隆中是目录
+	return err == nil && info.IsDir()
+}
+
+// createClone 在主仓库中基于 commit 创建分支，再克隆到 path 并检出该分支
+func (m *Manager) createClone(ctx context.Context, branchName string, commit string, path string) error {
+	if output, err := m.git(ctx, m.repoPath, "branch", branchName, commit); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w: %s", branchName, err, output)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if output, err := m.git(ctx, m.repoPath, "clone", "--shared", "--quiet", "--branch", branchName, m.repoPath, path); err != nil {
+		_, _ = m.git(ctx, m.repoPath, "branch", "-D", branchName)
+		return fmt.Errorf("failed to clone repository: %w: %s", err, output)
+	}
+
+	m.copyIdentity(ctx, path)
+	return nil
+}
+
+// copyIdentity 把主仓库本地配置的提交身份复制到克隆中，克隆不继承主仓库的本地配置
+func (m *Manager) copyIdentity(ctx context.Context, path string) {
+	for _, key := range []string{"user.name", "user.email"} {
+		if value, err := m.git(ctx, m.repoPath, "config", "--get", key); err == nil && value != "" {
+			_, _ = m.git(ctx, path, "config", key, value)
+		}
+	}
+}
+
+// publish 将克隆当前分支推送回主仓库的同名分支；worktree 中的提交无需推送
+func (m *Manager) publish(ctx context.Context, path string) error {
+	if !m.isClone(path) {
+		return nil
+	}
+	branch, err := m.git(ctx, path, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("no branch is checked out in %s: %w", path, err)
+	}
+	if output, err := m.git(ctx, path, "push", "--force", "--quiet", "origin", "HEAD:refs/heads/"+branch); err != nil {
+		return fmt.Errorf("failed to publish branch %s: %w: %s", branch, err, output)
+	}
+	return nil
+}
+
+// fetchBranch 在合并前把主仓库中的分支同步到克隆，使分支名在克隆中可用。
+// 分支不存在（例如传入的是 commit）时忽略，由后续命令自行报错。
+func (m *Manager) fetchBranch(ctx context.Context, path string, branch string) {
+	if !m.isClone(path) {
+		return
+	}
+	if current, err := m.git(ctx, path, "symbolic-ref", "--short", "HEAD"); err == nil && current == branch {
+		return
+	}
+	_, _ = m.git(ctx, path, "fetch", "--quiet", "origin", "+refs/heads/"+branch+":refs/heads/"+branch)
+}
+
+// git 在 dir 中运行 git 命令，返回去掉首尾空白的合并输出
+func (m *Manager) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
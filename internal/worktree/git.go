@@ -0,0 +1,30 @@
+package worktree
+
+import "context"
+
+// Git 是合并器和任务执行器使用的 worktree 与分支操作。
+// *Manager 通过 git 命令实现；测试可以换成内存实现，例如 worktreetest.Git。
+type Git interface {
+	Create(ctx context.Context, branchName string, commitHash string) (*Worktree, error)
+	Remove(ctx context.Context, path string) error
+
+	ChangedFiles(ctx context.Context, worktreePath string) ([]string, error)
+	CommitChanges(ctx context.Context, worktreePath string, message string) (string, error)
+	HeadCommitAt(ctx context.Context, worktreePath string) (string, error)
+	Diff(ctx context.Context, worktreePath string, from string) (string, error)
+	DiffStat(ctx context.Context, worktreePath string, from string, to string) (string, error)
+	DiffFiles(ctx context.Context, worktreePath string, from string, to string) ([]string, error)
+	CheckoutFile(ctx context.Context, worktreePath string, commit string, path string) error
+
+	Merge(ctx context.Context, worktreePath string, branchName string) (string, error)
+	TryMerge(ctx context.Context, worktreePath string, branchName string) (string, []string, error)
+	OctopusMerge(ctx context.Context, repoPath string, branches []string) (string, error)
+	AbortMerge(ctx context.Context, worktreePath string) error
+	HasConflicts(ctx context.Context, worktreePath string) (bool, []string, error)
+	ConflictMarkers(worktreePath string, files []string) ([]string, error)
+	DiffCheck(ctx context.Context, worktreePath string, files []string) (string, error)
+
+	GetRepoPath() string
+}
+
+var _ Git = (*Manager)(nil)
@@ -0,0 +1,62 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsBare 判断仓库是否为裸仓库（没有主工作区，例如服务端镜像）
+func (m *Manager) IsBare(ctx context.Context) bool {
+	output, err := m.git(ctx, m.repoPath, "rev-parse", "--is-bare-repository")
+	return err == nil && output == "true"
+}
+
+// HeadCommitAt 返回指定 worktree（或仓库）当前 HEAD 的 commit SHA
+func (m *Manager) HeadCommitAt(ctx context.Context, worktreePath string) (string, error) {
+	output, err := m.git(ctx, worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("rev-parse HEAD: %w: %s", err, output)
+	}
+	return output, nil
+}
+
+// IntegrationWorktree 返回检出 branch 的集成 worktree，不存在时创建。
+// 仓库为裸仓库或主工作区处于分离 HEAD 时，合并和验证在这里进行，分支的更新直接写回仓库。
+// 已存在的集成 worktree 会被重置到分支的最新提交（例如镜像 fetch 之后）。
+func (m *Manager) IntegrationWorktree(ctx context.Context, branch string) (string, error) {
+	path := m.GetPath("integration-" + strings.ReplaceAll(branch, "/", "-"))
+
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		if m.isClone(path) {
+			if output, err := m.git(ctx, path, "fetch", "--quiet", "origin"); err != nil {
+				return "", fmt.Errorf("failed to update integration clone: %w: %s", err, output)
+			}
+			if output, err := m.git(ctx, path, "reset", "--hard", "--quiet", "origin/"+branch); err != nil {
+				return "", fmt.Errorf("failed to update integration clone: %w: %s", err, output)
+			}
+			return path, nil
+		}
+		if output, err := m.git(ctx, path, "reset", "--hard", "--quiet", "HEAD"); err != nil {
+			return "", fmt.Errorf("failed to update integration worktree: %w: %s", err, output)
+		}
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if m.clones {
+		if output, err := m.git(ctx, m.repoPath, "clone", "--shared", "--quiet", "--branch", branch, m.repoPath, path); err != nil {
+			return "", fmt.Errorf("failed to clone integration checkout: %w: %s", err, output)
+		}
+		m.copyIdentity(ctx, path)
+		return path, nil
+	}
+	if output, err := m.git(ctx, m.repoPath, "worktree", "add", path, branch); err != nil {
+		return "", fmt.Errorf("failed to create integration worktree: %w: %s", err, output)
+	}
+	return path, nil
+}
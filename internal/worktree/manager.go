@@ -3,7 +3,9 @@ package worktree
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -12,6 +14,8 @@ import (
 // Manager 管理 Git worktree
 type Manager struct {
 	repoPath string // 仓库根目录
+	root     string // worktree 根目录（可选，默认为 <repo>/.worktrees）
+	clones   bool   // 用临时克隆代替 git worktree，见 SetCloneMode
 }
 
 // Worktree 表示一个 Git worktree
@@ -28,6 +32,11 @@ func NewManager(repoPath string) *Manager {
 	}
 }
 
+// SetRoot 将 worktree 创建在 root/<仓库名> 下，而不是仓库内的 .worktrees
+func (m *Manager) SetRoot(root string) {
+	m.root = root
+}
+
 // Create 创建一个新的 worktree
 // branchName: 分支名称
 // commitHash: 基于哪个提交创建（可选，默认为当前 HEAD）
@@ -40,15 +49,24 @@ func (m *Manager) Create(ctx context.Context, branchName string, commitHash stri
 
 	// worktree 路径
 	worktreePath := m.GetPath(branchName)
+	if err := m.excludeWorktrees(ctx); err != nil {
+		return nil, err
+	}
 
-	// 构建 git worktree add -b <branch> <path> <commit> 命令
-	// 使用 -b 创建命名分支，以便后续任务可以通过分支名 merge
-	cmd := exec.CommandContext(ctx, "git", "worktree", "add", "-b", branchName, worktreePath, commit)
-	cmd.Dir = m.repoPath
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create worktree: %w: %s", err, string(output))
+	if m.clones {
+		if err := m.createClone(ctx, branchName, commit, worktreePath); err != nil {
+			return nil, err
+		}
+	} else {
+		// 构建 git worktree add -b <branch> <path> <commit> 命令
+		// 使用 -b 创建命名分支，以便后续任务可以通过分支名 merge
+		cmd := exec.CommandContext(ctx, "git", "worktree", "add", "-b", branchName, worktreePath, commit)
+		cmd.Dir = m.repoPath
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create worktree: %w: %s", err, string(output))
+		}
 	}
 
 	// Resolve actual commit SHA
@@ -66,6 +84,43 @@ func (m *Manager) Create(ctx context.Context, branchName string, commitHash stri
 	}, nil
 }
 
+// excludeWorktrees 将 .worktrees/ 加入仓库的 info/exclude，
+// 避免仓库内的 worktree 被 git status 显示为未跟踪文件
+func (m *Manager) excludeWorktrees(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-path", "info/exclude")
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("locate info/exclude: %w", err)
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.repoPath, path)
+	}
+
+	const pattern = "/.worktrees/"
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read info/exclude: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, pattern+"\n"...)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("write info/exclude: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write info/exclude: %w", err)
+	}
+	return nil
+}
+
 // List 列出所有 worktree
 func (m *Manager) List(ctx context.Context) ([]Worktree, error) {
 	cmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
@@ -82,6 +137,9 @@ func (m *Manager) List(ctx context.Context) ([]Worktree, error) {
 
 // Remove 删除指定的 worktree
 func (m *Manager) Remove(ctx context.Context, path string) error {
+	if m.isClone(path) {
+		return os.RemoveAll(path)
+	}
 	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", path)
 	cmd.Dir = m.repoPath
 
@@ -93,8 +151,40 @@ func (m *Manager) Remove(ctx context.Context, path string) error {
 	return nil
 }
 
+// ForceRemove 强制删除 worktree（即使其中有未提交的修改）
+func (m *Manager) ForceRemove(ctx context.Context, path string) error {
+	if m.isClone(path) {
+		return os.RemoveAll(path)
+	}
+	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", "--force", path)
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to force remove worktree %s: %w: %s", path, err, string(output))
+	}
+
+	return nil
+}
+
+// DeleteBranch 删除指定的本地分支
+func (m *Manager) DeleteBranch(ctx context.Context, branchName string) error {
+	cmd := exec.CommandContext(ctx, "git", "branch", "-D", branchName)
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w: %s", branchName, err, string(output))
+	}
+
+	return nil
+}
+
 // GetPath 获取 worktree 的完整路径
 func (m *Manager) GetPath(branchName string) string {
+	if m.root != "" {
+		return filepath.Join(m.root, filepath.Base(m.repoPath), branchName)
+	}
 	return filepath.Join(m.repoPath, ".worktrees", branchName)
 }
 
@@ -164,6 +254,7 @@ func parseWorktreeList(output string) ([]Worktree, error) {
 
 // Merge 将指定分支合并到当前 worktree
 func (m *Manager) Merge(ctx context.Context, worktreePath string, branchName string) (string, error) {
+	m.fetchBranch(ctx, worktreePath, branchName)
 	cmd := exec.CommandContext(ctx, "git", "merge", "--no-ff",
 		"-m", fmt.Sprintf("Merge %s", branchName), branchName)
 	cmd.Dir = worktreePath
@@ -183,10 +274,48 @@ func (m *Manager) Merge(ctx context.Context, worktreePath string, branchName str
 	if err != nil {
 		return "", fmt.Errorf("rev-parse HEAD after merge: %w", err)
 	}
+	if err := m.publish(ctx, worktreePath); err != nil {
+		return "", err
+	}
 
 	return strings.TrimSpace(string(commitSha)), nil
 }
 
+// ErrConflict 表示合并产生了冲突，合并状态被保留以便解决
+var ErrConflict = errors.New("merge conflict")
+
+// TryMerge 将指定分支合并到 worktree；与 Merge 不同，发生冲突时不会中止合并，
+// 而是返回 ErrConflict 和冲突文件列表，调用方负责解决冲突或调用 AbortMerge。
+// 其他错误仍会中止合并。
+func (m *Manager) TryMerge(ctx context.Context, worktreePath string, branchName string) (string, []string, error) {
+	m.fetchBranch(ctx, worktreePath, branchName)
+	cmd := exec.CommandContext(ctx, "git", "merge", "--no-ff",
+		"-m", fmt.Sprintf("Merge %s", branchName), branchName)
+	cmd.Dir = worktreePath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		hasConflicts, files, cerr := m.HasConflicts(ctx, worktreePath)
+		if cerr == nil && hasConflicts {
+			return "", files, fmt.Errorf("merge branch %s: %w", branchName, ErrConflict)
+		}
+		_ = m.AbortMerge(ctx, worktreePath)
+		return "", nil, fmt.Errorf("failed to merge branch %s: %w: %s", branchName, err, string(output))
+	}
+
+	headCmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	headCmd.Dir = worktreePath
+	commitSha, err := headCmd.Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("rev-parse HEAD after merge: %w", err)
+	}
+	if err := m.publish(ctx, worktreePath); err != nil {
+		return "", nil, err
+	}
+
+	return strings.TrimSpace(string(commitSha)), nil, nil
+}
+
 // CommitChanges 提交 worktree 中的所有修改
 func (m *Manager) CommitChanges(ctx context.Context, worktreePath string, message string) (string, error) {
 	// git add -A（改用 CombinedOutput 获取详细错误）
@@ -203,7 +332,8 @@ func (m *Manager) CommitChanges(ctx context.Context, worktreePath string, messag
 	output, err := commitCmd.CombinedOutput()
 	if err != nil {
 		if strings.Contains(string(output), "nothing to commit") {
-			return "", nil
+			// 代理自己提交的修改同样需要推送回主仓库
+			return "", m.publish(ctx, worktreePath)
 		}
 		return "", fmt.Errorf("git commit failed: %w: %s", err, string(output))
 	}
@@ -215,10 +345,137 @@ func (m *Manager) CommitChanges(ctx context.Context, worktreePath string, messag
 	if err != nil {
 		return "", fmt.Errorf("rev-parse HEAD after commit: %w", err)
 	}
+	if err := m.publish(ctx, worktreePath); err != nil {
+		return "", err
+	}
 
 	return strings.TrimSpace(string(commitSha)), nil
 }
 
+// ChangedFiles 返回 worktree 中所有未提交修改的文件（基于 git status）
+func (m *Manager) ChangedFiles(ctx context.Context, worktreePath string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "--untracked-files=all")
+	cmd.Dir = worktreePath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		// 格式: "XY path" 或 "R  old -> new"
+		path := line[3:]
+		if idx := strings.Index(path, " -> "); idx >= 0 {
+			path = path[idx+4:]
+		}
+		files = append(files, strings.Trim(path, `"`))
+	}
+
+	return files, nil
+}
+
+// DiffStat 返回两个 commit 之间的 git diff --shortstat 摘要
+func (m *Manager) DiffStat(ctx context.Context, worktreePath string, from string, to string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--shortstat", from, to)
+	cmd.Dir = worktreePath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git diff --shortstat failed: %w: %s", err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DiffFiles 返回两个 commit 之间变更的文件路径（相对仓库根目录）
+func (m *Manager) DiffFiles(ctx context.Context, worktreePath string, from string, to string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-only", "--no-renames", from, to)
+	cmd.Dir = worktreePath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --name-only: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// Diff 返回 worktree 当前内容（含未提交的修改）相对 from 的 git diff
+func (m *Manager) Diff(ctx context.Context, worktreePath string, from string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", from)
+	cmd.Dir = worktreePath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// HeadCommit 返回仓库当前 HEAD 的 commit SHA
+func (m *Manager) HeadCommit(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("rev-parse HEAD: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsAncestor 判断 commit 是否已包含在 target 的历史中（即合并它不会带来任何提交）
+func (m *Manager) IsAncestor(ctx context.Context, commit string, target string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", commit, target)
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("git merge-base --is-ancestor failed: %w: %s", err, string(output))
+}
+
+// Stash 暂存工作区中所有未提交的修改（包括未跟踪文件）
+func (m *Manager) Stash(ctx context.Context, worktreePath string, message string) error {
+	cmd := exec.CommandContext(ctx, "git", "stash", "push", "--include-untracked", "-m", message)
+	cmd.Dir = worktreePath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git stash failed: %w: %s", err, string(output))
+	}
+	return nil
+}
+
+// StashPop 恢复最近一次暂存的修改
+func (m *Manager) StashPop(ctx context.Context, worktreePath string) error {
+	cmd := exec.CommandContext(ctx, "git", "stash", "pop")
+	cmd.Dir = worktreePath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git stash pop failed: %w: %s", err, string(output))
+	}
+	return nil
+}
+
 // GetRepoPath returns the repository root path.
 func (m *Manager) GetRepoPath() string {
 	return m.repoPath
@@ -240,6 +497,132 @@ func (m *Manager) HasConflicts(ctx context.Context, worktreePath string) (bool,
 	return hasConflicts, conflictedFiles, nil
 }
 
+// ConflictMarkers 返回文件中残留的冲突标记位置（file:line），用于检查冲突是否真正解决
+func (m *Manager) ConflictMarkers(worktreePath string, files []string) ([]string, error) {
+	var found []string
+	for _, file := range files {
+		f, err := os.Open(filepath.Join(worktreePath, file))
+		if os.IsNotExist(err) {
+			// 解决冲突时删除的文件
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			if strings.HasPrefix(text, "<<<<<<<") || strings.HasPrefix(text, ">>>>>>>") {
+				found = append(found, fmt.Sprintf("%s:%d", file, line))
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+	}
+	return found, nil
+}
+
+// DiffCheck 对暂存区中指定文件的修改运行 git diff --check，返回发现的问题（空白错误、冲突标记），没有问题时为空
+func (m *Manager) DiffCheck(ctx context.Context, worktreePath string, files []string) (string, error) {
+	args := append([]string{"diff", "--cached", "--check", "--"}, files...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = worktreePath
+
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && len(output) > 0) {
+		return "", fmt.Errorf("git diff --check failed: %w: %s", err, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// OwnCommits 返回 branch 上不属于 exclude 中任何提交历史的非合并提交，按从旧到新排列
+func (m *Manager) OwnCommits(ctx context.Context, branch string, exclude []string) ([]string, error) {
+	args := []string{"rev-list", "--reverse", "--no-merges", branch}
+	for _, ex := range exclude {
+		args = append(args, "^"+ex)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list %s: %w", branch, err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// CherryPick 在 worktree 中依次应用提交，失败时中止 cherry-pick 并保持 worktree 干净
+func (m *Manager) CherryPick(ctx context.Context, worktreePath string, commits []string) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	args := append([]string{"cherry-pick", "--allow-empty"}, commits...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = worktreePath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		abort := exec.CommandContext(ctx, "git", "cherry-pick", "--abort")
+		abort.Dir = worktreePath
+		_ = abort.Run()
+		return fmt.Errorf("git cherry-pick failed: %w: %s", err, string(output))
+	}
+	return m.publish(ctx, worktreePath)
+}
+
+// Push 将本地分支强制推送到远程同名分支（用于会反复重建的分支）
+func (m *Manager) Push(ctx context.Context, remote string, branch string) error {
+	cmd := exec.CommandContext(ctx, "git", "push", "--force", remote, branch+":refs/heads/"+branch)
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git push %s %s failed: %w: %s", remote, branch, err, string(output))
+	}
+	return nil
+}
+
+// PushBranch 把分支推送到远程同名分支，不强制覆盖远程的提交
+func (m *Manager) PushBranch(ctx context.Context, remote string, branch string) error {
+	cmd := exec.CommandContext(ctx, "git", "push", remote, branch+":refs/heads/"+branch)
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git push %s %s failed: %w: %s", remote, branch, err, string(output))
+	}
+	return nil
+}
+
+// RemoteURL 返回远程仓库的 URL
+func (m *Manager) RemoteURL(ctx context.Context, remote string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", remote)
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git remote get-url %s: %w", remote, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CurrentBranch 返回仓库当前检出的分支名
+func (m *Manager) CurrentBranch(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "HEAD")
+	cmd.Dir = m.repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no branch is checked out: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // OctopusMerge 执行 Octopus merge（一次性合并多个分支）
 func (m *Manager) OctopusMerge(ctx context.Context, repoPath string, branches []string) (string, error) {
 	if len(branches) == 0 {
@@ -277,3 +660,63 @@ func (m *Manager) AbortMerge(ctx context.Context, worktreePath string) error {
 	}
 	return nil
 }
+
+// ResetHard 将 worktree 当前分支重置到指定 commit，丢弃之后的提交和未提交的修改
+func (m *Manager) ResetHard(ctx context.Context, worktreePath string, commit string) error {
+	cmd := exec.CommandContext(ctx, "git", "reset", "--hard", commit)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git reset --hard failed: %w: %s", err, string(output))
+	}
+	return m.publish(ctx, worktreePath)
+}
+
+// CheckoutFile 从指定 commit 中取出文件写入 worktree 并暂存，用于把上游任务的产物带入当前任务
+func (m *Manager) CheckoutFile(ctx context.Context, worktreePath string, commit string, path string) error {
+	cmd := exec.CommandContext(ctx, "git", "checkout", commit, "--", path)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("checkout %s from %s failed: %w: %s", path, commit, err, string(output))
+	}
+	return nil
+}
+
+// ChangeStat 是两个 commit 之间的改动统计
+type ChangeStat struct {
+	Summary    string `json:"summary"` // git diff --shortstat 的原始输出
+	Files      int    `json:"files"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+}
+
+// ParseShortStat 解析 git diff --shortstat 的输出，如
+// "3 files changed, 10 insertions(+), 2 deletions(-)"
+func ParseShortStat(stat string) ChangeStat {
+	c := ChangeStat{Summary: stat}
+	for _, part := range strings.Split(stat, ",") {
+		var n int
+		var word string
+		if _, err := fmt.Sscanf(strings.TrimSpace(part), "%d %s", &n, &word); err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(word, "file"):
+			c.Files = n
+		case strings.HasPrefix(word, "insertion"):
+			c.Insertions = n
+		case strings.HasPrefix(word, "deletion"):
+			c.Deletions = n
+		}
+	}
+	return c
+}
+
+// Add 累加另一份改动统计，Summary 按合计重新生成
+func (c *ChangeStat) Add(o ChangeStat) {
+	c.Files += o.Files
+	c.Insertions += o.Insertions
+	c.Deletions += o.Deletions
+	c.Summary = fmt.Sprintf("%d files changed, %d insertions(+), %d deletions(-)", c.Files, c.Insertions, c.Deletions)
+}
@@ -0,0 +1,40 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Report 在 repoPath 中运行只读的诊断 git 命令（状态、worktree、给定分支
+// 及其最近提交），返回每条命令及其合并输出，用于问题报告。命令失败时
+// 记录错误并继续。
+func Report(ctx context.Context, repoPath string, branches ...string) []byte {
+	cmds := [][]string{
+		{"status", "--short", "--branch"},
+		{"worktree", "list", "--porcelain"},
+		{"log", "--oneline", "--decorate", "-n", "20", "HEAD"},
+	}
+	if len(branches) > 0 {
+		cmds = append(cmds, append([]string{"branch", "--list", "-vv"}, branches...))
+		for _, b := range branches {
+			cmds = append(cmds, []string{"log", "--oneline", "-n", "10", "HEAD.." + b, "--"})
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, args := range cmds {
+		fmt.Fprintf(&buf, "$ git %s\n", strings.Join(args, " "))
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repoPath
+		out, err := cmd.CombinedOutput()
+		buf.Write(out)
+		if err != nil {
+			fmt.Fprintf(&buf, "(%v)\n", err)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,652 @@
+// Package worktreetest provides an in-memory worktree.Git for tests of
+// the task executor and the merger that should not need git.
+package worktreetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"codex-agent-team/internal/worktree"
+)
+
+// Git is an in-memory worktree.Git. Commits are whole-file snapshots with
+// deterministic IDs ("c1", "c2", ...), and merges are three-way merges at
+// file granularity: a file changed differently on both sides conflicts.
+// The repository itself is a worktree at its path, with its branch
+// checked out.
+//
+// Fake agents change worktrees with WriteFile and RemoveFile, and mark
+// conflicted files resolved with Resolve, standing in for git add.
+type Git struct {
+	mu       sync.Mutex
+	repoPath string
+	commits  map[string]*commit
+	branches map[string]string // commit by branch name
+	trees    map[string]*tree  // worktrees by path
+	next     int
+}
+
+type commit struct {
+	parents []string
+	message string
+	files   map[string]string
+}
+
+type tree struct {
+	branch  string
+	head    string
+	files   map[string]string
+	merging *pendingMerge
+}
+
+// pendingMerge is a merge stopped on conflicts.
+type pendingMerge struct {
+	theirs     []string
+	message    string
+	unresolved map[string]bool
+}
+
+var _ worktree.Git = (*Git)(nil)
+
+// New creates a repository at repoPath whose branch holds one commit
+// with files.
+func New(repoPath, branch string, files map[string]string) *Git {
+	g := &Git{
+		repoPath: repoPath,
+		commits:  make(map[string]*commit),
+		branches: make(map[string]string),
+		trees:    make(map[string]*tree),
+	}
+	head := g.commit(nil, "Initial commit", files)
+	g.branches[branch] = head
+	g.trees[repoPath] = &tree{branch: branch, head: head, files: copyFiles(files)}
+	return g
+}
+
+// commit records a snapshot and returns its ID. The caller holds g.mu
+// unless g is being created.
+func (g *Git) commit(parents []string, message string, files map[string]string) string {
+	g.next++
+	id := fmt.Sprintf("c%d", g.next)
+	g.commits[id] = &commit{parents: parents, message: message, files: copyFiles(files)}
+	return id
+}
+
+func copyFiles(files map[string]string) map[string]string {
+	out := make(map[string]string, len(files))
+	for name, content := range files {
+		out[name] = content
+	}
+	return out
+}
+
+// tree returns the worktree at path. The caller holds g.mu.
+func (g *Git) tree(path string) (*tree, error) {
+	t, ok := g.trees[filepath.Clean(path)]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a worktree", path)
+	}
+	return t, nil
+}
+
+// resolve returns the commit a branch name, commit ID or "HEAD" (of the
+// repository) names. The caller holds g.mu.
+func (g *Git) resolve(ref string) (string, error) {
+	if ref == "" || ref == "HEAD" {
+		return g.trees[g.repoPath].head, nil
+	}
+	if id, ok := g.branches[ref]; ok {
+		return id, nil
+	}
+	if _, ok := g.commits[ref]; ok {
+		return ref, nil
+	}
+	return "", fmt.Errorf("unknown revision %s", ref)
+}
+
+// advance moves a worktree and its branch to a new commit. The caller
+// holds g.mu.
+func (g *Git) advance(t *tree, id string) {
+	t.head = id
+	t.files = copyFiles(g.commits[id].files)
+	t.merging = nil
+	if t.branch != "" {
+		g.branches[t.branch] = id
+	}
+}
+
+// WriteFile changes a file in a worktree.
+func (g *Git) WriteFile(worktreePath, name, content string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return err
+	}
+	t.files[name] = content
+	return nil
+}
+
+// RemoveFile deletes a file from a worktree.
+func (g *Git) RemoveFile(worktreePath, name string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return err
+	}
+	delete(t.files, name)
+	return nil
+}
+
+// ReadFile returns a file of a worktree, including uncommitted changes.
+func (g *Git) ReadFile(worktreePath, name string) (string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return "", false
+	}
+	content, ok := t.files[name]
+	return content, ok
+}
+
+// Resolve writes the resolution of a conflicted file and marks it
+// resolved, like editing it and running git add.
+func (g *Git) Resolve(worktreePath, name, content string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return err
+	}
+	t.files[name] = content
+	if t.merging != nil {
+		delete(t.merging.unresolved, name)
+	}
+	return nil
+}
+
+// Branch returns the commit a branch points to.
+func (g *Git) Branch(name string) (string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id, ok := g.branches[name]
+	return id, ok
+}
+
+// Files returns the files of a commit, nil for an unknown commit.
+func (g *Git) Files(id string) map[string]string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.commits[id]
+	if !ok {
+		return nil
+	}
+	return copyFiles(c.files)
+}
+
+// Parents returns the parents of a commit.
+func (g *Git) Parents(id string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.commits[id]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), c.parents...)
+}
+
+// Create 在 branchName 新分支上创建 worktree，路径为 <repo>/.worktrees/<branch>
+func (g *Git) Create(ctx context.Context, branchName string, commitHash string) (*worktree.Worktree, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.branches[branchName]; ok {
+		return nil, fmt.Errorf("branch %s already exists", branchName)
+	}
+	base, err := g.resolve(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(g.repoPath, ".worktrees", branchName)
+	g.branches[branchName] = base
+	g.trees[path] = &tree{branch: branchName, head: base, files: copyFiles(g.commits[base].files)}
+	return &worktree.Worktree{Path: path, Branch: branchName, Commit: base}, nil
+}
+
+// Remove 删除 worktree，分支保留
+func (g *Git) Remove(ctx context.Context, path string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, err := g.tree(path); err != nil {
+		return err
+	}
+	delete(g.trees, filepath.Clean(path))
+	return nil
+}
+
+// DeleteBranch 删除分支，其 worktree 须已删除
+func (g *Git) DeleteBranch(ctx context.Context, branchName string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.branches[branchName]; !ok {
+		return fmt.Errorf("branch %s not found", branchName)
+	}
+	for path, t := range g.trees {
+		if t.branch == branchName {
+			return fmt.Errorf("branch %s is checked out at %s", branchName, path)
+		}
+	}
+	delete(g.branches, branchName)
+	return nil
+}
+
+// changed returns the files whose content differs between a and b.
+func changed(a, b map[string]string) []string {
+	var files []string
+	for name, content := range a {
+		if other, ok := b[name]; !ok || other != content {
+			files = append(files, name)
+		}
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ChangedFiles 返回 worktree 中未提交修改的文件
+func (g *Git) ChangedFiles(ctx context.Context, worktreePath string) ([]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	return changed(g.commits[t.head].files, t.files), nil
+}
+
+// CommitChanges 提交 worktree 中的所有修改；合并进行中时提交合并，没有修改时返回空字符串
+func (g *Git) CommitChanges(ctx context.Context, worktreePath string, message string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	if m := t.merging; m != nil {
+		if len(m.unresolved) > 0 {
+			return "", fmt.Errorf("committing is not possible because you have unmerged files")
+		}
+		id := g.commit(append([]string{t.head}, m.theirs...), message, t.files)
+		g.advance(t, id)
+		return id, nil
+	}
+	if len(changed(g.commits[t.head].files, t.files)) == 0 {
+		return "", nil
+	}
+	id := g.commit([]string{t.head}, message, t.files)
+	g.advance(t, id)
+	return id, nil
+}
+
+// HeadCommitAt 返回 worktree 的 HEAD
+func (g *Git) HeadCommitAt(ctx context.Context, worktreePath string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	return t.head, nil
+}
+
+// Diff 返回 worktree 当前内容相对 from 的差异，每个文件以整体替换的形式列出
+func (g *Git) Diff(ctx context.Context, worktreePath string, from string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	base, err := g.resolve(from)
+	if err != nil {
+		return "", err
+	}
+	old := g.commits[base].files
+	var b strings.Builder
+	for _, name := range changed(old, t.files) {
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+		for _, line := range lines(old[name]) {
+			fmt.Fprintf(&b, "-%s\n", line)
+		}
+		for _, line := range lines(t.files[name]) {
+			fmt.Fprintf(&b, "+%s\n", line)
+		}
+	}
+	return b.String(), nil
+}
+
+// DiffStat 返回两个 commit 之间 git diff --shortstat 格式的摘要，修改的文件按整体替换计数
+func (g *Git) DiffStat(ctx context.Context, worktreePath string, from string, to string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a, err := g.resolve(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := g.resolve(to)
+	if err != nil {
+		return "", err
+	}
+	old, cur := g.commits[a].files, g.commits[b].files
+	files := changed(old, cur)
+	if len(files) == 0 {
+		return "", nil
+	}
+	insertions, deletions := 0, 0
+	for _, name := range files {
+		deletions += len(lines(old[name]))
+		insertions += len(lines(cur[name]))
+	}
+	return fmt.Sprintf("%d files changed, %d insertions(+), %d deletions(-)", len(files), insertions, deletions), nil
+}
+
+func lines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// DiffFiles 返回两个 commit 之间变更的文件
+func (g *Git) DiffFiles(ctx context.Context, worktreePath string, from string, to string) ([]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a, err := g.resolve(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := g.resolve(to)
+	if err != nil {
+		return nil, err
+	}
+	return changed(g.commits[a].files, g.commits[b].files), nil
+}
+
+// GetRepoPath returns the repository root path.
+func (g *Git) GetRepoPath() string {
+	return g.repoPath
+}
+
+// CheckoutFile 从 commit 中取出文件写入 worktree
+func (g *Git) CheckoutFile(ctx context.Context, worktreePath string, commitHash string, path string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return err
+	}
+	id, err := g.resolve(commitHash)
+	if err != nil {
+		return err
+	}
+	content, ok := g.commits[id].files[path]
+	if !ok {
+		return fmt.Errorf("path %s does not exist in %s", path, commitHash)
+	}
+	t.files[path] = content
+	return nil
+}
+
+// ancestors returns id and every commit reachable from it. The caller
+// holds g.mu.
+func (g *Git) ancestors(id string) map[string]bool {
+	seen := map[string]bool{}
+	queue := []string{id}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		queue = append(queue, g.commits[c].parents...)
+	}
+	return seen
+}
+
+// mergeBase returns the newest common ancestor of a and b. The caller
+// holds g.mu.
+func (g *Git) mergeBase(a, b string) string {
+	inA := g.ancestors(a)
+	seen := map[string]bool{}
+	queue := []string{b}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if inA[c] {
+			return c
+		}
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		queue = append(queue, g.commits[c].parents...)
+	}
+	return ""
+}
+
+// mergeFiles merges theirs into ours relative to base. Conflicted files
+// get conflict markers.
+func mergeFiles(base, ours, theirs map[string]string, label string) (map[string]string, []string) {
+	out := copyFiles(ours)
+	var conflicts []string
+	for _, name := range changed(base, theirs) {
+		b, inBase := base[name]
+		o, inOurs := ours[name]
+		th, inTheirs := theirs[name]
+		switch {
+		case inOurs == inBase && o == b:
+			// Only their side changed the file
+			if inTheirs {
+				out[name] = th
+			} else {
+				delete(out, name)
+			}
+		case inOurs == inTheirs && o == th:
+			// Both sides made the same change
+		default:
+			out[name] = fmt.Sprintf("<<<<<<< HEAD\n%s=======\n%s>>>>>>> %s\n", o, th, label)
+			conflicts = append(conflicts, name)
+		}
+	}
+	return out, conflicts
+}
+
+// merge merges branches into a worktree. Without conflicts it commits;
+// with conflicts the merge is left in progress. The caller holds g.mu.
+func (g *Git) merge(t *tree, branches []string, message string) (string, []string, error) {
+	if files := changed(g.commits[t.head].files, t.files); len(files) > 0 {
+		return "", nil, fmt.Errorf("your local changes would be overwritten by merge: %s", strings.Join(files, ", "))
+	}
+	var theirs []string
+	files := t.files
+	var conflicts []string
+	for _, branch := range branches {
+		id, err := g.resolve(branch)
+		if err != nil {
+			return "", nil, err
+		}
+		if g.ancestors(t.head)[id] {
+			continue // already merged
+		}
+		var c []string
+		files, c = mergeFiles(g.commits[g.mergeBase(t.head, id)].files, files, g.commits[id].files, branch)
+		conflicts = append(conflicts, c...)
+		theirs = append(theirs, id)
+	}
+	if len(theirs) == 0 {
+		return t.head, nil, nil
+	}
+	if len(conflicts) > 0 {
+		t.files = files
+		unresolved := make(map[string]bool, len(conflicts))
+		for _, name := range conflicts {
+			unresolved[name] = true
+		}
+		t.merging = &pendingMerge{theirs: theirs, message: message, unresolved: unresolved}
+		return "", conflicts, nil
+	}
+	id := g.commit(append([]string{t.head}, theirs...), message, files)
+	g.advance(t, id)
+	return id, nil, nil
+}
+
+// Merge 将分支合并到 worktree，冲突时中止合并并返回错误
+func (g *Git) Merge(ctx context.Context, worktreePath string, branchName string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	id, conflicts, err := g.merge(t, []string{branchName}, "Merge "+branchName)
+	if err != nil {
+		return "", err
+	}
+	if len(conflicts) > 0 {
+		g.abort(t)
+		return "", fmt.Errorf("failed to merge branch %s: conflicts in %s", branchName, strings.Join(conflicts, ", "))
+	}
+	return id, nil
+}
+
+// TryMerge 将分支合并到 worktree，冲突时保留合并状态并返回 worktree.ErrConflict
+func (g *Git) TryMerge(ctx context.Context, worktreePath string, branchName string) (string, []string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return "", nil, err
+	}
+	id, conflicts, err := g.merge(t, []string{branchName}, "Merge "+branchName)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(conflicts) > 0 {
+		return "", conflicts, fmt.Errorf("merge branch %s: %w", branchName, worktree.ErrConflict)
+	}
+	return id, nil, nil
+}
+
+// OctopusMerge 一次性合并多个分支，任何冲突都会中止合并
+func (g *Git) OctopusMerge(ctx context.Context, repoPath string, branches []string) (string, error) {
+	if len(branches) == 0 {
+		return "", fmt.Errorf("no branches to merge")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(repoPath)
+	if err != nil {
+		return "", err
+	}
+	id, conflicts, err := g.merge(t, branches, "Merge branches "+strings.Join(branches, ", "))
+	if err != nil {
+		return "", fmt.Errorf("octopus merge failed: %w", err)
+	}
+	if len(conflicts) > 0 {
+		g.abort(t)
+		return "", fmt.Errorf("octopus merge failed: conflicts in %s", strings.Join(conflicts, ", "))
+	}
+	return id, nil
+}
+
+// abort restores a worktree to its HEAD. The caller holds g.mu.
+func (g *Git) abort(t *tree) {
+	t.files = copyFiles(g.commits[t.head].files)
+	t.merging = nil
+}
+
+// AbortMerge 中止进行中的合并
+func (g *Git) AbortMerge(ctx context.Context, worktreePath string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return err
+	}
+	if t.merging == nil {
+		return errors.New("abort merge failed: there is no merge to abort")
+	}
+	g.abort(t)
+	return nil
+}
+
+// HasConflicts 返回进行中的合并里尚未标记解决的文件
+func (g *Git) HasConflicts(ctx context.Context, worktreePath string) (bool, []string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return false, nil, err
+	}
+	if t.merging == nil {
+		return false, nil, nil
+	}
+	var files []string
+	for name := range t.merging.unresolved {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return len(files) > 0, files, nil
+}
+
+// markers returns file:line positions of conflict markers in files. The
+// caller holds g.mu.
+func markers(t *tree, files []string, both bool) []string {
+	var found []string
+	for _, name := range files {
+		for i, line := range lines(t.files[name]) {
+			if strings.HasPrefix(line, "<<<<<<<") || strings.HasPrefix(line, ">>>>>>>") ||
+				(both && strings.HasPrefix(line, "=======")) {
+				found = append(found, fmt.Sprintf("%s:%d", name, i+1))
+			}
+		}
+	}
+	return found
+}
+
+// ConflictMarkers 返回文件中残留的冲突标记位置（file:line）
+func (g *Git) ConflictMarkers(worktreePath string, files []string) ([]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	return markers(t, files, false), nil
+}
+
+// DiffCheck 报告文件中残留的冲突标记，格式与 git diff --check 相同
+func (g *Git) DiffCheck(ctx context.Context, worktreePath string, files []string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, err := g.tree(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	var out []string
+	for _, pos := range markers(t, files, true) {
+		out = append(out, pos+": leftover conflict marker")
+	}
+	return strings.Join(out, "\n"), nil
+}
@@ -0,0 +1,162 @@
+// Package agentteam embeds the multi-agent pipeline in other Go programs:
+// a user task is decomposed into a DAG of tasks, the tasks run in parallel
+// Codex agents in their own worktrees, and their branches are merged
+// back. It has no HTTP dependency; the server's API is one consumer.
+//
+//	team, err := agentteam.New("codex", "/path/to/repo", agentteam.Options{})
+//	defer team.Close()
+//	sess, err := team.Run(ctx, "add a health endpoint", agentteam.RunConfig{})
+package agentteam
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/events"
+	"codex-agent-team/internal/session"
+	"codex-agent-team/internal/task"
+	"codex-agent-team/internal/teamaccess"
+)
+
+// Types of the pipeline, re-exported for embedders.
+type (
+	Session         = session.Session         // one user task and its DAG
+	DecomposeResult = session.DecomposeResult // outcome of a decomposition
+	Estimate        = session.Estimate        // execution estimate of a plan
+	Task            = task.Task               // one node of a session's DAG
+	Event           = events.Event            // something that happened in a session
+	Sink            = events.Sink             // receives events
+	SinkFunc        = events.SinkFunc         // adapts a function to a Sink
+)
+
+// Stage is a phase of a run.
+type Stage string
+
+const (
+	StageDecompose Stage = "decompose"
+	StageExecute   Stage = "execute"
+	StageMerge     Stage = "merge"
+)
+
+// StageError is returned by Run when a stage fails. Its message is that
+// of Err, which already names the stage.
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Team runs sessions against one repository.
+type Team struct {
+	mgr      *session.Manager
+	repoPath string
+}
+
+func init() {
+	teamaccess.Manager = func(team any) *session.Manager {
+		return team.(*Team).mgr
+	}
+}
+
+// New creates a team whose agents run codexBin against the repository at
+// repoPath.
+func New(codexBin, repoPath string, opts Options) (*Team, error) {
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve repo path: %w", err)
+	}
+	mgr := session.NewManagerWithOptions(codexBin, abs, opts.sessionOptions())
+	for role, cfg := range opts.Containers {
+		mgr.SetContainerConfig(agent.Role(role), agent.ContainerConfig(cfg))
+	}
+	mgr.SetStallPolicy(agent.StallPolicy(opts.Stall))
+	return &Team{mgr: mgr, repoPath: abs}, nil
+}
+
+// RepoPath returns the absolute path of the team's repository.
+func (t *Team) RepoPath() string {
+	return t.repoPath
+}
+
+// Subscribe delivers the events of every session to sink until the
+// returned function is called.
+func (t *Team) Subscribe(name string, sink Sink) func() {
+	return t.mgr.Bus().Subscribe(name, sink)
+}
+
+// Check verifies that the codex binary can be started.
+func (t *Team) Check(ctx context.Context) error {
+	return t.mgr.CheckCodex(ctx, agent.Runtime{})
+}
+
+// Close cancels running sessions and stops the team's agents.
+func (t *Team) Close() {
+	t.mgr.Shutdown()
+}
+
+// NewSession creates a session for userTask without running it.
+func (t *Team) NewSession(ctx context.Context, userTask string) (*Session, error) {
+	return t.mgr.CreateWithPath(ctx, userTask, t.repoPath)
+}
+
+// RunConfig controls a Run. The zero value runs every stage and refuses
+// plans whose estimate needs confirmation.
+type RunConfig struct {
+	RunOptions
+
+	// Confirm executes plans whose estimate exceeds the execution gate.
+	Confirm bool
+
+	// OnStage is called as each stage starts.
+	OnStage func(sess *Session, stage Stage)
+
+	// OnDecomposed is called with the plan before it is executed.
+	OnDecomposed func(sess *Session, result *DecomposeResult)
+}
+
+// Run creates a session for userTask, decomposes it, executes its tasks
+// and merges their branches. The session is returned even when a stage
+// fails; the error is then a *StageError.
+func (t *Team) Run(ctx context.Context, userTask string, cfg RunConfig) (*Session, error) {
+	sess, err := t.NewSession(ctx, userTask)
+	if err != nil {
+		return nil, err
+	}
+	stage := func(s Stage) {
+		if cfg.OnStage != nil {
+			cfg.OnStage(sess, s)
+		}
+	}
+
+	stage(StageDecompose)
+	result, err := sess.Decompose(ctx, false)
+	if err != nil {
+		return sess, &StageError{Stage: StageDecompose, Err: err}
+	}
+	if cfg.OnDecomposed != nil {
+		cfg.OnDecomposed(sess, result)
+	}
+	if cfg.Confirm && sess.Estimate().NeedsConfirm {
+		sess.Confirm()
+	}
+
+	stage(StageExecute)
+	if err := sess.Execute(ctx, cfg.RunOptions.sessionRunOptions()); err != nil {
+		return sess, &StageError{Stage: StageExecute, Err: err}
+	}
+
+	stage(StageMerge)
+	if err := sess.Merge(ctx, cfg.RunOptions.sessionRunOptions()); err != nil {
+		return sess, &StageError{Stage: StageMerge, Err: err}
+	}
+	return sess, nil
+}
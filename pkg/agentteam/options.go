@@ -0,0 +1,206 @@
+package agentteam
+
+import (
+	"time"
+
+	"codex-agent-team/internal/agent"
+	"codex-agent-team/internal/session"
+)
+
+// Role is the part an agent plays in a run.
+type Role string
+
+const (
+	RoleOrchestrator Role = "orchestrator" // decomposes the user task
+	RoleWorker       Role = "worker"       // implements one task
+	RoleMerger       Role = "merger"       // resolves merge conflicts
+)
+
+// Options configures a Team. The zero value works.
+type Options struct {
+	DataDir      string // sessions, templates and caches (default: user cache dir)
+	WorktreeRoot string // where task worktrees are created (default: <repo>/.worktrees)
+	CloneMode    bool   // use temporary clones instead of git worktrees
+	MaxParallel  int    // tasks run at once per session (default: 3)
+
+	MaxAgents      int // tasks run at once across all sessions (0 for no limit)
+	UrgentPriority int // sessions at or above pause lower-priority scheduling (0 to disable)
+
+	RoleBinaries         map[Role]string // per-role codex binaries
+	RoleSandboxes        map[Role]string // per-role sandbox overrides
+	RoleEphemeral        map[Role]bool   // per-role ephemeral thread overrides
+	ThreadApprovalPolicy string          // codex approval policy of new threads
+
+	RoleMCPServers map[Role]map[string]MCPServer // per-role MCP servers by name
+	TestCommand    TestCommand                   // backs the run_tests tool of workers
+
+	VerifyMerge bool // run TestCommand on the merged result
+	FixAttempts int  // fixer agents tried when the merged result fails its tests
+
+	OctopusThreshold int  // branches above which merges try an octopus merge first (default: 3)
+	NoOctopus        bool // always merge branches one at a time
+	ResolveAttempts  int  // merger agent prompts per conflicted file (default: 2)
+
+	ResolveCheck TestCommand // validates resolved conflicts before the merge is committed
+
+	PRRemote string // remote PR stack branches are pushed to (default: origin)
+
+	TargetBranch string // branch merges go into (default: the checked-out branch)
+	PushRemote   string // remote the merged target branch is pushed to, empty to not push
+
+	CodeIndex  bool       // ground decompositions in a symbol index of the repo
+	PlanLimits PlanLimits // guardrails on decomposition size
+
+	Gate ExecutionGate // estimates that need confirmation before execution
+
+	CodexBinaries  map[string]string // codex binaries sessions may select, by name
+	ModelProviders []string          // model providers sessions may select
+
+	MaxInFlightCalls int             // outstanding app-server calls per agent (0 for no limit)
+	Heartbeat        HeartbeatPolicy // liveness pings of agent app-servers
+	Stall            StallPolicy     // stuck-turn detection
+	RPCTraceDir      string          // records agents' JSON-RPC frames here (empty to disable)
+
+	Containers map[Role]ContainerConfig // roles whose agents run in containers
+}
+
+// RunOptions controls how a run treats the primary checkout.
+type RunOptions struct {
+	// AutoStash allows a dirty checkout: Merge stashes local changes
+	// before merging and restores them afterwards.
+	AutoStash bool
+
+	// RetryWithContext shows the agents of failed tasks that are run again
+	// why the previous attempt failed, its transcript and its diff.
+	RetryWithContext bool
+}
+
+// MCPServer is an MCP server made available to agents, either a command
+// or a URL.
+type MCPServer struct {
+	Command           string            `json:"command,omitempty"`
+	Args              []string          `json:"args,omitempty"`
+	Env               map[string]string `json:"env,omitempty"`
+	URL               string            `json:"url,omitempty"`
+	BearerTokenEnvVar string            `json:"bearer_token_env_var,omitempty"`
+	EnabledTools      []string          `json:"enabled_tools,omitempty"` // all tools when empty
+	StartupTimeoutSec float64           `json:"startup_timeout_sec,omitempty"`
+	ToolTimeoutSec    float64           `json:"tool_timeout_sec,omitempty"`
+}
+
+// Validate checks that the server is either a command or a URL.
+func (s MCPServer) Validate() error {
+	return agent.MCPServer(s).Validate()
+}
+
+// TestCommand is a shell command run in a checkout to test it.
+type TestCommand struct {
+	Command string        // shell command run in the checkout
+	Timeout time.Duration // 0 for no limit
+}
+
+// PlanLimits are guardrails on the plans a decomposition may produce.
+type PlanLimits struct {
+	MaxTasks        int           // tasks in the plan
+	MaxFilesPerTask int           // files suggested for one task
+	MaxTaskTime     time.Duration // upper bound of a task's estimated time
+	SplitRounds     int           // follow-up turns asking to split oversize tasks (default 1)
+}
+
+// ExecutionGate holds the estimates above which a plan needs
+// confirmation before it is executed. Zero fields do not gate.
+type ExecutionGate struct {
+	MaxTasks        int     // tasks to run
+	MaxAgentMinutes float64 // summed task estimates
+	MaxTokens       int64   // rough token cost
+	TokensPerMinute int64   // token cost of one agent-minute (default 20000)
+}
+
+// HeartbeatPolicy configures liveness pings of agent app-servers.
+type HeartbeatPolicy struct {
+	Interval time.Duration // time between pings (0 to disable)
+	Timeout  time.Duration // wait for an answer (default: Interval)
+	Misses   int           // missed pings in a row before the agent is given up (default 2)
+}
+
+// StallPolicy configures detection of turns that stopped making progress.
+type StallPolicy struct {
+	Timeout time.Duration // max silence before a turn counts as stalled (0 to disable)
+	Retries int           // times a stalled turn is interrupted and restarted
+}
+
+// ContainerConfig runs the agents of a role inside containers. The
+// agent's working directory is mounted read-write, the repository's git
+// directory read-only, plus any extra Mounts.
+type ContainerConfig struct {
+	Runtime    string   // "docker" (default) or a compatible CLI such as "podman"
+	Image      string   // image providing the codex binary
+	BinaryPath string   // codex path inside the image
+	CPUs       string   // e.g. "2"
+	Memory     string   // e.g. "4g"
+	Network    string   // e.g. "none", "bridge"
+	Env        []string // variables set in the container: NAME passes the host's value, NAME=value sets one
+	Mounts     []string // extra host directories mounted at the same path, e.g. a codex home holding credentials
+}
+
+// sessionOptions maps o to the session manager's options.
+func (o Options) sessionOptions() session.Options {
+	opts := session.Options{
+		DataDir:              o.DataDir,
+		WorktreeRoot:         o.WorktreeRoot,
+		CloneMode:            o.CloneMode,
+		MaxParallel:          o.MaxParallel,
+		MaxAgents:            o.MaxAgents,
+		UrgentPriority:       o.UrgentPriority,
+		RoleBinaries:         roleMap(o.RoleBinaries),
+		RoleSandboxes:        roleMap(o.RoleSandboxes),
+		RoleEphemeral:        roleMap(o.RoleEphemeral),
+		ThreadApprovalPolicy: o.ThreadApprovalPolicy,
+		TestCommand:          agent.TestCommand(o.TestCommand),
+		VerifyMerge:          o.VerifyMerge,
+		FixAttempts:          o.FixAttempts,
+		OctopusThreshold:     o.OctopusThreshold,
+		NoOctopus:            o.NoOctopus,
+		ResolveAttempts:      o.ResolveAttempts,
+		ResolveCheck:         agent.TestCommand(o.ResolveCheck),
+		PRRemote:             o.PRRemote,
+		TargetBranch:         o.TargetBranch,
+		PushRemote:           o.PushRemote,
+		CodeIndex:            o.CodeIndex,
+		PlanLimits:           agent.PlanLimits(o.PlanLimits),
+		Gate:                 session.ExecutionGate(o.Gate),
+		CodexBinaries:        o.CodexBinaries,
+		ModelProviders:       o.ModelProviders,
+		MaxInFlightCalls:     o.MaxInFlightCalls,
+		Heartbeat:            agent.HeartbeatPolicy(o.Heartbeat),
+		RPCTraceDir:          o.RPCTraceDir,
+	}
+	if o.RoleMCPServers != nil {
+		opts.RoleMCPServers = make(map[agent.Role]map[string]agent.MCPServer, len(o.RoleMCPServers))
+		for role, named := range o.RoleMCPServers {
+			servers := make(map[string]agent.MCPServer, len(named))
+			for name, server := range named {
+				servers[name] = agent.MCPServer(server)
+			}
+			opts.RoleMCPServers[agent.Role(role)] = servers
+		}
+	}
+	return opts
+}
+
+// sessionRunOptions maps o to the session's run options.
+func (o RunOptions) sessionRunOptions() session.RunOptions {
+	return session.RunOptions(o)
+}
+
+// roleMap rekeys a per-role map by the agent package's roles.
+func roleMap[V any](m map[Role]V) map[agent.Role]V {
+	if m == nil {
+		return nil
+	}
+	out := make(map[agent.Role]V, len(m))
+	for role, v := range m {
+		out[agent.Role(role)] = v
+	}
+	return out
+}
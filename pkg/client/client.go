@@ -0,0 +1,418 @@
+// Package client is a Go client for the Codex Agent Team HTTP and
+// WebSocket API.
+//
+//	c := client.New("http://localhost:8080")
+//	sess, err := c.CreateSession(ctx, client.CreateSessionRequest{UserTask: "add a health endpoint"})
+//	job, err := c.Decompose(ctx, sess.ID)
+//	err = c.WaitJob(ctx, job)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// APIVersion is the version of the API the client speaks.
+const APIVersion = "v1"
+
+const (
+	apiPrefix = "/api/" + APIVersion
+	wsPrefix  = "/ws/" + APIVersion
+)
+
+// Client calls the API of one server. It is safe for concurrent use.
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey authenticates requests with a tenant API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithHTTPClient sends requests through h instead of a client without
+// timeouts; requests are bounded by their contexts either way.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.http = h }
+}
+
+// New creates a client for the server at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is a response with an error status.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Code       string          // machine-readable code, e.g. "SESSION_NOT_FOUND"
+	Message    string          // error message, or the response body if it is not JSON
+	Details    json.RawMessage // code-specific details, if any
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Error codes the API returns; see Error.Code.
+const (
+	CodeSessionNotFound      = "SESSION_NOT_FOUND"
+	CodeInvalidState         = "INVALID_STATE"
+	CodeDecomposeParseFailed = "DECOMPOSE_PARSE_FAILED"
+	CodeMergeConflict        = "MERGE_CONFLICT"
+	CodeConfirmationRequired = "CONFIRMATION_REQUIRED"
+	CodeDirtyRepo            = "DIRTY_REPO"
+	CodeRepoLocked           = "REPO_LOCKED"
+	CodeValidationFailed     = "VALIDATION_FAILED" // Details are {"fields": [{"field", "message"}]}
+	CodeUnsupportedVersion   = "UNSUPPORTED_API_VERSION"
+)
+
+// HasCode reports whether err is an API error, or a failed job returned
+// by WaitJob, with the given code.
+func HasCode(err error, code string) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == code
+	}
+	var jobErr *JobError
+	return errors.As(err, &jobErr) && jobErr.Job.ErrorCode == code
+}
+
+// Session is a session returned by the API.
+type Session struct {
+	ID          string     `json:"ID"`
+	UserTask    string     `json:"UserTask"`
+	RepoPath    string     `json:"RepoPath"`
+	TenantID    string     `json:"TenantID,omitempty"`
+	Status      string     `json:"Status"`
+	CreatedAt   time.Time  `json:"CreatedAt"`
+	StartedAt   *time.Time `json:"StartedAt,omitempty"`
+	CompletedAt *time.Time `json:"CompletedAt,omitempty"`
+	Priority    int        `json:"Priority"`
+	Repo        *Repo      `json:"Repo,omitempty"`
+
+	// ETag identifies the session's revision; see WaitSession.
+	ETag string `json:"-"`
+}
+
+// Repo describes the checkout a session operates on.
+type Repo struct {
+	Path   string `json:"path"`
+	Head   string `json:"head,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Bare   bool   `json:"bare,omitempty"`
+	Dirty  bool   `json:"dirty"`
+}
+
+// Task is a task of a session.
+type Task struct {
+	ID           string       `json:"id"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Status       string       `json:"status"`
+	DependsOn    []Dependency `json:"dependsOn"`
+	BranchName   string       `json:"branchName"`
+	WorktreePath string       `json:"worktreePath"`
+	AgentID      string       `json:"agentId"`
+	AgentState   string       `json:"agentState"`
+	BaseCommit   string       `json:"baseCommit"`
+	ResultCommit string       `json:"resultCommit"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	StartedAt    *time.Time   `json:"startedAt"`
+	CompletedAt  *time.Time   `json:"completedAt"`
+	DurationMs   int64        `json:"durationMs"`
+	DiffStat     string       `json:"diffStat"`
+	Error        string       `json:"error"`
+	NoChanges    bool         `json:"noChanges,omitempty"`
+}
+
+// Dependency is a dependency edge of a task.
+type Dependency struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// Job is a background operation of a session, such as a decomposition
+// or a merge.
+type Job struct {
+	ID         string     `json:"id"`
+	SessionID  string     `json:"sessionId"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"` // running, succeeded or failed
+	Error      string     `json:"error,omitempty"`
+	ErrorCode  string     `json:"errorCode,omitempty"` // code of Error, as for Error.Code
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Estimate is the execution estimate of a session.
+type Estimate struct {
+	Tasks        int      `json:"tasks"`
+	AgentMinutes float64  `json:"agentMinutes"`
+	Tokens       int64    `json:"tokens"`
+	Unestimated  int      `json:"unestimated"`
+	Exceeds      []string `json:"exceeds,omitempty"`
+	NeedsConfirm bool     `json:"needsConfirmation"`
+	Confirmed    bool     `json:"confirmed"`
+}
+
+// CreateSessionRequest describes a new session. Empty fields use the
+// server defaults.
+type CreateSessionRequest struct {
+	UserTask      string `json:"userTask"`
+	RepoPath      string `json:"repoPath,omitempty"`
+	Codex         string `json:"codex,omitempty"`         // registered codex binary
+	ModelProvider string `json:"modelProvider,omitempty"` // registered model provider
+	Priority      int    `json:"priority,omitempty"`
+}
+
+// do sends a request and decodes the JSON response into out (if non-nil).
+// It returns the response headers.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal body: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		apiErr := &Error{Method: method, Path: path, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var body struct {
+			Code    string          `json:"code"`
+			Message string          `json:"message"`
+			Details json.RawMessage `json:"details"`
+		}
+		if json.Unmarshal(data, &body) == nil && body.Code != "" {
+			apiErr.Code, apiErr.Message, apiErr.Details = body.Code, body.Message, body.Details
+		}
+		return nil, apiErr
+	}
+	if out != nil && resp.StatusCode != http.StatusNotModified {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// sessionPath returns the API path of a session, followed by elem.
+func sessionPath(id string, elem ...string) string {
+	path := apiPrefix + "/sessions/" + url.PathEscape(id)
+	for _, e := range elem {
+		path += "/" + url.PathEscape(e)
+	}
+	return path
+}
+
+// CreateSession creates a session.
+func (c *Client) CreateSession(ctx context.Context, req CreateSessionRequest) (*Session, error) {
+	var sess Session
+	if _, err := c.do(ctx, http.MethodPost, apiPrefix+"/sessions", nil, req, &sess); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// ListSessions returns the sessions visible to the client.
+func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	_, err := c.do(ctx, http.MethodGet, apiPrefix+"/sessions", nil, nil, &sessions)
+	return sessions, err
+}
+
+// GetSession returns a session.
+func (c *Client) GetSession(ctx context.Context, id string) (*Session, error) {
+	var sess Session
+	header, err := c.do(ctx, http.MethodGet, sessionPath(id), nil, nil, &sess)
+	if err != nil {
+		return nil, err
+	}
+	sess.ETag = header.Get("ETag")
+	return &sess, nil
+}
+
+// WaitSession waits up to wait (at most two minutes) for the session to
+// change from the revision etag names, then returns its current state. It
+// returns as soon as the session changes, or with the unchanged session
+// when the wait runs out.
+func (c *Client) WaitSession(ctx context.Context, id, etag string, wait time.Duration) (*Session, error) {
+	q := url.Values{"wait": {wait.String()}, "ifNoneMatch": {etag}}
+	var sess Session
+	header, err := c.do(ctx, http.MethodGet, sessionPath(id)+"?"+q.Encode(), nil, nil, &sess)
+	if err != nil {
+		return nil, err
+	}
+	sess.ETag = header.Get("ETag")
+	return &sess, nil
+}
+
+// DeleteSession stops a session and deletes it; deleteBranches also
+// deletes its task branches.
+func (c *Client) DeleteSession(ctx context.Context, id string, deleteBranches bool) error {
+	path := sessionPath(id)
+	if deleteBranches {
+		path += "?deleteBranches=true"
+	}
+	_, err := c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+// GetTasks returns a session's tasks in topological order.
+func (c *Client) GetTasks(ctx context.Context, id string) ([]Task, error) {
+	var tasks []Task
+	_, err := c.do(ctx, http.MethodGet, sessionPath(id, "tasks"), nil, nil, &tasks)
+	return tasks, err
+}
+
+// GetEstimate returns the execution estimate of a decomposed session.
+func (c *Client) GetEstimate(ctx context.Context, id string) (*Estimate, error) {
+	var est Estimate
+	if _, err := c.do(ctx, http.MethodGet, sessionPath(id, "estimate"), nil, nil, &est); err != nil {
+		return nil, err
+	}
+	return &est, nil
+}
+
+// GetJob returns a background job of a session.
+func (c *Client) GetJob(ctx context.Context, sessionID, jobID string) (*Job, error) {
+	var job Job
+	if _, err := c.do(ctx, http.MethodGet, sessionPath(sessionID, "jobs", jobID), nil, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// action posts to an action endpoint of a session. It returns the job the
+// action started, or nil when it finished synchronously.
+func (c *Client) action(ctx context.Context, id, path string) (*Job, error) {
+	var out struct {
+		Job *Job `json:"job"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, sessionPath(id)+"/"+path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Job, nil
+}
+
+// Decompose starts decomposing a session into tasks.
+func (c *Client) Decompose(ctx context.Context, id string) (*Job, error) {
+	return c.action(ctx, id, "decompose")
+}
+
+// Confirm approves a session's execution estimate.
+func (c *Client) Confirm(ctx context.Context, id string) error {
+	_, err := c.action(ctx, id, "confirm")
+	return err
+}
+
+// Execute starts executing a session's tasks.
+func (c *Client) Execute(ctx context.Context, id string) (*Job, error) {
+	return c.action(ctx, id, "execute")
+}
+
+// Resume runs the failed and pending tasks of a session again.
+func (c *Client) Resume(ctx context.Context, id string) (*Job, error) {
+	return c.action(ctx, id, "resume")
+}
+
+// Merge starts merging a session's completed task branches.
+func (c *Client) Merge(ctx context.Context, id string) (*Job, error) {
+	return c.action(ctx, id, "merge")
+}
+
+// RollbackMerge resets the target branch of a failed merge to where the
+// merge started.
+func (c *Client) RollbackMerge(ctx context.Context, id string) error {
+	_, err := c.action(ctx, id, "merge/rollback")
+	return err
+}
+
+// JobError is returned by WaitJob for a failed job.
+type JobError struct {
+	Job Job
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Job.Kind, e.Job.Error)
+}
+
+// jobPollInterval is how often WaitJob checks a job.
+const jobPollInterval = time.Second
+
+// WaitJob polls a job until it finishes and returns its error if it
+// failed. A nil job is already finished.
+func (c *Client) WaitJob(ctx context.Context, job *Job) error {
+	for job != nil {
+		switch job.Status {
+		case "running":
+		case "failed":
+			return &JobError{Job: *job}
+		default:
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jobPollInterval):
+		}
+		var err error
+		if job, err = c.GetJob(ctx, job.SessionID, job.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"nhooyr.io/websocket"
+)
+
+// Event is a live event of a session, e.g. "session.statusChanged" or
+// "task.completed".
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Tail streams a session's events to handle until the context is
+// cancelled, the server closes the stream or handle returns false.
+func (c *Client) Tail(ctx context.Context, id string, handle func(Event) bool) error {
+	wsURL := c.baseURL
+	switch {
+	case strings.HasPrefix(wsURL, "https://"):
+		wsURL = "wss://" + strings.TrimPrefix(wsURL, "https://")
+	case strings.HasPrefix(wsURL, "http://"):
+		wsURL = "ws://" + strings.TrimPrefix(wsURL, "http://")
+	}
+
+	opts := &websocket.DialOptions{HTTPClient: c.http}
+	if c.apiKey != "" {
+		opts.HTTPHeader = http.Header{"Authorization": {"Bearer " + c.apiKey}}
+	}
+	conn, _, err := websocket.Dial(ctx, wsURL+wsPrefix+"/sessions/"+url.PathEscape(id), opts)
+	if err != nil {
+		return fmt.Errorf("dial websocket: %w", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	conn.SetReadLimit(4 << 20)
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			if ctx.Err() != nil || websocket.CloseStatus(err) == websocket.StatusNormalClosure {
+				return nil
+			}
+			return err
+		}
+		var ev Event
+		if err := json.Unmarshal(data, &ev); err != nil {
+			continue
+		}
+		if !handle(ev) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,1850 @@
+// gRPC API of Codex Agent Team. It mirrors the session and task routes of
+// the HTTP API under /api/v1 and streams what the WebSocket sends.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: pkg/teampb/team.proto
+
+package teampb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Session struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserTask      string                 `protobuf:"bytes,2,opt,name=user_task,json=userTask,proto3" json:"user_task,omitempty"`
+	RepoPath      string                 `protobuf:"bytes,3,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	TenantId      string                 `protobuf:"bytes,4,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"` // created, decomposing, ready, running, merging, completed, failed or archived
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	Priority      int32                  `protobuf:"varint,9,opt,name=priority,proto3" json:"priority,omitempty"`
+	Codex         string                 `protobuf:"bytes,10,opt,name=codex,proto3" json:"codex,omitempty"`
+	ModelProvider string                 `protobuf:"bytes,11,opt,name=model_provider,json=modelProvider,proto3" json:"model_provider,omitempty"`
+	Repo          *Repo                  `protobuf:"bytes,12,opt,name=repo,proto3" json:"repo,omitempty"`
+	Etag          string                 `protobuf:"bytes,13,opt,name=etag,proto3" json:"etag,omitempty"` // changes whenever the session does, as the HTTP ETag
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Session) GetUserTask() string {
+	if x != nil {
+		return x.UserTask
+	}
+	return ""
+}
+
+func (x *Session) GetRepoPath() string {
+	if x != nil {
+		return x.RepoPath
+	}
+	return ""
+}
+
+func (x *Session) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *Session) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Session) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Session) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Session) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Session) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Session) GetCodex() string {
+	if x != nil {
+		return x.Codex
+	}
+	return ""
+}
+
+func (x *Session) GetModelProvider() string {
+	if x != nil {
+		return x.ModelProvider
+	}
+	return ""
+}
+
+func (x *Session) GetRepo() *Repo {
+	if x != nil {
+		return x.Repo
+	}
+	return nil
+}
+
+func (x *Session) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// Repo describes the checkout a session operates on.
+type Repo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Head          string                 `protobuf:"bytes,2,opt,name=head,proto3" json:"head,omitempty"`
+	Branch        string                 `protobuf:"bytes,3,opt,name=branch,proto3" json:"branch,omitempty"`
+	Bare          bool                   `protobuf:"varint,4,opt,name=bare,proto3" json:"bare,omitempty"`
+	Dirty         bool                   `protobuf:"varint,5,opt,name=dirty,proto3" json:"dirty,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Repo) Reset() {
+	*x = Repo{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Repo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Repo) ProtoMessage() {}
+
+func (x *Repo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Repo.ProtoReflect.Descriptor instead.
+func (*Repo) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Repo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Repo) GetHead() string {
+	if x != nil {
+		return x.Head
+	}
+	return ""
+}
+
+func (x *Repo) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *Repo) GetBare() bool {
+	if x != nil {
+		return x.Bare
+	}
+	return false
+}
+
+func (x *Repo) GetDirty() bool {
+	if x != nil {
+		return x.Dirty
+	}
+	return false
+}
+
+type Task struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // pending, ready, running, completed, failed or cancelled
+	DependsOn     []*Dependency          `protobuf:"bytes,5,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	BranchName    string                 `protobuf:"bytes,6,opt,name=branch_name,json=branchName,proto3" json:"branch_name,omitempty"`
+	WorktreePath  string                 `protobuf:"bytes,7,opt,name=worktree_path,json=worktreePath,proto3" json:"worktree_path,omitempty"`
+	AgentId       string                 `protobuf:"bytes,8,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	AgentState    string                 `protobuf:"bytes,9,opt,name=agent_state,json=agentState,proto3" json:"agent_state,omitempty"`
+	BaseCommit    string                 `protobuf:"bytes,10,opt,name=base_commit,json=baseCommit,proto3" json:"base_commit,omitempty"`
+	ResultCommit  string                 `protobuf:"bytes,11,opt,name=result_commit,json=resultCommit,proto3" json:"result_commit,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,15,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	DiffStat      string                 `protobuf:"bytes,16,opt,name=diff_stat,json=diffStat,proto3" json:"diff_stat,omitempty"`
+	Error         string                 `protobuf:"bytes,17,opt,name=error,proto3" json:"error,omitempty"`
+	NoChanges     bool                   `protobuf:"varint,18,opt,name=no_changes,json=noChanges,proto3" json:"no_changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Task) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Task) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Task) GetDependsOn() []*Dependency {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *Task) GetBranchName() string {
+	if x != nil {
+		return x.BranchName
+	}
+	return ""
+}
+
+func (x *Task) GetWorktreePath() string {
+	if x != nil {
+		return x.WorktreePath
+	}
+	return ""
+}
+
+func (x *Task) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Task) GetAgentState() string {
+	if x != nil {
+		return x.AgentState
+	}
+	return ""
+}
+
+func (x *Task) GetBaseCommit() string {
+	if x != nil {
+		return x.BaseCommit
+	}
+	return ""
+}
+
+func (x *Task) GetResultCommit() string {
+	if x != nil {
+		return x.ResultCommit
+	}
+	return ""
+}
+
+func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Task) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Task) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *Task) GetDiffStat() string {
+	if x != nil {
+		return x.DiffStat
+	}
+	return ""
+}
+
+func (x *Task) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Task) GetNoChanges() bool {
+	if x != nil {
+		return x.NoChanges
+	}
+	return false
+}
+
+// Dependency is a dependency edge of a task.
+type Dependency struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Missing       bool                   `protobuf:"varint,4,opt,name=missing,proto3" json:"missing,omitempty"` // the task depends on an ID not in the DAG
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dependency) Reset() {
+	*x = Dependency{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dependency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dependency) ProtoMessage() {}
+
+func (x *Dependency) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dependency.ProtoReflect.Descriptor instead.
+func (*Dependency) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Dependency) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Dependency) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Dependency) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Dependency) GetMissing() bool {
+	if x != nil {
+		return x.Missing
+	}
+	return false
+}
+
+// Job is a background operation of a session.
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // running, succeeded or failed
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorCode     string                 `protobuf:"bytes,8,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"` // code of error, as in the HTTP API
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Job) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *Job) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+// Estimate is the execution estimate of a session.
+type Estimate struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Tasks             int32                  `protobuf:"varint,1,opt,name=tasks,proto3" json:"tasks,omitempty"`
+	AgentMinutes      float64                `protobuf:"fixed64,2,opt,name=agent_minutes,json=agentMinutes,proto3" json:"agent_minutes,omitempty"`
+	Tokens            int64                  `protobuf:"varint,3,opt,name=tokens,proto3" json:"tokens,omitempty"`
+	Unestimated       int32                  `protobuf:"varint,4,opt,name=unestimated,proto3" json:"unestimated,omitempty"`
+	Exceeds           []string               `protobuf:"bytes,5,rep,name=exceeds,proto3" json:"exceeds,omitempty"`
+	NeedsConfirmation bool                   `protobuf:"varint,6,opt,name=needs_confirmation,json=needsConfirmation,proto3" json:"needs_confirmation,omitempty"`
+	Confirmed         bool                   `protobuf:"varint,7,opt,name=confirmed,proto3" json:"confirmed,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Estimate) Reset() {
+	*x = Estimate{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Estimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Estimate) ProtoMessage() {}
+
+func (x *Estimate) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Estimate.ProtoReflect.Descriptor instead.
+func (*Estimate) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Estimate) GetTasks() int32 {
+	if x != nil {
+		return x.Tasks
+	}
+	return 0
+}
+
+func (x *Estimate) GetAgentMinutes() float64 {
+	if x != nil {
+		return x.AgentMinutes
+	}
+	return 0
+}
+
+func (x *Estimate) GetTokens() int64 {
+	if x != nil {
+		return x.Tokens
+	}
+	return 0
+}
+
+func (x *Estimate) GetUnestimated() int32 {
+	if x != nil {
+		return x.Unestimated
+	}
+	return 0
+}
+
+func (x *Estimate) GetExceeds() []string {
+	if x != nil {
+		return x.Exceeds
+	}
+	return nil
+}
+
+func (x *Estimate) GetNeedsConfirmation() bool {
+	if x != nil {
+		return x.NeedsConfirmation
+	}
+	return false
+}
+
+func (x *Estimate) GetConfirmed() bool {
+	if x != nil {
+		return x.Confirmed
+	}
+	return false
+}
+
+type CreateSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserTask      string                 `protobuf:"bytes,1,opt,name=user_task,json=userTask,proto3" json:"user_task,omitempty"`
+	RepoPath      string                 `protobuf:"bytes,2,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`                // default repository when empty
+	Codex         string                 `protobuf:"bytes,3,opt,name=codex,proto3" json:"codex,omitempty"`                                      // registered codex binary
+	ModelProvider string                 `protobuf:"bytes,4,opt,name=model_provider,json=modelProvider,proto3" json:"model_provider,omitempty"` // registered model provider
+	Priority      int32                  `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSessionRequest) Reset() {
+	*x = CreateSessionRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSessionRequest) ProtoMessage() {}
+
+func (x *CreateSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateSessionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateSessionRequest) GetUserTask() string {
+	if x != nil {
+		return x.UserTask
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetRepoPath() string {
+	if x != nil {
+		return x.RepoPath
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetCodex() string {
+	if x != nil {
+		return x.Codex
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetModelProvider() string {
+	if x != nil {
+		return x.ModelProvider
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+type GetSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionRequest) Reset() {
+	*x = GetSessionRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionRequest) ProtoMessage() {}
+
+func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetSessionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{8}
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type DeleteSessionRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeleteBranches bool                   `protobuf:"varint,2,opt,name=delete_branches,json=deleteBranches,proto3" json:"delete_branches,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteSessionRequest) Reset() {
+	*x = DeleteSessionRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSessionRequest) ProtoMessage() {}
+
+func (x *DeleteSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSessionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSessionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteSessionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteSessionRequest) GetDeleteBranches() bool {
+	if x != nil {
+		return x.DeleteBranches
+	}
+	return false
+}
+
+type DeleteSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSessionResponse) Reset() {
+	*x = DeleteSessionResponse{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSessionResponse) ProtoMessage() {}
+
+func (x *DeleteSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSessionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSessionResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{11}
+}
+
+type DecomposeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Force         bool                   `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"` // bypass the decomposition cache
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecomposeRequest) Reset() {
+	*x = DecomposeRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecomposeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecomposeRequest) ProtoMessage() {}
+
+func (x *DecomposeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecomposeRequest.ProtoReflect.Descriptor instead.
+func (*DecomposeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DecomposeRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *DecomposeRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type ConfirmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmRequest) Reset() {
+	*x = ConfirmRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmRequest) ProtoMessage() {}
+
+func (x *ConfirmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ConfirmRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type RunRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SessionId        string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	AutoStash        bool                   `protobuf:"varint,2,opt,name=auto_stash,json=autoStash,proto3" json:"auto_stash,omitempty"`                        // stash local changes around the merge
+	RetryWithContext bool                   `protobuf:"varint,3,opt,name=retry_with_context,json=retryWithContext,proto3" json:"retry_with_context,omitempty"` // Resume only: show retried tasks their failed attempt
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RunRequest) Reset() {
+	*x = RunRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunRequest) ProtoMessage() {}
+
+func (x *RunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunRequest.ProtoReflect.Descriptor instead.
+func (*RunRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RunRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *RunRequest) GetAutoStash() bool {
+	if x != nil {
+		return x.AutoStash
+	}
+	return false
+}
+
+func (x *RunRequest) GetRetryWithContext() bool {
+	if x != nil {
+		return x.RetryWithContext
+	}
+	return false
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetJobRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type ListTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListTasksRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type ListTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type InterruptTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TaskId        string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Instruction   string                 `protobuf:"bytes,3,opt,name=instruction,proto3" json:"instruction,omitempty"` // optional follow-up for the agent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InterruptTaskRequest) Reset() {
+	*x = InterruptTaskRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InterruptTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterruptTaskRequest) ProtoMessage() {}
+
+func (x *InterruptTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterruptTaskRequest.ProtoReflect.Descriptor instead.
+func (*InterruptTaskRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *InterruptTaskRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *InterruptTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *InterruptTaskRequest) GetInstruction() string {
+	if x != nil {
+		return x.Instruction
+	}
+	return ""
+}
+
+type InterruptTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InterruptTaskResponse) Reset() {
+	*x = InterruptTaskResponse{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InterruptTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterruptTaskResponse) ProtoMessage() {}
+
+func (x *InterruptTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterruptTaskResponse.ProtoReflect.Descriptor instead.
+func (*InterruptTaskResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{19}
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WatchEventsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+// Event is a live event of a session, e.g. "session.statusChanged" or
+// "task.completed".
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"` // JSON, as in the data field of WebSocket events
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type StreamTranscriptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TaskId        string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Follow        bool                   `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"` // keep streaming until the task completes or fails
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTranscriptRequest) Reset() {
+	*x = StreamTranscriptRequest{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTranscriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTranscriptRequest) ProtoMessage() {}
+
+func (x *StreamTranscriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTranscriptRequest.ProtoReflect.Descriptor instead.
+func (*StreamTranscriptRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StreamTranscriptRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StreamTranscriptRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *StreamTranscriptRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+type TranscriptChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// replace is set when the transcript started over, e.g. for a retried
+	// task: text replaces everything received before.
+	Replace       bool `protobuf:"varint,2,opt,name=replace,proto3" json:"replace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranscriptChunk) Reset() {
+	*x = TranscriptChunk{}
+	mi := &file_pkg_teampb_team_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscriptChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscriptChunk) ProtoMessage() {}
+
+func (x *TranscriptChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_teampb_team_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscriptChunk.ProtoReflect.Descriptor instead.
+func (*TranscriptChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_teampb_team_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TranscriptChunk) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TranscriptChunk) GetReplace() bool {
+	if x != nil {
+		return x.Replace
+	}
+	return false
+}
+
+var File_pkg_teampb_team_proto protoreflect.FileDescriptor
+
+const file_pkg_teampb_team_proto_rawDesc = "" +
+	"\n" +
+	"\x15pkg/teampb/team.proto\x12\fcodexteam.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd2\x03\n" +
+	"\aSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tuser_task\x18\x02 \x01(\tR\buserTask\x12\x1b\n" +
+	"\trepo_path\x18\x03 \x01(\tR\brepoPath\x12\x1b\n" +
+	"\ttenant_id\x18\x04 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x1a\n" +
+	"\bpriority\x18\t \x01(\x05R\bpriority\x12\x14\n" +
+	"\x05codex\x18\n" +
+	" \x01(\tR\x05codex\x12%\n" +
+	"\x0emodel_provider\x18\v \x01(\tR\rmodelProvider\x12&\n" +
+	"\x04repo\x18\f \x01(\v2\x12.codexteam.v1.RepoR\x04repo\x12\x12\n" +
+	"\x04etag\x18\r \x01(\tR\x04etag\"p\n" +
+	"\x04Repo\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04head\x18\x02 \x01(\tR\x04head\x12\x16\n" +
+	"\x06branch\x18\x03 \x01(\tR\x06branch\x12\x12\n" +
+	"\x04bare\x18\x04 \x01(\bR\x04bare\x12\x14\n" +
+	"\x05dirty\x18\x05 \x01(\bR\x05dirty\"\x8f\x05\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x127\n" +
+	"\n" +
+	"depends_on\x18\x05 \x03(\v2\x18.codexteam.v1.DependencyR\tdependsOn\x12\x1f\n" +
+	"\vbranch_name\x18\x06 \x01(\tR\n" +
+	"branchName\x12#\n" +
+	"\rworktree_path\x18\a \x01(\tR\fworktreePath\x12\x19\n" +
+	"\bagent_id\x18\b \x01(\tR\aagentId\x12\x1f\n" +
+	"\vagent_state\x18\t \x01(\tR\n" +
+	"agentState\x12\x1f\n" +
+	"\vbase_commit\x18\n" +
+	" \x01(\tR\n" +
+	"baseCommit\x12#\n" +
+	"\rresult_commit\x18\v \x01(\tR\fresultCommit\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x1f\n" +
+	"\vduration_ms\x18\x0f \x01(\x03R\n" +
+	"durationMs\x12\x1b\n" +
+	"\tdiff_stat\x18\x10 \x01(\tR\bdiffStat\x12\x14\n" +
+	"\x05error\x18\x11 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"no_changes\x18\x12 \x01(\bR\tnoChanges\"d\n" +
+	"\n" +
+	"Dependency\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x18\n" +
+	"\amissing\x18\x04 \x01(\bR\amissing\"\x8d\x02\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\b \x01(\tR\terrorCode\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"\xe6\x01\n" +
+	"\bEstimate\x12\x14\n" +
+	"\x05tasks\x18\x01 \x01(\x05R\x05tasks\x12#\n" +
+	"\ragent_minutes\x18\x02 \x01(\x01R\fagentMinutes\x12\x16\n" +
+	"\x06tokens\x18\x03 \x01(\x03R\x06tokens\x12 \n" +
+	"\vunestimated\x18\x04 \x01(\x05R\vunestimated\x12\x18\n" +
+	"\aexceeds\x18\x05 \x03(\tR\aexceeds\x12-\n" +
+	"\x12needs_confirmation\x18\x06 \x01(\bR\x11needsConfirmation\x12\x1c\n" +
+	"\tconfirmed\x18\a \x01(\bR\tconfirmed\"\xa9\x01\n" +
+	"\x14CreateSessionRequest\x12\x1b\n" +
+	"\tuser_task\x18\x01 \x01(\tR\buserTask\x12\x1b\n" +
+	"\trepo_path\x18\x02 \x01(\tR\brepoPath\x12\x14\n" +
+	"\x05codex\x18\x03 \x01(\tR\x05codex\x12%\n" +
+	"\x0emodel_provider\x18\x04 \x01(\tR\rmodelProvider\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\x05R\bpriority\"#\n" +
+	"\x11GetSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13ListSessionsRequest\"I\n" +
+	"\x14ListSessionsResponse\x121\n" +
+	"\bsessions\x18\x01 \x03(\v2\x15.codexteam.v1.SessionR\bsessions\"O\n" +
+	"\x14DeleteSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0fdelete_branches\x18\x02 \x01(\bR\x0edeleteBranches\"\x17\n" +
+	"\x15DeleteSessionResponse\"G\n" +
+	"\x10DecomposeRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"/\n" +
+	"\x0eConfirmRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"x\n" +
+	"\n" +
+	"RunRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
+	"\n" +
+	"auto_stash\x18\x02 \x01(\bR\tautoStash\x12,\n" +
+	"\x12retry_with_context\x18\x03 \x01(\bR\x10retryWithContext\"E\n" +
+	"\rGetJobRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"1\n" +
+	"\x10ListTasksRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"=\n" +
+	"\x11ListTasksResponse\x12(\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x12.codexteam.v1.TaskR\x05tasks\"p\n" +
+	"\x14InterruptTaskRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12 \n" +
+	"\vinstruction\x18\x03 \x01(\tR\vinstruction\"\x17\n" +
+	"\x15InterruptTaskResponse\"3\n" +
+	"\x12WatchEventsRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"/\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"i\n" +
+	"\x17StreamTranscriptRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06follow\x18\x03 \x01(\bR\x06follow\"?\n" +
+	"\x0fTranscriptChunk\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x18\n" +
+	"\areplace\x18\x02 \x01(\bR\areplace2\xf5\a\n" +
+	"\x04Team\x12J\n" +
+	"\rCreateSession\x12\".codexteam.v1.CreateSessionRequest\x1a\x15.codexteam.v1.Session\x12D\n" +
+	"\n" +
+	"GetSession\x12\x1f.codexteam.v1.GetSessionRequest\x1a\x15.codexteam.v1.Session\x12U\n" +
+	"\fListSessions\x12!.codexteam.v1.ListSessionsRequest\x1a\".codexteam.v1.ListSessionsResponse\x12X\n" +
+	"\rDeleteSession\x12\".codexteam.v1.DeleteSessionRequest\x1a#.codexteam.v1.DeleteSessionResponse\x12>\n" +
+	"\tDecompose\x12\x1e.codexteam.v1.DecomposeRequest\x1a\x11.codexteam.v1.Job\x12?\n" +
+	"\aConfirm\x12\x1c.codexteam.v1.ConfirmRequest\x1a\x16.codexteam.v1.Estimate\x126\n" +
+	"\aExecute\x12\x18.codexteam.v1.RunRequest\x1a\x11.codexteam.v1.Job\x125\n" +
+	"\x06Resume\x12\x18.codexteam.v1.RunRequest\x1a\x11.codexteam.v1.Job\x124\n" +
+	"\x05Merge\x12\x18.codexteam.v1.RunRequest\x1a\x11.codexteam.v1.Job\x128\n" +
+	"\x06GetJob\x12\x1b.codexteam.v1.GetJobRequest\x1a\x11.codexteam.v1.Job\x12L\n" +
+	"\tListTasks\x12\x1e.codexteam.v1.ListTasksRequest\x1a\x1f.codexteam.v1.ListTasksResponse\x12X\n" +
+	"\rInterruptTask\x12\".codexteam.v1.InterruptTaskRequest\x1a#.codexteam.v1.InterruptTaskResponse\x12F\n" +
+	"\vWatchEvents\x12 .codexteam.v1.WatchEventsRequest\x1a\x13.codexteam.v1.Event0\x01\x12Z\n" +
+	"\x10StreamTranscript\x12%.codexteam.v1.StreamTranscriptRequest\x1a\x1d.codexteam.v1.TranscriptChunk0\x01B\x1dZ\x1bcodex-agent-team/pkg/teampbb\x06proto3"
+
+var (
+	file_pkg_teampb_team_proto_rawDescOnce sync.Once
+	file_pkg_teampb_team_proto_rawDescData []byte
+)
+
+func file_pkg_teampb_team_proto_rawDescGZIP() []byte {
+	file_pkg_teampb_team_proto_rawDescOnce.Do(func() {
+		file_pkg_teampb_team_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_teampb_team_proto_rawDesc), len(file_pkg_teampb_team_proto_rawDesc)))
+	})
+	return file_pkg_teampb_team_proto_rawDescData
+}
+
+var file_pkg_teampb_team_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_pkg_teampb_team_proto_goTypes = []any{
+	(*Session)(nil),                 // 0: codexteam.v1.Session
+	(*Repo)(nil),                    // 1: codexteam.v1.Repo
+	(*Task)(nil),                    // 2: codexteam.v1.Task
+	(*Dependency)(nil),              // 3: codexteam.v1.Dependency
+	(*Job)(nil),                     // 4: codexteam.v1.Job
+	(*Estimate)(nil),                // 5: codexteam.v1.Estimate
+	(*CreateSessionRequest)(nil),    // 6: codexteam.v1.CreateSessionRequest
+	(*GetSessionRequest)(nil),       // 7: codexteam.v1.GetSessionRequest
+	(*ListSessionsRequest)(nil),     // 8: codexteam.v1.ListSessionsRequest
+	(*ListSessionsResponse)(nil),    // 9: codexteam.v1.ListSessionsResponse
+	(*DeleteSessionRequest)(nil),    // 10: codexteam.v1.DeleteSessionRequest
+	(*DeleteSessionResponse)(nil),   // 11: codexteam.v1.DeleteSessionResponse
+	(*DecomposeRequest)(nil),        // 12: codexteam.v1.DecomposeRequest
+	(*ConfirmRequest)(nil),          // 13: codexteam.v1.ConfirmRequest
+	(*RunRequest)(nil),              // 14: codexteam.v1.RunRequest
+	(*GetJobRequest)(nil),           // 15: codexteam.v1.GetJobRequest
+	(*ListTasksRequest)(nil),        // 16: codexteam.v1.ListTasksRequest
+	(*ListTasksResponse)(nil),       // 17: codexteam.v1.ListTasksResponse
+	(*InterruptTaskRequest)(nil),    // 18: codexteam.v1.InterruptTaskRequest
+	(*InterruptTaskResponse)(nil),   // 19: codexteam.v1.InterruptTaskResponse
+	(*WatchEventsRequest)(nil),      // 20: codexteam.v1.WatchEventsRequest
+	(*Event)(nil),                   // 21: codexteam.v1.Event
+	(*StreamTranscriptRequest)(nil), // 22: codexteam.v1.StreamTranscriptRequest
+	(*TranscriptChunk)(nil),         // 23: codexteam.v1.TranscriptChunk
+	(*timestamppb.Timestamp)(nil),   // 24: google.protobuf.Timestamp
+}
+var file_pkg_teampb_team_proto_depIdxs = []int32{
+	24, // 0: codexteam.v1.Session.created_at:type_name -> google.protobuf.Timestamp
+	24, // 1: codexteam.v1.Session.started_at:type_name -> google.protobuf.Timestamp
+	24, // 2: codexteam.v1.Session.completed_at:type_name -> google.protobuf.Timestamp
+	1,  // 3: codexteam.v1.Session.repo:type_name -> codexteam.v1.Repo
+	3,  // 4: codexteam.v1.Task.depends_on:type_name -> codexteam.v1.Dependency
+	24, // 5: codexteam.v1.Task.created_at:type_name -> google.protobuf.Timestamp
+	24, // 6: codexteam.v1.Task.started_at:type_name -> google.protobuf.Timestamp
+	24, // 7: codexteam.v1.Task.completed_at:type_name -> google.protobuf.Timestamp
+	24, // 8: codexteam.v1.Job.started_at:type_name -> google.protobuf.Timestamp
+	24, // 9: codexteam.v1.Job.finished_at:type_name -> google.protobuf.Timestamp
+	0,  // 10: codexteam.v1.ListSessionsResponse.sessions:type_name -> codexteam.v1.Session
+	2,  // 11: codexteam.v1.ListTasksResponse.tasks:type_name -> codexteam.v1.Task
+	6,  // 12: codexteam.v1.Team.CreateSession:input_type -> codexteam.v1.CreateSessionRequest
+	7,  // 13: codexteam.v1.Team.GetSession:input_type -> codexteam.v1.GetSessionRequest
+	8,  // 14: codexteam.v1.Team.ListSessions:input_type -> codexteam.v1.ListSessionsRequest
+	10, // 15: codexteam.v1.Team.DeleteSession:input_type -> codexteam.v1.DeleteSessionRequest
+	12, // 16: codexteam.v1.Team.Decompose:input_type -> codexteam.v1.DecomposeRequest
+	13, // 17: codexteam.v1.Team.Confirm:input_type -> codexteam.v1.ConfirmRequest
+	14, // 18: codexteam.v1.Team.Execute:input_type -> codexteam.v1.RunRequest
+	14, // 19: codexteam.v1.Team.Resume:input_type -> codexteam.v1.RunRequest
+	14, // 20: codexteam.v1.Team.Merge:input_type -> codexteam.v1.RunRequest
+	15, // 21: codexteam.v1.Team.GetJob:input_type -> codexteam.v1.GetJobRequest
+	16, // 22: codexteam.v1.Team.ListTasks:input_type -> codexteam.v1.ListTasksRequest
+	18, // 23: codexteam.v1.Team.InterruptTask:input_type -> codexteam.v1.InterruptTaskRequest
+	20, // 24: codexteam.v1.Team.WatchEvents:input_type -> codexteam.v1.WatchEventsRequest
+	22, // 25: codexteam.v1.Team.StreamTranscript:input_type -> codexteam.v1.StreamTranscriptRequest
+	0,  // 26: codexteam.v1.Team.CreateSession:output_type -> codexteam.v1.Session
+	0,  // 27: codexteam.v1.Team.GetSession:output_type -> codexteam.v1.Session
+	9,  // 28: codexteam.v1.Team.ListSessions:output_type -> codexteam.v1.ListSessionsResponse
+	11, // 29: codexteam.v1.Team.DeleteSession:output_type -> codexteam.v1.DeleteSessionResponse
+	4,  // 30: codexteam.v1.Team.Decompose:output_type -> codexteam.v1.Job
+	5,  // 31: codexteam.v1.Team.Confirm:output_type -> codexteam.v1.Estimate
+	4,  // 32: codexteam.v1.Team.Execute:output_type -> codexteam.v1.Job
+	4,  // 33: codexteam.v1.Team.Resume:output_type -> codexteam.v1.Job
+	4,  // 34: codexteam.v1.Team.Merge:output_type -> codexteam.v1.Job
+	4,  // 35: codexteam.v1.Team.GetJob:output_type -> codexteam.v1.Job
+	17, // 36: codexteam.v1.Team.ListTasks:output_type -> codexteam.v1.ListTasksResponse
+	19, // 37: codexteam.v1.Team.InterruptTask:output_type -> codexteam.v1.InterruptTaskResponse
+	21, // 38: codexteam.v1.Team.WatchEvents:output_type -> codexteam.v1.Event
+	23, // 39: codexteam.v1.Team.StreamTranscript:output_type -> codexteam.v1.TranscriptChunk
+	26, // [26:40] is the sub-list for method output_type
+	12, // [12:26] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_pkg_teampb_team_proto_init() }
+func file_pkg_teampb_team_proto_init() {
+	if File_pkg_teampb_team_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_teampb_team_proto_rawDesc), len(file_pkg_teampb_team_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_teampb_team_proto_goTypes,
+		DependencyIndexes: file_pkg_teampb_team_proto_depIdxs,
+		MessageInfos:      file_pkg_teampb_team_proto_msgTypes,
+	}.Build()
+	File_pkg_teampb_team_proto = out.File
+	file_pkg_teampb_team_proto_goTypes = nil
+	file_pkg_teampb_team_proto_depIdxs = nil
+}
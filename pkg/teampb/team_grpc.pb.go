@@ -0,0 +1,655 @@
+// gRPC API of Codex Agent Team. It mirrors the session and task routes of
+// the HTTP API under /api/v1 and streams what the WebSocket sends.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pkg/teampb/team.proto
+
+package teampb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Team_CreateSession_FullMethodName    = "/codexteam.v1.Team/CreateSession"
+	Team_GetSession_FullMethodName       = "/codexteam.v1.Team/GetSession"
+	Team_ListSessions_FullMethodName     = "/codexteam.v1.Team/ListSessions"
+	Team_DeleteSession_FullMethodName    = "/codexteam.v1.Team/DeleteSession"
+	Team_Decompose_FullMethodName        = "/codexteam.v1.Team/Decompose"
+	Team_Confirm_FullMethodName          = "/codexteam.v1.Team/Confirm"
+	Team_Execute_FullMethodName          = "/codexteam.v1.Team/Execute"
+	Team_Resume_FullMethodName           = "/codexteam.v1.Team/Resume"
+	Team_Merge_FullMethodName            = "/codexteam.v1.Team/Merge"
+	Team_GetJob_FullMethodName           = "/codexteam.v1.Team/GetJob"
+	Team_ListTasks_FullMethodName        = "/codexteam.v1.Team/ListTasks"
+	Team_InterruptTask_FullMethodName    = "/codexteam.v1.Team/InterruptTask"
+	Team_WatchEvents_FullMethodName      = "/codexteam.v1.Team/WatchEvents"
+	Team_StreamTranscript_FullMethodName = "/codexteam.v1.Team/StreamTranscript"
+)
+
+// TeamClient is the client API for Team service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Team runs sessions: a user task is decomposed into a DAG of tasks,
+// executed by parallel agents and merged back.
+//
+// With tenants configured every call needs the tenant's API key in the
+// "authorization" metadata ("Bearer <key>") or in "x-api-key", and sees
+// only the tenant's sessions. Errors carry a google.rpc.ErrorInfo whose
+// reason is the HTTP API's error code, e.g. "SESSION_NOT_FOUND".
+type TeamClient interface {
+	CreateSession(ctx context.Context, in *CreateSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	DeleteSession(ctx context.Context, in *DeleteSessionRequest, opts ...grpc.CallOption) (*DeleteSessionResponse, error)
+	// Decompose, Execute, Resume and Merge start a background job and
+	// return it right away; follow it with GetJob or WatchEvents.
+	Decompose(ctx context.Context, in *DecomposeRequest, opts ...grpc.CallOption) (*Job, error)
+	Confirm(ctx context.Context, in *ConfirmRequest, opts ...grpc.CallOption) (*Estimate, error)
+	Execute(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*Job, error)
+	Resume(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*Job, error)
+	Merge(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*Job, error)
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error)
+	InterruptTask(ctx context.Context, in *InterruptTaskRequest, opts ...grpc.CallOption) (*InterruptTaskResponse, error)
+	// WatchEvents streams a session's live events, the same ones the
+	// WebSocket sends, until the client cancels.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// StreamTranscript streams the agent output of a task: first what was
+	// produced so far, then, with follow, new output until the task ends.
+	StreamTranscript(ctx context.Context, in *StreamTranscriptRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TranscriptChunk], error)
+}
+
+type teamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTeamClient(cc grpc.ClientConnInterface) TeamClient {
+	return &teamClient{cc}
+}
+
+func (c *teamClient) CreateSession(ctx context.Context, in *CreateSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Team_CreateSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Team_GetSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, Team_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) DeleteSession(ctx context.Context, in *DeleteSessionRequest, opts ...grpc.CallOption) (*DeleteSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSessionResponse)
+	err := c.cc.Invoke(ctx, Team_DeleteSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) Decompose(ctx context.Context, in *DecomposeRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Team_Decompose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) Confirm(ctx context.Context, in *ConfirmRequest, opts ...grpc.CallOption) (*Estimate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Estimate)
+	err := c.cc.Invoke(ctx, Team_Confirm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) Execute(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Team_Execute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) Resume(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Team_Resume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) Merge(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Team_Merge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Team_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTasksResponse)
+	err := c.cc.Invoke(ctx, Team_ListTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) InterruptTask(ctx context.Context, in *InterruptTaskRequest, opts ...grpc.CallOption) (*InterruptTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InterruptTaskResponse)
+	err := c.cc.Invoke(ctx, Team_InterruptTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *teamClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Team_ServiceDesc.Streams[0], Team_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Team_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *teamClient) StreamTranscript(ctx context.Context, in *StreamTranscriptRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TranscriptChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Team_ServiceDesc.Streams[1], Team_StreamTranscript_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTranscriptRequest, TranscriptChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Team_StreamTranscriptClient = grpc.ServerStreamingClient[TranscriptChunk]
+
+// TeamServer is the server API for Team service.
+// All implementations must embed UnimplementedTeamServer
+// for forward compatibility.
+//
+// Team runs sessions: a user task is decomposed into a DAG of tasks,
+// executed by parallel agents and merged back.
+//
+// With tenants configured every call needs the tenant's API key in the
+// "authorization" metadata ("Bearer <key>") or in "x-api-key", and sees
+// only the tenant's sessions. Errors carry a google.rpc.ErrorInfo whose
+// reason is the HTTP API's error code, e.g. "SESSION_NOT_FOUND".
+type TeamServer interface {
+	CreateSession(context.Context, *CreateSessionRequest) (*Session, error)
+	GetSession(context.Context, *GetSessionRequest) (*Session, error)
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	DeleteSession(context.Context, *DeleteSessionRequest) (*DeleteSessionResponse, error)
+	// Decompose, Execute, Resume and Merge start a background job and
+	// return it right away; follow it with GetJob or WatchEvents.
+	Decompose(context.Context, *DecomposeRequest) (*Job, error)
+	Confirm(context.Context, *ConfirmRequest) (*Estimate, error)
+	Execute(context.Context, *RunRequest) (*Job, error)
+	Resume(context.Context, *RunRequest) (*Job, error)
+	Merge(context.Context, *RunRequest) (*Job, error)
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error)
+	InterruptTask(context.Context, *InterruptTaskRequest) (*InterruptTaskResponse, error)
+	// WatchEvents streams a session's live events, the same ones the
+	// WebSocket sends, until the client cancels.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	// StreamTranscript streams the agent output of a task: first what was
+	// produced so far, then, with follow, new output until the task ends.
+	StreamTranscript(*StreamTranscriptRequest, grpc.ServerStreamingServer[TranscriptChunk]) error
+	mustEmbedUnimplementedTeamServer()
+}
+
+// UnimplementedTeamServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTeamServer struct{}
+
+func (UnimplementedTeamServer) CreateSession(context.Context, *CreateSessionRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSession not implemented")
+}
+func (UnimplementedTeamServer) GetSession(context.Context, *GetSessionRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSession not implemented")
+}
+func (UnimplementedTeamServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedTeamServer) DeleteSession(context.Context, *DeleteSessionRequest) (*DeleteSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSession not implemented")
+}
+func (UnimplementedTeamServer) Decompose(context.Context, *DecomposeRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Decompose not implemented")
+}
+func (UnimplementedTeamServer) Confirm(context.Context, *ConfirmRequest) (*Estimate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Confirm not implemented")
+}
+func (UnimplementedTeamServer) Execute(context.Context, *RunRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
+}
+func (UnimplementedTeamServer) Resume(context.Context, *RunRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedTeamServer) Merge(context.Context, *RunRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Merge not implemented")
+}
+func (UnimplementedTeamServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedTeamServer) ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (UnimplementedTeamServer) InterruptTask(context.Context, *InterruptTaskRequest) (*InterruptTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InterruptTask not implemented")
+}
+func (UnimplementedTeamServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedTeamServer) StreamTranscript(*StreamTranscriptRequest, grpc.ServerStreamingServer[TranscriptChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTranscript not implemented")
+}
+func (UnimplementedTeamServer) mustEmbedUnimplementedTeamServer() {}
+func (UnimplementedTeamServer) testEmbeddedByValue()              {}
+
+// UnsafeTeamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TeamServer will
+// result in compilation errors.
+type UnsafeTeamServer interface {
+	mustEmbedUnimplementedTeamServer()
+}
+
+func RegisterTeamServer(s grpc.ServiceRegistrar, srv TeamServer) {
+	// If the following call pancis, it indicates UnimplementedTeamServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Team_ServiceDesc, srv)
+}
+
+func _Team_CreateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).CreateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_CreateSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).CreateSession(ctx, req.(*CreateSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).GetSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_GetSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).GetSession(ctx, req.(*GetSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_DeleteSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).DeleteSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_DeleteSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).DeleteSession(ctx, req.(*DeleteSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_Decompose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecomposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).Decompose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_Decompose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).Decompose(ctx, req.(*DecomposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_Confirm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).Confirm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_Confirm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).Confirm(ctx, req.(*ConfirmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_Execute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).Execute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_Execute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).Execute(ctx, req.(*RunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).Resume(ctx, req.(*RunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_Merge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).Merge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_Merge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).Merge(ctx, req.(*RunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_InterruptTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InterruptTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TeamServer).InterruptTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Team_InterruptTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TeamServer).InterruptTask(ctx, req.(*InterruptTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Team_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TeamServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Team_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+func _Team_StreamTranscript_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTranscriptRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TeamServer).StreamTranscript(m, &grpc.GenericServerStream[StreamTranscriptRequest, TranscriptChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Team_StreamTranscriptServer = grpc.ServerStreamingServer[TranscriptChunk]
+
+// Team_ServiceDesc is the grpc.ServiceDesc for Team service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Team_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "codexteam.v1.Team",
+	HandlerType: (*TeamServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateSession",
+			Handler:    _Team_CreateSession_Handler,
+		},
+		{
+			MethodName: "GetSession",
+			Handler:    _Team_GetSession_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _Team_ListSessions_Handler,
+		},
+		{
+			MethodName: "DeleteSession",
+			Handler:    _Team_DeleteSession_Handler,
+		},
+		{
+			MethodName: "Decompose",
+			Handler:    _Team_Decompose_Handler,
+		},
+		{
+			MethodName: "Confirm",
+			Handler:    _Team_Confirm_Handler,
+		},
+		{
+			MethodName: "Execute",
+			Handler:    _Team_Execute_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Team_Resume_Handler,
+		},
+		{
+			MethodName: "Merge",
+			Handler:    _Team_Merge_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Team_GetJob_Handler,
+		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _Team_ListTasks_Handler,
+		},
+		{
+			MethodName: "InterruptTask",
+			Handler:    _Team_InterruptTask_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Team_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamTranscript",
+			Handler:       _Team_StreamTranscript_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/teampb/team.proto",
+}
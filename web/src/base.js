@@ -0,0 +1,23 @@
+// Path prefix the server is mounted under (e.g. "/codex-team"), injected
+// into index.html by the server. Empty when served from the root.
+export const BASE = (window.__BASE_PATH__ || '').replace(/\/$/, '')
+
+// Versioned API and WebSocket roots. The unversioned /api and /ws paths
+// still work but are deprecated.
+export const API = `${BASE}/api/v1`
+export const WS = `${BASE}/ws/v1`
+
+// apiError turns an error response into an Error carrying the server's
+// message, its machine-readable code (e.g. "INVALID_STATE") and details.
+export async function apiError(res, fallback) {
+  let body = null
+  try {
+    body = await res.json()
+  } catch {
+    // Not a JSON error body
+  }
+  const err = new Error(body?.message || fallback)
+  err.code = body?.code || ''
+  err.details = body?.details
+  return err
+}
@@ -1,4 +1,5 @@
 import { ref, onUnmounted } from 'vue'
+import { WS } from '../base'
 
 export function useWebSocket(sessionId) {
   const connected = ref(false)
@@ -9,7 +10,7 @@ export function useWebSocket(sessionId) {
   const connect = () => {
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
     const host = window.location.host
-    ws = new WebSocket(`${protocol}//${host}/ws/sessions/${sessionId}`)
+    ws = new WebSocket(`${protocol}//${host}${WS}/sessions/${sessionId}`)
 
     ws.onopen = () => {
       connected.value = true
@@ -2,6 +2,7 @@ import { createApp } from 'vue'
 import { createRouter, createWebHistory } from 'vue-router'
 import { createPinia } from 'pinia'
 import App from './App.vue'
+import { BASE } from './base'
 import HomeView from './views/HomeView.vue'
 import SessionView from './views/SessionView.vue'
 
@@ -11,7 +12,7 @@ const routes = [
 ]
 
 const router = createRouter({
-  history: createWebHistory(),
+  history: createWebHistory(BASE + '/'),
   routes
 })
 
@@ -1,5 +1,6 @@
 import { defineStore } from 'pinia'
 import { ref } from 'vue'
+import { API, apiError } from '../base'
 
 export const useSessionStore = defineStore('session', () => {
   const currentSession = ref(null)
@@ -7,18 +8,16 @@ export const useSessionStore = defineStore('session', () => {
   const loading = ref(false)
   const error = ref(null)
 
-  const API_BASE = '/api'
-
   async function createSession(userTask) {
     loading.value = true
     error.value = null
     try {
-      const response = await fetch(`${API_BASE}/sessions`, {
+      const response = await fetch(`${API}/sessions`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({ userTask })
       })
-      if (!response.ok) throw new Error('Failed to create session')
+      if (!response.ok) throw await apiError(response, 'Failed to create session')
       const session = await response.json()
       currentSession.value = session
       return session
@@ -34,8 +33,8 @@ export const useSessionStore = defineStore('session', () => {
     loading.value = true
     error.value = null
     try {
-      const response = await fetch(`${API_BASE}/sessions/${id}`)
-      if (!response.ok) throw new Error('Failed to get session')
+      const response = await fetch(`${API}/sessions/${id}`)
+      if (!response.ok) throw await apiError(response, 'Failed to get session')
       const session = await response.json()
       currentSession.value = session
       return session
@@ -51,10 +50,10 @@ export const useSessionStore = defineStore('session', () => {
     loading.value = true
     error.value = null
     try {
-      const response = await fetch(`${API_BASE}/sessions/${id}/decompose`, {
+      const response = await fetch(`${API}/sessions/${id}/decompose`, {
         method: 'POST'
       })
-      if (!response.ok) throw new Error('Failed to decompose')
+      if (!response.ok) throw await apiError(response, 'Failed to decompose')
       return await response.json()
     } catch (e) {
       error.value = e.message
@@ -68,10 +67,10 @@ export const useSessionStore = defineStore('session', () => {
     loading.value = true
     error.value = null
     try {
-      const response = await fetch(`${API_BASE}/sessions/${id}/execute`, {
+      const response = await fetch(`${API}/sessions/${id}/execute`, {
         method: 'POST'
       })
-      if (!response.ok) throw new Error('Failed to execute')
+      if (!response.ok) throw await apiError(response, 'Failed to execute')
       return await response.json()
     } catch (e) {
       error.value = e.message
@@ -85,10 +84,10 @@ export const useSessionStore = defineStore('session', () => {
     loading.value = true
     error.value = null
     try {
-      const response = await fetch(`${API_BASE}/sessions/${id}/merge`, {
+      const response = await fetch(`${API}/sessions/${id}/merge`, {
         method: 'POST'
       })
-      if (!response.ok) throw new Error('Failed to merge')
+      if (!response.ok) throw await apiError(response, 'Failed to merge')
       return await response.json()
     } catch (e) {
       error.value = e.message
@@ -102,8 +101,8 @@ export const useSessionStore = defineStore('session', () => {
     loading.value = true
     error.value = null
     try {
-      const response = await fetch(`${API_BASE}/sessions/${id}/tasks`)
-      if (!response.ok) throw new Error('Failed to get tasks')
+      const response = await fetch(`${API}/sessions/${id}/tasks`)
+      if (!response.ok) throw await apiError(response, 'Failed to get tasks')
       const tasksData = await response.json()
       tasks.value = tasksData
       return tasksData